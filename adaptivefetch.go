@@ -0,0 +1,119 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include "dpiImpl.h"
+*/
+import "C"
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// adaptiveFetchStart is the initial fetch array size with AdaptiveFetch.
+	adaptiveFetchStart = 1
+	// adaptiveFetchTarget is the consumption time a fetch array should last, when grown.
+	adaptiveFetchTarget = 100 * time.Millisecond
+	// adaptiveFetchMaxGrowth is the maximal growth factor of the fetch array size at once.
+	adaptiveFetchMaxGrowth = 8
+)
+
+// adaptiveFetchSizes sets the initial and maximal fetch array size of r
+// for the row width computed from vis, and returns the initial size (the length of the fetch variables).
+func adaptiveFetchSizes(vis []varInfo, maxBytes int, r *rows) int {
+	width := 0
+	for _, vi := range vis {
+		width += vi.BufSize + C.sizeof_dpiData
+	}
+	n := 1 << 16
+	if width > 0 && maxBytes/width < n {
+		n = maxBytes / width
+	}
+	if n < 1 {
+		n = 1
+	}
+	r.maxFetchSize, r.fetchSize = C.uint32_t(n), adaptiveFetchStart
+	if r.fetchSize > r.maxFetchSize {
+		r.fetchSize = r.maxFetchSize
+	}
+	r.fetchVis = vis
+	return int(r.fetchSize)
+}
+
+// nextFetchSize returns the fetch array size for the next fetch, after consumed rows are consumed in dur:
+// enough rows to last adaptiveFetchTarget at the observed rate, at least cur,
+// at most adaptiveFetchMaxGrowth times cur and max.
+func nextFetchSize(cur, max, consumed uint32, dur time.Duration) uint32 {
+	if cur == 0 || consumed < cur || cur >= max {
+		return cur
+	}
+	n := uint64(cur) * adaptiveFetchMaxGrowth
+	if dur > 0 {
+		if want := uint64(consumed) * uint64(adaptiveFetchTarget) / uint64(dur); want < n {
+			n = want
+		}
+	}
+	if n < uint64(cur) {
+		n = uint64(cur)
+	}
+	if n > uint64(max) {
+		n = uint64(max)
+	}
+	return uint32(n)
+}
+
+// growFetchSize grows the fetch array size for the next fetch, when the rows of the previous fetch
+// (a full fetch array) have been consumed - re-allocating the fetch variables for the new size.
+//
+// Must be called with r.statement locked.
+func (r *rows) growFetchSize() error {
+	if r.lastFetched == 0 {
+		return nil
+	}
+	now := getClock().Now()
+	n := C.uint32_t(nextFetchSize(uint32(r.fetchSize), uint32(r.maxFetchSize), uint32(r.lastFetched), now.Sub(r.lastFetchAt)))
+	if n == r.fetchSize {
+		return nil
+	}
+	vars := make([]*C.dpiVar, len(r.fetchVis))
+	datas := make([][]C.dpiData, len(r.fetchVis))
+	for i, vi := range r.fetchVis {
+		vi.SliceLen = int(n)
+		var err error
+		if vars[i], datas[i], err = r.statement.conn.newVar(vi); err == nil {
+			err = r.statement.checkExecNoLOT(func() C.int {
+				return C.dpiStmt_define(r.dpiStmt, C.uint32_t(i+1), vars[i])
+			})
+		}
+		if err != nil {
+			for _, v := range vars[:i+1] {
+				if v != nil {
+					C.dpiVar_release(v)
+				}
+			}
+			// the columns defined already hold a reference to their new variable, so restore the old ones
+			for j := 0; j < i; j++ {
+				_ = C.dpiStmt_define(r.dpiStmt, C.uint32_t(j+1), r.vars[j])
+			}
+			return fmt.Errorf("grow fetch array to %d: %w", n, err)
+		}
+	}
+	for i, v := range r.vars {
+		r.statement.mem.addVar(true, vars[i], len(datas[i]))
+		r.statement.mem.releaseVar(v)
+		r.vars[i], r.data[i] = vars[i], datas[i]
+	}
+	if err := r.statement.checkExecNoLOT(func() C.int {
+		return C.dpiStmt_setFetchArraySize(r.dpiStmt, n)
+	}); err != nil {
+		return fmt.Errorf("setFetchArraySize(%d): %w", n, err)
+	}
+	r.fetchSize = n
+	return nil
+}
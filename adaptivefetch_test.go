@@ -0,0 +1,54 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdaptiveFetchSizes(t *testing.T) {
+	vis := []varInfo{{BufSize: 1000}, {BufSize: 3000}}
+	for i, tc := range []struct {
+		MaxBytes int
+		Max      int
+	}{
+		{MaxBytes: 1, Max: 1},
+		{MaxBytes: 40 << 10, Max: 10},
+		{MaxBytes: 4 << 20, Max: 1040},
+		{MaxBytes: 1 << 40, Max: 1 << 16},
+	} {
+		var r rows
+		n := adaptiveFetchSizes(vis, tc.MaxBytes, &r)
+		if m := int(r.maxFetchSize); m < tc.Max-tc.Max/10 || m > tc.Max {
+			t.Errorf("%d. got max=%d, wanted ~%d", i, m, tc.Max)
+		}
+		if n != adaptiveFetchStart || int(r.fetchSize) != n || len(r.fetchVis) != len(vis) {
+			t.Errorf("%d. got start=%d (%d), wanted %d", i, n, r.fetchSize, adaptiveFetchStart)
+		}
+	}
+}
+
+func TestNextFetchSize(t *testing.T) {
+	for i, tc := range []struct {
+		Dur                      time.Duration
+		Cur, Max, Consumed, Want uint32
+	}{
+		{Cur: 1, Max: 1000, Consumed: 1, Dur: time.Microsecond, Want: adaptiveFetchMaxGrowth}, // fast: max growth
+		{Cur: 100, Max: 1000, Consumed: 100, Dur: time.Microsecond, Want: 800},
+		{Cur: 100, Max: 500, Consumed: 100, Dur: time.Microsecond, Want: 500},         // capped by memory
+		{Cur: 100, Max: 1000, Consumed: 100, Dur: 50 * time.Millisecond, Want: 200},   // 2000 rows/s
+		{Cur: 100, Max: 1000, Consumed: 100, Dur: 25 * time.Millisecond, Want: 400},   // 4000 rows/s
+		{Cur: 100, Max: 1000, Consumed: 100, Dur: time.Second, Want: 100},             // slow consumer: no growth
+		{Cur: 100, Max: 1000, Consumed: 10, Dur: time.Microsecond, Want: 100},         // partial fetch
+		{Cur: 1000, Max: 1000, Consumed: 1000, Dur: time.Microsecond, Want: 1000},     // at max
+		{Cur: 10, Max: 1000, Consumed: 10, Dur: 0, Want: 10 * adaptiveFetchMaxGrowth}, // no timing
+	} {
+		if got := nextFetchSize(tc.Cur, tc.Max, tc.Consumed, tc.Dur); got != tc.Want {
+			t.Errorf("%d. got %d, wanted %d", i, got, tc.Want)
+		}
+	}
+}
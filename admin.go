@@ -0,0 +1,91 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/godror/godror/dsn"
+)
+
+// StartupState is the state StartupDatabase brings the database to.
+type StartupState uint8
+
+const (
+	// StartupNoMount only starts the instance ("startup nomount").
+	StartupNoMount = StartupState(iota)
+	// StartupMount starts the instance and mounts the database ("startup mount").
+	StartupMount
+	// StartupOpen starts, mounts and opens the database ("startup").
+	StartupOpen
+	// StartupOpenReadOnly starts, mounts and opens the database read-only.
+	StartupOpenReadOnly
+)
+
+// StartupDatabase starts the database described by P, just as SQL*Plus "startup" does:
+// starts the instance on a preliminary connection, then mounts and opens the database (as state says).
+//
+// P needs an administrative role (SYSDBA or SYSOPER), SYSDBA is used if none is given.
+func StartupDatabase(ctx context.Context, P dsn.ConnectionParams, mode StartupMode, state StartupState) error {
+	if !(P.IsSysDBA || P.IsSysOper) {
+		P.IsSysDBA = true
+	}
+	P.IsPrelim = true
+	if err := withAdminConn(ctx, P, func(conn *sql.Conn) error {
+		return Raw(ctx, conn, func(c Conn) error { return c.Startup(mode) })
+	}); err != nil || state == StartupNoMount {
+		return err
+	}
+
+	// The database cannot be altered on the preliminary connection.
+	P.IsPrelim = false
+	return withAdminConn(ctx, P, func(conn *sql.Conn) error {
+		qrys := []string{"ALTER DATABASE MOUNT"}
+		switch state {
+		case StartupOpen:
+			qrys = append(qrys, "ALTER DATABASE OPEN")
+		case StartupOpenReadOnly:
+			qrys = append(qrys, "ALTER DATABASE OPEN READ ONLY")
+		}
+		for _, qry := range qrys {
+			if _, err := conn.ExecContext(ctx, qry); err != nil {
+				return fmt.Errorf("%s: %w", qry, err)
+			}
+		}
+		return nil
+	})
+}
+
+// ShutdownDatabase shuts down the database, just as SQL*Plus "shutdown" does:
+// after the first Shutdown(mode), closes and dismounts the database, then finishes with Shutdown(ShutdownFinal).
+// With ShutdownAbort, the first step is enough.
+//
+// ex must be connected with SYSDBA or SYSOPER - use an *sql.Conn, as all the steps need the same session.
+func ShutdownDatabase(ctx context.Context, ex Execer, mode ShutdownMode) error {
+	if err := Raw(ctx, ex, func(c Conn) error { return c.Shutdown(mode) }); err != nil || mode == ShutdownAbort {
+		return err
+	}
+	for _, qry := range []string{"ALTER DATABASE CLOSE NORMAL", "ALTER DATABASE DISMOUNT"} {
+		if _, err := ex.ExecContext(ctx, qry); err != nil {
+			return fmt.Errorf("%s: %w", qry, err)
+		}
+	}
+	return Raw(ctx, ex, func(c Conn) error { return c.Shutdown(ShutdownFinal) })
+}
+
+// withAdminConn calls f with a connection opened with P.
+func withAdminConn(ctx context.Context, P dsn.ConnectionParams, f func(*sql.Conn) error) error {
+	db := sql.OpenDB(NewConnector(P))
+	defer db.Close()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("connect %s: %w", P, err)
+	}
+	defer conn.Close()
+	return f(conn)
+}
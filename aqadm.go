@@ -0,0 +1,184 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// QueueTableOptions are the options of CreateQueueTable.
+type QueueTableOptions struct {
+	// Comment of the queue table.
+	Comment string
+	// SortList is such as "PRIORITY,ENQ_TIME".
+	SortList string
+	// MultipleConsumers allows subscribers and recipient lists.
+	MultipleConsumers bool
+}
+
+// CreateQueueTable creates a queue table with DBMS_AQADM.CREATE_QUEUE_TABLE,
+// for payloadType ("RAW", "JSON" or an object type name).
+func CreateQueueTable(ctx context.Context, ex Execer, name, payloadType string, opts QueueTableOptions) error {
+	var buf strings.Builder
+	buf.WriteString("BEGIN SYS.DBMS_AQADM.CREATE_QUEUE_TABLE(queue_table=>:queue_table, queue_payload_type=>:payload_type")
+	args := []interface{}{sql.Named("queue_table", name), sql.Named("payload_type", payloadType)}
+	if opts.SortList != "" {
+		buf.WriteString(", sort_list=>:sort_list")
+		args = append(args, sql.Named("sort_list", opts.SortList))
+	}
+	if opts.MultipleConsumers {
+		buf.WriteString(", multiple_consumers=>TRUE")
+	}
+	if opts.Comment != "" {
+		buf.WriteString(", comment=>:comment")
+		args = append(args, sql.Named("comment", opts.Comment))
+	}
+	buf.WriteString("); END;")
+	qry := buf.String()
+	if _, err := ex.ExecContext(ctx, qry, args...); err != nil {
+		return fmt.Errorf("%s: %w", qry, err)
+	}
+	return nil
+}
+
+// DropQueueTable drops the queue table. With force, its queues are stopped and dropped, too.
+func DropQueueTable(ctx context.Context, ex Execer, name string, force bool) error {
+	qry := "BEGIN SYS.DBMS_AQADM.DROP_QUEUE_TABLE(queue_table=>:1, force=>FALSE); END;"
+	if force {
+		qry = "BEGIN SYS.DBMS_AQADM.DROP_QUEUE_TABLE(queue_table=>:1, force=>TRUE); END;"
+	}
+	if _, err := ex.ExecContext(ctx, qry, name); err != nil {
+		return fmt.Errorf("%s: %w", qry, err)
+	}
+	return nil
+}
+
+// QueueOptions are the options of CreateQueue.
+type QueueOptions struct {
+	// Comment of the queue.
+	Comment string
+	// MaxRetries is the number of dequeue attempts (rollbacks) before the message is moved to the exception queue.
+	MaxRetries int
+	// RetryDelay is the delay before a rolled back message can be dequeued again.
+	RetryDelay time.Duration
+	// RetentionTime is how long the dequeued messages are kept.
+	RetentionTime time.Duration
+}
+
+// CreateQueue creates a queue in the queue table with DBMS_AQADM.CREATE_QUEUE.
+// The queue must be started with StartQueue.
+func CreateQueue(ctx context.Context, ex Execer, name, queueTable string, opts QueueOptions) error {
+	var buf strings.Builder
+	buf.WriteString("BEGIN SYS.DBMS_AQADM.CREATE_QUEUE(queue_name=>:queue_name, queue_table=>:queue_table")
+	args := []interface{}{sql.Named("queue_name", name), sql.Named("queue_table", queueTable)}
+	if opts.MaxRetries > 0 {
+		buf.WriteString(", max_retries=>:max_retries")
+		args = append(args, sql.Named("max_retries", opts.MaxRetries))
+	}
+	if opts.RetryDelay > 0 {
+		buf.WriteString(", retry_delay=>:retry_delay")
+		args = append(args, sql.Named("retry_delay", int64(opts.RetryDelay/time.Second)))
+	}
+	if opts.RetentionTime > 0 {
+		buf.WriteString(", retention_time=>:retention_time")
+		args = append(args, sql.Named("retention_time", int64(opts.RetentionTime/time.Second)))
+	}
+	if opts.Comment != "" {
+		buf.WriteString(", comment=>:comment")
+		args = append(args, sql.Named("comment", opts.Comment))
+	}
+	buf.WriteString("); END;")
+	qry := buf.String()
+	if _, err := ex.ExecContext(ctx, qry, args...); err != nil {
+		return fmt.Errorf("%s: %w", qry, err)
+	}
+	return nil
+}
+
+// DropQueue drops the (stopped) queue.
+func DropQueue(ctx context.Context, ex Execer, name string) error {
+	const qry = "BEGIN SYS.DBMS_AQADM.DROP_QUEUE(queue_name=>:1); END;"
+	if _, err := ex.ExecContext(ctx, qry, name); err != nil {
+		return fmt.Errorf("%s: %w", qry, err)
+	}
+	return nil
+}
+
+// StartQueue enables enqueue and dequeue on the queue.
+func StartQueue(ctx context.Context, ex Execer, name string) error {
+	const qry = "BEGIN SYS.DBMS_AQADM.START_QUEUE(queue_name=>:1); END;"
+	if _, err := ex.ExecContext(ctx, qry, name); err != nil {
+		return fmt.Errorf("%s: %w", qry, err)
+	}
+	return nil
+}
+
+// StopQueue disables enqueue and dequeue on the queue.
+// With wait, it waits for the outstanding transactions on the queue, otherwise fails if there are any.
+func StopQueue(ctx context.Context, ex Execer, name string, wait bool) error {
+	qry := "BEGIN SYS.DBMS_AQADM.STOP_QUEUE(queue_name=>:1, wait=>FALSE); END;"
+	if wait {
+		qry = "BEGIN SYS.DBMS_AQADM.STOP_QUEUE(queue_name=>:1, wait=>TRUE); END;"
+	}
+	if _, err := ex.ExecContext(ctx, qry, name); err != nil {
+		return fmt.Errorf("%s: %w", qry, err)
+	}
+	return nil
+}
+
+// QueueInfo describes a queue, as in the all_queues and all_queue_tables views.
+type QueueInfo struct {
+	Owner, Name, QueueTable string
+	// QueueType is NORMAL_QUEUE or EXCEPTION_QUEUE.
+	QueueType string
+	// PayloadType is RAW, JSON or the object type name.
+	PayloadType           string
+	RetryDelay, Retention time.Duration
+	MaxRetries            int
+	Enqueue, Dequeue      bool
+	MultipleConsumers     bool
+}
+
+// DescribeQueue returns the description of the queue (owner defaults to the current user).
+func DescribeQueue(ctx context.Context, q Querier, owner, name string) (QueueInfo, error) {
+	const qry = `SELECT q.owner, q.name, q.queue_table, q.queue_type,
+    NVL(t.object_type, t.type), q.retry_delay, NVL(q.retention, '0'), q.max_retries,
+    TRIM(q.enqueue_enabled), TRIM(q.dequeue_enabled), t.recipients
+  FROM all_queues q, all_queue_tables t
+  WHERE t.owner = q.owner AND t.queue_table = q.queue_table AND
+        q.owner = NVL(:1, USER) AND q.name = :2`
+	var qi QueueInfo
+	rows, err := q.QueryContext(ctx, qry, owner, name)
+	if err != nil {
+		return qi, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err = rows.Err(); err == nil {
+			err = sql.ErrNoRows
+		}
+		return qi, fmt.Errorf("%s: %w", qry, err)
+	}
+	var retryDelay float64
+	var retention, enq, deq, recipients string
+	if err = rows.Scan(&qi.Owner, &qi.Name, &qi.QueueTable, &qi.QueueType,
+		&qi.PayloadType, &retryDelay, &retention, &qi.MaxRetries,
+		&enq, &deq, &recipients,
+	); err != nil {
+		return qi, fmt.Errorf("%s: %w", qry, err)
+	}
+	qi.RetryDelay = time.Duration(retryDelay * float64(time.Second))
+	if secs, err := time.ParseDuration(strings.TrimSpace(retention) + "s"); err == nil {
+		qi.Retention = secs
+	}
+	qi.Enqueue, qi.Dequeue = enq == "YES", deq == "YES"
+	qi.MultipleConsumers = recipients == "MULTIPLE"
+	return qi, nil
+}
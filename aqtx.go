@@ -0,0 +1,52 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+)
+
+// WithTxQueue calls f with the queue opened on the connection of tx,
+// with VisibleOnCommit enqueue and dequeue, so the messages dequeued (and enqueued) in f
+// and the writes of tx commit (or roll back) atomically - exactly-once consumption.
+//
+// The queue (and the Objects of the dequeued messages) are valid only inside f,
+// and must not be used concurrently with tx.
+// The zero fields of deqOpts are taken from DefaultDeqOptions.
+//
+//	tx, _ := db.BeginTx(ctx, nil)
+//	defer tx.Rollback()
+//	err := WithTxQueue(ctx, tx, "orders_q", "", DeqOptions{}, func(Q *Queue) error {
+//		msgs := make([]Message, 10)
+//		n, err := Q.Dequeue(msgs)
+//		...
+//		_, err = tx.ExecContext(ctx, "INSERT INTO orders ...", ...)
+//		return err
+//	})
+//	if err == nil {
+//		err = tx.Commit()
+//	}
+func WithTxQueue(ctx context.Context, tx *sql.Tx, name, payloadObjectTypeName string, deqOpts DeqOptions, f func(*Queue) error) error {
+	enqOpts := DefaultEnqOptions
+	enqOpts.Visibility = VisibleOnCommit
+	if deqOpts.Mode == 0 {
+		deqOpts.Mode = DefaultDeqOptions.Mode
+	}
+	if deqOpts.DeliveryMode == 0 {
+		deqOpts.DeliveryMode = DefaultDeqOptions.DeliveryMode
+	}
+	if deqOpts.Navigation == 0 {
+		deqOpts.Navigation = DefaultDeqOptions.Navigation
+	}
+	deqOpts.Visibility = VisibleOnCommit
+	Q, err := NewQueue(ctx, tx, name, payloadObjectTypeName, WithEnqOptions(enqOpts), WithDeqOptions(deqOpts))
+	if err != nil {
+		return err
+	}
+	defer Q.Close()
+	return f(Q)
+}
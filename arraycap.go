@@ -0,0 +1,60 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxPlSQLArraySize is the maximum number of elements of a PL/SQL array parameter.
+const maxPlSQLArraySize = 1 << 16
+
+// ArrayCapacityError is returned (wrapping the ORA-06513 error) when a PL/SQL block
+// returns more elements in an OUT PL/SQL array than the capacity allocated for it.
+//
+// The capacity of an OUT PL/SQL array is the capacity of its destination slice
+// (such as make([]string, 0, 5000)), or ArraySize if that is zero.
+//
+// It unwraps to the original error, so AsOraErr works on it.
+type ArrayCapacityError struct {
+	Err error
+	// Capacities of the OUT PL/SQL array parameters, by their position (1-based).
+	Capacities map[int]int
+}
+
+func (ae *ArrayCapacityError) Error() string {
+	positions := make([]int, 0, len(ae.Capacities))
+	for pos := range ae.Capacities {
+		positions = append(positions, pos)
+	}
+	sort.Ints(positions)
+	var buf strings.Builder
+	for _, pos := range positions {
+		fmt.Fprintf(&buf, " %d.=%d", pos, ae.Capacities[pos])
+	}
+	return fmt.Sprintf("%v (OUT PL/SQL array capacities:%s - use destination slices with bigger capacity)", ae.Err, buf.String())
+}
+func (ae *ArrayCapacityError) Unwrap() error { return ae.Err }
+
+// withArrayCapacity returns err wrapped in an *ArrayCapacityError,
+// iff it is ORA-06513 (index for PL/SQL table out of range for host language array).
+func (st *statement) withArrayCapacity(err error) error {
+	if err == nil || !st.PlSQLArrays() {
+		return err
+	}
+	if oe, ok := AsOraErr(err); !ok || oe.Code() != 6513 {
+		return err
+	}
+	ae := ArrayCapacityError{Err: err, Capacities: make(map[int]int)}
+	for i, vi := range st.varInfos {
+		if vi.IsPLSArray && i < len(st.gets) && st.gets[i] != nil {
+			ae.Capacities[i+1] = vi.SliceLen
+		}
+	}
+	return &ae
+}
@@ -0,0 +1,45 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestArrayCapacityError(t *testing.T) {
+	oe := &OraErr{code: 6513, message: "PL/SQL: index for PL/SQL table out of range for host language array"}
+	noGet := dataGetter(nil)
+	get := dataGetter(dataGetBytes)
+	st := &statement{
+		varInfos: []varInfo{{SliceLen: 1}, {IsPLSArray: true, SliceLen: 5}, {IsPLSArray: true, SliceLen: 10}},
+		gets:     []dataGetter{get, noGet, get},
+	}
+	if err := st.withArrayCapacity(oe); err != error(oe) {
+		t.Errorf("got %v, wanted the original error without PlSQLArrays", err)
+	}
+
+	st.plSQLArrays = true
+	if err := st.withArrayCapacity(&OraErr{code: 1}); errors.As(err, new(*ArrayCapacityError)) {
+		t.Errorf("got %v for ORA-00001", err)
+	}
+	err := st.withArrayCapacity(fmt.Errorf("execute: %w", oe))
+	var ae *ArrayCapacityError
+	if !errors.As(err, &ae) {
+		t.Fatalf("got %v, wanted ArrayCapacityError", err)
+	}
+	if len(ae.Capacities) != 1 || ae.Capacities[3] != 10 {
+		t.Errorf("got %v, wanted only the OUT array 3.=10", ae.Capacities)
+	}
+	if got, ok := AsOraErr(err); !ok || got.Code() != 6513 {
+		t.Errorf("AsOraErr: got %v", got)
+	}
+	if s := err.Error(); !strings.Contains(s, "capacities: 3.=10 ") {
+		t.Errorf("got %q", s)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// AutotraceStats are the session statistics' changes during an Autotrace call,
+// as SQL*Plus AUTOTRACE shows them.
+type AutotraceStats struct {
+	// Stats contains all the statistics which changed, by their V$STATNAME name.
+	Stats map[string]int64
+
+	RecursiveCalls, DBBlockGets, ConsistentGets, PhysicalReads, RedoSize int64
+	BytesSent, BytesReceived, RoundTrips                                 int64
+	SortsMemory, SortsDisk                                               int64
+}
+
+func (as AutotraceStats) String() string {
+	return fmt.Sprintf("recursive calls=%d db block gets=%d consistent gets=%d physical reads=%d redo size=%d"+
+		" bytes sent=%d bytes received=%d roundtrips=%d sorts (memory)=%d sorts (disk)=%d",
+		as.RecursiveCalls, as.DBBlockGets, as.ConsistentGets, as.PhysicalReads, as.RedoSize,
+		as.BytesSent, as.BytesReceived, as.RoundTrips, as.SortsMemory, as.SortsDisk)
+}
+
+// Autotrace calls f, and returns the changes of the session statistics (V$MYSTAT) during it,
+// just as SQL*Plus AUTOTRACE does: execute and fetch the statement(s) to be measured in f.
+//
+// q must be an *sql.Conn or *sql.Tx, and f must use that, as the statistics are of its session.
+// The statistics include the round-trip of the second V$MYSTAT query.
+//
+// Needs SELECT privilege on V$MYSTAT and V$STATNAME.
+func Autotrace(ctx context.Context, q Querier, f func() error) (AutotraceStats, error) {
+	before, err := myStats(ctx, q)
+	if err != nil {
+		return AutotraceStats{}, err
+	}
+	if err = f(); err != nil {
+		return AutotraceStats{}, err
+	}
+	after, err := myStats(ctx, q)
+	if err != nil {
+		return AutotraceStats{}, err
+	}
+	return autotraceStats(before, after), nil
+}
+
+// autotraceStats returns the changes of the statistics from before to after.
+func autotraceStats(before, after map[string]int64) AutotraceStats {
+	as := AutotraceStats{Stats: make(map[string]int64)}
+	for name, v := range after {
+		if d := v - before[name]; d != 0 {
+			as.Stats[name] = d
+		}
+	}
+	for dest, name := range map[*int64]string{
+		&as.RecursiveCalls: "recursive calls",
+		&as.DBBlockGets:    "db block gets",
+		&as.ConsistentGets: "consistent gets",
+		&as.PhysicalReads:  "physical reads",
+		&as.RedoSize:       "redo size",
+		&as.BytesSent:      "bytes sent via SQL*Net to client",
+		&as.BytesReceived:  "bytes received via SQL*Net from client",
+		&as.RoundTrips:     "SQL*Net roundtrips to/from client",
+		&as.SortsMemory:    "sorts (memory)",
+		&as.SortsDisk:      "sorts (disk)",
+	} {
+		*dest = as.Stats[name]
+	}
+	return as
+}
+
+// myStats returns the non-zero statistics of the session.
+func myStats(ctx context.Context, q Querier) (map[string]int64, error) {
+	const qry = `SELECT N.name, S.value
+  FROM v$mystat S INNER JOIN v$statname N ON N.statistic# = S.statistic#
+  WHERE S.value <> 0`
+	rows, err := q.QueryContext(ctx, qry)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	stats := make(map[string]int64, 256)
+	for rows.Next() {
+		var name string
+		var value int64
+		if err = rows.Scan(&name, &value); err != nil {
+			return stats, fmt.Errorf("scan %s: %w", qry, err)
+		}
+		stats[strings.TrimSpace(name)] = value
+	}
+	return stats, rows.Err()
+}
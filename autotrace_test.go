@@ -0,0 +1,39 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAutotraceStats(t *testing.T) {
+	before := map[string]int64{
+		"consistent gets":                   100,
+		"SQL*Net roundtrips to/from client": 7,
+		"session pga memory":                1 << 20,
+		"user calls":                        3,
+	}
+	after := map[string]int64{
+		"consistent gets":                   142,
+		"SQL*Net roundtrips to/from client": 9,
+		"session pga memory":                1 << 20,
+		"user calls":                        5,
+		"sorts (memory)":                    1,
+	}
+	as := autotraceStats(before, after)
+	if want := map[string]int64{
+		"consistent gets":                   42,
+		"SQL*Net roundtrips to/from client": 2,
+		"user calls":                        2,
+		"sorts (memory)":                    1,
+	}; !reflect.DeepEqual(as.Stats, want) {
+		t.Errorf("got %v, wanted %v", as.Stats, want)
+	}
+	if as.ConsistentGets != 42 || as.RoundTrips != 2 || as.SortsMemory != 1 || as.PhysicalReads != 0 {
+		t.Errorf("got %s", as)
+	}
+}
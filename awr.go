@@ -0,0 +1,241 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+var diagnosticsPackLicensed uint32
+
+// SetDiagnosticsPackLicensed declares that the database is licensed for the Oracle Diagnostics Pack,
+// which is needed for the AWR and ASH views (DBA_HIST_*, V$ACTIVE_SESSION_HISTORY).
+//
+// Without it, TopSQLByElapsed and ASHSamples fall back to Statspack or the V$ views - see ProbePerfSource.
+func SetDiagnosticsPackLicensed(licensed bool) {
+	var u uint32
+	if licensed {
+		u = 1
+	}
+	atomic.StoreUint32(&diagnosticsPackLicensed, u)
+}
+
+// ErrNotLicensed is returned for AWR/ASH without SetDiagnosticsPackLicensed(true).
+var ErrNotLicensed = errors.New("the Diagnostics Pack is not declared as licensed (see SetDiagnosticsPackLicensed)")
+
+// PerfSource is the source of the performance helpers' data.
+type PerfSource uint8
+
+const (
+	// PerfSourceVViews is the V$ views (V$SQLSTATS, V$SESSION): no history, only the current state.
+	PerfSourceVViews = PerfSource(iota)
+	// PerfSourceStatspack is Statspack (the PERFSTAT schema), available on Standard Edition, too.
+	PerfSourceStatspack
+	// PerfSourceAWR is AWR and ASH, needing the Diagnostics Pack.
+	PerfSourceAWR
+)
+
+func (ps PerfSource) String() string {
+	switch ps {
+	case PerfSourceAWR:
+		return "AWR"
+	case PerfSourceStatspack:
+		return "Statspack"
+	default:
+		return "V$"
+	}
+}
+
+// ProbePerfSource returns the best available source for the performance helpers:
+// AWR with SetDiagnosticsPackLicensed(true), else Statspack if PERFSTAT.STATS$SNAPSHOT is readable,
+// else the V$ views.
+func ProbePerfSource(ctx context.Context, q Querier) PerfSource {
+	if checkDiagnosticsPack() == nil {
+		return PerfSourceAWR
+	}
+	const qry = "SELECT 1 FROM perfstat.stats$snapshot WHERE ROWNUM = 1"
+	rows, err := q.QueryContext(ctx, qry)
+	if err != nil {
+		return PerfSourceVViews
+	}
+	rows.Close()
+	return PerfSourceStatspack
+}
+
+func checkDiagnosticsPack() error {
+	if atomic.LoadUint32(&diagnosticsPackLicensed) == 0 {
+		return ErrNotLicensed
+	}
+	return nil
+}
+
+// TopSQL is a statement's summary from AWR.
+type TopSQL struct {
+	SQLID, Module, SQLText                  string
+	PlanHashValue                           int64
+	Executions, BufferGets, DiskReads, Rows int64
+	Elapsed, CPU                            time.Duration
+}
+
+// TopSQLByElapsed returns the statements with the most elapsed time in the [since, until) interval,
+// at most limit of them, from the source returned by ProbePerfSource:
+//
+//   - AWR: the snapshots overlapping the interval;
+//   - Statspack: the difference of the cumulative values of the first and last snapshot in the interval
+//     (without PlanHashValue);
+//   - V$SQLSTATS: the statements active since "since", with their totals since they were loaded.
+//
+// SQLText is the first 1000 characters of the statement.
+func TopSQLByElapsed(ctx context.Context, q Querier, since, until time.Time, limit int) ([]TopSQL, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	var qry string
+	switch ProbePerfSource(ctx, q) {
+	case PerfSourceAWR:
+		qry = topSQLAWR
+	case PerfSourceStatspack:
+		qry = topSQLStatspack
+	default:
+		qry = topSQLVViews
+	}
+	return topSQL(ctx, q, qry, since, until, limit)
+}
+
+const topSQLAWR = `SELECT X.sql_id, X.plan_hash_value, X.executions, X.elapsed, X.cpu,
+       X.buffer_gets, X.disk_reads, X.rows_processed, X.module,
+       (SELECT DBMS_LOB.SUBSTR(T.sql_text, 1000, 1) FROM dba_hist_sqltext T
+          WHERE T.dbid = X.dbid AND T.sql_id = X.sql_id) AS sql_text
+  FROM (SELECT S.dbid, S.sql_id, MAX(S.plan_hash_value) AS plan_hash_value,
+               SUM(S.executions_delta) AS executions,
+               SUM(S.elapsed_time_delta) AS elapsed, SUM(S.cpu_time_delta) AS cpu,
+               SUM(S.buffer_gets_delta) AS buffer_gets, SUM(S.disk_reads_delta) AS disk_reads,
+               SUM(S.rows_processed_delta) AS rows_processed, MAX(S.module) AS module
+          FROM dba_hist_sqlstat S
+            INNER JOIN dba_hist_snapshot N ON
+              N.dbid = S.dbid AND N.instance_number = S.instance_number AND N.snap_id = S.snap_id
+          WHERE N.end_interval_time > :since AND N.begin_interval_time < :until
+          GROUP BY S.dbid, S.sql_id
+          ORDER BY elapsed DESC) X
+  WHERE ROWNUM <= :limit`
+
+const topSQLStatspack = `SELECT * FROM (
+  SELECT S.sql_id, 0 AS plan_hash_value,
+         MAX(S.executions) - MIN(S.executions) AS executions,
+         MAX(S.elapsed_time) - MIN(S.elapsed_time) AS elapsed, MAX(S.cpu_time) - MIN(S.cpu_time) AS cpu,
+         MAX(S.buffer_gets) - MIN(S.buffer_gets) AS buffer_gets, MAX(S.disk_reads) - MIN(S.disk_reads) AS disk_reads,
+         MAX(S.rows_processed) - MIN(S.rows_processed) AS rows_processed, MAX(S.module) AS module,
+         MAX(S.text_subset) AS sql_text
+    FROM perfstat.stats$sql_summary S
+      INNER JOIN perfstat.stats$snapshot N ON
+        N.dbid = S.dbid AND N.instance_number = S.instance_number AND N.snap_id = S.snap_id
+    WHERE N.snap_time >= :since AND N.snap_time < :until
+    GROUP BY S.sql_id
+    ORDER BY elapsed DESC)
+  WHERE ROWNUM <= :limit`
+
+const topSQLVViews = `SELECT * FROM (
+  SELECT S.sql_id, S.plan_hash_value, S.executions, S.elapsed_time, S.cpu_time,
+         S.buffer_gets, S.disk_reads, S.rows_processed,
+         (SELECT MAX(Q.module) FROM v$sql Q WHERE Q.sql_id = S.sql_id) AS module,
+         SUBSTR(S.sql_text, 1, 1000) AS sql_text
+    FROM v$sqlstats S
+    WHERE S.last_active_time >= :since AND :until IS NOT NULL
+    ORDER BY S.elapsed_time DESC)
+  WHERE ROWNUM <= :limit`
+
+func topSQL(ctx context.Context, q Querier, qry string, since, until time.Time, limit int) ([]TopSQL, error) {
+	rows, err := q.QueryContext(ctx, qry,
+		sql.Named("since", since), sql.Named("until", until), sql.Named("limit", limit))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	var tops []TopSQL
+	for rows.Next() {
+		var t TopSQL
+		var elapsed, cpu int64
+		var module, text sql.NullString
+		if err = rows.Scan(&t.SQLID, &t.PlanHashValue, &t.Executions, &elapsed, &cpu,
+			&t.BufferGets, &t.DiskReads, &t.Rows, &module, &text,
+		); err != nil {
+			return tops, fmt.Errorf("scan %s: %w", qry, err)
+		}
+		t.Elapsed, t.CPU = time.Duration(elapsed)*time.Microsecond, time.Duration(cpu)*time.Microsecond
+		t.Module, t.SQLText = module.String, text.String
+		tops = append(tops, t)
+	}
+	return tops, rows.Err()
+}
+
+// ASHSample is an Active Session History sample.
+type ASHSample struct {
+	SampleTime                                             time.Time
+	SQLID, Event, WaitClass, SessionState, Module, Program string
+	SessionID, SessionSerial, PlanHashValue                int64
+	// BlockingSession is the SID of the blocking session, 0 if none.
+	BlockingSession int64
+}
+
+// ASHSamples returns the Active Session History samples of sqlID in the [since, until) interval, ordered by time:
+// the recent ones from V$ACTIVE_SESSION_HISTORY, the older ones from DBA_HIST_ACTIVE_SESS_HISTORY.
+//
+// Without the Diagnostics Pack (see SetDiagnosticsPackLicensed), there is no history:
+// it returns one sample (now) of the active sessions executing sqlID from V$SESSION,
+// iff until is in the future.
+//
+// Event is "ON CPU" for the samples on CPU.
+func ASHSamples(ctx context.Context, q Querier, sqlID string, since, until time.Time) ([]ASHSample, error) {
+	if checkDiagnosticsPack() != nil {
+		if !until.After(getClock().Now()) {
+			return nil, nil
+		}
+		const qry = `SELECT SYSTIMESTAMP, sql_id,
+       DECODE(state, 'WAITING', event, 'ON CPU'), DECODE(state, 'WAITING', wait_class), DECODE(state, 'WAITING', 'WAITING', 'ON CPU'),
+       module, program, sid, serial#, 0, NVL(blocking_session, 0)
+  FROM v$session
+  WHERE sql_id = :sql_id AND status = 'ACTIVE' AND :since IS NOT NULL`
+		return ashSamples(ctx, q, qry, sql.Named("sql_id", sqlID), sql.Named("since", since))
+	}
+	const cols = `sample_time, sql_id, NVL(event, 'ON CPU'), wait_class, session_state, module, program,
+       session_id, session_serial#, NVL(sql_plan_hash_value, 0), NVL(blocking_session, 0)`
+	const qry = `SELECT ` + cols + `
+  FROM v$active_session_history
+  WHERE sql_id = :sql_id AND sample_time >= :since AND sample_time < :until
+UNION ALL
+SELECT ` + cols + `
+  FROM dba_hist_active_sess_history
+  WHERE sql_id = :sql_id AND sample_time >= :since AND sample_time < :until AND
+        sample_time < (SELECT NVL(MIN(sample_time), SYSTIMESTAMP) FROM v$active_session_history)
+  ORDER BY 1`
+	return ashSamples(ctx, q, qry, sql.Named("sql_id", sqlID), sql.Named("since", since), sql.Named("until", until))
+}
+
+func ashSamples(ctx context.Context, q Querier, qry string, args ...interface{}) ([]ASHSample, error) {
+	rows, err := q.QueryContext(ctx, qry, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	var samples []ASHSample
+	for rows.Next() {
+		var s ASHSample
+		var waitClass, module, program sql.NullString
+		if err = rows.Scan(&s.SampleTime, &s.SQLID, &s.Event, &waitClass, &s.SessionState, &module, &program,
+			&s.SessionID, &s.SessionSerial, &s.PlanHashValue, &s.BlockingSession,
+		); err != nil {
+			return samples, fmt.Errorf("scan %s: %w", qry, err)
+		}
+		s.WaitClass, s.Module, s.Program = waitClass.String, module.String, program.String
+		samples = append(samples, s)
+	}
+	return samples, rows.Err()
+}
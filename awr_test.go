@@ -0,0 +1,45 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAWRNotLicensed(t *testing.T) {
+	ctx := context.Background()
+	var ex recordingExecer
+	now := time.Now()
+	// the Statspack probe fails with recordingExecer, so the V$ views are used
+	if src := ProbePerfSource(ctx, &ex); src != PerfSourceVViews {
+		t.Errorf("ProbePerfSource: got %s, wanted %s", src, PerfSourceVViews)
+	}
+	if _, err := TopSQLByElapsed(ctx, &ex, now.Add(-time.Hour), now, 10); !errors.Is(err, sql.ErrConnDone) {
+		t.Errorf("TopSQLByElapsed: got %v, wanted %v", err, sql.ErrConnDone)
+	}
+	if !strings.Contains(ex.qry, "v$sqlstats") || strings.Contains(ex.qry, "dba_hist") {
+		t.Errorf("TopSQLByElapsed queried %q without license", ex.qry)
+	}
+
+	ex.qry = ""
+	if samples, err := ASHSamples(ctx, &ex, "abc", now.Add(-2*time.Hour), now.Add(-time.Hour)); err != nil || len(samples) != 0 {
+		t.Errorf("ASHSamples in the past: got %v, %v, wanted nothing", samples, err)
+	}
+	if ex.qry != "" {
+		t.Errorf("queried %q for the past without license", ex.qry)
+	}
+	if _, err := ASHSamples(ctx, &ex, "abc", now.Add(-time.Hour), now.Add(time.Minute)); !errors.Is(err, sql.ErrConnDone) {
+		t.Errorf("ASHSamples: got %v, wanted %v", err, sql.ErrConnDone)
+	}
+	if !strings.Contains(ex.qry, "v$session") || strings.Contains(ex.qry, "active_sess") {
+		t.Errorf("ASHSamples queried %q without license", ex.qry)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"crypto/md5"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// SQLID returns the SQL_ID Oracle computes for qry - the exact text as sent to the database
+// (the statements are sent as is by this driver).
+//
+// This allows finding the statement in V$SQL, or loading its plan with LoadPlanBaselines.
+func SQLID(qry string) string {
+	h := md5.New()
+	h.Write([]byte(qry))
+	h.Write([]byte{0})
+	sum := h.Sum(nil)
+	n := uint64(binary.LittleEndian.Uint32(sum[8:12]))<<32 | uint64(binary.LittleEndian.Uint32(sum[12:16]))
+	const alphabet = "0123456789abcdfghjkmnpqrstuvwxyz"
+	var id [13]byte
+	for i := len(id) - 1; i >= 0; i-- {
+		id[i] = alphabet[n&31]
+		n >>= 5
+	}
+	return string(id[:])
+}
+
+// LoadPlanBaselines loads the plans of the statement qry from the cursor cache as SQL plan baselines
+// (DBMS_SPM.LOAD_PLANS_FROM_CURSOR_CACHE), so the optimizer will use only those (accepted) plans.
+//
+// The statement must have been executed, and still be in the shared pool.
+// With planHashValue != 0, only that plan is loaded. A fixed plan is preferred over the others.
+//
+// Returns the number of plans loaded. Needs the ADMINISTER SQL MANAGEMENT OBJECT privilege.
+func LoadPlanBaselines(ctx context.Context, ex Execer, qry string, planHashValue int64, fixed bool) (int, error) {
+	var hash interface{}
+	if planHashValue != 0 {
+		hash = planHashValue
+	}
+	const plsql = `BEGIN
+  :n := DBMS_SPM.LOAD_PLANS_FROM_CURSOR_CACHE(sql_id=>:sql_id, plan_hash_value=>:plan_hash_value, fixed=>:fixed);
+END;`
+	var n int
+	if _, err := ex.ExecContext(ctx, plsql,
+		sql.Named("n", sql.Out{Dest: &n}), sql.Named("sql_id", SQLID(qry)),
+		sql.Named("plan_hash_value", hash), sql.Named("fixed", yesNo(fixed)),
+	); err != nil {
+		return 0, fmt.Errorf("%s: %w", plsql, err)
+	}
+	return n, nil
+}
+
+// PlanBaseline is an SQL plan baseline, from DBA_SQL_PLAN_BASELINES.
+type PlanBaseline struct {
+	Created, LastExecuted                time.Time
+	SQLHandle, PlanName, Origin          string
+	Enabled, Accepted, Fixed, Reproduced bool
+}
+
+// GetPlanBaselines returns the SQL plan baselines of the statement qry.
+func GetPlanBaselines(ctx context.Context, q Querier, qry string) ([]PlanBaseline, error) {
+	const sel = `SELECT sql_handle, plan_name, origin, enabled, accepted, fixed, reproduced, created, last_executed
+  FROM dba_sql_plan_baselines
+  WHERE signature = DBMS_SQLTUNE.SQLTEXT_TO_SIGNATURE(:1)
+  ORDER BY created`
+	rows, err := q.QueryContext(ctx, sel, qry)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", sel, err)
+	}
+	defer rows.Close()
+	var baselines []PlanBaseline
+	for rows.Next() {
+		var b PlanBaseline
+		var enabled, accepted, fixed, reproduced string
+		var lastExecuted sql.NullTime
+		if err = rows.Scan(&b.SQLHandle, &b.PlanName, &b.Origin,
+			&enabled, &accepted, &fixed, &reproduced, &b.Created, &lastExecuted,
+		); err != nil {
+			return baselines, fmt.Errorf("scan %s: %w", sel, err)
+		}
+		b.Enabled, b.Accepted, b.Fixed, b.Reproduced = enabled == "YES", accepted == "YES", fixed == "YES", reproduced == "YES"
+		b.LastExecuted = lastExecuted.Time
+		baselines = append(baselines, b)
+	}
+	return baselines, rows.Err()
+}
+
+// AlterPlanBaseline sets the attribute ("enabled", "fixed", "autopurge", "plan_name", "description")
+// of the plan baseline (all plans of sqlHandle if planName is empty) with DBMS_SPM.ALTER_SQL_PLAN_BASELINE.
+//
+// Returns the number of plans altered.
+func AlterPlanBaseline(ctx context.Context, ex Execer, sqlHandle, planName, attribute, value string) (int, error) {
+	const plsql = `BEGIN
+  :n := DBMS_SPM.ALTER_SQL_PLAN_BASELINE(sql_handle=>:sql_handle, plan_name=>:plan_name,
+          attribute_name=>:attribute_name, attribute_value=>:attribute_value);
+END;`
+	var n int
+	if _, err := ex.ExecContext(ctx, plsql,
+		sql.Named("n", sql.Out{Dest: &n}), sql.Named("sql_handle", sqlHandle), sql.Named("plan_name", planName),
+		sql.Named("attribute_name", attribute), sql.Named("attribute_value", value),
+	); err != nil {
+		return 0, fmt.Errorf("%s: %w", plsql, err)
+	}
+	return n, nil
+}
+
+// DropPlanBaseline drops the plan baseline (all plans of sqlHandle if planName is empty)
+// with DBMS_SPM.DROP_SQL_PLAN_BASELINE.
+//
+// Returns the number of plans dropped.
+func DropPlanBaseline(ctx context.Context, ex Execer, sqlHandle, planName string) (int, error) {
+	const plsql = `BEGIN
+  :n := DBMS_SPM.DROP_SQL_PLAN_BASELINE(sql_handle=>:sql_handle, plan_name=>:plan_name);
+END;`
+	var n int
+	if _, err := ex.ExecContext(ctx, plsql,
+		sql.Named("n", sql.Out{Dest: &n}), sql.Named("sql_handle", sqlHandle), sql.Named("plan_name", planName),
+	); err != nil {
+		return 0, fmt.Errorf("%s: %w", plsql, err)
+	}
+	return n, nil
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "YES"
+	}
+	return "NO"
+}
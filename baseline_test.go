@@ -0,0 +1,37 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+func TestSQLID(t *testing.T) {
+	for qry, want := range map[string]string{
+		"select * from dual": "a5ks9fhw2v9s1",
+		"SELECT * FROM DUAL": "9g6pyx7qz035v",
+	} {
+		if got := SQLID(qry); got != want {
+			t.Errorf("%q: got %q, wanted %q", qry, got, want)
+		}
+	}
+}
+
+func TestLoadPlanBaselines(t *testing.T) {
+	var ex recordingExecer
+	if _, err := LoadPlanBaselines(context.Background(), &ex, "select * from dual", 0, true); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"sql_id": "a5ks9fhw2v9s1", "plan_hash_value": nil, "fixed": "YES"}
+	for _, a := range ex.args {
+		na := a.(sql.NamedArg)
+		if w, ok := want[na.Name]; ok && na.Value != w {
+			t.Errorf("%s: got %v, wanted %v", na.Name, na.Value, w)
+		}
+	}
+}
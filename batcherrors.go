@@ -0,0 +1,77 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include "dpiImpl.h"
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+)
+
+// BatchErrors returns an option to collect the errors of the individual rows of an array DML
+// (Exec with slices), instead of failing the whole statement on the first bad row.
+//
+// The good rows are processed, and the errors are returned as a *BatchError.
+// Without a transaction, the good rows are committed, too!
+//
+// Use it "naked", without sql.Named!
+func BatchErrors() Option { return func(o *stmtOptions) { o.batchErrors = true } }
+
+// ErrBatch is the error BatchError unwraps to.
+var ErrBatch = errors.New("batch errors")
+
+// BatchError is returned by an array DML executed with the BatchErrors option,
+// when some rows failed.
+type BatchError struct {
+	// Errors of the failed rows - each's Offset is the row's index in the arrays.
+	Errors []*OraErr
+	// RowsAffected is the number of rows processed successfully.
+	RowsAffected int64
+}
+
+func (be *BatchError) Error() string {
+	if len(be.Errors) == 0 {
+		return ErrBatch.Error()
+	}
+	return fmt.Sprintf("%s: %d rows failed (%d succeeded), first: row %d: %v",
+		ErrBatch, len(be.Errors), be.RowsAffected, be.Errors[0].Offset(), be.Errors[0])
+}
+func (be *BatchError) Unwrap() error { return ErrBatch }
+
+// Rows returns the indexes of the failed rows.
+func (be *BatchError) Rows() []int {
+	rows := make([]int, len(be.Errors))
+	for i, oe := range be.Errors {
+		rows[i] = oe.Offset()
+	}
+	return rows
+}
+
+// getBatchErrors returns the batch errors of the last executeMany as a *BatchError, or nil if there are none.
+func (st *statement) getBatchErrors(rowsAffected int64) error {
+	var n C.uint32_t
+	if err := st.checkExec(func() C.int { return C.dpiStmt_getBatchErrorCount(st.dpiStmt, &n) }); err != nil {
+		return fmt.Errorf("getBatchErrorCount: %w", err)
+	}
+	if n == 0 {
+		return nil
+	}
+	infos := make([]C.dpiErrorInfo, int(n))
+	if err := st.checkExec(func() C.int { return C.dpiStmt_getBatchErrors(st.dpiStmt, n, &infos[0]) }); err != nil {
+		return fmt.Errorf("getBatchErrors: %w", err)
+	}
+	be := BatchError{RowsAffected: rowsAffected, Errors: make([]*OraErr, 0, len(infos))}
+	for _, info := range infos {
+		if oe, ok := fromErrorInfo(info).(*OraErr); ok && oe != nil {
+			be.Errors = append(be.Errors, oe)
+		}
+	}
+	return &be
+}
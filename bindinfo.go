@@ -0,0 +1,245 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include "dpiImpl.h"
+*/
+import "C"
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// BindInfo describes a bind variable of a statement.
+type BindInfo struct {
+	// Name is as Oracle reports it: upper-cased for named (:name), the number for positional (:1) binds.
+	Name string
+	// Position is the 1-based position of the first occurrence of the bind variable.
+	Position int
+	// Returning is true for the targets of RETURNING ... INTO, which must be bound as sql.Out.
+	Returning bool
+}
+
+// StatementInfo describes a prepared statement.
+type StatementInfo struct {
+	// Binds are the unique bind variables, in order of their first occurrence.
+	Binds                                       []BindInfo
+	IsQuery, IsPLSQL, IsDDL, IsDML, IsReturning bool
+}
+
+// DescribeStatement prepares the statement (without executing it), and returns its bind variables and kind.
+//
+// Oracle does not report the types of the bind variables (they come from the bound values),
+// only the RETURNING INTO targets are known to be OUT.
+func DescribeStatement(ctx context.Context, ex Execer, qry string) (StatementInfo, error) {
+	var info StatementInfo
+	err := Raw(ctx, ex, func(c Conn) error {
+		stmt, err := c.PrepareContext(ctx, qry)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		st, ok := stmt.(*statement)
+		if !ok {
+			return fmt.Errorf("%T: %w", stmt, ErrNotGodror)
+		}
+		info, err = st.describe()
+		return err
+	})
+	if err != nil {
+		return info, fmt.Errorf("%s: %w", sqlForLog(qry), err)
+	}
+	return info, nil
+}
+
+// describe returns the StatementInfo of the prepared statement.
+func (st *statement) describe() (StatementInfo, error) {
+	if st.dpiStmt == nil {
+		return StatementInfo{}, errors.New("statement is not prepared")
+	}
+	info := StatementInfo{
+		IsQuery: st.dpiStmtInfo.isQuery == 1, IsPLSQL: st.dpiStmtInfo.isPLSQL == 1,
+		IsDDL: st.dpiStmtInfo.isDDL == 1, IsDML: st.dpiStmtInfo.isDML == 1,
+		IsReturning: st.dpiStmtInfo.isReturning == 1,
+	}
+	st.Lock()
+	names, err := st.bindNames()
+	st.Unlock()
+	if err != nil {
+		return info, err
+	}
+	var returning map[string]bool
+	if info.IsReturning {
+		returning = returningBinds(st.query)
+	}
+	info.Binds = make([]BindInfo, len(names))
+	for i, name := range names {
+		info.Binds[i] = BindInfo{Name: name, Position: i + 1, Returning: returning[name]}
+	}
+	return info, nil
+}
+
+// bindNames returns the unique bind variable names of the statement.
+//
+// Must be called with st locked.
+func (st *statement) bindNames() ([]string, error) {
+	var cnt C.uint32_t
+	if err := st.checkExec(func() C.int { return C.dpiStmt_getBindCount(st.dpiStmt, &cnt) }); err != nil {
+		return nil, fmt.Errorf("getBindCount: %w", err)
+	}
+	if cnt == 0 {
+		return nil, nil
+	}
+	cNames := make([]*C.char, int(cnt))
+	lengths := make([]C.uint32_t, int(cnt))
+	if err := st.checkExec(func() C.int { return C.dpiStmt_getBindNames(st.dpiStmt, &cnt, &cNames[0], &lengths[0]) }); err != nil {
+		return nil, fmt.Errorf("getBindNames: %w", err)
+	}
+	names := make([]string, int(cnt))
+	for i := range names {
+		names[i] = C.GoStringN(cNames[i], C.int(lengths[i]))
+	}
+	return names, nil
+}
+
+// ErrBindMismatch is the error BindError unwraps to.
+var ErrBindMismatch = errors.New("bind mismatch")
+
+// BindError is returned when the named and positional arguments do not match the bind variables of the statement.
+type BindError struct {
+	// Reason is one of "duplicate", "unknown", "extra" or "missing".
+	Reason string
+	// Names are the offending argument names (or ordinals) for duplicate, unknown and extra,
+	// the unbound variable names for missing.
+	Names []string
+}
+
+func (be *BindError) Error() string {
+	return fmt.Sprintf("%s: %s %s", ErrBindMismatch, be.Reason, strings.Join(be.Names, ", "))
+}
+func (be *BindError) Unwrap() error { return ErrBindMismatch }
+
+// resolveBindNames returns the bind variable name for each argument.
+//
+// Named arguments are matched case-insensitively, positional arguments get
+// the variable named as their ordinal (:1, :2), or else the next unclaimed variable,
+// in order of appearance.
+func resolveBindNames(names []string, args []driver.NamedValue) ([]string, error) {
+	claimed := make(map[string]bool, len(names))
+	for _, nm := range names {
+		claimed[nm] = false
+	}
+	lookup := func(nm string) (string, bool) {
+		if _, ok := claimed[nm]; ok {
+			return nm, true
+		}
+		for k := range claimed {
+			if strings.EqualFold(k, nm) {
+				return k, true
+			}
+		}
+		return "", false
+	}
+	res := make([]string, len(args))
+	var dups, unknown, extra []string
+	for i, a := range args {
+		if a.Name == "" {
+			continue
+		}
+		nm, ok := lookup(a.Name)
+		if !ok {
+			unknown = append(unknown, a.Name)
+			continue
+		}
+		if claimed[nm] {
+			dups = append(dups, a.Name)
+			continue
+		}
+		claimed[nm], res[i] = true, nm
+	}
+	for i, a := range args {
+		if a.Name != "" {
+			continue
+		}
+		if nm := strconv.Itoa(a.Ordinal); !claimed[nm] {
+			if _, ok := claimed[nm]; ok {
+				claimed[nm], res[i] = true, nm
+			}
+		}
+	}
+	next := 0
+	for i, a := range args {
+		if a.Name != "" || res[i] != "" {
+			continue
+		}
+		for next < len(names) && claimed[names[next]] {
+			next++
+		}
+		if next == len(names) {
+			extra = append(extra, strconv.Itoa(a.Ordinal))
+			continue
+		}
+		claimed[names[next]], res[i] = true, names[next]
+	}
+	if dups != nil {
+		return res, &BindError{Reason: "duplicate", Names: dups}
+	}
+	if unknown != nil {
+		return res, &BindError{Reason: "unknown", Names: unknown}
+	}
+	if extra != nil {
+		return res, &BindError{Reason: "extra", Names: extra}
+	}
+	var missing []string
+	for _, nm := range names {
+		if !claimed[nm] {
+			missing = append(missing, nm)
+		}
+	}
+	if missing != nil {
+		return res, &BindError{Reason: "missing", Names: missing}
+	}
+	return res, nil
+}
+
+// returningBinds returns the (upper-cased) names of the bind variables after the RETURNING ... INTO of the DML.
+func returningBinds(qry string) map[string]bool {
+	upper := strings.ToUpper(qry)
+	i := strings.LastIndex(upper, "RETURNING")
+	if i < 0 {
+		return nil
+	}
+	j := strings.Index(upper[i:], "INTO")
+	if j < 0 {
+		return nil
+	}
+	m := make(map[string]bool)
+	rest := upper[i+j+4:]
+	for {
+		k := strings.IndexByte(rest, ':')
+		if k < 0 {
+			return m
+		}
+		rest = rest[k+1:]
+		n := strings.IndexFunc(rest, func(r rune) bool {
+			return !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '$' || r == '#')
+		})
+		if n < 0 {
+			n = len(rest)
+		}
+		if n > 0 {
+			m[rest[:n]] = true
+		}
+		rest = rest[n:]
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestReturningBinds(t *testing.T) {
+	for i, tc := range []struct {
+		Qry  string
+		Want map[string]bool
+	}{
+		{"SELECT :a FROM DUAL", nil},
+		{"INSERT INTO t (a) VALUES (:a) RETURNING id, b INTO :id, :b_2", map[string]bool{"ID": true, "B_2": true}},
+		{"update t set a=:1 where b=:2 returning rowid into :3", map[string]bool{"3": true}},
+	} {
+		if got := returningBinds(tc.Qry); !reflect.DeepEqual(got, tc.Want) {
+			t.Errorf("%d. got %v, wanted %v", i, got, tc.Want)
+		}
+	}
+}
+
+func TestResolveBindNames(t *testing.T) {
+	named := func(name string, ordinal int) driver.NamedValue {
+		return driver.NamedValue{Name: name, Ordinal: ordinal}
+	}
+	for i, tc := range []struct {
+		Names  []string
+		Args   []driver.NamedValue
+		Want   []string
+		Reason string
+	}{
+		{Names: []string{"A", "B"}, Args: []driver.NamedValue{named("b", 1), named("a", 2)}, Want: []string{"B", "A"}},
+		{Names: []string{"A", "B", "C"}, Args: []driver.NamedValue{named("", 1), named("b", 2), named("", 3)}, Want: []string{"A", "B", "C"}},
+		{Names: []string{"X", "1"}, Args: []driver.NamedValue{named("x", 1), named("", 2)}, Want: []string{"X", "1"}},
+		{Names: []string{"2", "1"}, Args: []driver.NamedValue{named("", 1), named("", 2), named("", 3)}, Reason: "extra"},
+		{Names: []string{"A", "B"}, Args: []driver.NamedValue{named("a", 1), named("A", 2)}, Reason: "duplicate"},
+		{Names: []string{"A"}, Args: []driver.NamedValue{named("a", 1), named("c", 2)}, Reason: "unknown"},
+		{Names: []string{"A", "B"}, Args: []driver.NamedValue{named("b", 1)}, Reason: "missing"},
+	} {
+		got, err := resolveBindNames(tc.Names, tc.Args)
+		if tc.Reason != "" {
+			var be *BindError
+			if !errors.As(err, &be) || be.Reason != tc.Reason {
+				t.Errorf("%d. got %v, wanted %q", i, err, tc.Reason)
+			} else if !errors.Is(err, ErrBindMismatch) {
+				t.Errorf("%d. %v is not ErrBindMismatch", i, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d. %+v", i, err)
+		} else if !reflect.DeepEqual(got, tc.Want) {
+			t.Errorf("%d. got %q, wanted %q", i, got, tc.Want)
+		}
+	}
+}
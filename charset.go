@@ -0,0 +1,76 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include "dpiImpl.h"
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrCharsetConversion is ORA-29275: partial multibyte character,
+// or ORA-12713: character data loss in NCHAR/CHAR conversion.
+//
+// Use errors.Is(err, ErrCharsetConversion) to check for it.
+var ErrCharsetConversion = errors.New("character set conversion")
+
+// CharsetConversionError is returned by fetches failing with ErrCharsetConversion,
+// with the offending column.
+//
+// Oracle does not tell which column failed, so when the row has more than one character column,
+// all of them are listed in Candidates, and Column is empty.
+type CharsetConversionError struct {
+	Err error
+	// Column is the name of the offending column, Position is its 1-based position.
+	Column   string
+	Position int
+	// Candidates are the names of the character columns, when the offending one cannot be determined.
+	Candidates []string
+}
+
+func (cce *CharsetConversionError) Error() string {
+	if cce.Column != "" {
+		return fmt.Sprintf("column %q (#%d): %v", cce.Column, cce.Position, cce.Err)
+	}
+	return fmt.Sprintf("one of the columns %s: %v", strings.Join(cce.Candidates, ", "), cce.Err)
+}
+func (cce *CharsetConversionError) Unwrap() error { return cce.Err }
+
+// newCharsetConversionError returns err as a *CharsetConversionError for the character columns
+// (given by their name and 1-based position), if it is an ErrCharsetConversion; else err as is.
+func newCharsetConversionError(err error, names []string, positions []int) error {
+	if err == nil || !errors.Is(err, ErrCharsetConversion) || len(names) == 0 {
+		return err
+	}
+	if len(names) == 1 {
+		return &CharsetConversionError{Err: err, Column: names[0], Position: positions[0]}
+	}
+	return &CharsetConversionError{Err: err, Candidates: names}
+}
+
+// charsetConversionError is newCharsetConversionError for the character columns of cols.
+func charsetConversionError(err error, cols []Column) error {
+	if err == nil || !errors.Is(err, ErrCharsetConversion) {
+		return err
+	}
+	var names []string
+	var positions []int
+	for i, col := range cols {
+		switch col.OrigOracleType {
+		case C.DPI_ORACLE_TYPE_VARCHAR, C.DPI_ORACLE_TYPE_NVARCHAR,
+			C.DPI_ORACLE_TYPE_CHAR, C.DPI_ORACLE_TYPE_NCHAR,
+			C.DPI_ORACLE_TYPE_LONG_VARCHAR, C.DPI_ORACLE_TYPE_LONG_NVARCHAR,
+			C.DPI_ORACLE_TYPE_CLOB, C.DPI_ORACLE_TYPE_NCLOB:
+			names, positions = append(names, col.Name), append(positions, i+1)
+		}
+	}
+	return newCharsetConversionError(err, names, positions)
+}
@@ -0,0 +1,35 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCharsetConversionError(t *testing.T) {
+	oraErr := fromErrorInfo(newErrorInfo(0, "ORA-29275: partial multibyte character"))
+	if err := newCharsetConversionError(io.EOF, []string{"A"}, []int{1}); err != io.EOF {
+		t.Errorf("got %v, wanted io.EOF as is", err)
+	}
+
+	err := fmt.Errorf("Next: %w", newCharsetConversionError(oraErr, []string{"NAME"}, []int{2}))
+	var cce *CharsetConversionError
+	if !errors.As(err, &cce) || cce.Column != "NAME" || cce.Position != 2 {
+		t.Fatalf("got %#v, wanted NAME (#2)", err)
+	}
+	if !errors.Is(err, ErrCharsetConversion) || !strings.Contains(err.Error(), `column "NAME" (#2)`) {
+		t.Errorf("got %q", err)
+	}
+
+	err = newCharsetConversionError(oraErr, []string{"A", "B"}, []int{1, 3})
+	if !errors.As(err, &cce) || cce.Column != "" || strings.Join(cce.Candidates, ",") != "A,B" {
+		t.Errorf("got %#v, wanted candidates A, B", err)
+	}
+}
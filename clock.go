@@ -0,0 +1,47 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// clock is the source of time for the timing logic of the driver
+// (keepalive, clock skew checks, resubscription backoff, polling),
+// replaceable in tests with setClock.
+type clock interface {
+	Now() time.Time
+	After(time.Duration) <-chan time.Time
+	// NewTicker returns the ticks channel and the func to stop the ticker.
+	NewTicker(time.Duration) (<-chan time.Time, func())
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) NewTicker(d time.Duration) (<-chan time.Time, func()) {
+	t := time.NewTicker(d)
+	return t.C, t.Stop
+}
+
+type clockHolder struct{ clock }
+
+var clk atomic.Value
+
+func init() { clk.Store(clockHolder{realClock{}}) }
+
+// getClock returns the current clock.
+func getClock() clock { return clk.Load().(clockHolder).clock }
+
+// setClock sets the clock, and returns the func to restore the previous one.
+// For tests only.
+func setClock(c clock) (restore func()) {
+	prev := clk.Load().(clockHolder)
+	clk.Store(clockHolder{c})
+	return func() { clk.Store(prev) }
+}
@@ -0,0 +1,207 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock which moves only by Advance.
+type fakeClock struct {
+	now     time.Time
+	waiters []*fakeWaiter
+	mu      sync.Mutex
+}
+
+type fakeWaiter struct {
+	at     time.Time
+	ch     chan time.Time
+	period time.Duration
+}
+
+func newFakeClock() *fakeClock { return &fakeClock{now: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)} }
+
+func (fc *fakeClock) Now() time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.now
+}
+func (fc *fakeClock) After(d time.Duration) <-chan time.Time {
+	return fc.add(d, 0).ch
+}
+func (fc *fakeClock) NewTicker(d time.Duration) (<-chan time.Time, func()) {
+	w := fc.add(d, d)
+	return w.ch, func() {
+		fc.mu.Lock()
+		defer fc.mu.Unlock()
+		for i, x := range fc.waiters {
+			if x == w {
+				fc.waiters = append(fc.waiters[:i], fc.waiters[i+1:]...)
+				break
+			}
+		}
+	}
+}
+func (fc *fakeClock) add(d, period time.Duration) *fakeWaiter {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	w := &fakeWaiter{at: fc.now.Add(d), period: period, ch: make(chan time.Time, 1)}
+	fc.waiters = append(fc.waiters, w)
+	return w
+}
+
+// Advance the clock by d, firing the timers and tickers due.
+func (fc *fakeClock) Advance(d time.Duration) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.now = fc.now.Add(d)
+	waiters := fc.waiters[:0]
+	for _, w := range fc.waiters {
+		if w.at.After(fc.now) {
+			waiters = append(waiters, w)
+			continue
+		}
+		select {
+		case w.ch <- fc.now:
+		default: // drop ticks, as time.Ticker does
+		}
+		if w.period > 0 {
+			for !w.at.After(fc.now) {
+				w.at = w.at.Add(w.period)
+			}
+			waiters = append(waiters, w)
+		}
+	}
+	fc.waiters = waiters
+}
+
+func TestFakeClock(t *testing.T) {
+	fc := newFakeClock()
+	after := fc.After(time.Second)
+	ticks, stop := fc.NewTicker(time.Minute)
+	defer stop()
+	fc.Advance(999 * time.Millisecond)
+	select {
+	case <-after:
+		t.Fatal("After fired early")
+	default:
+	}
+	fc.Advance(time.Millisecond)
+	if got := <-after; !got.Equal(fc.Now()) {
+		t.Errorf("After: got %v, wanted %v", got, fc.Now())
+	}
+	fc.Advance(time.Minute)
+	<-ticks
+	fc.Advance(time.Minute)
+	<-ticks
+}
+
+func TestKeepAliveLastUsed(t *testing.T) {
+	fc := newFakeClock()
+	defer setClock(fc)()
+	c := &conn{}
+	leave, err := c.enter("Exec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&c.active) != 1 {
+		t.Errorf("active=%d, wanted 1", c.active)
+	}
+	fc.Advance(time.Minute)
+	leave()
+	if got := time.Unix(0, atomic.LoadInt64(&c.lastUsed)); !got.Equal(fc.Now()) {
+		t.Errorf("lastUsed=%v, wanted %v", got, fc.Now())
+	}
+}
+
+func TestKeepAliveReacquire(t *testing.T) {
+	fc := newFakeClock()
+	defer setClock(fc)()
+	tickers := func() int {
+		fc.mu.Lock()
+		defer fc.mu.Unlock()
+		return len(fc.waiters)
+	}
+	waitFor := func(want int) {
+		t.Helper()
+		for i := 0; i < 1000 && tickers() != want; i++ {
+			time.Sleep(time.Millisecond)
+		}
+		if got := tickers(); got != want {
+			t.Fatalf("got %d tickers, wanted %d", got, want)
+		}
+	}
+
+	c := &conn{}
+	c.startKeepAlive(time.Minute)
+	waitFor(1)
+	// ResetSession releases the session and acquires a new one
+	c.mu.Lock()
+	_ = c.closeNotLocking()
+	c.mu.Unlock()
+	fc.Advance(2 * time.Minute)
+	waitFor(1)
+	if c.keepAlive == nil {
+		t.Error("keepalive stopped by the session release")
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(0)
+	if c.keepAlive != nil {
+		t.Error("keepalive not stopped by Close")
+	}
+}
+
+func TestClockSkewInterval(t *testing.T) {
+	fc := newFakeClock()
+	defer setClock(fc)()
+	cs := clockSkew{checkedAt: fc.Now(), skew: time.Second}
+	fc.Advance(59 * time.Minute)
+	var measured int
+	measure := func(context.Context) (time.Duration, error) {
+		measured++
+		if measured == 1 {
+			return 0, errors.New("failed")
+		}
+		return time.Duration(measured) * time.Second, nil
+	}
+	ctx := context.Background()
+	cs.check(ctx, time.Hour, measure)
+	if skew, checkedAt := cs.get(); measured != 0 || skew != time.Second || !checkedAt.Equal(fc.Now().Add(-59*time.Minute)) {
+		t.Errorf("not due: measured %d times, got %s at %v, wanted the old measurement", measured, skew, checkedAt)
+	}
+
+	// a failed measurement keeps the old one, and is retried at the next check
+	fc.Advance(time.Minute)
+	cs.check(ctx, time.Hour, measure)
+	if skew, _ := cs.get(); measured != 1 || skew != time.Second {
+		t.Errorf("failed: measured %d times, got %s", measured, skew)
+	}
+	cs.check(ctx, time.Hour, measure)
+	if skew, checkedAt := cs.get(); measured != 2 || skew != 2*time.Second || !checkedAt.Equal(fc.Now()) {
+		t.Errorf("recheck: measured %d times, got %s at %v", measured, skew, checkedAt)
+	}
+
+	// refreshed again only after the interval passes
+	fc.Advance(30 * time.Minute)
+	cs.check(ctx, time.Hour, measure)
+	fc.Advance(30 * time.Minute)
+	cs.check(ctx, time.Hour, measure)
+	if skew, _ := cs.get(); measured != 3 || skew != 3*time.Second {
+		t.Errorf("periodic: measured %d times, got %s", measured, skew)
+	}
+	cs.check(ctx, 0, measure)
+	if measured != 3 {
+		t.Error("measured with zero interval")
+	}
+}
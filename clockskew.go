@@ -0,0 +1,90 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// clockSkew is the measured difference of the database and the application clocks.
+type clockSkew struct {
+	checkedAt time.Time
+	skew      time.Duration
+	mu        sync.Mutex
+}
+
+func (cs *clockSkew) get() (time.Duration, time.Time) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.skew, cs.checkedAt
+}
+
+// check measures the clock skew with measure (conn.measureClockSkew), if the last measurement is older than interval.
+//
+// It is called on each session acquisition (new connections and ResetSession re-acquires),
+// so the skew is refreshed every interval while the pool is in use.
+//
+// Errors are only logged, they must not fail the connection.
+func (cs *clockSkew) check(ctx context.Context, interval time.Duration, measure func(context.Context) (time.Duration, error)) {
+	if interval <= 0 {
+		return
+	}
+	cs.mu.Lock()
+	now := getClock().Now()
+	due := now.Sub(cs.checkedAt) >= interval
+	var checkedAt time.Time
+	if due {
+		// reserve it, so concurrent acquisitions won't measure, too
+		checkedAt, cs.checkedAt = cs.checkedAt, now
+	}
+	cs.mu.Unlock()
+	if !due {
+		return
+	}
+	skew, err := measure(ctx)
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if err != nil {
+		cs.checkedAt = checkedAt
+		if logger := ctxGetLog(ctx); logger != nil {
+			logger.Log("msg", "measureClockSkew", "error", err)
+		}
+		return
+	}
+	cs.skew, cs.checkedAt = skew, getClock().Now()
+}
+
+// measureClockSkew returns how much SYSTIMESTAMP is ahead of the local clock,
+// at the middle of the round trip.
+func (c *conn) measureClockSkew(ctx context.Context) (time.Duration, error) {
+	const qry = "SELECT SYSTIMESTAMP FROM DUAL"
+	st, err := c.prepareContextNotLocked(ctx, qry)
+	if err != nil {
+		return 0, fmt.Errorf("prepare %s: %w", qry, err)
+	}
+	defer st.Close()
+	start := time.Now()
+	rows, err := st.(*statement).queryContextNotLocked(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	vals := []driver.Value{nil}
+	if err = rows.Next(vals); err != nil && err != io.EOF {
+		return 0, fmt.Errorf("%s.Next: %w", qry, err)
+	}
+	end := time.Now()
+	dbTime, ok := vals[0].(time.Time)
+	if !ok {
+		return 0, fmt.Errorf("%s: got %T, wanted time.Time", qry, vals[0])
+	}
+	return dbTime.Sub(start.Add(end.Sub(start) / 2)), nil
+}
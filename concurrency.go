@@ -0,0 +1,105 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+var concurrencyDebug uint32
+
+// SetConcurrencyDebug enables (or disables) the detection of concurrent use of a connection:
+// a connection (and its statements and rows) must be used by one goroutine at a time,
+// otherwise memory corruption may happen.
+//
+// When enabled, each call saves the stack of its goroutine, and a concurrent call
+// from another goroutine fails with a *ConcurrentUseError, containing both stacks.
+//
+// This has a cost, so it is meant for debugging.
+func SetConcurrencyDebug(enable bool) {
+	var u uint32
+	if enable {
+		u = 1
+	}
+	atomic.StoreUint32(&concurrencyDebug, u)
+}
+
+// ErrConcurrentUse is the error ConcurrentUseError unwraps to.
+var ErrConcurrentUse = errors.New("concurrent use of connection")
+
+// ConcurrentUseError is returned (with SetConcurrencyDebug(true)) when a connection
+// is used by another goroutine while a call is in progress.
+type ConcurrentUseError struct {
+	// Op is the operation tried, OtherOp is the one in progress.
+	Op, OtherOp string
+	// Stack and OtherStack are the stacks of the respective goroutines.
+	Stack, OtherStack string
+}
+
+func (ce *ConcurrentUseError) Error() string {
+	return fmt.Sprintf("%s: %s while %s is in progress\n--- this goroutine:\n%s\n--- other goroutine:\n%s",
+		ErrConcurrentUse, ce.Op, ce.OtherOp, ce.Stack, ce.OtherStack)
+}
+func (ce *ConcurrentUseError) Unwrap() error { return ErrConcurrentUse }
+
+// useGuard tracks the goroutine using the connection.
+type useGuard struct {
+	op    string
+	stack []byte
+	gid   uint64
+	depth int
+	mu    sync.Mutex
+}
+
+func noLeave() {}
+
+// enter marks the start of op, and returns the func to mark its end.
+//
+// Nested calls from the same goroutine are allowed.
+// Without SetConcurrencyDebug(true) this is a no-op.
+func (g *useGuard) enter(op string) (leave func(), err error) {
+	if g == nil || atomic.LoadUint32(&concurrencyDebug) == 0 {
+		return noLeave, nil
+	}
+	var a [4096]byte
+	stack := a[:runtime.Stack(a[:], false)]
+	gid := goroutineID(stack)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.depth != 0 && g.gid != gid {
+		return noLeave, &ConcurrentUseError{
+			Op: op, OtherOp: g.op,
+			Stack: string(stack), OtherStack: string(g.stack),
+		}
+	}
+	if g.depth == 0 {
+		g.gid, g.op, g.stack = gid, op, append(g.stack[:0], stack...)
+	}
+	g.depth++
+	return func() {
+		g.mu.Lock()
+		if g.depth > 0 {
+			g.depth--
+		}
+		g.mu.Unlock()
+	}, nil
+}
+
+// goroutineID parses the goroutine ID from the "goroutine 123 [running]:" first line of the stack.
+func goroutineID(stack []byte) uint64 {
+	stack = bytes.TrimPrefix(stack, []byte("goroutine "))
+	if i := bytes.IndexByte(stack, ' '); i >= 0 {
+		stack = stack[:i]
+	}
+	n, _ := strconv.ParseUint(string(stack), 10, 64)
+	return n
+}
@@ -0,0 +1,55 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUseGuard(t *testing.T) {
+	SetConcurrencyDebug(true)
+	defer SetConcurrencyDebug(false)
+
+	var g useGuard
+	leave, err := g.enter("Exec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nested call from the same goroutine
+	leave2, err := g.enter("Next")
+	if err != nil {
+		t.Fatal(err)
+	}
+	leave2()
+
+	errCh := make(chan error, 1)
+	go func() {
+		leave, err := g.enter("Query")
+		leave()
+		errCh <- err
+	}()
+	err = <-errCh
+	var ce *ConcurrentUseError
+	if !errors.As(err, &ce) {
+		t.Fatalf("wanted ConcurrentUseError, got %+v", err)
+	}
+	if !errors.Is(err, ErrConcurrentUse) || ce.Op != "Query" || ce.OtherOp != "Exec" ||
+		!strings.Contains(ce.OtherStack, "TestUseGuard") {
+		t.Errorf("got %+v", ce)
+	}
+	leave()
+
+	go func() {
+		leave, err := g.enter("Query")
+		leave()
+		errCh <- err
+	}()
+	if err = <-errCh; err != nil {
+		t.Error(err)
+	}
+}
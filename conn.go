@@ -22,6 +22,7 @@ import (
 	"net/url"
 	"os"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -51,6 +52,7 @@ var _ driver.Validator = (*conn)(nil)
 //var _ driver.NamedValueChecker = (*conn)(nil)
 
 type conn struct {
+	lastUsed      int64 // first for 64-bit alignment
 	drv           *drv
 	dpiConn       *C.dpiConn
 	currentTT     atomic.Value
@@ -59,13 +61,107 @@ type conn struct {
 	Server        VersionInfo
 	params        dsn.ConnectionParams
 	mu            sync.RWMutex
+	callTimeout   callTimeout
 	objTypes      map[string]*ObjectType
+	openStmts     openStmts
+	use           useGuard
+	mem           *memStats
+	keepAlive     chan struct{}
+	active        int32
+	panicked      uint32 // atomic, set by recoverPanic
+	tag, retag    string
 	tzOffSecs     int
+	maxStringSize int
 	inTransaction bool
 	released      bool
+	retagging     bool
 	tzValid       bool
 }
 
+// ErrTooManyOpenCursors is returned when the connection has reached the MaxOpenCursors limit.
+var ErrTooManyOpenCursors = errors.New("too many open cursors")
+
+// ErrLimitExceeded is the error LimitError unwraps to.
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// LimitError is returned when a statement exceeds the MaxSQLLength, MaxBinds or MaxArraySize limit of the pool.
+type LimitError struct {
+	// Limit is the name of the exceeded limit: maxSQLLength, maxBinds or maxArraySize.
+	Limit      string
+	Value, Max int
+}
+
+func (le *LimitError) Error() string {
+	return fmt.Sprintf("%s: %d > %s=%d", ErrLimitExceeded, le.Value, le.Limit, le.Max)
+}
+func (le *LimitError) Unwrap() error { return ErrLimitExceeded }
+
+// openStmts counts the open statements of a connection, by query text.
+type openStmts struct {
+	m  map[string]int
+	mu sync.Mutex
+	n  int
+}
+
+// add registers a statement, or returns ErrTooManyOpenCursors if limit is already reached.
+func (o *openStmts) add(query string, limit int) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.n >= limit {
+		return fmt.Errorf("%w: %d statements are open (maxOpenCursors=%d), top: %s",
+			ErrTooManyOpenCursors, o.n, limit, o.top(5))
+	}
+	if o.m == nil {
+		o.m = make(map[string]int)
+	}
+	o.m[query]++
+	o.n++
+	return nil
+}
+
+func (o *openStmts) remove(query string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if n, ok := o.m[query]; !ok {
+		return
+	} else if n <= 1 {
+		delete(o.m, query)
+	} else {
+		o.m[query] = n - 1
+	}
+	o.n--
+}
+
+// top returns the n most often open query texts, with their counts.
+func (o *openStmts) top(n int) string {
+	type queryCount struct {
+		Query string
+		Count int
+	}
+	qc := make([]queryCount, 0, len(o.m))
+	for q, c := range o.m {
+		qc = append(qc, queryCount{Query: q, Count: c})
+	}
+	sort.Slice(qc, func(i, j int) bool {
+		return qc[i].Count > qc[j].Count || qc[i].Count == qc[j].Count && qc[i].Query < qc[j].Query
+	})
+	if len(qc) > n {
+		qc = qc[:n]
+	}
+	var buf strings.Builder
+	for i, x := range qc {
+		if i != 0 {
+			buf.WriteString("; ")
+		}
+		q := sqlForLog(x.Query)
+		if len(q) > 100 {
+			q = q[:100] + "..."
+		}
+		fmt.Fprintf(&buf, "%dx %q", x.Count, q)
+	}
+	return buf.String()
+}
+
 func (c *conn) getError() error {
 	if c == nil {
 		return driver.ErrBadConn
@@ -85,18 +181,23 @@ func (c *conn) checkExecNoLOT(f func() C.int) error {
 	return c.drv.checkExecNoLOT(f)
 }
 
-// used before an ODPI call to force it to return within the context deadline
-func (c *conn) handleDeadline(ctx context.Context, done <-chan struct{}) error {
+// used before an ODPI call to force it to return within the context deadline.
+//
+// The returned func resets the call timeout set for the deadline: call it (always) before returning,
+// so the next call on the connection won't inherit the timeout.
+func (c *conn) handleDeadline(ctx context.Context, done <-chan struct{}) (func(), error) {
 	logger := ctxGetLog(ctx)
 	if err := ctx.Err(); err != nil {
 		if logger != nil {
 			logger.Log("msg", "handleDeadline", "error", err)
 		}
-		return err
+		return func() {}, err
 	}
 	dl, hasDeadline := ctx.Deadline()
+	resetTimeout := func() {}
 	if hasDeadline {
 		c.mu.RLock()
+		c.callTimeout.Lock()
 		ok := func() bool {
 			if c.drv.clientVersion.Version < 18 {
 				// nosemgrep: trailofbits.go.missing-runlock-on-rwmutex.missing-runlock-on-rwmutex
@@ -122,10 +223,16 @@ func (c *conn) handleDeadline(ctx context.Context, done <-chan struct{}) error {
 			// nosemgrep: trailofbits.go.missing-runlock-on-rwmutex.missing-runlock-on-rwmutex
 			return false
 		}()
-		c.mu.RUnlock()
 		if ok {
-			defer func() { _ = C.dpiConn_setCallTimeout(c.dpiConn, 0) }()
+			// The call timeout must be in effect for the ODPI calls following this,
+			// so it is reset by the caller, after those calls.
+			c.callTimeout.gen++
+			gen, dpiConn := c.callTimeout.gen, c.dpiConn
+			var once sync.Once
+			resetTimeout = func() { once.Do(func() { c.resetCallTimeout(dpiConn, gen) }) }
 		}
+		c.callTimeout.Unlock()
+		c.mu.RUnlock()
 	}
 
 	go func() {
@@ -147,7 +254,25 @@ func (c *conn) handleDeadline(ctx context.Context, done <-chan struct{}) error {
 			}
 		}
 	}()
-	return nil
+	return resetTimeout, nil
+}
+
+// callTimeout guards the call timeout set on the connection by handleDeadline.
+type callTimeout struct {
+	sync.Mutex
+	// gen is incremented on each set, so a late reset won't clear a newer timeout.
+	gen uint32
+}
+
+// resetCallTimeout clears the call timeout of dpiConn, iff it has not been set again since gen.
+//
+// The caller of handleDeadline holds the connection (c.mu), so dpiConn is still the connection's.
+func (c *conn) resetCallTimeout(dpiConn *C.dpiConn, gen uint32) {
+	c.callTimeout.Lock()
+	defer c.callTimeout.Unlock()
+	if c.callTimeout.gen == gen && dpiConn != nil {
+		_ = C.dpiConn_setCallTimeout(dpiConn, 0)
+	}
 }
 
 // Break signals the server to stop the execution on the connection.
@@ -190,6 +315,11 @@ func (c *conn) Ping(ctx context.Context) error {
 	if err := ctx.Err(); err != nil {
 		return err
 	}
+	leave, useErr := c.enter("Ping")
+	if useErr != nil {
+		return useErr
+	}
+	defer leave()
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -199,10 +329,12 @@ func (c *conn) Ping(ctx context.Context) error {
 		dl, ok := ctx.Deadline()
 		logger.Log("msg", "Ping", "deadline", dl, "ok", ok)
 	}
-	if err := c.handleDeadline(ctx, done); err != nil {
+	resetTimeout, err := c.handleDeadline(ctx, done)
+	if err != nil {
 		return err
 	}
-	err := c.checkExec(func() C.int { return C.dpiConn_ping(c.dpiConn) })
+	err = c.checkExec(func() C.int { return C.dpiConn_ping(c.dpiConn) })
+	resetTimeout()
 	close(done)
 	if err != nil {
 		return maybeBadConn(fmt.Errorf("Ping: %w", err), c)
@@ -236,6 +368,7 @@ func (c *conn) Close() error {
 	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	c.stopKeepAlive()
 	return c.closeNotLocking()
 }
 
@@ -249,6 +382,10 @@ func (c *conn) closeNotLocking() error {
 		return nil
 	}
 	c.dpiConn = nil
+	if c.retagging && c.poolKey != "" {
+		c.releaseTagged(dpiConn)
+	}
+	c.tag, c.retag, c.retagging = "", "", false
 	if dpiConn.refCount <= 1 {
 		c.tzOffSecs, c.tzValid, c.params.Timezone = 0, false, nil
 	}
@@ -262,6 +399,9 @@ func (c *conn) closeNotLocking() error {
 	//
 	// To track reference counting, use DPI_DEBUG_LEVEL=2
 	C.dpiConn_release(dpiConn)
+	if c.poolKey != "" && c.drv != nil {
+		c.drv.notifyReleased()
+	}
 	return nil
 }
 
@@ -288,6 +428,11 @@ func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, e
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
+	leave, err := c.enter("BeginTx")
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
 
 	const (
 		trRO = "READ ONLY"
@@ -352,10 +497,18 @@ type tranParams struct {
 // PrepareContext returns a prepared statement, bound to this connection.
 // context is for the preparation of the statement,
 // it must not store the context within the statement itself.
-func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+func (c *conn) PrepareContext(ctx context.Context, query string) (_ driver.Stmt, retErr error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
+	if c.params.RecoverPanics {
+		defer c.recoverPanic("Prepare", query, &retErr)
+	}
+	leave, err := c.enter("Prepare")
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
 
 	if tt, ok := ctx.Value(traceTagCtxKey{}).(TraceTag); ok {
 		_ = c.setTraceTag(tt)
@@ -364,10 +517,13 @@ func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, e
 	if query == getConnection {
 		logger := ctxGetLog(ctx)
 		if logger != nil {
-			logger.Log("msg", "PrepareContext", "shortcut", query)
+			logger.Log("msg", "PrepareContext", "shortcut", sqlForLog(query))
 		}
 		return &statement{conn: c, query: query}, nil
 	}
+	if comment, ok := ctx.Value(sqlCommentCtxKey{}).(string); ok && comment != "" {
+		query = addSQLComment(query, comment)
+	}
 
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -378,17 +534,36 @@ func (c *conn) prepareContextNotLocked(ctx context.Context, query string) (drive
 		return nil, err
 	}
 
+	if max := c.params.MaxSQLLength; max > 0 && len(query) > max {
+		return nil, &LimitError{Limit: "maxSQLLength", Value: len(query), Max: max}
+	}
+	limit := c.params.MaxOpenCursors
+	if limit > 0 {
+		if err := c.openStmts.add(query, limit); err != nil {
+			return nil, err
+		}
+	}
 	cSQL := C.CString(query)
 	defer func() {
 		C.free(unsafe.Pointer(cSQL))
 	}()
 	st := &statement{conn: c, query: query}
+	// the connection's defaults, can be overridden by the FetchArraySize and PrefetchCount options
+	st.fetchArraySize = c.params.FetchArraySize
+	if n := c.params.PrefetchCount; n != 0 {
+		PrefetchCount(n)(&st.stmtOptions)
+	}
+	// then the context's, overridden by the call's arguments in CheckNamedValue
+	Options(ctxOptions(ctx)...)(&st.stmtOptions)
 	err := c.checkExec(func() C.int {
 		return C.dpiConn_prepareStmt(c.dpiConn, 0, cSQL, C.uint32_t(len(query)), nil, 0,
 			(**C.dpiStmt)(unsafe.Pointer(&st.dpiStmt)))
 	})
 	if err != nil {
-		return nil, maybeBadConn(fmt.Errorf("prepare: %s: %w", query, err), c)
+		if limit > 0 {
+			c.openStmts.remove(query)
+		}
+		return nil, maybeBadConn(fmt.Errorf("prepare: %s: %w", sqlForLog(query), err), c)
 	}
 	if err := c.checkExec(func() C.int { return C.dpiStmt_getInfo(st.dpiStmt, &st.dpiStmtInfo) }); err != nil {
 		err = maybeBadConn(fmt.Errorf("getStmtInfo: %w", err), c)
@@ -405,6 +580,15 @@ func (c *conn) Rollback() error {
 	return c.endTran(false)
 }
 func (c *conn) endTran(isCommit bool) error {
+	op := "Rollback"
+	if isCommit {
+		op = "Commit"
+	}
+	leave, useErr := c.enter(op)
+	if useErr != nil {
+		return useErr
+	}
+	defer leave()
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.inTransaction = false
@@ -494,7 +678,10 @@ func (c *conn) init(ctx context.Context, onInit func(ctx context.Context, conn d
 		logger.Log("msg", "init connection", "params", c.params)
 	}
 
-	if err := c.initTZ(); err != nil || onInit == nil {
+	if err := c.initTZ(); err != nil {
+		return err
+	}
+	if err := c.setCurrentSchema(); err != nil || onInit == nil {
 		return err
 	}
 	if logger != nil {
@@ -503,6 +690,23 @@ func (c *conn) init(ctx context.Context, onInit func(ctx context.Context, conn d
 	return onInit(ctx, c)
 }
 
+// setCurrentSchema sets the session's CURRENT_SCHEMA to the currentSchema connection parameter, if set.
+// This does not need a round-trip: it is sent with the next call.
+func (c *conn) setCurrentSchema() error {
+	schema := c.params.CurrentSchema
+	if schema == "" {
+		return nil
+	}
+	cSchema := C.CString(schema)
+	defer C.free(unsafe.Pointer(cSchema))
+	if err := c.checkExec(func() C.int {
+		return C.dpiConn_setCurrentSchema(c.dpiConn, cSchema, C.uint32_t(len(schema)))
+	}); err != nil {
+		return fmt.Errorf("setCurrentSchema(%q): %w", schema, err)
+	}
+	return nil
+}
+
 func (c *conn) initTZ() error {
 	logger := getLogger()
 	if logger != nil {
@@ -895,6 +1099,45 @@ func ContextWithTraceTag(ctx context.Context, tt TraceTag) context.Context {
 	return context.WithValue(ctx, traceTagCtxKey{}, tt)
 }
 
+type sqlCommentCtxKey struct{}
+
+// ContextWithSQLComment returns a context with the specified comment, which will be
+// added to the statements prepared with this context, so DBAs can attribute the cursors
+// (in V$SQL for example) to services.
+//
+// A comment (such as "svc=orders op=list") is prepended as /* svc=orders op=list */,
+// an optimizer hint (such as "/*+ MONITOR */") is inserted after the first keyword.
+//
+// As the statement text changes, statements with different comments are cached separately.
+func ContextWithSQLComment(ctx context.Context, comment string) context.Context {
+	return context.WithValue(ctx, sqlCommentCtxKey{}, comment)
+}
+
+// addSQLComment adds the comment (or hint) to the query.
+// A "*/" inside the comment is escaped, so it cannot close the comment early and inject SQL.
+func addSQLComment(query, comment string) string {
+	comment = strings.TrimSpace(comment)
+	prefix := "/* "
+	if strings.HasPrefix(comment, "/*") {
+		prefix = "/*"
+		if strings.HasPrefix(comment, "/*+") {
+			prefix = "/*+"
+		}
+		comment = strings.TrimSuffix(comment[len(prefix):], "*/")
+	} else {
+		comment += " "
+	}
+	comment = prefix + strings.ReplaceAll(comment, "*/", "* /") + "*/"
+	if prefix == "/*+" {
+		q := strings.TrimLeft(query, " \t\r\n")
+		if i := strings.IndexAny(q, " \t\r\n"); i > 0 {
+			return q[:i] + " " + comment + q[i:]
+		}
+		return q + " " + comment
+	}
+	return comment + " " + query
+}
+
 // TraceTag holds tracing information for the session. It can be set on the session
 // with ContextWithTraceTag.
 type TraceTag struct {
@@ -1047,7 +1290,7 @@ func (c *conn) ResetSession(ctx context.Context) error {
 		return driver.ErrBadConn
 	}
 	c.mu.RLock()
-	key, drv, params, dpiConnOK := c.poolKey, c.drv, c.params, c.dpiConn != nil
+	key, drv, params, dpiConnOK := c.poolKey, c.drv, c.params, c.dpiConn != nil && atomic.LoadUint32(&c.panicked) == 0
 	c.mu.RUnlock()
 	if dpiConnOK {
 		dpiConnOK = c.isHealthy()
@@ -1057,7 +1300,7 @@ func (c *conn) ResetSession(ctx context.Context) error {
 		if !dpiConnOK {
 			return driver.ErrBadConn
 		}
-		return nil
+		return c.setCurrentSchema()
 	}
 	// FIXME(tgulacsi): Prepared statements hold the previous session,
 	// so sometimes sessions are not released, resulting in
@@ -1084,6 +1327,9 @@ func (c *conn) ResetSession(ctx context.Context) error {
 			P.ConnParams.ConnClass = cc.ConnParams.ConnClass
 		}
 	}
+	if tag, ok := ctx.Value(sessionTagCtxKey{}).(string); ok {
+		P.ConnParams.Tag = tag
+	}
 	logger := ctxGetLog(ctx)
 	if !paramsFromCtx {
 		if ctxValue := ctx.Value(paramsCtxKey{}); ctxValue != nil {
@@ -1098,23 +1344,41 @@ func (c *conn) ResetSession(ctx context.Context) error {
 			}
 		}
 	}
+	if err := applyProxyUser(ctx, &P.CommonParams, false, pool.params.Heterogeneous); err != nil {
+		return err
+	}
 	if logger != nil {
 		logger.Log("msg", "ResetSession re-acquire session", "pool", pool.key)
 	}
+	// the keepalive must not ping the session while it is re-acquired and initialized
+	leave, err := c.enter("ResetSession")
+	if err != nil {
+		return err
+	}
+	defer leave()
 	c.mu.Lock()
 	// Close and then reacquire a fresh dpiConn
 	if c.dpiConn != nil {
 		// Just release
 		_ = c.closeNotLocking()
 	}
-	dpiConn, err := c.drv.acquireConn(pool, P)
+	dpiConn, at, err := c.drv.acquireConn(pool, P)
+	if err == nil {
+		c.dpiConn = dpiConn
+	}
 	c.mu.Unlock()
 	if err != nil {
 		return fmt.Errorf("%v: %w", err, driver.ErrBadConn)
 	}
-	c.dpiConn = dpiConn
 
-	return c.init(ctx, P.OnInit)
+	if err = c.init(ctx, getOnInit(&P.CommonParams)); err != nil {
+		return err
+	}
+	if err = c.applyTag(ctx, P.ConnParams, at); err != nil {
+		return err
+	}
+	pool.clockSkew.check(ctx, c.params.ClockSkewInterval, c.measureClockSkew)
+	return nil
 }
 
 // Validator may be implemented by Conn to allow drivers to
@@ -1130,6 +1394,10 @@ func (c *conn) IsValid() bool {
 		return false
 	}
 	c.mu.RLock()
+	if atomic.LoadUint32(&c.panicked) != 0 {
+		c.mu.RUnlock()
+		return false
+	}
 	dpiConnOK, released, pooled, tzOK := c.dpiConn != nil, c.released, c.poolKey != "", c.params.Timezone != nil
 	c.mu.RUnlock()
 	if dpiConnOK {
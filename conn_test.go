@@ -6,12 +6,16 @@
 package godror
 
 import (
+	"context"
 	"database/sql/driver"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/godror/godror/dsn"
 )
 
 func TestMaybeBadConn(t *testing.T) {
@@ -118,3 +122,102 @@ func TestTZName(t *testing.T) {
 		}
 	}
 }
+
+func TestAddSQLComment(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		Query, Comment, Want string
+	}{
+		{"SELECT 1 FROM DUAL", "svc=orders op=list", "/* svc=orders op=list */ SELECT 1 FROM DUAL"},
+		{"SELECT 1 FROM DUAL", "/* app */", "/* app */ SELECT 1 FROM DUAL"},
+		{"SELECT 1 FROM DUAL", "a */ b", "/* a * / b */ SELECT 1 FROM DUAL"},
+		{"  SELECT 1 FROM DUAL", "/*+ MONITOR */", "SELECT /*+ MONITOR */ 1 FROM DUAL"},
+		{"COMMIT", "/*+ MONITOR */", "COMMIT /*+ MONITOR */"},
+		{"SELECT 1 FROM DUAL", "/* a */ DELETE FROM t /* b */", "/* a * / DELETE FROM t /* b */ SELECT 1 FROM DUAL"},
+		{"SELECT 1 FROM DUAL", "/*+ FULL(t) */ 1 FROM t; --*/", "SELECT /*+ FULL(t) * / 1 FROM t; --*/ 1 FROM DUAL"},
+		{"SELECT 1 FROM DUAL", "/*+ MONITOR", "SELECT /*+ MONITOR*/ 1 FROM DUAL"},
+		{"SELECT 1 FROM DUAL", "/*/", "/*/*/ SELECT 1 FROM DUAL"},
+	} {
+		if got := addSQLComment(tc.Query, tc.Comment); got != tc.Want {
+			t.Errorf("%q+%q: got %q, wanted %q", tc.Query, tc.Comment, got, tc.Want)
+		}
+	}
+}
+
+func TestOpenStmts(t *testing.T) {
+	t.Parallel()
+	var os openStmts
+	for i := 0; i < 3; i++ {
+		if err := os.add("SELECT 1 FROM DUAL", 4); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.add("SELECT 2 FROM DUAL", 4); err != nil {
+		t.Fatal(err)
+	}
+	err := os.add("SELECT 3 FROM DUAL", 4)
+	if !errors.Is(err, ErrTooManyOpenCursors) {
+		t.Fatalf("got %v, wanted ErrTooManyOpenCursors", err)
+	}
+	t.Log(err)
+	if want := `3x "SELECT 1 FROM DUAL"; 1x "SELECT 2 FROM DUAL"`; !strings.Contains(err.Error(), want) {
+		t.Errorf("got %q, wanted %q", err, want)
+	}
+	os.remove("SELECT 2 FROM DUAL")
+	os.remove("SELECT 2 FROM DUAL") // not open
+	if err = os.add("SELECT 3 FROM DUAL", 4); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLimitError(t *testing.T) {
+	c := &conn{params: dsn.ConnectionParams{CommonParams: dsn.CommonParams{MaxSQLLength: 10}}}
+	_, err := c.prepareContextNotLocked(context.Background(), "SELECT * FROM all_objects")
+	var le *LimitError
+	if !errors.As(err, &le) || !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("got %+v, wanted LimitError", err)
+	}
+	if le.Limit != "maxSQLLength" || le.Value != 25 || le.Max != 10 {
+		t.Errorf("got %#v", le)
+	}
+	t.Log(err)
+}
+
+func TestTimeFromTimestamp(t *testing.T) {
+	budapest, err := time.LoadLocation("Europe/Budapest")
+	if err != nil {
+		t.Skip(err)
+	}
+	helsinki, err := time.LoadLocation("Europe/Helsinki")
+	if err != nil {
+		t.Skip(err)
+	}
+	for _, tC := range []struct {
+		In       time.Time
+		WantZone *time.Location
+	}{
+		{In: time.Date(2021, 1, 15, 12, 0, 0, 0, budapest), WantZone: budapest},
+		{In: time.Date(2021, 7, 15, 12, 0, 0, 0, budapest), WantZone: budapest},
+		// DST transitions: the last second before, and the first after
+		{In: time.Date(2021, 3, 28, 1, 59, 59, 0, budapest), WantZone: budapest},
+		{In: time.Date(2021, 3, 28, 3, 0, 0, 0, budapest), WantZone: budapest},
+		// 02:30 twice on 2021-10-31: +02:00, then +01:00
+		{In: time.Date(2021, 10, 31, 0, 30, 0, 0, time.UTC).In(budapest), WantZone: budapest},
+		{In: time.Date(2021, 10, 31, 1, 30, 0, 0, time.UTC).In(budapest), WantZone: budapest},
+		// another zone with the same offset as Budapest's summer time, in winter
+		{In: time.Date(2021, 1, 15, 12, 0, 0, 0, helsinki)},
+	} {
+		var d Data
+		d.SetTime(tC.In)
+		got := d.GetTimeIn(budapest)
+		if !got.Equal(tC.In) {
+			t.Errorf("%s: got %s", tC.In, got)
+		}
+		if tC.WantZone != nil && got.Location() != tC.WantZone {
+			t.Errorf("%s: got zone %s, wanted %s", tC.In, got.Location(), tC.WantZone)
+		}
+		if _, off := got.Zone(); off != tzOffset(tC.In) {
+			t.Errorf("%s: got offset %d, wanted %d", tC.In, off, tzOffset(tC.In))
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultCopyTableBatchSize is the default number of rows inserted at once by CopyTable.
+const DefaultCopyTableBatchSize = 1024
+
+// CopyTableOptions are the options of CopyTable.
+type CopyTableOptions struct {
+	// Progress is called after each inserted batch with the number of rows copied so far.
+	Progress func(ctx context.Context, copied int64) error
+	// Columns of the target table, in the order of the query's columns.
+	// Defaults to the query's column names.
+	Columns []string
+	// BatchSize is DefaultCopyTableBatchSize by default.
+	BatchSize int
+}
+
+// CopyTable copies the rows returned by qry from srcDB into the targetTable of dstDB,
+// with array inserts of BatchSize rows.
+//
+// The columns are mapped by the Go type they are scanned into
+// (string, Number, time.Time, []byte, int64, float64), so LOBs are read into memory.
+//
+// Transactions are up to the caller: if dstDB is a *sql.DB, each batch is committed separately;
+// for an all-or-nothing copy, pass a *sql.Tx.
+//
+// Returns the number of rows copied.
+func CopyTable(ctx context.Context, srcDB Querier, dstDB Execer, qry, targetTable string, opts CopyTableOptions) (int64, error) {
+	if targetTable == "" {
+		return 0, errors.New("CopyTable: empty targetTable")
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultCopyTableBatchSize
+	}
+	rows, err := srcDB.QueryContext(ctx, qry, FetchArraySize(batchSize), PrefetchCount(batchSize+1))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", sqlForLog(qry), err)
+	}
+	defer rows.Close()
+	srcCols, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	dstCols := opts.Columns
+	if len(dstCols) == 0 {
+		dstCols = srcCols
+	} else if len(dstCols) != len(srcCols) {
+		return 0, fmt.Errorf("CopyTable: query has %d columns, but %d target columns given", len(srcCols), len(dstCols))
+	}
+	var buf strings.Builder
+	buf.WriteString("INSERT INTO " + targetTable + " (" + strings.Join(dstCols, ", ") + ") VALUES (")
+	for i := range dstCols {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(":" + strconv.Itoa(i+1))
+	}
+	buf.WriteByte(')')
+	qryIns := buf.String()
+
+	var copied int64
+	batch := make([][]interface{}, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		columns, err := columnSlices(batch, len(dstCols))
+		if err != nil {
+			return err
+		}
+		if _, err = dstDB.ExecContext(ctx, qryIns, columns...); err != nil {
+			return fmt.Errorf("%s: %w", sqlForLog(qryIns), err)
+		}
+		copied += int64(len(batch))
+		batch = batch[:0]
+		if opts.Progress != nil {
+			return opts.Progress(ctx, copied)
+		}
+		return nil
+	}
+	for rows.Next() {
+		values := make([]interface{}, len(srcCols))
+		dest := make([]interface{}, len(values))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return copied, fmt.Errorf("scan %s: %w", sqlForLog(qry), err)
+		}
+		if batch = append(batch, values); len(batch) >= batchSize {
+			if err = flush(); err != nil {
+				return copied, err
+			}
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return copied, fmt.Errorf("%s: %w", sqlForLog(qry), err)
+	}
+	return copied, flush()
+}
@@ -0,0 +1,18 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCopyTableEmptyTarget(t *testing.T) {
+	// checked before querying, so the nil Querier is not used
+	if _, err := CopyTable(context.Background(), nil, nil, "SELECT 1 FROM DUAL", "", CopyTableOptions{}); err == nil {
+		t.Error("wanted error for empty targetTable")
+	}
+}
@@ -0,0 +1,377 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// structField is a column mapped field of a struct, by the `godror:"COLUMN_NAME,key,returning"` tag.
+//
+// The column name defaults to the upper-cased field name, "-" skips the field.
+// "key" marks the primary key columns (used in the WHERE of UpdateStruct and DeleteStruct),
+// "returning" marks the columns set by the database (identity, triggers), which are
+// returned (with RETURNING INTO) instead of being inserted or updated.
+// "version" marks the version column, "rowscn" the field holding ORA_ROWSCN,
+// for UpdateStructOptimistic.
+type structField struct {
+	Column                          string
+	Index                           int
+	Key, Returning, Version, RowSCN bool
+}
+
+func structFields(typ reflect.Type) ([]structField, error) {
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s is not a struct", typ)
+	}
+	fields := make([]structField, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" { // unexported
+			continue
+		}
+		tag := f.Tag.Get("godror")
+		if tag == "-" {
+			continue
+		}
+		sf := structField{Index: i, Column: strings.ToUpper(f.Name)}
+		parts := strings.Split(tag, ",")
+		if parts[0] != "" {
+			sf.Column = parts[0]
+		}
+		for _, opt := range parts[1:] {
+			switch opt {
+			case "key":
+				sf.Key = true
+			case "returning":
+				sf.Returning = true
+			case "version":
+				sf.Version = true
+			case "rowscn":
+				sf.RowSCN, sf.Column = true, "ORA_ROWSCN"
+			default:
+				return nil, fmt.Errorf("%s.%s: unknown tag option %q", typ, f.Name, opt)
+			}
+		}
+		fields = append(fields, sf)
+	}
+	return fields, nil
+}
+
+// structRows returns the fields of the (pointer to a) struct or slice of structs,
+// and the struct values.
+func structRows(v interface{}) (fields []structField, rows reflect.Value, isSlice bool, err error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Struct:
+		if !rv.CanAddr() {
+			return nil, rv, false, errors.New("a pointer to the struct is needed")
+		}
+		fields, err = structFields(rv.Type())
+		return fields, rv, false, err
+	case reflect.Slice:
+		fields, err = structFields(rv.Type().Elem())
+		return fields, rv, true, err
+	default:
+		return nil, rv, false, fmt.Errorf("%T is not a struct or a slice of structs", v)
+	}
+}
+
+// arg returns the value of the field to be bound: for slices, the slice of the field values (array DML),
+// for a struct, the field's value.
+func (sf structField) arg(rows reflect.Value, isSlice bool) interface{} {
+	if !isSlice {
+		return rows.Field(sf.Index).Interface()
+	}
+	s := reflect.MakeSlice(reflect.SliceOf(rows.Type().Elem().Field(sf.Index).Type), rows.Len(), rows.Len())
+	for i := 0; i < rows.Len(); i++ {
+		s.Index(i).Set(rows.Index(i).Field(sf.Index))
+	}
+	return s.Interface()
+}
+
+// appendReturning appends the RETURNING INTO clause for the returning fields of a single struct.
+func appendReturning(buf *strings.Builder, args []interface{}, fields []structField, row reflect.Value) []interface{} {
+	var cols, binds []string
+	for _, f := range fields {
+		if f.Returning {
+			cols = append(cols, f.Column)
+			args = append(args, sql.Out{Dest: row.Field(f.Index).Addr().Interface()})
+			binds = append(binds, ":"+strconv.Itoa(len(args)))
+		}
+	}
+	if len(cols) != 0 {
+		buf.WriteString(" RETURNING " + strings.Join(cols, ", ") + " INTO " + strings.Join(binds, ", "))
+	}
+	return args
+}
+
+// InsertStruct inserts v (a pointer to a struct, or a slice of structs) into table,
+// mapping the fields to columns by their `godror` tag (see structField).
+//
+// For a slice, one array insert is executed. For a single struct,
+// the "returning" fields (such as an identity key) are set with RETURNING INTO.
+func InsertStruct(ctx context.Context, ex Execer, table string, v interface{}) error {
+	fields, rows, isSlice, err := structRows(v)
+	if err != nil {
+		return err
+	}
+	if isSlice && rows.Len() == 0 {
+		return nil
+	}
+	var cols []string
+	var args []interface{}
+	for _, f := range fields {
+		if !f.Returning && !f.RowSCN {
+			cols = append(cols, f.Column)
+			args = append(args, f.arg(rows, isSlice))
+		}
+	}
+	var buf strings.Builder
+	buf.WriteString("INSERT INTO " + table + " (" + strings.Join(cols, ", ") + ") VALUES (")
+	for i := range cols {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(":" + strconv.Itoa(i+1))
+	}
+	buf.WriteByte(')')
+	if !isSlice {
+		args = appendReturning(&buf, args, fields, rows)
+	}
+	qry := buf.String()
+	if _, err = ex.ExecContext(ctx, qry, args...); err != nil {
+		return fmt.Errorf("%s: %w", sqlForLog(qry), err)
+	}
+	return nil
+}
+
+// UpdateStruct updates the non-key, non-returning columns of the rows of table
+// identified by the "key" fields of v (a pointer to a struct, or a slice of structs),
+// and returns the number of updated rows.
+//
+// For a single struct, the "returning" fields are set with RETURNING INTO.
+func UpdateStruct(ctx context.Context, ex Execer, table string, v interface{}) (int64, error) {
+	fields, rows, isSlice, err := structRows(v)
+	if err != nil {
+		return 0, err
+	}
+	if isSlice && rows.Len() == 0 {
+		return 0, nil
+	}
+	var sets, where []string
+	var args []interface{}
+	for _, f := range fields {
+		if !f.Key && !f.Returning && !f.RowSCN {
+			args = append(args, f.arg(rows, isSlice))
+			sets = append(sets, f.Column+" = :"+strconv.Itoa(len(args)))
+		}
+	}
+	for _, f := range fields {
+		if f.Key {
+			args = append(args, f.arg(rows, isSlice))
+			where = append(where, f.Column+" = :"+strconv.Itoa(len(args)))
+		}
+	}
+	if len(where) == 0 {
+		return 0, errors.New("UpdateStruct: no key field")
+	}
+	if len(sets) == 0 {
+		return 0, errors.New("UpdateStruct: nothing to update")
+	}
+	var buf strings.Builder
+	buf.WriteString("UPDATE " + table + " SET " + strings.Join(sets, ", ") + " WHERE " + strings.Join(where, " AND "))
+	if !isSlice {
+		args = appendReturning(&buf, args, fields, rows)
+	}
+	qry := buf.String()
+	res, err := ex.ExecContext(ctx, qry, args...)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", sqlForLog(qry), err)
+	}
+	return res.RowsAffected()
+}
+
+// DeleteStruct deletes the rows of table identified by the "key" fields of v
+// (a pointer to a struct, or a slice of structs), and returns the number of deleted rows.
+func DeleteStruct(ctx context.Context, ex Execer, table string, v interface{}) (int64, error) {
+	fields, rows, isSlice, err := structRows(v)
+	if err != nil {
+		return 0, err
+	}
+	if isSlice && rows.Len() == 0 {
+		return 0, nil
+	}
+	var where []string
+	var args []interface{}
+	for _, f := range fields {
+		if f.Key {
+			args = append(args, f.arg(rows, isSlice))
+			where = append(where, f.Column+" = :"+strconv.Itoa(len(args)))
+		}
+	}
+	if len(where) == 0 {
+		return 0, errors.New("DeleteStruct: no key field")
+	}
+	qry := "DELETE FROM " + table + " WHERE " + strings.Join(where, " AND ")
+	res, err := ex.ExecContext(ctx, qry, args...)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", sqlForLog(qry), err)
+	}
+	return res.RowsAffected()
+}
+
+var (
+	// ErrNotFound is returned by UpdateStructOptimistic when the row does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrStaleVersion is returned by UpdateStructOptimistic when the row has been modified since it was read.
+	ErrStaleVersion = errors.New("stale version")
+)
+
+// UpdateStructOptimistic updates the row identified by the "key" fields of v (a pointer to a struct)
+// only if it has not been modified since it was read - without SELECT FOR UPDATE.
+//
+// The check uses the "version" field (which is incremented, and set in v),
+// or the "rowscn" field holding the ORA_ROWSCN read with the row - which is only row-level with
+// tables created with ROWDEPENDENCIES, block-level (more false conflicts) otherwise.
+//
+// After the update, the "rowscn" field is refreshed with the row's ORA_ROWSCN, so v can be updated again.
+// It is zeroed if the database does not report it till the commit (NULL): read the row again after the commit then,
+// as a zero "rowscn" field is rejected.
+//
+// Returns ErrNotFound if the row does not exist, and ErrStaleVersion if it has been modified.
+func UpdateStructOptimistic(ctx context.Context, db interface {
+	Execer
+	Querier
+}, table string, v interface{}) error {
+	fields, row, isSlice, err := structRows(v)
+	if err != nil {
+		return err
+	}
+	if isSlice {
+		return errors.New("UpdateStructOptimistic: a pointer to a struct is needed")
+	}
+	var sets, where, keyWhere []string
+	var args, keyArgs []interface{}
+	var check *structField
+	for i, f := range fields {
+		switch {
+		case f.Version:
+			sets = append(sets, f.Column+" = "+f.Column+" + 1")
+			check = &fields[i]
+		case f.RowSCN:
+			check = &fields[i]
+		case !f.Key && !f.Returning:
+			args = append(args, f.arg(row, false))
+			sets = append(sets, f.Column+" = :"+strconv.Itoa(len(args)))
+		}
+	}
+	if check == nil {
+		return errors.New("UpdateStructOptimistic: no version or rowscn field")
+	}
+	if check.RowSCN {
+		if f := row.Field(check.Index); !isRowSCNKind(f.Kind()) {
+			return fmt.Errorf("UpdateStructOptimistic: rowscn field of type %s, wanted int64", f.Type())
+		} else if f.IsZero() {
+			return errors.New("UpdateStructOptimistic: zero rowscn field, read the row (again, after the commit)")
+		}
+	}
+	for _, f := range fields {
+		if f.Key {
+			args = append(args, f.arg(row, false))
+			where = append(where, f.Column+" = :"+strconv.Itoa(len(args)))
+			keyArgs = append(keyArgs, f.arg(row, false))
+			keyWhere = append(keyWhere, f.Column+" = :"+strconv.Itoa(len(keyArgs)))
+		}
+	}
+	if len(where) == 0 {
+		return errors.New("UpdateStructOptimistic: no key field")
+	}
+	args = append(args, check.arg(row, false))
+	where = append(where, check.Column+" = :"+strconv.Itoa(len(args)))
+
+	var buf strings.Builder
+	buf.WriteString("UPDATE " + table + " SET " + strings.Join(sets, ", ") + " WHERE " + strings.Join(where, " AND "))
+	if check.Version {
+		// return the new version, too
+		fields = append(fields, structField{Column: check.Column, Index: check.Index, Returning: true})
+	}
+	args = appendReturning(&buf, args, fields, row)
+	qry := buf.String()
+	res, err := db.ExecContext(ctx, qry, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", sqlForLog(qry), err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n != 0 {
+		if check.RowSCN {
+			return refreshRowSCN(ctx, db, table, keyWhere, keyArgs, row.Field(check.Index))
+		}
+		return nil
+	}
+
+	qry = "SELECT COUNT(0) FROM " + table + " WHERE " + strings.Join(keyWhere, " AND ")
+	rows, err := db.QueryContext(ctx, qry, keyArgs...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", sqlForLog(qry), err)
+	}
+	defer rows.Close()
+	var n int64
+	if rows.Next() {
+		err = rows.Scan(&n)
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", sqlForLog(qry), err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return ErrStaleVersion
+}
+
+// refreshRowSCN sets the rowscn field to the ORA_ROWSCN of the row, or zero if it is NULL.
+func refreshRowSCN(ctx context.Context, db Querier, table string, keyWhere []string, keyArgs []interface{}, field reflect.Value) error {
+	qry := "SELECT ORA_ROWSCN FROM " + table + " WHERE " + strings.Join(keyWhere, " AND ")
+	rows, err := db.QueryContext(ctx, qry, keyArgs...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", sqlForLog(qry), err)
+	}
+	defer rows.Close()
+	var scn sql.NullInt64
+	if rows.Next() {
+		err = rows.Scan(&scn)
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", sqlForLog(qry), err)
+	}
+	field.Set(reflect.ValueOf(scn.Int64).Convert(field.Type()))
+	return nil
+}
+
+// isRowSCNKind reports whether the rowscn field can hold an SCN.
+func isRowSCNKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64, reflect.Float64:
+		return true
+	}
+	return false
+}
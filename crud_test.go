@@ -0,0 +1,138 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type recordingExecer struct {
+	qry  string
+	args []interface{}
+}
+
+func (re *recordingExecer) ExecContext(ctx context.Context, qry string, args ...interface{}) (sql.Result, error) {
+	re.qry, re.args = qry, args
+	return driverResult(1), nil
+}
+
+type driverResult int64
+
+func (r driverResult) LastInsertId() (int64, error) { return 0, nil }
+func (r driverResult) RowsAffected() (int64, error) { return int64(r), nil }
+
+func TestCRUDStruct(t *testing.T) {
+	type employee struct {
+		ID      int64  `godror:"EMP_ID,key,returning"`
+		Name    string `godror:"ENAME"`
+		Salary  float64
+		Ignored string `godror:"-"`
+		secret  string
+	}
+	ctx := context.Background()
+	var ex recordingExecer
+
+	e := employee{Name: "Scott", Salary: 3000, secret: "x"}
+	if err := InsertStruct(ctx, &ex, "emp", &e); err != nil {
+		t.Fatal(err)
+	}
+	if want := "INSERT INTO emp (ENAME, SALARY) VALUES (:1, :2) RETURNING EMP_ID INTO :3"; ex.qry != want {
+		t.Errorf("got %q, wanted %q", ex.qry, want)
+	}
+	if out, ok := ex.args[2].(sql.Out); !ok || out.Dest != &e.ID {
+		t.Errorf("got %#v, wanted sql.Out for ID", ex.args[2])
+	}
+
+	emps := []employee{{ID: 1, Name: "A", Salary: 1}, {ID: 2, Name: "B", Salary: 2}}
+	if _, err := UpdateStruct(ctx, &ex, "emp", emps); err != nil {
+		t.Fatal(err)
+	}
+	if want := "UPDATE emp SET ENAME = :1, SALARY = :2 WHERE EMP_ID = :3"; ex.qry != want {
+		t.Errorf("got %q, wanted %q", ex.qry, want)
+	}
+	if want := []interface{}{[]string{"A", "B"}, []float64{1, 2}, []int64{1, 2}}; !reflect.DeepEqual(ex.args, want) {
+		t.Errorf("got %#v, wanted %#v", ex.args, want)
+	}
+
+	if _, err := DeleteStruct(ctx, &ex, "emp", &e); err != nil {
+		t.Fatal(err)
+	}
+	if want := "DELETE FROM emp WHERE EMP_ID = :1"; ex.qry != want {
+		t.Errorf("got %q, wanted %q", ex.qry, want)
+	}
+}
+
+func (re *recordingExecer) QueryContext(ctx context.Context, qry string, args ...interface{}) (*sql.Rows, error) {
+	re.qry, re.args = qry, args
+	return nil, sql.ErrConnDone
+}
+
+func TestUpdateStructOptimistic(t *testing.T) {
+	type account struct {
+		ID      int64 `godror:",key"`
+		Balance float64
+		Version int64 `godror:"VER,version"`
+	}
+	var ex recordingExecer
+	a := account{ID: 1, Balance: 10, Version: 3}
+	if err := UpdateStructOptimistic(context.Background(), &ex, "accounts", &a); err != nil {
+		t.Fatal(err)
+	}
+	if want := "UPDATE accounts SET BALANCE = :1, VER = VER + 1 WHERE ID = :2 AND VER = :3 RETURNING VER INTO :4"; ex.qry != want {
+		t.Errorf("got %q, wanted %q", ex.qry, want)
+	}
+	if out, ok := ex.args[3].(sql.Out); !ok || out.Dest != &a.Version {
+		t.Errorf("got %#v, wanted sql.Out for Version", ex.args[3])
+	}
+
+	type doc struct {
+		ID     string `godror:",key"`
+		Body   string
+		RowSCN int64 `godror:",rowscn"`
+	}
+	// after the update, the rowscn is re-read
+	rex := &qryRecorder{recordingExecer: &ex}
+	if err := UpdateStructOptimistic(context.Background(), rex, "docs", &doc{ID: "a", RowSCN: 42}); !errors.Is(err, sql.ErrConnDone) {
+		t.Fatalf("got %+v, wanted the error of the re-read", err)
+	}
+	if want := []string{
+		"UPDATE docs SET BODY = :1 WHERE ID = :2 AND ORA_ROWSCN = :3",
+		"SELECT ORA_ROWSCN FROM docs WHERE ID = :1",
+	}; !reflect.DeepEqual(rex.qrys, want) {
+		t.Errorf("got %q, wanted %q", rex.qrys, want)
+	}
+
+	if err := UpdateStructOptimistic(context.Background(), &ex, "docs", &doc{ID: "a"}); err == nil {
+		t.Error("zero rowscn: wanted error")
+	}
+	type badDoc struct {
+		ID     string `godror:",key"`
+		Body   string
+		RowSCN string `godror:",rowscn"`
+	}
+	if err := UpdateStructOptimistic(context.Background(), &ex, "docs", &badDoc{ID: "a", RowSCN: "42"}); err == nil {
+		t.Error("string rowscn: wanted error")
+	}
+}
+
+// qryRecorder records all the statements.
+type qryRecorder struct {
+	*recordingExecer
+	qrys []string
+}
+
+func (qr *qryRecorder) ExecContext(ctx context.Context, qry string, args ...interface{}) (sql.Result, error) {
+	qr.qrys = append(qr.qrys, qry)
+	return qr.recordingExecer.ExecContext(ctx, qry, args...)
+}
+func (qr *qryRecorder) QueryContext(ctx context.Context, qry string, args ...interface{}) (*sql.Rows, error) {
+	qr.qrys = append(qr.qrys, qry)
+	return qr.recordingExecer.QueryContext(ctx, qry, args...)
+}
@@ -13,10 +13,13 @@ import "C"
 import (
 	"database/sql"
 	"database/sql/driver"
+	"encoding"
 	"errors"
 	"fmt"
 	"io"
 	"reflect"
+	"strconv"
+	"strings"
 	"time"
 	"unsafe"
 )
@@ -27,6 +30,7 @@ type Data struct {
 	dpiData       C.dpiData
 	implicitObj   bool
 	NativeTypeNum C.dpiNativeTypeNum
+	oracleTypeNum C.dpiOracleTypeNum // of the variable or JSON node, if known
 }
 
 var ErrNotSupported = errors.New("not supported")
@@ -269,11 +273,18 @@ func (d *Data) GetTimeIn(serverTZ *time.Location) time.Time {
 	}
 	//ts := C.dpiData_getTimestamp(&d.dpiData)
 	ts := *((*C.dpiTimestamp)(unsafe.Pointer(&d.dpiData.value)))
-	return time.Date(
-		int(ts.year), time.Month(ts.month), int(ts.day),
-		int(ts.hour), int(ts.minute), int(ts.second), int(ts.fsecond),
-		timeZoneFor(ts.tzHourOffset, ts.tzMinuteOffset, serverTZ),
-	)
+	return timeFromTimestamp(ts, d.hasTZ(ts), serverTZ)
+}
+
+// hasTZ reports whether ts is of a type with time zone - guessed from its offset if the type is unknown.
+func (d *Data) hasTZ(ts C.dpiTimestamp) bool {
+	if d.oracleTypeNum != 0 {
+		return isTZType(d.oracleTypeNum)
+	}
+	if d.ObjectType != nil && d.ObjectType.OracleTypeNum != 0 {
+		return isTZType(d.ObjectType.OracleTypeNum)
+	}
+	return hasTZOffset(ts)
 }
 
 // SetTime sets Time to data.
@@ -307,10 +318,303 @@ func (d *Data) SetUint64(u uint64) {
 }
 
 // IntervalYM holds Years and Months as interval.
+//
+// It binds as INTERVAL YEAR TO MONTH (also as OUT parameter), and scans from such columns.
 type IntervalYM struct {
 	Years, Months int
 }
 
+var _ = fmt.Stringer(IntervalYM{})
+var _ = encoding.TextMarshaler(IntervalYM{})
+var _ = encoding.TextUnmarshaler((*IntervalYM)(nil))
+var _ = sql.Scanner((*IntervalYM)(nil))
+var _ = driver.Valuer(IntervalYM{})
+
+// String returns the interval in Oracle's Y-M format (as rows return INTERVAL YEAR TO MONTH).
+func (ym IntervalYM) String() string {
+	years, months := ym.Years, ym.Months
+	var sign string
+	if years < 0 || months < 0 {
+		sign = "-"
+		if years < 0 {
+			years = -years
+		}
+		if months < 0 {
+			months = -months
+		}
+	}
+	return sign + strconv.Itoa(years) + "-" + strconv.Itoa(months)
+}
+
+// MarshalText marshals the interval in Y-M format.
+func (ym IntervalYM) MarshalText() ([]byte, error) { return []byte(ym.String()), nil }
+
+// UnmarshalText parses the Y-M format (with optional sign).
+func (ym *IntervalYM) UnmarshalText(p []byte) error {
+	s := strings.TrimSpace(string(p))
+	*ym = IntervalYM{}
+	if s == "" {
+		return nil
+	}
+	var neg bool
+	switch s[0] {
+	case '-':
+		neg, s = true, s[1:]
+	case '+':
+		s = s[1:]
+	}
+	i := strings.IndexByte(s, '-')
+	if i < 0 {
+		return fmt.Errorf("%q: %w", string(p), errBadIntervalYM)
+	}
+	years, err := strconv.Atoi(s[:i])
+	if err != nil {
+		return fmt.Errorf("%q: %w", string(p), errBadIntervalYM)
+	}
+	months, err := strconv.Atoi(s[i+1:])
+	if err != nil || years < 0 || months < 0 || months > 11 {
+		return fmt.Errorf("%q: %w", string(p), errBadIntervalYM)
+	}
+	if neg {
+		years, months = -years, -months
+	}
+	ym.Years, ym.Months = years, months
+	return nil
+}
+
+var errBadIntervalYM = errors.New("bad INTERVAL YEAR TO MONTH: wanted Y-M")
+
+// Scan the interval from IntervalYM, or a string or []byte in Y-M format.
+func (ym *IntervalYM) Scan(v interface{}) error {
+	switch x := v.(type) {
+	case nil:
+		*ym = IntervalYM{}
+	case IntervalYM:
+		*ym = x
+	case string:
+		return ym.UnmarshalText([]byte(x))
+	case []byte:
+		return ym.UnmarshalText(x)
+	default:
+		return fmt.Errorf("cannot scan %T into IntervalYM", v)
+	}
+	return nil
+}
+
+// Value returns the interval in Y-M format, which Oracle converts implicitly.
+func (ym IntervalYM) Value() (driver.Value, error) { return ym.String(), nil }
+
+// IntervalDS is an INTERVAL DAY TO SECOND, with text marshaling.
+//
+// Binds as time.Duration; INTERVAL DAY TO SECOND columns are returned as time.Duration, which it can Scan.
+type IntervalDS time.Duration
+
+var _ = fmt.Stringer(IntervalDS(0))
+var _ = encoding.TextMarshaler(IntervalDS(0))
+var _ = encoding.TextUnmarshaler((*IntervalDS)(nil))
+var _ = sql.Scanner((*IntervalDS)(nil))
+var _ = driver.Valuer(IntervalDS(0))
+
+// Duration returns the interval as time.Duration.
+func (ds IntervalDS) Duration() time.Duration { return time.Duration(ds) }
+
+// String returns the interval in Oracle's D HH:MI:SS[.FF] format, as TO_DSINTERVAL accepts it.
+func (ds IntervalDS) String() string {
+	d := time.Duration(ds)
+	var sign string
+	u := uint64(d)
+	if d < 0 {
+		// negated as uint64, so the minimum does not overflow
+		sign, u = "-", -u
+	}
+	const day = uint64(24 * time.Hour)
+	days, u := u/day, u%day
+	hours, u := u/uint64(time.Hour), u%uint64(time.Hour)
+	minutes, u := u/uint64(time.Minute), u%uint64(time.Minute)
+	seconds, nanos := u/uint64(time.Second), u%uint64(time.Second)
+	s := fmt.Sprintf("%s%d %02d:%02d:%02d", sign, days, hours, minutes, seconds)
+	if nanos != 0 {
+		s += strings.TrimRight(fmt.Sprintf(".%09d", nanos), "0")
+	}
+	return s
+}
+
+// MarshalText marshals the interval in D HH:MI:SS[.FF] format.
+func (ds IntervalDS) MarshalText() ([]byte, error) { return []byte(ds.String()), nil }
+
+// UnmarshalText parses the D HH:MI:SS[.FF] format (with optional sign).
+func (ds *IntervalDS) UnmarshalText(p []byte) error {
+	s := strings.TrimSpace(string(p))
+	*ds = 0
+	if s == "" {
+		return nil
+	}
+	var neg bool
+	switch s[0] {
+	case '-':
+		neg, s = true, s[1:]
+	case '+':
+		s = s[1:]
+	}
+	bad := func() error { return fmt.Errorf("%q: %w", string(p), errBadIntervalDS) }
+	i := strings.IndexByte(s, ' ')
+	if i < 0 {
+		return bad()
+	}
+	days, err := strconv.ParseUint(s[:i], 10, 32)
+	if err != nil {
+		return bad()
+	}
+	s = strings.TrimLeft(s[i+1:], " ")
+	var frac string
+	if i = strings.IndexByte(s, '.'); i >= 0 {
+		s, frac = s[:i], s[i+1:]
+		if len(frac) == 0 || len(frac) > 9 {
+			return bad()
+		}
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return bad()
+	}
+	var hms [3]uint64
+	for j, part := range parts {
+		if hms[j], err = strconv.ParseUint(part, 10, 8); err != nil || len(part) > 2 {
+			return bad()
+		}
+	}
+	if hms[0] > 23 || hms[1] > 59 || hms[2] > 59 {
+		return bad()
+	}
+	var nanos uint64
+	if frac != "" {
+		if nanos, err = strconv.ParseUint(frac+strings.Repeat("0", 9-len(frac)), 10, 32); err != nil {
+			return bad()
+		}
+	}
+	// the magnitude may be 1<<63 for a negative interval
+	const day = uint64(24 * time.Hour)
+	u := hms[0]*uint64(time.Hour) + hms[1]*uint64(time.Minute) + hms[2]*uint64(time.Second) + nanos
+	if days > (1<<63-u)/day || !neg && days*day+u == 1<<63 {
+		return fmt.Errorf("%q: %w", string(p), errIntervalDSRange)
+	}
+	u += days * day
+	if neg {
+		u = -u
+	}
+	*ds = IntervalDS(u)
+	return nil
+}
+
+var (
+	errBadIntervalDS   = errors.New("bad INTERVAL DAY TO SECOND: wanted D HH:MI:SS[.FF]")
+	errIntervalDSRange = errors.New("INTERVAL DAY TO SECOND out of the range of time.Duration")
+)
+
+// Scan the interval from time.Duration (as rows return INTERVAL DAY TO SECOND),
+// or a string or []byte in D HH:MI:SS[.FF] format.
+func (ds *IntervalDS) Scan(v interface{}) error {
+	switch x := v.(type) {
+	case nil:
+		*ds = 0
+	case IntervalDS:
+		*ds = x
+	case time.Duration:
+		*ds = IntervalDS(x)
+	case string:
+		return ds.UnmarshalText([]byte(x))
+	case []byte:
+		return ds.UnmarshalText(x)
+	default:
+		return fmt.Errorf("cannot scan %T into IntervalDS", v)
+	}
+	return nil
+}
+
+// Value returns the interval in D HH:MI:SS[.FF] format, which Oracle converts implicitly.
+func (ds IntervalDS) Value() (driver.Value, error) { return ds.String(), nil }
+
+// Rowid is the text form of a ROWID (or UROWID), as ROWIDTOCHAR returns it.
+//
+// Binds as a string, which Oracle converts implicitly; ROWID columns are returned as string, which it can Scan.
+type Rowid string
+
+var _ = fmt.Stringer(Rowid(""))
+var _ = encoding.TextMarshaler(Rowid(""))
+var _ = encoding.TextUnmarshaler((*Rowid)(nil))
+var _ = sql.Scanner((*Rowid)(nil))
+var _ = driver.Valuer(Rowid(""))
+
+// String returns the rowid.
+func (r Rowid) String() string { return string(r) }
+
+// MarshalText marshals the rowid.
+func (r Rowid) MarshalText() ([]byte, error) { return []byte(r), nil }
+
+// UnmarshalText checks and sets the rowid: the base64 characters of a physical ROWID,
+// with a '*' prefix for a logical UROWID (of an index-organized table).
+func (r *Rowid) UnmarshalText(p []byte) error {
+	s := strings.TrimSpace(string(p))
+	*r = ""
+	if s == "" {
+		return nil
+	}
+	t := strings.TrimPrefix(s, "*")
+	if t == "" || len(s) > 4000 {
+		return fmt.Errorf("%q: %w", string(p), errBadRowid)
+	}
+	for i := 0; i < len(t); i++ {
+		c := t[i]
+		if !('A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '+' || c == '/') {
+			return fmt.Errorf("%q: %w", string(p), errBadRowid)
+		}
+	}
+	*r = Rowid(s)
+	return nil
+}
+
+var errBadRowid = errors.New("bad ROWID")
+
+// Scan the rowid from a string or []byte.
+func (r *Rowid) Scan(v interface{}) error {
+	switch x := v.(type) {
+	case nil:
+		*r = ""
+	case Rowid:
+		*r = x
+	case string:
+		return r.UnmarshalText([]byte(x))
+	case []byte:
+		return r.UnmarshalText(x)
+	default:
+		return fmt.Errorf("cannot scan %T into Rowid", v)
+	}
+	return nil
+}
+
+// Value returns the rowid as string (NULL if empty).
+func (r Rowid) Value() (driver.Value, error) {
+	if r == "" {
+		return nil, nil
+	}
+	return string(r), nil
+}
+
+// GetRowid gets the ROWID from data, in text form.
+func (d *Data) GetRowid() Rowid {
+	if d.IsNull() {
+		return ""
+	}
+	//cRowid := C.dpiData_getRowid(&d.dpiData)
+	cRowid := *((**C.dpiRowid)(unsafe.Pointer(&d.dpiData.value)))
+	var cBuf *C.char
+	var cLen C.uint32_t
+	if C.dpiRowid_getStringValue(cRowid, &cBuf, &cLen) == C.DPI_FAILURE {
+		return ""
+	}
+	return Rowid(C.GoStringN(cBuf, C.int(cLen)))
+}
+
 // Get returns the contents of Data.
 func (d *Data) Get() interface{} {
 	if logger := getLogger(); logger != nil {
@@ -337,6 +641,8 @@ func (d *Data) Get() interface{} {
 		return d.GetLob()
 	case C.DPI_NATIVE_TYPE_OBJECT:
 		return d.GetObject()
+	case C.DPI_NATIVE_TYPE_ROWID:
+		return d.GetRowid()
 	case C.DPI_NATIVE_TYPE_STMT:
 		return d.GetStmt()
 	case C.DPI_NATIVE_TYPE_TIMESTAMP:
@@ -395,6 +701,8 @@ func (d *Data) Set(v interface{}) error {
 		}
 	case time.Duration:
 		d.SetIntervalDS(x)
+	case IntervalDS:
+		d.SetIntervalDS(time.Duration(x))
 	case IntervalYM:
 		d.SetIntervalYM(x)
 	case *Lob:
@@ -416,9 +724,9 @@ func (d *Data) Set(v interface{}) error {
 	//d.SetStmt(x)
 	case bool:
 		d.SetBool(x)
-	//case rowid:
-	//d.NativeTypeNum = C.DPI_NATIVE_TYPE_ROWID
-	//d.SetRowid(x)
+	case Rowid:
+		// ODPI-C cannot create a dpiRowid from its text, but Oracle converts the string implicitly.
+		d.SetBytes([]byte(x))
 	default:
 		return fmt.Errorf("data Set type %T: %w", v, ErrNotSupported)
 	}
@@ -453,7 +761,7 @@ func (c *conn) NewData(baseType interface{}, sliceLen, bufSize int) ([]*Data, er
 
 	data := make([]*Data, sliceLen)
 	for i := 0; i < sliceLen; i++ {
-		data[i] = &Data{dpiData: dpiData[i], NativeTypeNum: vi.NatTyp}
+		data[i] = &Data{dpiData: dpiData[i], NativeTypeNum: vi.NatTyp, oracleTypeNum: vi.Typ}
 	}
 
 	return data, nil
@@ -536,6 +844,7 @@ func newVarInfo(baseType interface{}, sliceLen, bufSize int) (varInfo, error) {
 
 func (d *Data) reset() {
 	d.NativeTypeNum = 0
+	d.oracleTypeNum = 0
 	d.ObjectType = nil
 	d.implicitObj = false
 	d.SetBytes(nil)
@@ -6,6 +6,9 @@
 package godror
 
 import (
+	"database/sql/driver"
+	"errors"
+	"math"
 	"testing"
 	"time"
 )
@@ -101,3 +104,175 @@ func BenchmarkDataGetBytes(b *testing.B) {
 		b.Log("n:", n)
 	})
 }
+
+func TestNumberScanTypeConvert(t *testing.T) {
+	for _, tc := range []struct {
+		In   driver.Value
+		Typ  NumberScanType
+		Want driver.Value
+		Err  bool
+	}{
+		{In: Number("12.5"), Typ: NumberScanString, Want: "12.5"},
+		{In: "12", Typ: NumberScanNumber, Want: Number("12")},
+		{In: Number("12"), Typ: NumberScanInt64, Want: int64(12)},
+		{In: Number("12.5"), Typ: NumberScanInt64, Err: true},
+		{In: Number("12.5"), Typ: NumberScanFloat64, Want: 12.5},
+		{In: int64(3), Typ: NumberScanFloat64, Want: float64(3)},
+		{In: int64(3), Typ: NumberScanString, Want: "3"},
+	} {
+		got, err := tc.Typ.convert(tc.In)
+		if tc.Err {
+			if err == nil {
+				t.Errorf("%#v->%d: wanted error, got %#v", tc.In, tc.Typ, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%#v->%d: %+v", tc.In, tc.Typ, err)
+		} else if got != tc.Want {
+			t.Errorf("%#v->%d: got %#v, wanted %#v", tc.In, tc.Typ, got, tc.Want)
+		}
+	}
+
+	var o stmtOptions
+	NumberColumnAs(1, NumberScanInt64)(&o)
+	NumberColumnAs(3, NumberScanString)(&o)
+	if o.NumberColumnAs(0) != NumberScanDefault || o.NumberColumnAs(1) != NumberScanInt64 || o.NumberColumnAs(3) != NumberScanString {
+		t.Errorf("got %v", o.numberColumnAs)
+	}
+}
+
+func TestIntervalYMText(t *testing.T) {
+	for _, tc := range []struct {
+		In   IntervalYM
+		Want string
+	}{
+		{IntervalYM{}, "0-0"},
+		{IntervalYM{Years: 1, Months: 6}, "1-6"},
+		{IntervalYM{Years: -2, Months: -3}, "-2-3"},
+		{IntervalYM{Months: -3}, "-0-3"},
+	} {
+		b, err := tc.In.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(b); got != tc.Want {
+			t.Errorf("%#v: got %q, wanted %q", tc.In, got, tc.Want)
+		}
+		var got IntervalYM
+		if err = got.Scan(tc.Want); err != nil {
+			t.Errorf("%q: %+v", tc.Want, err)
+		} else if got != tc.In {
+			t.Errorf("%q: got %#v, wanted %#v", tc.Want, got, tc.In)
+		}
+	}
+	var ym IntervalYM
+	if err := ym.UnmarshalText([]byte("+01-06")); err != nil || ym != (IntervalYM{Years: 1, Months: 6}) {
+		t.Errorf("+01-06: got %#v, %+v", ym, err)
+	}
+	for _, s := range []string{"1", "1-12", "a-1"} {
+		if err := ym.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%q: wanted error", s)
+		}
+	}
+}
+
+func TestIntervalDSText(t *testing.T) {
+	for _, tc := range []struct {
+		In   IntervalDS
+		Want string
+	}{
+		{0, "0 00:00:00"},
+		{IntervalDS(26*time.Hour + 3*time.Minute + 4*time.Second + 500*time.Millisecond), "1 02:03:04.5"},
+		{IntervalDS(-90 * time.Second), "-0 00:01:30"},
+		{IntervalDS(time.Nanosecond), "0 00:00:00.000000001"},
+		{IntervalDS(math.MaxInt64), "106751 23:47:16.854775807"},
+		{IntervalDS(math.MinInt64), "-106751 23:47:16.854775808"},
+	} {
+		b, err := tc.In.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(b); got != tc.Want {
+			t.Errorf("%d: got %q, wanted %q", tc.In, got, tc.Want)
+		}
+		var got IntervalDS
+		if err = got.Scan(tc.Want); err != nil {
+			t.Errorf("%q: %+v", tc.Want, err)
+		} else if got != tc.In {
+			t.Errorf("%q: got %d, wanted %d", tc.Want, got, tc.In)
+		}
+	}
+	var ds IntervalDS
+	if err := ds.Scan(90 * time.Second); err != nil || ds.Duration() != 90*time.Second {
+		t.Errorf("scan time.Duration: got %v, %+v", ds, err)
+	}
+	if err := ds.UnmarshalText([]byte("+2 3:04:05.25")); err != nil || ds.Duration() != 51*time.Hour+4*time.Minute+5250*time.Millisecond {
+		t.Errorf("+2 3:04:05.25: got %v, %+v", ds, err)
+	}
+	if v, err := IntervalDS(time.Hour).Value(); err != nil || v != "0 01:00:00" {
+		t.Errorf("Value: got %v, %+v", v, err)
+	}
+	for _, s := range []string{"1", "01:02:03", "1 24:00:00", "1 00:60:00", "1 00:00:00.", "1 00:00:00.1234567890", "a 00:00:00", "1 0:0", "200000 00:00:00", "106751 23:47:16.854775808"} {
+		if err := ds.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%q: wanted error, got %v", s, ds)
+		}
+	}
+}
+
+func TestRowidText(t *testing.T) {
+	for _, s := range []string{"AAAR3sAAEAAAACXAAA", "*BAEAcQwCwQL+", ""} {
+		var r Rowid
+		if err := r.Scan([]byte(s)); err != nil {
+			t.Errorf("%q: %+v", s, err)
+			continue
+		}
+		if b, err := r.MarshalText(); err != nil || string(b) != s || r.String() != s {
+			t.Errorf("%q: got %q, %+v", s, b, err)
+		}
+	}
+	if v, err := Rowid("").Value(); err != nil || v != nil {
+		t.Errorf("empty Value: got %v, %+v", v, err)
+	}
+	if v, err := Rowid("AAAR3sAAEAAAACXAAA").Value(); err != nil || v != "AAAR3sAAEAAAACXAAA" {
+		t.Errorf("Value: got %v, %+v", v, err)
+	}
+	var r Rowid
+	for _, s := range []string{"*", "AAAR3s-AAEAAAACX", "AAAR3s AAEAAAACX", "ROWID('x')"} {
+		if err := r.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("%q: wanted error, got %q", s, r)
+		}
+	}
+	if err := r.Scan(1); err == nil {
+		t.Error("scanned an int")
+	}
+}
+
+func TestCheckUTF8(t *testing.T) {
+	bad := "a\xffb"
+	var o stmtOptions
+	if got, err := o.checkUTF8(0, "A", bad); err != nil || got != bad {
+		t.Errorf("no check: got %q, %+v", got, err)
+	}
+	ValidateUTF8(UTF8Error, nil)(&o)
+	if _, err := o.checkUTF8(1, "B", "árvíztűrő"); err != nil {
+		t.Errorf("valid: %+v", err)
+	}
+	_, err := o.checkUTF8(1, "B", bad)
+	var ue *InvalidUTF8Error
+	if !errors.As(err, &ue) || ue.Column != 1 || ue.Name != "B" {
+		t.Errorf("error: got %+v", err)
+	}
+	var reported []InvalidUTF8Error
+	ValidateUTF8(UTF8Replace, func(e InvalidUTF8Error) { reported = append(reported, e) })(&o)
+	if got, err := o.checkUTF8(2, "C", bad); err != nil || got != "a�b" {
+		t.Errorf("replace: got %q, %+v", got, err)
+	}
+	ValidateUTF8(UTF8Report, func(e InvalidUTF8Error) { reported = append(reported, e) })(&o)
+	if got, err := o.checkUTF8(3, "D", bad); err != nil || got != bad {
+		t.Errorf("report: got %q, %+v", got, err)
+	}
+	if len(reported) != 2 || reported[0].Column != 2 || reported[1].Column != 3 {
+		t.Errorf("reported: %+v", reported)
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// DiffKind is the kind of a row difference.
+type DiffKind uint8
+
+const (
+	// DiffInserted means the key is only in the new rows.
+	DiffInserted = DiffKind('I')
+	// DiffUpdated means the key is in both, with different values.
+	DiffUpdated = DiffKind('U')
+	// DiffDeleted means the key is only in the old rows.
+	DiffDeleted = DiffKind('D')
+)
+
+func (k DiffKind) String() string {
+	switch k {
+	case DiffInserted:
+		return "inserted"
+	case DiffUpdated:
+		return "updated"
+	case DiffDeleted:
+		return "deleted"
+	default:
+		return fmt.Sprintf("DiffKind(%d)", uint8(k))
+	}
+}
+
+// RowDiff is a difference of the old and the new rows.
+type RowDiff struct {
+	// Key is the values of the key columns.
+	Key []interface{}
+	// Old is nil for DiffInserted, New is nil for DiffDeleted.
+	Old, New []interface{}
+	Kind     DiffKind
+}
+
+// DiffStats is the number of the rows per kind of difference.
+type DiffStats struct {
+	Inserted, Updated, Deleted, Same int64
+}
+
+func (s DiffStats) String() string {
+	return fmt.Sprintf("inserted=%d updated=%d deleted=%d same=%d", s.Inserted, s.Updated, s.Deleted, s.Same)
+}
+
+// ErrDiffOrder is returned by DiffRows when the rows are not ordered by their keys.
+var ErrDiffOrder = errors.New("rows are not ordered by the key")
+
+// DiffRows streams the old and new rows, and calls report for each inserted, updated or deleted row.
+//
+// The first keyColumns columns are the key, which must be unique and NOT NULL,
+// and both rows must be ordered by it: strings by their bytes
+// (ORDER BY NLSSORT(col, 'NLS_SORT=BINARY') or with NLS_SORT=BINARY),
+// NUMBERs by value, times in absolute time.
+// Unordered rows return ErrDiffOrder.
+//
+// The non-key values are compared as HashRows canonicalizes them, so the rows may come
+// from different databases. The rows are not closed.
+func DiffRows(ctx context.Context, oldRows, newRows *sql.Rows, keyColumns int, report func(context.Context, RowDiff) error) (DiffStats, error) {
+	var stats DiffStats
+	if keyColumns <= 0 {
+		keyColumns = 1
+	}
+	oldCols, err := oldRows.Columns()
+	if err != nil {
+		return stats, err
+	}
+	newCols, err := newRows.Columns()
+	if err != nil {
+		return stats, err
+	}
+	if len(oldCols) != len(newCols) || len(oldCols) < keyColumns {
+		return stats, fmt.Errorf("old has %d, new has %d columns, key is %d columns", len(oldCols), len(newCols), keyColumns)
+	}
+	oldNext := diffRowReader(oldRows, len(oldCols), keyColumns)
+	newNext := diffRowReader(newRows, len(newCols), keyColumns)
+	oldRow, err := oldNext()
+	if err != nil {
+		return stats, fmt.Errorf("old: %w", err)
+	}
+	newRow, err := newNext()
+	if err != nil {
+		return stats, fmt.Errorf("new: %w", err)
+	}
+	var oldBuf, newBuf []byte
+	for oldRow != nil || newRow != nil {
+		if err = ctx.Err(); err != nil {
+			return stats, err
+		}
+		var c int
+		if oldRow == nil {
+			c = 1
+		} else if newRow == nil {
+			c = -1
+		} else if c, err = compareKeys(oldRow[:keyColumns], newRow[:keyColumns]); err != nil {
+			return stats, err
+		}
+		var d RowDiff
+		switch {
+		case c < 0:
+			d = RowDiff{Kind: DiffDeleted, Key: oldRow[:keyColumns], Old: oldRow}
+			stats.Deleted++
+		case c > 0:
+			d = RowDiff{Kind: DiffInserted, Key: newRow[:keyColumns], New: newRow}
+			stats.Inserted++
+		default:
+			oldBuf, newBuf = oldBuf[:0], newBuf[:0]
+			for i := keyColumns; i < len(oldRow); i++ {
+				if oldBuf, err = appendCanonical(oldBuf, oldRow[i]); err != nil {
+					return stats, fmt.Errorf("old column %d (%s): %w", i, oldCols[i], err)
+				}
+				if newBuf, err = appendCanonical(newBuf, newRow[i]); err != nil {
+					return stats, fmt.Errorf("new column %d (%s): %w", i, newCols[i], err)
+				}
+			}
+			if bytes.Equal(oldBuf, newBuf) {
+				stats.Same++
+			} else {
+				d = RowDiff{Kind: DiffUpdated, Key: newRow[:keyColumns], Old: oldRow, New: newRow}
+				stats.Updated++
+			}
+		}
+		if d.Kind != 0 {
+			if err = report(ctx, d); err != nil {
+				return stats, err
+			}
+		}
+		if c <= 0 {
+			if oldRow, err = oldNext(); err != nil {
+				return stats, fmt.Errorf("old: %w", err)
+			}
+		}
+		if c >= 0 {
+			if newRow, err = newNext(); err != nil {
+				return stats, fmt.Errorf("new: %w", err)
+			}
+		}
+	}
+	return stats, nil
+}
+
+// DiffQueries runs the old and the new query (possibly on different databases),
+// and compares their results with DiffRows.
+func DiffQueries(ctx context.Context,
+	oldDB Querier, oldQry string,
+	newDB Querier, newQry string,
+	keyColumns int, report func(context.Context, RowDiff) error,
+) (DiffStats, error) {
+	oldRows, err := oldDB.QueryContext(ctx, oldQry)
+	if err != nil {
+		return DiffStats{}, fmt.Errorf("%s: %w", oldQry, err)
+	}
+	defer oldRows.Close()
+	newRows, err := newDB.QueryContext(ctx, newQry)
+	if err != nil {
+		return DiffStats{}, fmt.Errorf("%s: %w", newQry, err)
+	}
+	defer newRows.Close()
+	return DiffRows(ctx, oldRows, newRows, keyColumns, report)
+}
+
+// diffRowReader returns a function returning the next row (nil at the end),
+// checking that the keys are NOT NULL and ascending.
+func diffRowReader(rows *sql.Rows, n, keyColumns int) func() ([]interface{}, error) {
+	dest := make([]interface{}, n)
+	var prev []interface{}
+	return func() ([]interface{}, error) {
+		if !rows.Next() {
+			return nil, rows.Err()
+		}
+		row := make([]interface{}, n)
+		for i := range row {
+			dest[i] = &row[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		for i, v := range row[:keyColumns] {
+			if v == nil {
+				return nil, fmt.Errorf("key column %d is NULL", i)
+			}
+		}
+		if prev != nil {
+			c, err := compareKeys(prev[:keyColumns], row[:keyColumns])
+			if err != nil {
+				return nil, err
+			}
+			if c >= 0 {
+				return nil, fmt.Errorf("%v after %v: %w", row[:keyColumns], prev[:keyColumns], ErrDiffOrder)
+			}
+		}
+		prev = row
+		return row, nil
+	}
+}
+
+func compareKeys(a, b []interface{}) (int, error) {
+	for i := range a {
+		if c, err := compareValues(a[i], b[i]); err != nil || c != 0 {
+			return c, err
+		}
+	}
+	return 0, nil
+}
+
+// compareValues compares two non-nil values, numbers by value, strings by bytes.
+func compareValues(a, b interface{}) (int, error) {
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), nil
+		}
+	case []byte:
+		if y, ok := b.([]byte); ok {
+			return bytes.Compare(x, y), nil
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			if x.Before(y) {
+				return -1, nil
+			} else if x.After(y) {
+				return 1, nil
+			}
+			return 0, nil
+		}
+	case time.Duration:
+		if y, ok := b.(time.Duration); ok {
+			if x < y {
+				return -1, nil
+			} else if x > y {
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	ra, okA := diffRat(a)
+	rb, okB := diffRat(b)
+	if okA && okB {
+		return ra.Cmp(rb), nil
+	}
+	return 0, fmt.Errorf("cannot compare %T with %T", a, b)
+}
+
+func diffRat(v interface{}) (*big.Rat, bool) {
+	switch x := v.(type) {
+	case Number:
+		return new(big.Rat).SetString(string(x))
+	case int64:
+		return new(big.Rat).SetInt64(x), true
+	case float64:
+		r := new(big.Rat)
+		if r.SetFloat64(x) == nil {
+			return nil, false
+		}
+		return r, true
+	}
+	return nil, false
+}
@@ -0,0 +1,39 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompareValues(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		A, B interface{}
+		Want int
+	}{
+		{A: "a", B: "b", Want: -1},
+		{A: "B", B: "a", Want: -1},
+		{A: Number("10"), B: Number("9"), Want: 1},
+		{A: Number("1.50"), B: int64(1), Want: 1},
+		{A: Number("1.50"), B: float64(1.5), Want: 0},
+		{A: int64(-3), B: Number("-2.9"), Want: -1},
+		{A: []byte{1}, B: []byte{1, 0}, Want: -1},
+		{A: now, B: now.UTC(), Want: 0},
+		{A: now, B: now.Add(time.Second), Want: -1},
+	} {
+		got, err := compareValues(tc.A, tc.B)
+		if err != nil {
+			t.Errorf("%#v <> %#v: %+v", tc.A, tc.B, err)
+		} else if got != tc.Want {
+			t.Errorf("%#v <> %#v: got %d, wanted %d", tc.A, tc.B, got, tc.Want)
+		}
+	}
+	if _, err := compareValues("1", int64(1)); err == nil {
+		t.Error("wanted error for string <> int64")
+	}
+}
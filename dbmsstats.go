@@ -0,0 +1,156 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StatsOptions are the options of GatherTableStats and GatherSchemaStats.
+// The zero values mean the DBMS_STATS defaults.
+type StatsOptions struct {
+	// Progress is called by GatherSchemaStats after each table.
+	// If set, the tables are gathered one-by-one with GATHER_TABLE_STATS.
+	Progress func(context.Context, StatsProgress) error
+	// Cascade gathers the index statistics, too (default: DBMS_STATS.AUTO_CASCADE).
+	Cascade *bool
+	// NoInvalidate does not invalidate the dependent cursors (default: DBMS_STATS.AUTO_INVALIDATE).
+	NoInvalidate *bool
+	// MethodOpt is such as "FOR ALL COLUMNS SIZE AUTO".
+	MethodOpt string
+	// Granularity is for partitioned tables: AUTO, ALL, GLOBAL, PARTITION, ...
+	Granularity string
+	// PartName is the partition to gather (GatherTableStats only).
+	PartName string
+	// EstimatePercent is the percentage of rows to sample (default: DBMS_STATS.AUTO_SAMPLE_SIZE).
+	EstimatePercent float64
+	// Degree is the degree of parallelism.
+	Degree int
+	// Force gathers even if the statistics are locked.
+	Force bool
+}
+
+// StatsProgress is reported by GatherSchemaStats.
+type StatsProgress struct {
+	Table       string
+	Elapsed     time.Duration
+	Done, Total int
+}
+
+// GatherTableStats gathers the optimizer statistics of the table with DBMS_STATS.GATHER_TABLE_STATS.
+//
+// Gather stats right after a direct-path load, as the optimizer does not know about the new rows.
+func GatherTableStats(ctx context.Context, ex Execer, owner, table string, opts StatsOptions) error {
+	args := []interface{}{sql.Named("ownname", owner), sql.Named("tabname", table)}
+	var params string
+	if opts.PartName != "" {
+		params = ", partname=>:partname"
+		args = append(args, sql.Named("partname", opts.PartName))
+	}
+	rest, args := opts.plsqlArgs(args)
+	qry := "BEGIN DBMS_STATS.GATHER_TABLE_STATS(ownname=>:ownname, tabname=>:tabname" + params + rest + "); END;"
+	if _, err := ex.ExecContext(ctx, qry, args...); err != nil {
+		return fmt.Errorf("%s: %w", qry, err)
+	}
+	return nil
+}
+
+// GatherSchemaStats gathers the optimizer statistics of the schema.
+//
+// Without opts.Progress this is a DBMS_STATS.GATHER_SCHEMA_STATS call,
+// otherwise the (non-temporary) tables of the schema are gathered one-by-one,
+// and Progress is called after each of them - returning an error stops the gathering.
+func GatherSchemaStats(ctx context.Context, db interface {
+	Execer
+	Querier
+}, owner string, opts StatsOptions) error {
+	if opts.Progress == nil {
+		rest, args := opts.plsqlArgs([]interface{}{sql.Named("ownname", owner)})
+		qry := "BEGIN DBMS_STATS.GATHER_SCHEMA_STATS(ownname=>:ownname" + rest + "); END;"
+		if _, err := db.ExecContext(ctx, qry, args...); err != nil {
+			return fmt.Errorf("%s: %w", qry, err)
+		}
+		return nil
+	}
+
+	const qry = `SELECT table_name FROM all_tables
+  WHERE owner = :1 AND temporary = 'N' AND nested = 'NO' AND NVL(iot_type, '-') <> 'IOT_OVERFLOW'
+  ORDER BY table_name`
+	rows, err := db.QueryContext(ctx, qry, owner)
+	if err != nil {
+		return fmt.Errorf("%s: %w", qry, err)
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err = rows.Scan(&table); err != nil {
+			rows.Close()
+			return fmt.Errorf("%s: %w", qry, err)
+		}
+		tables = append(tables, table)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %w", qry, err)
+	}
+
+	opts.PartName = ""
+	start := time.Now()
+	for i, table := range tables {
+		if err = GatherTableStats(ctx, db, owner, table, opts); err != nil {
+			return err
+		}
+		if err = opts.Progress(ctx, StatsProgress{
+			Table: table, Done: i + 1, Total: len(tables), Elapsed: time.Since(start),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// plsqlArgs returns the named parameters for the set options, and appends their values to args.
+func (opts StatsOptions) plsqlArgs(args []interface{}) (string, []interface{}) {
+	var buf strings.Builder
+	if opts.EstimatePercent > 0 {
+		buf.WriteString(", estimate_percent=>:estimate_percent")
+		args = append(args, sql.Named("estimate_percent", opts.EstimatePercent))
+	}
+	if opts.MethodOpt != "" {
+		buf.WriteString(", method_opt=>:method_opt")
+		args = append(args, sql.Named("method_opt", opts.MethodOpt))
+	}
+	if opts.Degree > 0 {
+		buf.WriteString(", degree=>:degree")
+		args = append(args, sql.Named("degree", opts.Degree))
+	}
+	if opts.Granularity != "" {
+		buf.WriteString(", granularity=>:granularity")
+		args = append(args, sql.Named("granularity", opts.Granularity))
+	}
+	// PL/SQL BOOLEANs as literals, for older clients
+	plsqlBool := func(b bool) string {
+		if b {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+	if opts.Cascade != nil {
+		buf.WriteString(", cascade=>" + plsqlBool(*opts.Cascade))
+	}
+	if opts.NoInvalidate != nil {
+		buf.WriteString(", no_invalidate=>" + plsqlBool(*opts.NoInvalidate))
+	}
+	if opts.Force {
+		buf.WriteString(", force=>TRUE")
+	}
+	return buf.String(), args
+}
@@ -0,0 +1,27 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import "testing"
+
+func TestStatsOptionsArgs(t *testing.T) {
+	params, args := StatsOptions{}.plsqlArgs(nil)
+	if params != "" || len(args) != 0 {
+		t.Errorf("zero: got %q %v", params, args)
+	}
+	no := false
+	params, args = StatsOptions{
+		EstimatePercent: 10, MethodOpt: "FOR ALL COLUMNS SIZE 1", Degree: 4,
+		Cascade: &no, Force: true,
+	}.plsqlArgs(nil)
+	const want = ", estimate_percent=>:estimate_percent, method_opt=>:method_opt, degree=>:degree, cascade=>FALSE, force=>TRUE"
+	if params != want {
+		t.Errorf("got %q, wanted %q", params, want)
+	}
+	if len(args) != 3 {
+		t.Errorf("got %d args, wanted 3", len(args))
+	}
+}
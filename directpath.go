@@ -0,0 +1,83 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// InsertOptions are the options of InsertArrays.
+type InsertOptions struct {
+	// DirectPath inserts with the APPEND_VALUES hint: the rows are written above the high water mark,
+	// bypassing the buffer cache - much faster for big batches, but the table is locked exclusively
+	// till the commit, and cannot be read or modified in the same transaction (ORA-12838).
+	DirectPath bool
+}
+
+// InsertArrays inserts the column slices (as in array DML: []string, []int64, []time.Time, ...)
+// into table, in its own transaction, which is committed - as direct-path inserts require.
+//
+// With opts.DirectPath, the statement is retried as a conventional insert
+// when the direct-path insert is not possible (ORA-12838, ORA-12840).
+// Oracle itself falls back to a conventional insert silently when the table has triggers
+// or enabled foreign keys, for example.
+//
+// Returns the number of inserted rows.
+func InsertArrays(ctx context.Context, db TxBeginner, table string, columns []string, values []interface{}, opts InsertOptions) (int64, error) {
+	if table == "" || len(columns) == 0 || len(columns) != len(values) {
+		return 0, errors.New("InsertArrays: table, and the same number of columns and values are required")
+	}
+	qry := insertArraysQry(table, columns, opts.DirectPath)
+	n, err := insertArraysTx(ctx, db, qry, values)
+	if err != nil && opts.DirectPath {
+		if oerr, ok := AsOraErr(err); ok && (oerr.Code() == 12838 || oerr.Code() == 12840) {
+			qry = insertArraysQry(table, columns, false)
+			n, err = insertArraysTx(ctx, db, qry, values)
+		}
+	}
+	if err != nil {
+		return n, fmt.Errorf("%s: %w", qry, err)
+	}
+	return n, nil
+}
+
+func insertArraysTx(ctx context.Context, db TxBeginner, qry string, values []interface{}) (int64, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx, qry, values...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return n, err
+	}
+	return n, tx.Commit()
+}
+
+func insertArraysQry(table string, columns []string, directPath bool) string {
+	var buf strings.Builder
+	buf.WriteString("INSERT ")
+	if directPath {
+		buf.WriteString("/*+ APPEND_VALUES */ ")
+	}
+	buf.WriteString("INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES (")
+	for i := range columns {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(":" + strconv.Itoa(i+1))
+	}
+	buf.WriteByte(')')
+	return buf.String()
+}
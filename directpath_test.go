@@ -0,0 +1,18 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import "testing"
+
+func TestInsertArraysQry(t *testing.T) {
+	cols := []string{"ID", "NAME"}
+	if got, want := insertArraysQry("T", cols, false), "INSERT INTO T (ID, NAME) VALUES (:1, :2)"; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+	if got, want := insertArraysQry("T", cols, true), "INSERT /*+ APPEND_VALUES */ INTO T (ID, NAME) VALUES (:1, :2)"; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+}
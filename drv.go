@@ -16,7 +16,7 @@
 //     poolMinSessions=1
 //     poolMaxSessions=1000
 //     poolMaxSessionsPerShard=
-//     poolPingInterval=
+//     poolPingInterval=60s
 //     poolIncrement=1
 //     connectionClass=
 //     standaloneConnection=0
@@ -31,6 +31,9 @@
 //     noTimezoneCheck=
 //     newPassword=
 //     onInit="ALTER SESSION SET current_schema=my_schema"
+//     currentSchema=
+//     nlsComp=
+//     nlsSort=
 //     configDir=
 //     libDir=
 //     stmtCacheSize=
@@ -86,6 +89,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unsafe"
 
@@ -173,14 +177,64 @@ func init() {
 var _ driver.Driver = (*drv)(nil)
 
 type drv struct {
-	dpiContext    *C.dpiContext
-	pools         map[string]*connPool
-	timezones     map[string]locationWithOffSecs
-	clientVersion VersionInfo
-	mu            sync.RWMutex
+	dpiContext     *C.dpiContext
+	pools          map[string]*connPool
+	supersededKeys map[string]struct{} // keys of the pools closed for a rotated password
+	timezones      map[string]locationWithOffSecs
+	clientVersion  VersionInfo
+	mu             sync.RWMutex
+	draining       bool
+	released       chan struct{} // signalled on session release while draining
+	clientOptions  ClientOptions
+	cDriverName    *C.char
 }
 
 func NewDriver() *drv { return &drv{} }
+
+// ClientOptions are the options of the Oracle Client library initialization, see Init.
+type ClientOptions struct {
+	// LibDir is the directory of the Oracle Client (Instant Client) libraries.
+	LibDir string
+	// ConfigDir is the directory of the network configuration files (as TNS_ADMIN).
+	ConfigDir string
+	// DriverName is set on the connections to be seen in the DB, instead of DriverName.
+	// It cannot be longer than 30 bytes !
+	DriverName string
+	// ErrorURL is shown in the error message when the Oracle Client library cannot be loaded.
+	ErrorURL string
+}
+
+// ErrClientInitialized is returned by Init when the Oracle Client library is already initialized.
+var ErrClientInitialized = errors.New("Oracle Client library is already initialized")
+
+// Init sets the options of the Oracle Client library initialization,
+// which is deferred till the first connection.
+//
+// The LibDir and ConfigDir given in the connection parameters take precedence.
+//
+// Init must be called before the first connection, otherwise it returns ErrClientInitialized.
+func Init(opts ClientOptions) error { return defaultDrv.Init(opts) }
+
+// Init sets the options of the Oracle Client library initialization of this driver - see Init.
+func (d *drv) Init(opts ClientOptions) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.dpiContext != nil {
+		return ErrClientInitialized
+	}
+	if len(opts.DriverName) > 30 {
+		opts.DriverName = opts.DriverName[:30]
+	}
+	d.clientOptions = opts
+	if d.cDriverName != nil {
+		C.free(unsafe.Pointer(d.cDriverName))
+		d.cDriverName = nil
+	}
+	if opts.DriverName != "" {
+		d.cDriverName = C.CString(opts.DriverName)
+	}
+	return nil
+}
 func (d *drv) Close() error {
 	if d == nil {
 		return nil
@@ -189,6 +243,9 @@ func (d *drv) Close() error {
 	defer d.mu.Unlock()
 	dpiCtx, pools := d.dpiContext, d.pools
 	d.dpiContext, d.pools, d.timezones = nil, nil, nil
+	if dpiCtx == nil {
+		return nil
+	}
 	done := make(chan error, 1)
 	go func() {
 		for _, pool := range pools {
@@ -215,34 +272,216 @@ func (d *drv) Close() error {
 	}
 }
 
+// ErrDraining is returned for new connection requests while CloseWithTimeout is running.
+var ErrDraining = errors.New("driver is draining")
+
+// PoolDrainInfo describes a session pool closed by CloseWithTimeout.
+type PoolDrainInfo struct {
+	Username, ConnectString string
+	// ForceClosed is the number of sessions still busy at the deadline.
+	ForceClosed uint32
+}
+
+// CloseWithTimeout drains and closes the driver: stops handing out sessions (returning ErrDraining),
+// waits for the busy pooled sessions to be released till the timeout, then force-closes the pools.
+//
+// Returns the pools that had busy sessions at the deadline.
+// Standalone connections are not tracked - close them before calling this.
+//
+// Use it with the driver of the sql.DB (db.Driver()) before db.Close,
+// as the Close of the driver (called by db.Close) force-closes the pools immediately.
+//
+// After it returns, the driver accepts new connection requests again (with new pools).
+func CloseWithTimeout(d driver.Driver, timeout time.Duration) ([]PoolDrainInfo, error) {
+	dr, ok := d.(*drv)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a godror driver", d)
+	}
+	return dr.CloseWithTimeout(timeout)
+}
+
+// CloseWithTimeout drains and closes the driver - see the CloseWithTimeout function.
+func (d *drv) CloseWithTimeout(timeout time.Duration) ([]PoolDrainInfo, error) {
+	if d == nil {
+		return nil, nil
+	}
+	released := make(chan struct{}, 1)
+	d.mu.Lock()
+	d.draining, d.released = true, released
+	pools := make([]*connPool, 0, len(d.pools))
+	for _, pool := range d.pools {
+		pools = append(pools, pool)
+	}
+	d.mu.Unlock()
+	defer d.undrain()
+
+	logger := getLogger()
+	busy := func() (map[*connPool]uint32, uint32) {
+		m := make(map[*connPool]uint32, len(pools))
+		var total uint32
+		for _, pool := range pools {
+			if stats, err := d.getPoolStats(pool); err == nil && stats.Busy != 0 {
+				m[pool] = stats.Busy
+				total += stats.Busy
+			}
+		}
+		return m, total
+	}
+	var m map[*connPool]uint32
+	waitReleased(released, getClock().After(timeout), func() uint32 {
+		var total uint32
+		m, total = busy()
+		if total != 0 && logger != nil {
+			logger.Log("msg", "CloseWithTimeout", "busy", total)
+		}
+		return total
+	})
+	var infos []PoolDrainInfo
+	for pool, n := range m {
+		infos = append(infos, PoolDrainInfo{
+			Username: pool.params.Username, ConnectString: pool.params.ConnectString,
+			ForceClosed: n,
+		})
+	}
+	if logger != nil && len(infos) != 0 {
+		logger.Log("msg", "CloseWithTimeout force-closes", "pools", infos)
+	}
+	return infos, d.Close()
+}
+
+// waitReleased waits till busy returns 0, re-checking it on each release, or till the deadline.
+// Reports whether all the sessions have been released.
+func waitReleased(released <-chan struct{}, deadline <-chan time.Time, busy func() uint32) bool {
+	for busy() != 0 {
+		select {
+		case <-released:
+		case <-deadline:
+			return busy() == 0
+		}
+	}
+	return true
+}
+
+// notifyReleased signals the release of a pooled session to CloseWithTimeout, if it's waiting.
+func (d *drv) notifyReleased() {
+	d.mu.RLock()
+	released := d.released
+	d.mu.RUnlock()
+	if released != nil {
+		select {
+		case released <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// undrain accepts connection requests again.
+func (d *drv) undrain() {
+	d.mu.Lock()
+	d.draining, d.released = false, nil
+	d.mu.Unlock()
+}
+
 type locationWithOffSecs struct {
 	*time.Location
 	offSecs int
 }
 type connPool struct {
-	dpiPool *C.dpiPool
-	key     string
-	params  commonAndPoolParams
+	panics    uint64 // first for 64-bit alignment
+	acquires  uint64
+	waitNanos uint64
+	timeouts  uint64
+	// superseding is set after the first successful acquire, when the pools of the old passwords have been retired
+	superseding uint32
+	mem         memStats
+	refs        poolRefs
+	dpiPool     *C.dpiPool // valid only between use and its release
+	key         string
+	baseKey     string
+	params      commonAndPoolParams
+	clockSkew   clockSkew
+}
+
+// use returns the ODPI-C pool, and a func to release it after use,
+// or nil if the pool has already been closed.
+func (p *connPool) use() (*C.dpiPool, func()) {
+	if !p.refs.use() {
+		return nil, func() {}
+	}
+	return p.dpiPool, func() {
+		if p.refs.release() {
+			p.release()
+		}
+	}
+}
+
+func (p *connPool) release() {
+	if p.dpiPool != nil {
+		C.dpiPool_release(p.dpiPool)
+		p.dpiPool = nil
+	}
 }
 
 // Purge force-closes the pool's connections then closes the pool.
 func (p *connPool) Purge() {
-	dpiPool := p.dpiPool
-	p.dpiPool = nil
-	if dpiPool != nil {
+	if dpiPool, release := p.use(); dpiPool != nil {
 		C.dpiPool_close(dpiPool, C.DPI_MODE_POOL_CLOSE_FORCE)
+		release()
 	}
+	_ = p.Close()
 }
 
+// Close closes the pool when the ones using it (acquiring a session, or reading its stats) have finished.
 func (p *connPool) Close() error {
-	dpiPool := p.dpiPool
-	p.dpiPool = nil
-	if dpiPool != nil {
-		C.dpiPool_release(dpiPool)
+	if p.refs.retire() {
+		p.release()
 	}
 	return nil
 }
 
+// poolRefs counts the users of a pool, so it is closed only when it is not used anymore.
+type poolRefs struct {
+	mu      sync.Mutex
+	n       int
+	retired bool
+	closed  bool
+}
+
+// use reports whether the pool can be used - if so, release must be called after the use.
+func (r *poolRefs) use() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return false
+	}
+	r.n++
+	return true
+}
+
+// release reports whether the pool must be closed now, as it was the last user of a retired pool.
+func (r *poolRefs) release() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.n--
+	if r.retired && r.n == 0 && !r.closed {
+		r.closed = true
+		return true
+	}
+	return false
+}
+
+// retire marks the pool to be closed, and reports whether it must be closed now, as it is not used.
+func (r *poolRefs) retire() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.retired = true
+	if r.n == 0 && !r.closed {
+		r.closed = true
+		return true
+	}
+	return false
+}
+
 func (d *drv) checkExec(f func() C.int) error {
 	runtime.LockOSThread()
 	err := d.checkExecNoLOT(f)
@@ -275,14 +514,30 @@ func (d *drv) init(configDir, libDir string) error {
 	if d.dpiContext != nil {
 		return nil
 	}
+	if configDir == "" {
+		configDir = d.clientOptions.ConfigDir
+	}
+	if libDir == "" {
+		libDir = d.clientOptions.LibDir
+	}
 	ctxParams := new(C.dpiContextCreateParams)
 	ctxParams.defaultDriverName, ctxParams.defaultEncoding = cDriverName, cUTF8
+	if d.cDriverName != nil {
+		ctxParams.defaultDriverName = d.cDriverName
+	}
+	// ODPI-C uses (or copies) these strings only during dpiContext_createWithParams
+	if d.clientOptions.ErrorURL != "" {
+		ctxParams.loadErrorUrl = C.CString(d.clientOptions.ErrorURL)
+		defer C.free(unsafe.Pointer(ctxParams.loadErrorUrl))
+	}
 	if !(configDir == "" && libDir == "") {
 		if configDir != "" {
 			ctxParams.oracleClientConfigDir = C.CString(configDir)
+			defer C.free(unsafe.Pointer(ctxParams.oracleClientConfigDir))
 		}
 		if libDir != "" {
 			ctxParams.oracleClientLibDir = C.CString(libDir)
+			defer C.free(unsafe.Pointer(ctxParams.oracleClientLibDir))
 		}
 	}
 	logger := getLogger()
@@ -329,12 +584,20 @@ var cUTF8, cDriverName = C.CString("UTF-8"), C.CString(DriverName)
 // initCommonCreateParams initializes ODPI-C common creation parameters used for creating pools and
 // standalone connections. The C strings for the encoding and driver name are
 // defined at the package level for convenience.
-func (d *drv) initCommonCreateParams(P *C.dpiCommonCreateParams, enableEvents bool, stmtCacheSize int, charset string) error {
+//
+// The returned func frees the C strings allocated for P: call it after the connection or pool is created.
+func (d *drv) initCommonCreateParams(P *C.dpiCommonCreateParams, enableEvents bool, stmtCacheSize int, charset, ncharset, driverName string) (func(), error) {
 	// initialize ODPI-C structure for common creation parameters
 	if err := d.checkExec(func() C.int {
 		return C.dpiContext_initCommonCreateParams(d.dpiContext, P)
 	}); err != nil {
-		return fmt.Errorf("initCommonCreateParams: %w", err)
+		return func() {}, fmt.Errorf("initCommonCreateParams: %w", err)
+	}
+	var toFree []*C.char
+	free := func() {
+		for _, p := range toFree {
+			C.free(unsafe.Pointer(p))
+		}
 	}
 
 	// assign encoding and national encoding
@@ -342,11 +605,28 @@ func (d *drv) initCommonCreateParams(P *C.dpiCommonCreateParams, enableEvents bo
 	if charset != "" {
 		P.encoding = C.CString(charset)
 		P.nencoding = P.encoding
+		toFree = append(toFree, P.encoding)
+	}
+	if ncharset != "" {
+		P.nencoding = C.CString(ncharset)
+		toFree = append(toFree, P.nencoding)
 	}
 
 	// assign driver name
 	P.driverName = cDriverName
 	P.driverNameLength = C.uint32_t(len(DriverName))
+	if d.cDriverName != nil {
+		P.driverName = d.cDriverName
+		P.driverNameLength = C.uint32_t(len(d.clientOptions.DriverName))
+	}
+	if driverName != "" {
+		if len(driverName) > 30 {
+			driverName = driverName[:30]
+		}
+		P.driverName = C.CString(driverName)
+		P.driverNameLength = C.uint32_t(len(driverName))
+		toFree = append(toFree, P.driverName)
+	}
 
 	// assign creation mode; always use threaded mode in order to allow
 	// goroutines to function without mutexing; enable events mode, if
@@ -363,7 +643,7 @@ func (d *drv) initCommonCreateParams(P *C.dpiCommonCreateParams, enableEvents bo
 		}
 	}
 
-	return nil
+	return free, nil
 }
 
 // createConn creates an ODPI-C connection with the specified parameters. If a pool is
@@ -375,7 +655,7 @@ func (d *drv) createConn(pool *connPool, P commonAndConnParams) (*conn, error) {
 		return nil, err
 	}
 
-	dc, err := d.acquireConn(pool, P)
+	dc, at, err := d.acquireConn(pool, P)
 	if err != nil {
 		return nil, err
 	}
@@ -389,19 +669,31 @@ func (d *drv) createConn(pool *connPool, P commonAndConnParams) (*conn, error) {
 		params:   dsn.ConnectionParams{CommonParams: P.CommonParams, ConnParams: P.ConnParams},
 		poolKey:  poolKey,
 		objTypes: make(map[string]*ObjectType),
+		mem:      &standaloneMem,
 	}
 	if pool != nil {
+		c.mem = &pool.mem
 		c.params.PoolParams = pool.params.PoolParams
 		if c.params.Username == "" {
 			c.params.Username = pool.params.Username
 		}
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), nvlD(c.params.WaitTimeout, time.Minute))
+	defer cancel()
 	if err := c.init(ctx, getOnInit(&c.params.CommonParams)); err != nil {
 		_ = c.closeNotLocking()
 		return nil, err
 	}
-	cancel()
+	if err := c.applyTag(ctx, P.ConnParams, at); err != nil {
+		_ = c.closeNotLocking()
+		return nil, err
+	}
+	if pool != nil {
+		pool.clockSkew.check(ctx, c.params.ClockSkewInterval, c.measureClockSkew)
+	}
+	if c.params.KeepAliveInterval > 0 {
+		c.startKeepAlive(c.params.KeepAliveInterval)
+	}
 
 	var a [4096]byte
 	stack := a[:runtime.Stack(a[:], false)]
@@ -414,7 +706,13 @@ func (d *drv) createConn(pool *connPool, P commonAndConnParams) (*conn, error) {
 	return &c, nil
 }
 
-func (d *drv) acquireConn(pool *connPool, P commonAndConnParams) (*C.dpiConn, error) {
+// acquiredTag is the tag of the session acquired from the pool.
+type acquiredTag struct {
+	Tag   string
+	Found bool
+}
+
+func (d *drv) acquireConn(pool *connPool, P commonAndConnParams) (*C.dpiConn, acquiredTag, error) {
 	logger := getLogger()
 	if logger != nil {
 		logger.Log("msg", "acquireConn", "pool", pool, "connParams", P)
@@ -425,14 +723,19 @@ func (d *drv) acquireConn(pool *connPool, P commonAndConnParams) (*C.dpiConn, er
 	var commonCreateParamsPtr *C.dpiCommonCreateParams
 	var commonCreateParams C.dpiCommonCreateParams
 	if pool == nil {
-		if err := d.initCommonCreateParams(&commonCreateParams, P.EnableEvents, P.StmtCacheSize, P.Charset); err != nil {
-			return nil, err
+		freeCommon, err := d.initCommonCreateParams(&commonCreateParams, P.EnableEvents, P.StmtCacheSize, P.Charset, P.NCharset, P.DriverName)
+		defer freeCommon()
+		if err != nil {
+			return nil, acquiredTag{}, err
 		}
 		commonCreateParamsPtr = &commonCreateParams
 	}
 	// manage strings
-	var cUsername, cPassword, cNewPassword, cConnectString, cConnClass *C.char
+	var cUsername, cPassword, cNewPassword, cConnectString, cConnClass, cTag *C.char
 	defer func() {
+		if cTag != nil {
+			C.free(unsafe.Pointer(cTag))
+		}
 		if cUsername != nil {
 			C.free(unsafe.Pointer(cUsername))
 		}
@@ -455,7 +758,7 @@ func (d *drv) acquireConn(pool *connPool, P commonAndConnParams) (*C.dpiConn, er
 	if err := d.checkExec(func() C.int {
 		return C.dpiContext_initConnCreateParams(d.dpiContext, &connCreateParams)
 	}); err != nil {
-		return nil, fmt.Errorf("initConnCreateParams: %w", err)
+		return nil, acquiredTag{}, fmt.Errorf("initConnCreateParams: %w", err)
 	}
 
 	// assign connection class
@@ -465,6 +768,24 @@ func (d *drv) acquireConn(pool *connPool, P commonAndConnParams) (*C.dpiConn, er
 		connCreateParams.connectionClassLength = C.uint32_t(len(P.ConnClass))
 	}
 
+	// assign tag and purity (only relevant for pooled connections)
+	if pool != nil && P.Tag != "" {
+		cTag = C.CString(P.Tag)
+		connCreateParams.tag = cTag
+		connCreateParams.tagLength = C.uint32_t(len(P.Tag))
+		if P.MatchAnyTag {
+			connCreateParams.matchAnyTag = 1
+		}
+	}
+	if pool != nil {
+		switch P.Purity {
+		case dsn.PurityNew:
+			connCreateParams.purity = C.DPI_PURITY_NEW
+		case dsn.PuritySelf:
+			connCreateParams.purity = C.DPI_PURITY_SELF
+		}
+	}
+
 	// assign new password (only relevant for standalone connections)
 	if pool == nil && !P.NewPassword.IsZero() {
 		cNewPassword = C.CString(P.NewPassword.Secret())
@@ -520,7 +841,7 @@ func (d *drv) acquireConn(pool *connPool, P commonAndConnParams) (*C.dpiConn, er
 				tbd = append(tbd, func() { C.free(unsafe.Pointer(cs)) })
 				C.dpiData_setBytes(&tempData, cs, C.uint32_t(len(value)))
 			default:
-				return nil, errors.New("unsupported data type for sharding")
+				return nil, acquiredTag{}, errors.New("unsupported data type for sharding")
 			}
 			columns[i].value = tempData.value
 		}
@@ -538,7 +859,12 @@ func (d *drv) acquireConn(pool *connPool, P commonAndConnParams) (*C.dpiConn, er
 
 	// if a pool was provided, assign the pool
 	if pool != nil {
-		connCreateParams.pool = pool.dpiPool
+		dpiPool, release := pool.use()
+		if dpiPool == nil {
+			return nil, acquiredTag{}, fmt.Errorf("pool %p is closed: %w", pool, driver.ErrBadConn)
+		}
+		defer release()
+		connCreateParams.pool = dpiPool
 	}
 
 	// setup credentials
@@ -553,31 +879,56 @@ func (d *drv) acquireConn(pool *connPool, P commonAndConnParams) (*C.dpiConn, er
 	if password != "" {
 		cPassword = C.CString(password)
 	}
-	if P.ConnectString != "" {
-		cConnectString = C.CString(P.ConnectString)
+	connectString, err := P.NetConnectString()
+	if err != nil {
+		return nil, acquiredTag{}, err
+	}
+	if connectString != "" {
+		cConnectString = C.CString(connectString)
 	}
 
 	// create ODPI-C connection
 	var dc *C.dpiConn
-	if err := d.checkExec(func() C.int {
+	start := time.Now()
+	err = d.checkExec(func() C.int {
 		return C.dpiConn_create(
 			d.dpiContext,
 			cUsername, C.uint32_t(len(username)),
 			cPassword, C.uint32_t(len(password)),
-			cConnectString, C.uint32_t(len(P.ConnectString)),
+			cConnectString, C.uint32_t(len(connectString)),
 			commonCreateParamsPtr,
 			&connCreateParams, &dc,
 		)
-	}); err != nil {
+	})
+	if pool != nil {
+		atomic.AddUint64(&pool.acquires, 1)
+		atomic.AddUint64(&pool.waitNanos, uint64(time.Since(start)))
+	}
+	if err != nil {
 		if pool != nil {
+			var cd interface{ Code() int }
+			if errors.As(err, &cd) && cd.Code() == 24457 {
+				// ORA-24457: OCISessionGet() could not find a free session in the specified timeout period
+				atomic.AddUint64(&pool.timeouts, 1)
+			}
 			stats, _ := d.getPoolStats(pool)
-			return nil, fmt.Errorf("pool=%p stats=%s params=%+v: %w",
-				pool.dpiPool, stats, connCreateParams, err)
+			return nil, acquiredTag{}, fmt.Errorf("pool=%p stats=%s params=%+v: %w",
+				connCreateParams.pool, stats, connCreateParams, err)
 		}
-		return nil, fmt.Errorf("user=%q standalone params=%+v: %w",
+		return nil, acquiredTag{}, fmt.Errorf("user=%q standalone params=%+v: %w",
 			username, connCreateParams, err)
 	}
-	return dc, nil
+	var at acquiredTag
+	if pool != nil {
+		if atomic.LoadUint32(&pool.superseding) == 0 {
+			d.retireSuperseded(pool)
+		}
+		at.Found = connCreateParams.outTagFound != 0
+		if connCreateParams.outTagLength != 0 {
+			at.Tag = C.GoStringN(connCreateParams.outTag, C.int(connCreateParams.outTagLength))
+		}
+	}
+	return dc, at, nil
 }
 
 // createConnFromParams creates a driver connection given pool parameters and connection
@@ -589,6 +940,18 @@ func (d *drv) acquireConn(pool *connPool, P commonAndConnParams) (*C.dpiConn, er
 // to acquire a connection from the pool specified by the pool parameters or
 // are used to create a standalone connection.
 func (d *drv) createConnFromParams(P dsn.ConnectionParams) (*conn, error) {
+	return d.createConnAs(P, commonAndConnParams{CommonParams: P.CommonParams, ConnParams: P.ConnParams})
+}
+
+// createConnAs is createConnFromParams with the session acquired as connP,
+// which may differ from the pool's user (P.CommonParams) - for a proxy user.
+func (d *drv) createConnAs(P dsn.ConnectionParams, connP commonAndConnParams) (*conn, error) {
+	d.mu.RLock()
+	draining := d.draining
+	d.mu.RUnlock()
+	if draining {
+		return nil, ErrDraining
+	}
 	var err error
 	var pool *connPool
 	if !P.IsStandalone() {
@@ -597,7 +960,7 @@ func (d *drv) createConnFromParams(P dsn.ConnectionParams) (*conn, error) {
 			return nil, err
 		}
 	}
-	conn, err := d.createConn(pool, commonAndConnParams{CommonParams: P.CommonParams, ConnParams: P.ConnParams})
+	conn, err := d.createConn(pool, connP)
 	if err != nil {
 		return conn, err
 	}
@@ -625,20 +988,7 @@ func (d *drv) getPool(P commonAndPoolParams) (*connPool, error) {
 		return nil, err
 	}
 
-	var usernameKey string
-	var passwordHash [sha256.Size]byte
-	if !P.Heterogeneous && !P.ExternalAuth {
-		// skip username being part of key in heterogeneous pools
-		usernameKey = P.Username
-		passwordHash = sha256.Sum256([]byte(P.Password.Secret())) // See issue #245
-	}
-	// determine key to use for pool
-	poolKey := fmt.Sprintf("%s\t%x\t%s\t%d\t%d\t%d\t%s\t%s\t%s\t%t\t%t\t%t\t%s\t%d\t%s",
-		usernameKey, passwordHash[:4], P.ConnectString, P.MinSessions, P.MaxSessions,
-		P.SessionIncrement, P.WaitTimeout, P.MaxLifeTime, P.SessionTimeout,
-		P.Heterogeneous, P.EnableEvents, P.ExternalAuth,
-		P.Timezone, P.MaxSessionsPerShard, P.PingInterval,
-	)
+	poolKey, baseKey := poolKeys(P)
 	logger := getLogger()
 	if logger != nil {
 		logger.Log("msg", "getPool", "key", poolKey)
@@ -667,11 +1017,78 @@ func (d *drv) getPool(P commonAndPoolParams) (*connPool, error) {
 		_ = pool.Close()
 		return poolOld, nil
 	}
-	pool.key = poolKey
+	pool.key, pool.baseKey = poolKey, baseKey
 	d.pools[poolKey] = pool
 	return pool, nil
 }
 
+// retireSuperseded closes the pools superseded by pool, after its first successful acquire
+// proved that its (rotated) password works.
+// The superseded pools are closed when they're not in use anymore.
+func (d *drv) retireSuperseded(pool *connPool) {
+	if !atomic.CompareAndSwapUint32(&pool.superseding, 0, 1) {
+		return
+	}
+	d.mu.Lock()
+	old := d.supersededPoolsLocked(pool)
+	d.mu.Unlock()
+	logger := getLogger()
+	for _, p := range old {
+		if logger != nil {
+			logger.Log("msg", "close superseded pool", "key", p.key)
+		}
+		_ = p.Close()
+	}
+}
+
+// poolKeys returns the key of the pool, and its base key: the key without the password,
+// so the pools of a rotated password (see CredentialProvider) can be found.
+func poolKeys(P commonAndPoolParams) (key, baseKey string) {
+	var usernameKey string
+	var passwordHash [sha256.Size]byte
+	if !P.Heterogeneous && !P.ExternalAuth {
+		// skip username being part of key in heterogeneous pools
+		usernameKey = P.Username
+		passwordHash = sha256.Sum256([]byte(P.Password.Secret())) // See issue #245
+	}
+	// RecoverPanics and KeepAliveInterval are per-connection settings, so they're not part of the key.
+	baseKey = fmt.Sprintf("%s\t%s\t%d\t%d\t%d\t%s\t%s\t%s\t%t\t%t\t%t\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s",
+		usernameKey, P.ConnectString, P.MinSessions, P.MaxSessions,
+		P.SessionIncrement, P.WaitTimeout, P.MaxLifeTime, P.SessionTimeout,
+		P.Heterogeneous, P.EnableEvents, P.ExternalAuth,
+		P.Timezone, P.MaxSessionsPerShard, P.PingInterval, P.ClockSkewInterval,
+		P.Charset, P.NCharset, P.DriverName, P.Compression, P.KerberosCCName, P.KerberosPrincipal,
+	)
+	return fmt.Sprintf("%x\t%s", passwordHash[:4], baseKey), baseKey
+}
+
+// supersededPoolsLocked removes the pools superseded by pool (the same base key with an old password),
+// and returns them for closing.
+//
+// The sessions already acquired keep their pool alive till they're closed - ODPI-C reference counts it.
+//
+// A pool with an already superseded key (an old password still in use) does not supersede the others,
+// so the pools of the old and new passwords are not recreated in turn.
+//
+// Must be called with d.mu held.
+func (d *drv) supersededPoolsLocked(pool *connPool) []*connPool {
+	if _, ok := d.supersededKeys[pool.key]; ok {
+		return nil
+	}
+	var old []*connPool
+	for k, p := range d.pools {
+		if p != pool && p.baseKey == pool.baseKey {
+			delete(d.pools, k)
+			old = append(old, p)
+			if d.supersededKeys == nil {
+				d.supersededKeys = make(map[string]struct{})
+			}
+			d.supersededKeys[k] = struct{}{}
+		}
+	}
+	return old
+}
+
 // createPool creates an ODPI-C pool with the specified parameters.
 //
 // This is done while holding the mutex in order to ensure that
@@ -680,7 +1097,9 @@ func (d *drv) createPool(P commonAndPoolParams) (*connPool, error) {
 
 	// set up common creation parameters
 	var commonCreateParams C.dpiCommonCreateParams
-	if err := d.initCommonCreateParams(&commonCreateParams, P.EnableEvents, P.StmtCacheSize, P.Charset); err != nil {
+	freeCommon, err := d.initCommonCreateParams(&commonCreateParams, P.EnableEvents, P.StmtCacheSize, P.Charset, P.NCharset, P.DriverName)
+	defer freeCommon()
+	if err != nil {
 		return nil, err
 	}
 
@@ -752,8 +1171,12 @@ func (d *drv) createPool(P commonAndPoolParams) (*connPool, error) {
 		cPassword = C.CString(P.Password.Secret())
 		defer C.free(unsafe.Pointer(cPassword))
 	}
-	if P.ConnectString != "" {
-		cConnectString = C.CString(P.ConnectString)
+	connectString, err := P.NetConnectString()
+	if err != nil {
+		return nil, err
+	}
+	if connectString != "" {
+		cConnectString = C.CString(connectString)
 		defer C.free(unsafe.Pointer(cConnectString))
 	}
 
@@ -761,7 +1184,7 @@ func (d *drv) createPool(P commonAndPoolParams) (*connPool, error) {
 	var dp *C.dpiPool
 	logger := getLogger()
 	if logger != nil {
-		logger.Log("C", "dpiPool_create", "user", P.Username, "ConnectString", P.ConnectString,
+		logger.Log("C", "dpiPool_create", "user", P.Username, "ConnectString", connectString,
 			"common", commonCreateParams, "pool",
 			fmt.Sprintf("%#v", poolCreateParams))
 	}
@@ -770,7 +1193,7 @@ func (d *drv) createPool(P commonAndPoolParams) (*connPool, error) {
 			d.dpiContext,
 			cUsername, C.uint32_t(len(P.Username)),
 			cPassword, C.uint32_t(P.Password.Len()),
-			cConnectString, C.uint32_t(len(P.ConnectString)),
+			cConnectString, C.uint32_t(len(connectString)),
 			&commonCreateParams,
 			&poolCreateParams,
 			(**C.dpiPool)(unsafe.Pointer(&dp)),
@@ -791,6 +1214,21 @@ func (d *drv) createPool(P commonAndPoolParams) (*connPool, error) {
 	}
 	C.dpiPool_setStmtCacheSize(dp, stmtCacheSize)
 
+	// set ping interval: sessions idle longer than this are pinged on acquisition
+	if P.PingInterval != 0 {
+		pingInterval := C.int(-1) // disable
+		if P.PingInterval > 0 {
+			pingInterval = C.int((P.PingInterval + time.Second - 1) / time.Second)
+			if P.PingInterval < time.Second {
+				pingInterval = 0 // always
+			}
+		}
+		if err := d.checkExec(func() C.int { return C.dpiPool_setPingInterval(dp, pingInterval) }); err != nil {
+			C.dpiPool_release(dp)
+			return nil, fmt.Errorf("setPingInterval(%d): %w", pingInterval, err)
+		}
+	}
+
 	return &connPool{dpiPool: dp, params: P}, nil
 }
 
@@ -798,46 +1236,95 @@ func (d *drv) createPool(P commonAndPoolParams) (*connPool, error) {
 type PoolStats struct {
 	Busy, Open, Max                   uint32
 	MaxLifetime, Timeout, WaitTimeout time.Duration
+	// Acquires is the number of sessions requested from the pool,
+	// WaitTime is the total time spent waiting for them,
+	// and Timeouts is the number of requests failed with ORA-24457 (no free session within WaitTimeout).
+	Acquires, Timeouts uint64
+	WaitTime           time.Duration
+	// ClockSkew is how much the database clock (SYSTIMESTAMP) is ahead of the application's,
+	// measured at ClockSkewCheckedAt (zero if clockSkewInterval is not set).
+	ClockSkew          time.Duration
+	ClockSkewCheckedAt time.Time
+	// Panics is the number of panics recovered (with recoverPanics).
+	Panics uint64
+	// Memory is the memory allocated by the driver for the variables of the pool's connections.
+	Memory MemStats
 }
 
 func (s PoolStats) String() string {
-	return fmt.Sprintf("busy=%d open=%d max=%d maxLifetime=%s timeout=%s waitTimeout=%s",
+	t := fmt.Sprintf("busy=%d open=%d max=%d maxLifetime=%s timeout=%s waitTimeout=%s",
 		s.Busy, s.Open, s.Max, s.MaxLifetime, s.Timeout, s.WaitTimeout)
+	if s.Acquires != 0 {
+		t += fmt.Sprintf(" acquires=%d waitTime=%s timeouts=%d", s.Acquires, s.WaitTime, s.Timeouts)
+	}
+	if !s.ClockSkewCheckedAt.IsZero() {
+		t += " clockSkew=" + s.ClockSkew.String()
+	}
+	if s.Panics != 0 {
+		t += fmt.Sprintf(" panics=%d", s.Panics)
+	}
+	if s.Memory != (MemStats{}) {
+		t += " memory={" + s.Memory.String() + "}"
+	}
+	return t
 }
+
+// Idle returns the number of open, but not busy sessions.
+func (s PoolStats) Idle() uint32 {
+	if s.Open < s.Busy {
+		return 0
+	}
+	return s.Open - s.Busy
+}
+
 func (p PoolStats) AsDBStats() sql.DBStats {
 	return sql.DBStats{
 		MaxOpenConnections: int(p.Max),
 		// Pool Status
 		OpenConnections: int(p.Open),
 		InUse:           int(p.Busy),
-		Idle:            int(p.Open) - int(p.Busy),
+		Idle:            int(p.Idle()),
+
+		// Counters
+		WaitDuration: p.WaitTime,
 	}
 }
 
 // Stats returns PoolStats of the pool.
 func (d *drv) getPoolStats(p *connPool) (stats PoolStats, err error) {
-	if p == nil || p.dpiPool == nil {
+	if p == nil {
+		return stats, nil
+	}
+	dpiPool, release := p.use()
+	if dpiPool == nil {
 		return stats, nil
 	}
+	defer release()
 
 	stats.Max = uint32(p.params.PoolParams.MaxSessions)
+	stats.ClockSkew, stats.ClockSkewCheckedAt = p.clockSkew.get()
+	stats.Panics = atomic.LoadUint64(&p.panics)
+	stats.Acquires = atomic.LoadUint64(&p.acquires)
+	stats.WaitTime = time.Duration(atomic.LoadUint64(&p.waitNanos))
+	stats.Timeouts = atomic.LoadUint64(&p.timeouts)
+	stats.Memory = p.mem.get()
 
 	runtime.LockOSThread()
 	defer runtime.UnlockOSThread()
 	var u C.uint32_t
-	if C.dpiPool_getBusyCount(p.dpiPool, &u) != C.DPI_FAILURE {
+	if C.dpiPool_getBusyCount(dpiPool, &u) != C.DPI_FAILURE {
 		stats.Busy = uint32(u)
 	}
-	if C.dpiPool_getOpenCount(p.dpiPool, &u) != C.DPI_FAILURE {
+	if C.dpiPool_getOpenCount(dpiPool, &u) != C.DPI_FAILURE {
 		stats.Open = uint32(u)
 	}
-	if C.dpiPool_getMaxLifetimeSession(p.dpiPool, &u) != C.DPI_FAILURE {
+	if C.dpiPool_getMaxLifetimeSession(dpiPool, &u) != C.DPI_FAILURE {
 		stats.MaxLifetime = time.Duration(u) * time.Second
 	}
-	if C.dpiPool_getTimeout(p.dpiPool, &u) != C.DPI_FAILURE {
+	if C.dpiPool_getTimeout(dpiPool, &u) != C.DPI_FAILURE {
 		stats.Timeout = time.Duration(u) * time.Second
 	}
-	if C.dpiPool_getWaitTimeout(p.dpiPool, &u) != C.DPI_FAILURE {
+	if C.dpiPool_getWaitTimeout(dpiPool, &u) != C.DPI_FAILURE {
 		stats.WaitTimeout = time.Duration(u) * time.Millisecond
 		return stats, nil
 	}
@@ -903,6 +1390,15 @@ func (oe *OraErr) Error() string {
 	}
 	return fmt.Sprintf("ORA-%05d: %s", oe.code, oe.message)
 }
+
+// Is reports whether the OraErr matches the target sentinel error (such as ErrResourceBusy).
+func (oe *OraErr) Is(target error) bool {
+	if oe == nil {
+		return false
+	}
+	sentinel, ok := oraErrSentinels[oe.code]
+	return ok && sentinel == target
+}
 func fromErrorInfo(errInfo C.dpiErrorInfo) error {
 	oe := OraErr{
 		code:        int(errInfo.code),
@@ -996,21 +1492,86 @@ func (V *VersionInfo) String() string {
 	return fmt.Sprintf("%d.%d.%d.%d.%d%s", V.Version, V.Release, V.Update, V.PortRelease, V.PortUpdate, s)
 }
 
+// LibraryVersions holds the versions of the driver stack.
+type LibraryVersions struct {
+	// Driver is the version of this driver.
+	Driver string
+	// ODPI is the version of the embedded ODPI-C library.
+	ODPI VersionInfo
+	// Client is the version of the loaded Oracle Client library.
+	Client VersionInfo
+}
+
+func (L LibraryVersions) String() string {
+	return fmt.Sprintf("godror %s ODPI-C %d.%d.%d Oracle Client %s",
+		L.Driver, L.ODPI.Version, L.ODPI.Release, L.ODPI.Update, L.Client.String())
+}
+
+// GetLibraryVersions returns the versions of this driver, the embedded ODPI-C
+// and the Oracle Client library - which is initialized if it hasn't been yet.
+func GetLibraryVersions() (LibraryVersions, error) { return defaultDrv.LibraryVersions() }
+
+// LibraryVersions returns the versions of the driver stack, see GetLibraryVersions.
+func (d *drv) LibraryVersions() (LibraryVersions, error) {
+	V := LibraryVersions{
+		Driver: Version,
+		ODPI:   VersionInfo{Version: DpiMajorVersion, Release: DpiMinorVersion, Update: DpiPatchLevel},
+	}
+	if err := d.init("", ""); err != nil {
+		return V, err
+	}
+	d.mu.RLock()
+	V.Client = d.clientVersion
+	d.mu.RUnlock()
+	return V, nil
+}
+
 var timezones = make(map[string]*time.Location)
 var timezonesMu sync.RWMutex
 
-func timeZoneFor(hourOffset, minuteOffset C.int8_t, local *time.Location) *time.Location {
+// timeFromTimestamp returns ts as a time.Time.
+//
+// A value with time zone (hasTZ) is in local iff local has the same offset at that instant
+// (keeping the region name, even around DST transitions), otherwise in a fixed zone of its offset.
+// A value without time zone is in local (UTC if nil).
+func timeFromTimestamp(ts C.dpiTimestamp, hasTZ bool, local *time.Location) time.Time {
+	date := func(loc *time.Location) time.Time {
+		return time.Date(
+			int(ts.year), time.Month(ts.month), int(ts.day),
+			int(ts.hour), int(ts.minute), int(ts.second), int(ts.fsecond),
+			loc)
+	}
+	if local == nil {
+		local = time.UTC
+	}
+	if !hasTZ {
+		return date(local)
+	}
+	off := int(ts.tzHourOffset)*3600 + int(ts.tzMinuteOffset)*60
+	t := date(time.UTC).Add(-time.Duration(off) * time.Second)
+	if lt := t.In(local); tzOffset(lt) == off {
+		return lt
+	}
+	return t.In(fixedZone(ts.tzHourOffset, ts.tzMinuteOffset))
+}
+
+func tzOffset(t time.Time) int { _, off := t.Zone(); return off }
+
+// hasTZOffset reports whether ts has a non-zero time zone offset -
+// the best guess for values of unknown type, which may come from a column without time zone.
+func hasTZOffset(ts C.dpiTimestamp) bool { return ts.tzHourOffset != 0 || ts.tzMinuteOffset != 0 }
+
+// isTZType reports whether typ carries a time zone: TIMESTAMP WITH (LOCAL) TIME ZONE.
+func isTZType(typ C.dpiOracleTypeNum) bool {
+	return typ == C.DPI_ORACLE_TYPE_TIMESTAMP_TZ || typ == C.DPI_ORACLE_TYPE_TIMESTAMP_LTZ
+}
+
+// fixedZone returns a (cached) fixed zone with the given offset, UTC for zero offset.
+func fixedZone(hourOffset, minuteOffset C.int8_t) *time.Location {
 	if hourOffset == 0 && minuteOffset == 0 {
-		if local == nil {
-			return time.UTC
-		}
-		return local
-	}
-	var tS string
-	if local != nil {
-		tS = local.String()
+		return time.UTC
 	}
-	key := fmt.Sprintf("%02d:%02d\t%s", hourOffset, minuteOffset, tS)
+	key := fmt.Sprintf("%02d:%02d", hourOffset, minuteOffset)
 	timezonesMu.RLock()
 	tz, ok := timezones[key]
 	timezonesMu.RUnlock()
@@ -1022,15 +1583,7 @@ func timeZoneFor(hourOffset, minuteOffset C.int8_t, local *time.Location) *time.
 	if tz, ok = timezones[key]; ok {
 		return tz
 	}
-	off := int(hourOffset)*3600 + int(minuteOffset)*60
-	if local != nil {
-		if _, localOff := time.Now().In(local).Zone(); off == localOff {
-			tz = local
-		}
-	}
-	if tz == nil {
-		tz = time.FixedZone(key[:5], off)
-	}
+	tz = time.FixedZone(key, int(hourOffset)*3600+int(minuteOffset)*60)
 	timezones[key] = tz
 	return tz
 }
@@ -1102,18 +1655,46 @@ func (c connector) Connect(ctx context.Context) (driver.Conn, error) {
 		}
 	}
 
+	if tag, ok := ctx.Value(sessionTagCtxKey{}).(string); ok {
+		params.ConnParams.Tag = tag
+	}
 	if ctxValue := ctx.Value(userPasswCtxKey{}); ctxValue != nil {
 		if up, ok := ctxValue.(UserPasswdConnClassTag); ok {
 			params.CommonParams.Username = up.Username
 			params.CommonParams.Password = up.Password
 			params.ConnParams.ConnClass = up.ConnClass
 		}
+	} else if params.CredentialProvider != nil {
+		username, password, err := params.CredentialProvider(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("CredentialProvider: %w", err)
+		}
+		if username != "" {
+			params.CommonParams.Username = username
+		}
+		params.CommonParams.Password = password
+		params.ExternalAuth = params.ExternalAuth && params.Username == "" && password.IsZero()
+	}
+	// The proxy user is only for acquiring the session, the pool must be created with the proxy's credentials.
+	connP := commonAndConnParams{CommonParams: params.CommonParams, ConnParams: params.ConnParams}
+	if err := applyProxyUser(ctx, &connP.CommonParams, params.IsStandalone(), params.Heterogeneous); err != nil {
+		return nil, err
+	}
+	if params.SecondFactor != nil {
+		if !params.IsStandalone() {
+			return nil, errors.New("SecondFactor needs standaloneConnection=1")
+		}
+		code, err := params.SecondFactor(ctx, connP.Username)
+		if err != nil {
+			return nil, fmt.Errorf("SecondFactor: %w", err)
+		}
+		connP.CommonParams.Password = dsn.NewPassword(connP.Password.Secret() + code)
 	}
 
 	if logger != nil {
-		logger.Log("msg", "connect", "poolParams", params.PoolParams, "connParams", params.ConnParams, "common", params.CommonParams)
+		logger.Log("msg", "connect", "poolParams", params.PoolParams, "connParams", connP.ConnParams, "common", connP.CommonParams)
 	}
-	return c.drv.createConnFromParams(params)
+	return c.drv.createConnAs(params, connP)
 }
 
 // Driver returns the underlying Driver of the Connector,
@@ -1144,35 +1725,58 @@ func NewSessionIniter(m map[string]string) func(context.Context, driver.ConnPrep
 	}
 	return mkExecMany([]string{buf.String()})
 }
+
+// getOnInit returns the session initializer: OnInit, preceded by the ALTER SESSION of NLSComp and NLSSort (if set),
+// or, if OnInit is nil, the execution of OnInitStmts and AlterSession (extended with NLSComp and NLSSort).
 func getOnInit(P *CommonParams) func(context.Context, driver.ConnPrepareContext) error {
+	var nls [][2]string
+	if P.NLSComp != "" {
+		nls = append(nls, [2]string{"NLS_COMP", P.NLSComp})
+	}
+	if P.NLSSort != "" {
+		nls = append(nls, [2]string{"NLS_SORT", P.NLSSort})
+	}
 	if P.OnInit != nil {
-		return P.OnInit
+		if len(nls) == 0 {
+			return P.OnInit
+		}
+		setNLS, onInit := mkExecMany([]string{alterSessionQry(nls)}), P.OnInit
+		return func(ctx context.Context, conn driver.ConnPrepareContext) error {
+			if err := setNLS(ctx, conn); err != nil {
+				return err
+			}
+			return onInit(ctx, conn)
+		}
 	}
 	stmts := P.OnInitStmts
 	stmts = stmts[:len(stmts):len(stmts)]
-	if len(P.AlterSession) != 0 {
-		var buf strings.Builder
-		buf.WriteString("ALTER SESSION SET")
-		for _, kv := range P.AlterSession {
-			buf.WriteByte(' ')
-			buf.WriteString(kv[0])
-			buf.WriteByte('=')
-			if strings.EqualFold(kv[0], "CURRENT_SCHEMA") {
-				buf.WriteString(kv[1])
-			} else {
-				buf.WriteByte('\'')
-				buf.WriteString(strings.Replace(kv[1], "'", "''", -1))
-				buf.WriteByte('\'')
-			}
-		}
-		stmts = append(stmts, buf.String())
+	alterSession := append(P.AlterSession[:len(P.AlterSession):len(P.AlterSession)], nls...)
+	if len(alterSession) != 0 {
+		stmts = append(stmts, alterSessionQry(alterSession))
 	}
 	if len(stmts) == 0 {
 		return nil
 	}
+	return mkExecMany(stmts)
+}
 
-	P.OnInit = mkExecMany(stmts)
-	return P.OnInit
+// alterSessionQry returns the ALTER SESSION statement that sets the given key-value pairs.
+func alterSessionQry(alterSession [][2]string) string {
+	var buf strings.Builder
+	buf.WriteString("ALTER SESSION SET")
+	for _, kv := range alterSession {
+		buf.WriteByte(' ')
+		buf.WriteString(kv[0])
+		buf.WriteByte('=')
+		if strings.EqualFold(kv[0], "CURRENT_SCHEMA") {
+			buf.WriteString(kv[1])
+		} else {
+			buf.WriteByte('\'')
+			buf.WriteString(strings.Replace(kv[1], "'", "''", -1))
+			buf.WriteByte('\'')
+		}
+	}
+	return buf.String()
 }
 
 // mkExecMany returns a function that applies the queries to the connection.
@@ -1181,7 +1785,7 @@ func mkExecMany(qrys []string) func(context.Context, driver.ConnPrepareContext)
 		logger := ctxGetLog(ctx)
 		for _, qry := range qrys {
 			if logger != nil {
-				logger.Log("msg", "execMany", "qry", qry)
+				logger.Log("msg", "execMany", "qry", sqlForLog(qry))
 			}
 			st, err := conn.PrepareContext(ctx, qry)
 			if err == nil {
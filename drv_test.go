@@ -6,9 +6,17 @@
 package godror
 
 import (
+	"context"
+	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/godror/godror/dsn"
 )
 
 func TestNewDriverSepContext(t *testing.T) {
@@ -77,3 +85,294 @@ func TestMarshalJSON(t *testing.T) {
 	}
 	t.Log(string(b))
 }
+
+func TestPoolStatsIdle(t *testing.T) {
+	s := PoolStats{Busy: 3, Open: 5, Max: 10, Acquires: 7, Timeouts: 1}
+	if got := s.Idle(); got != 2 {
+		t.Errorf("got %d idle, wanted 2", got)
+	}
+	if got := s.AsDBStats().Idle; got != 2 {
+		t.Errorf("got %d DBStats.Idle, wanted 2", got)
+	}
+	if got := (PoolStats{Busy: 2, Open: 1}).Idle(); got != 0 {
+		t.Errorf("got %d idle, wanted 0", got)
+	}
+}
+
+func TestSecondFactor(t *testing.T) {
+	errNoToken := errors.New("no token")
+	var P dsn.ConnectionParams
+	P.Username, P.Password = "scott", dsn.NewPassword("tiger")
+	P.SecondFactor = func(_ context.Context, username string) (string, error) {
+		if username != "scott" {
+			t.Errorf("got username %q, wanted scott", username)
+		}
+		return "", errNoToken
+	}
+	ctx := context.Background()
+	if _, err := (connector{drv: &drv{}, ConnectionParams: P}).Connect(ctx); err == nil {
+		t.Error("wanted error for pooled connection")
+	}
+	P.StandaloneConnection = true
+	if _, err := (connector{drv: &drv{}, ConnectionParams: P}).Connect(ctx); !errors.Is(err, errNoToken) {
+		t.Errorf("got %v, wanted %v", err, errNoToken)
+	}
+}
+
+type recordingPreparer struct{ qrys []string }
+
+func (rp *recordingPreparer) PrepareContext(ctx context.Context, qry string) (driver.Stmt, error) {
+	rp.qrys = append(rp.qrys, qry)
+	return recordingStmt{}, nil
+}
+
+type recordingStmt struct{}
+
+func (recordingStmt) Close() error  { return nil }
+func (recordingStmt) NumInput() int { return -1 }
+func (recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.ResultNoRows, nil
+}
+func (recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("no rows")
+}
+func (recordingStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return driver.ResultNoRows, nil
+}
+
+func TestOnInitNLS(t *testing.T) {
+	P := dsn.CommonParams{
+		AlterSession: [][2]string{{"TIME_ZONE", "UTC"}},
+		NLSComp:      "LINGUISTIC", NLSSort: "BINARY_CI",
+	}
+	onInit := getOnInit(&P)
+	if onInit == nil {
+		t.Fatal("no onInit")
+	}
+	var rp recordingPreparer
+	if err := onInit(context.Background(), &rp); err != nil {
+		t.Fatal(err)
+	}
+	const want = "ALTER SESSION SET TIME_ZONE='UTC' NLS_COMP='LINGUISTIC' NLS_SORT='BINARY_CI'"
+	if len(rp.qrys) != 1 || rp.qrys[0] != want {
+		t.Errorf("got %q, wanted %q", rp.qrys, want)
+	}
+	if len(P.AlterSession) != 1 {
+		t.Errorf("AlterSession changed: %q", P.AlterSession)
+	}
+}
+
+func TestSupersededPools(t *testing.T) {
+	P := commonAndPoolParams{
+		CommonParams: dsn.CommonParams{Username: "app", Password: dsn.NewPassword("old")},
+		PoolParams:   dsn.PoolParams{MaxSessions: 10},
+	}
+	oldKey, oldBase := poolKeys(P)
+	P.Password = dsn.NewPassword("rotated")
+	newKey, newBase := poolKeys(P)
+	if oldKey == newKey || oldBase != newBase {
+		t.Fatalf("a rotated password must change the key (%q, %q), but not the base key (%q, %q)", oldKey, newKey, oldBase, newBase)
+	}
+	P.MaxSessions = 11
+	otherKey, otherBase := poolKeys(P)
+	P.Compression = "high"
+	if compKey, _ := poolKeys(P); compKey == otherKey {
+		t.Errorf("compression does not change the pool key %q", compKey)
+	}
+	compKey, _ := poolKeys(P)
+	P.RecoverPanics, P.KeepAliveInterval = true, time.Minute
+	if key, _ := poolKeys(P); key != compKey {
+		t.Errorf("the per-connection settings changed the pool key: %q != %q", key, compKey)
+	}
+
+	d := drv{pools: make(map[string]*connPool)}
+	oldPool := &connPool{key: oldKey, baseKey: oldBase}
+	otherPool := &connPool{key: otherKey, baseKey: otherBase}
+	newPool := &connPool{key: newKey, baseKey: newBase}
+	for _, p := range []*connPool{oldPool, otherPool, newPool} {
+		d.pools[p.key] = p
+	}
+	if got := d.supersededPoolsLocked(newPool); len(got) != 1 || got[0] != oldPool {
+		t.Errorf("got %v, wanted only the old pool", got)
+	}
+	if len(d.pools) != 2 || d.pools[newKey] != newPool || d.pools[otherKey] != otherPool {
+		t.Errorf("remaining pools: %v", d.pools)
+	}
+
+	// the old password is still in use somewhere: its recreated pool must not supersede the new one
+	oldAgain := &connPool{key: oldKey, baseKey: oldBase}
+	d.pools[oldKey] = oldAgain
+	d.retireSuperseded(oldAgain)
+	if len(d.pools) != 3 || d.pools[newKey] != newPool {
+		t.Errorf("the old password superseded the new one: %v", d.pools)
+	}
+	if oldAgain.refs.closed || newPool.refs.closed {
+		t.Error("closed the wrong pool")
+	}
+
+	// a superseded pool in use is closed by its last user
+	d = drv{pools: map[string]*connPool{oldKey: oldPool, newKey: newPool}}
+	oldPool.refs = poolRefs{}
+	_, release := oldPool.use()
+	d.retireSuperseded(newPool)
+	if _, ok := d.pools[oldKey]; ok || oldPool.refs.closed {
+		t.Errorf("superseded pool: %v closed=%t", d.pools, oldPool.refs.closed)
+	}
+	release()
+	if !oldPool.refs.closed {
+		t.Error("superseded pool is not closed after its last use")
+	}
+}
+
+func TestPoolRefs(t *testing.T) {
+	var r poolRefs
+	var closed, inUse, usedAfterClose int32
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			for j := 0; j < 1000; j++ {
+				if !r.use() {
+					continue
+				}
+				atomic.AddInt32(&inUse, 1)
+				if atomic.LoadInt32(&closed) != 0 {
+					atomic.AddInt32(&usedAfterClose, 1)
+				}
+				atomic.AddInt32(&inUse, -1)
+				if r.release() {
+					atomic.AddInt32(&closed, 1)
+				}
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-start
+		time.Sleep(time.Millisecond)
+		if r.retire() {
+			if n := atomic.LoadInt32(&inUse); n != 0 {
+				t.Errorf("closed while %d use it", n)
+			}
+			atomic.AddInt32(&closed, 1)
+		}
+	}()
+	close(start)
+	wg.Wait()
+	if closed != 1 {
+		t.Errorf("closed %d times", closed)
+	}
+	if usedAfterClose != 0 {
+		t.Errorf("used %d times after close", usedAfterClose)
+	}
+	if r.use() {
+		t.Error("usable after close")
+	}
+}
+
+func TestOnInitNLSChained(t *testing.T) {
+	var called int
+	P := dsn.CommonParams{
+		NLSComp: "LINGUISTIC", NLSSort: "BINARY_AI",
+		OnInit: func(ctx context.Context, conn driver.ConnPrepareContext) error {
+			called++
+			_, err := conn.PrepareContext(ctx, "BEGIN NULL; END;")
+			return err
+		},
+	}
+	for i := 1; i <= 2; i++ {
+		var rp recordingPreparer
+		if err := getOnInit(&P)(context.Background(), &rp); err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"ALTER SESSION SET NLS_COMP='LINGUISTIC' NLS_SORT='BINARY_AI'", "BEGIN NULL; END;"}
+		if !reflect.DeepEqual(rp.qrys, want) || called != i {
+			t.Errorf("%d. got %q (OnInit called %d times), wanted %q", i, rp.qrys, called, want)
+		}
+	}
+}
+
+func TestWaitReleased(t *testing.T) {
+	fc := newFakeClock()
+	defer setClock(fc)()
+	d := &drv{draining: true, released: make(chan struct{}, 1)}
+	var busy int32 = 2
+	done := make(chan bool, 1)
+	go func() {
+		done <- waitReleased(d.released, getClock().After(time.Minute), func() uint32 {
+			return uint32(atomic.LoadInt32(&busy))
+		})
+	}()
+	for _, n := range []int32{1, 0} {
+		atomic.StoreInt32(&busy, n)
+		d.notifyReleased()
+	}
+	if ok := <-done; !ok {
+		t.Error("all released, yet reported busy")
+	}
+
+	atomic.StoreInt32(&busy, 1)
+	go func() {
+		done <- waitReleased(d.released, getClock().After(time.Minute), func() uint32 {
+			return uint32(atomic.LoadInt32(&busy))
+		})
+	}()
+	for i := 0; i < 100 && len(done) == 0; i++ {
+		fc.Advance(time.Minute)
+		time.Sleep(time.Millisecond)
+	}
+	if ok := <-done; ok {
+		t.Error("busy at the deadline, yet reported released")
+	}
+}
+
+func TestCloseWithTimeoutUndrains(t *testing.T) {
+	d := &drv{}
+	if _, err := d.CloseWithTimeout(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	d.mu.RLock()
+	draining, released := d.draining, d.released
+	d.mu.RUnlock()
+	if draining || released != nil {
+		t.Errorf("still draining (%t, %v)", draining, released)
+	}
+	d.notifyReleased() // must not block
+}
+
+func TestInitClientOptions(t *testing.T) {
+	d := NewDriver()
+	defer d.Close()
+	long := "a-driver-name-longer-than-thirty-bytes"
+	if err := d.Init(ClientOptions{DriverName: long, LibDir: "/opt/oracle"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := d.clientOptions.DriverName; got != long[:30] {
+		t.Errorf("DriverName: got %q, wanted %q", got, long[:30])
+	}
+	if d.cDriverName == nil {
+		t.Error("no C driver name")
+	}
+	// a second Init before connecting replaces the options
+	if err := d.Init(ClientOptions{ConfigDir: "/etc/tns"}); err != nil {
+		t.Fatal(err)
+	}
+	if d.cDriverName != nil || d.clientOptions.LibDir != "" || d.clientOptions.ConfigDir != "/etc/tns" {
+		t.Errorf("options not replaced: %+v", d.clientOptions)
+	}
+}
+
+func TestLibraryVersionsString(t *testing.T) {
+	V := LibraryVersions{
+		Driver: "v1.2.3",
+		ODPI:   VersionInfo{Version: 4, Release: 5, Update: 6},
+		Client: VersionInfo{Version: 21, Release: 9},
+	}
+	if got, want := V.String(), "godror v1.2.3 ODPI-C 4.5.6 Oracle Client "+V.Client.String(); got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+}
@@ -54,15 +54,68 @@ type CommonParams struct {
 	ConfigDir, LibDir       string
 	// OnInit is executed on session init. Overrides AlterSession and OnInitStmts!
 	OnInit func(context.Context, driver.ConnPrepareContext) error
+	// CredentialProvider is called before each connection (and pool) creation,
+	// and the returned username (if not empty) and password override Username and Password,
+	// so the secrets can come from a vault and can be rotated without restarting the process.
+	// A new password means a new session pool; the old one is closed after the first session acquired with
+	// the new password, when it is not used anymore. Its sessions in use keep it alive till they are closed.
+	CredentialProvider func(context.Context) (username string, password Password, err error)
+	// SecondFactor is called before each connection creation, and the returned code
+	// (such as a one-time token from a RADIUS server in synchronous mode) is appended to the password.
+	// As each session needs a fresh code, it is used only for standalone connections.
+	SecondFactor func(ctx context.Context, username string) (code string, err error)
 	// OnInitStmts are executed on session init, iff OnInit is nil.
 	OnInitStmts []string
 	// AlterSession key-values are set with "ALTER SESSION SET key=value" on session init, iff OnInit is nil.
 	AlterSession [][2]string
 	Timezone     *time.Location
 	// StmtCacheSize of 0 means the default, -1 to disable the stmt cache completely
-	StmtCacheSize           int
-	EnableEvents, NoTZCheck bool
-	Charset                 string
+	StmtCacheSize int
+	// MaxOpenCursors limits the number of open statements per connection:
+	// preparing more fails fast with a descriptive error instead of ORA-01000. 0 means no limit.
+	MaxOpenCursors int
+	// FetchArraySize and PrefetchCount are the connection's defaults for the FetchArraySize and PrefetchCount statement options.
+	// 0 means the driver's default; a negative PrefetchCount disables prefetching.
+	FetchArraySize, PrefetchCount int
+	// MaxSQLLength, MaxBinds and MaxArraySize are safety limits for the statement text length (in bytes),
+	// the number of bind variables and the length of the bound (array DML) slices.
+	// Exceeding them returns a *LimitError (in the godror package), without sending anything to the database.
+	// 0 means no limit.
+	MaxSQLLength, MaxBinds, MaxArraySize int
+	EnableEvents, NoTZCheck              bool
+	// Charset is the client character set (such as "UTF-8" or "AL32UTF8") for CHAR data, instead of NLS_LANG.
+	// UTF-8 by default.
+	Charset string
+	// NCharset is the client character set for NCHAR data - Charset by default.
+	NCharset string
+	// Compression enables Advanced Network Compression (needs the Advanced Compression option):
+	// "on" (or "low"), "high", or "" (or "off") to leave it to sqlnet.ora - any other value is an error.
+	// It is added to the connect descriptor or Easy Connect string - for a TNS alias, set it in tnsnames.ora
+	// (ErrCompressionNeedsDescriptor is returned).
+	Compression string
+	// KerberosCCName and KerberosPrincipal select the Kerberos credential cache (such as "/tmp/krb5cc_1000")
+	// and principal for the external authentication (empty Username and Password) of this connection,
+	// instead of the process-wide SQLNET.KERBEROS5_CC_NAME of sqlnet.ora (needs Oracle Client 21c).
+	// They are added to the connect descriptor or Easy Connect string, as Compression
+	// (ErrKerberosNeedsDescriptor is returned for a TNS alias).
+	KerberosCCName, KerberosPrincipal string
+	// DriverName is shown in V$SESSION_CONNECT_INFO.CLIENT_DRIVER instead of the default "godror : <version>",
+	// so it can carry an application string, too. It cannot be longer than 30 bytes!
+	DriverName string
+	// CurrentSchema is set as the session's CURRENT_SCHEMA (the default schema of the unqualified names)
+	// on each new session, and re-asserted each time a session is reused.
+	CurrentSchema string
+	// NLSComp and NLSSort are set (with ALTER SESSION, before calling OnInit) on session init,
+	// for the comparisons and sorting: NLSComp=LINGUISTIC and NLSSort=BINARY_CI makes them case-insensitive,
+	// NLSSort=BINARY_AI accent- and case-insensitive.
+	NLSComp, NLSSort string
+	// RecoverPanics converts panics in the driver into errors (counted in the pool statistics),
+	// instead of crashing the process - the connection is discarded.
+	RecoverPanics bool
+	// KeepAliveInterval is the idle time after which a connection (even if checked out) is pinged,
+	// to keep the session (and its state: GTTs, package variables) alive across firewall idle timeouts.
+	// 0 means no pinging.
+	KeepAliveInterval time.Duration
 }
 
 // String returns the string representation of CommonParams.
@@ -93,22 +146,180 @@ func (P CommonParams) String() string {
 	if P.NoTZCheck {
 		q.Add("noTimezoneCheck", "1")
 	}
+	if P.RecoverPanics {
+		q.Add("recoverPanics", "1")
+	}
 	if P.StmtCacheSize != 0 {
 		q.Add("stmtCacheSize", strconv.Itoa(int(P.StmtCacheSize)))
 	}
+	if P.MaxOpenCursors != 0 {
+		q.Add("maxOpenCursors", strconv.Itoa(P.MaxOpenCursors))
+	}
+	if P.FetchArraySize != 0 {
+		q.Add("fetchArraySize", strconv.Itoa(P.FetchArraySize))
+	}
+	if P.PrefetchCount != 0 {
+		q.Add("prefetchCount", strconv.Itoa(P.PrefetchCount))
+	}
+	if P.MaxSQLLength != 0 {
+		q.Add("maxSQLLength", strconv.Itoa(P.MaxSQLLength))
+	}
+	if P.MaxBinds != 0 {
+		q.Add("maxBinds", strconv.Itoa(P.MaxBinds))
+	}
+	if P.MaxArraySize != 0 {
+		q.Add("maxArraySize", strconv.Itoa(P.MaxArraySize))
+	}
 	if P.Charset != "" {
 		q.Add("charset", P.Charset)
 	}
+	if P.NCharset != "" {
+		q.Add("ncharset", P.NCharset)
+	}
+	if P.Compression != "" {
+		q.Add("compression", P.Compression)
+	}
+	if P.KerberosCCName != "" {
+		q.Add("kerberosCCName", P.KerberosCCName)
+	}
+	if P.KerberosPrincipal != "" {
+		q.Add("kerberosPrincipal", P.KerberosPrincipal)
+	}
+	if P.DriverName != "" {
+		q.Add("driverName", P.DriverName)
+	}
+	if P.CurrentSchema != "" {
+		q.Add("currentSchema", P.CurrentSchema)
+	}
+	if P.NLSComp != "" {
+		q.Add("nlsComp", P.NLSComp)
+	}
+	if P.NLSSort != "" {
+		q.Add("nlsSort", P.NLSSort)
+	}
+	if P.KeepAliveInterval != 0 {
+		q.Add("keepAliveInterval", P.KeepAliveInterval.String())
+	}
 
 	return q.String()
 }
 
+// ErrCompressionNeedsDescriptor is returned for Compression with a TNS alias as ConnectString:
+// set the compression in tnsnames.ora or sqlnet.ora instead.
+var ErrCompressionNeedsDescriptor = errors.New("compression needs a connect descriptor or an Easy Connect string, set it in tnsnames.ora for an alias")
+
+// ErrKerberosNeedsDescriptor is returned for KerberosCCName or KerberosPrincipal with a TNS alias as ConnectString:
+// set them in tnsnames.ora instead.
+var ErrKerberosNeedsDescriptor = errors.New("kerberosCCName and kerberosPrincipal need a connect descriptor or an Easy Connect string, set them in tnsnames.ora for an alias")
+
+// ErrKerberosNeedsExternalAuth is returned for KerberosCCName or KerberosPrincipal with a user name or password.
+var ErrKerberosNeedsExternalAuth = errors.New("kerberosCCName and kerberosPrincipal need external authentication (no user and password)")
+
+// NetConnectString returns the ConnectString, with the network compression and Kerberos parameters added.
+//
+// Returns ErrCompressionNeedsDescriptor (ErrKerberosNeedsDescriptor) if the compression (the Kerberos parameters)
+// cannot be added (to a TNS alias).
+func (P CommonParams) NetConnectString() (string, error) {
+	cs := P.ConnectString
+	level, err := compressionLevel(P.Compression)
+	if err != nil {
+		return cs, err
+	}
+	if level == "" && P.KerberosCCName == "" && P.KerberosPrincipal == "" {
+		return cs, nil
+	}
+	errNeedsDescriptor := ErrKerberosNeedsDescriptor
+	if level != "" {
+		errNeedsDescriptor = ErrCompressionNeedsDescriptor
+	}
+	if strings.HasPrefix(cs, "(") {
+		// Connect descriptor: add to the DESCRIPTION, and its SECURITY.
+		var desc, sec string
+		if level != "" {
+			desc = "(COMPRESSION=ON)(COMPRESSION_LEVELS=(LEVEL=" + level + "))"
+		}
+		if P.KerberosCCName != "" {
+			sec += "(KERBEROS5_CC_NAME=" + P.KerberosCCName + ")"
+		}
+		if P.KerberosPrincipal != "" {
+			sec += "(KERBEROS5_PRINCIPAL=" + P.KerberosPrincipal + ")"
+		}
+		insertAfter := func(cs, section, s string) (string, bool) {
+			i := strings.Index(strings.ToUpper(cs), "("+section)
+			if i < 0 {
+				return cs, false
+			}
+			j := strings.IndexByte(cs[i:], '=')
+			if j < 0 {
+				return cs, false
+			}
+			i += j + 1
+			return cs[:i] + s + cs[i:], true
+		}
+		if sec != "" {
+			if withSec, ok := insertAfter(cs, "SECURITY", sec); ok {
+				cs, sec = withSec, ""
+			} else {
+				desc += "(SECURITY=" + sec + ")"
+			}
+		}
+		withDesc, ok := insertAfter(cs, "DESCRIPTION", desc)
+		if !ok {
+			return P.ConnectString, fmt.Errorf("%q: %w", P.ConnectString, errNeedsDescriptor)
+		}
+		return withDesc, nil
+	}
+	if !strings.ContainsAny(cs, "/:") {
+		// TNS alias
+		return cs, fmt.Errorf("%q: %w", cs, errNeedsDescriptor)
+	}
+	// Easy Connect Plus (19c) passes the parameters into the DESCRIPTION.
+	var params []string
+	if level != "" {
+		params = append(params, "compression=on", "compression_levels=(LEVEL="+level+")")
+	}
+	if P.KerberosCCName != "" {
+		params = append(params, "kerberos5_cc_name="+P.KerberosCCName)
+	}
+	if P.KerberosPrincipal != "" {
+		params = append(params, "kerberos5_principal="+P.KerberosPrincipal)
+	}
+	sep := "?"
+	if strings.Contains(cs, "?") {
+		sep = "&"
+	}
+	return cs + sep + strings.Join(params, "&"), nil
+}
+
+// compressionLevel returns the COMPRESSION_LEVELS level of the compression ("" for none).
+func compressionLevel(compression string) (string, error) {
+	switch level := strings.ToLower(compression); level {
+	case "", "off", "0", "false":
+		return "", nil
+	case "on", "1", "true":
+		return "low", nil
+	case "low", "high":
+		return level, nil
+	}
+	return "", fmt.Errorf("unknown compression %q (wanted on, off, low or high)", compression)
+}
+
 // ConnParams holds the connection-specific parameters.
 //
 // For details, see https://oracle.github.io/odpi/doc/structs/dpiConnCreateParams.html#dpiconncreateparams
 type ConnParams struct {
-	NewPassword                             Password
-	ConnClass                               string
+	NewPassword Password
+	ConnClass   string
+	// Purity of the session acquired from the pool - see Purity.
+	Purity Purity
+	// Tag of the session to acquire from the pool, such as "NLS=GERMAN".
+	// A session without this tag gets it on release, after TagCallback has set it up.
+	Tag string
+	// TagCallback is called when the session acquired with Tag has another (or no) tag,
+	// to set it up (with ALTER SESSION, for example) - once per session.
+	TagCallback func(ctx context.Context, conn driver.ConnPrepareContext, requested, actual string) error
+	// MatchAnyTag allows acquiring a session with another tag, if none has Tag.
+	MatchAnyTag                             bool
 	IsSysDBA, IsSysOper, IsSysASM, IsPrelim bool
 	ShardingKey, SuperShardingKey           []interface{}
 }
@@ -122,6 +333,15 @@ func (P ConnParams) String() string {
 	if !P.NewPassword.IsZero() {
 		q.Add("newPassword", P.NewPassword.String())
 	}
+	if P.Purity != PurityDefault {
+		q.Add("purity", P.Purity.String())
+	}
+	if P.Tag != "" {
+		q.Add("tag", P.Tag)
+	}
+	if P.MatchAnyTag {
+		q.Add("matchAnyTag", "1")
+	}
 	if P.IsSysDBA {
 		q.Add("sysdba", "1")
 	}
@@ -140,6 +360,43 @@ func (P ConnParams) String() string {
 	return q.String()
 }
 
+// Purity says whether a session acquired from the pool may carry the session state
+// (package variables, ALTER SESSION settings, temporary tables) of its previous use.
+type Purity uint8
+
+const (
+	// PurityDefault leaves the choice to the pool (SELF for non-DRCP pools).
+	PurityDefault = Purity(iota)
+	// PurityNew requests a session without any previous state - each acquisition gets a fresh session.
+	PurityNew
+	// PuritySelf allows reusing a pooled session, with its state.
+	PuritySelf
+)
+
+func (p Purity) String() string {
+	switch p {
+	case PurityNew:
+		return "new"
+	case PuritySelf:
+		return "self"
+	default:
+		return "default"
+	}
+}
+
+// ParsePurity parses "default", "new" or "self".
+func ParsePurity(s string) (Purity, error) {
+	switch strings.ToLower(s) {
+	case "", "default":
+		return PurityDefault, nil
+	case "new":
+		return PurityNew, nil
+	case "self":
+		return PuritySelf, nil
+	}
+	return PurityDefault, fmt.Errorf("unknown purity %q (wanted default, new or self)", s)
+}
+
 // PoolParams holds the configuration of the Oracle Session Pool.
 //
 // For details, see https://oracle.github.io/odpi/doc/structs/dpiPoolCreateParams.html#dpipoolcreateparams
@@ -149,8 +406,14 @@ type PoolParams struct {
 	MinSessions, MaxSessions, SessionIncrement int
 	MaxSessionsPerShard                        int
 	WaitTimeout, MaxLifeTime, SessionTimeout   time.Duration
-	PingInterval                               time.Duration
-	Heterogeneous, ExternalAuth                bool
+	// PingInterval is the idle time after which a session is pinged on acquisition from the pool,
+	// to not hand out broken sessions after quiet periods. 0 means the default (60s),
+	// less than a second means on every acquisition, negative disables the ping.
+	PingInterval time.Duration
+	// ClockSkewInterval is how often the database clock is compared to the application's,
+	// on connection acquisition - see PoolStats.ClockSkew. 0 means never.
+	ClockSkewInterval           time.Duration
+	Heterogeneous, ExternalAuth bool
 }
 
 // String returns the string representation of PoolParams.
@@ -174,6 +437,9 @@ func (P PoolParams) String() string {
 	if P.PingInterval != 0 {
 		q.Add("pingInterval", P.PingInterval.String())
 	}
+	if P.ClockSkewInterval != 0 {
+		q.Add("clockSkewInterval", P.ClockSkewInterval.String())
+	}
 	return q.String()
 }
 
@@ -241,6 +507,15 @@ func (P ConnectionParams) string(class, withPassword bool) string {
 		s = ""
 	}
 	q.Add("connectionClass", s)
+	if P.Purity != PurityDefault {
+		q.Add("purity", P.Purity.String())
+	}
+	if P.Tag != "" {
+		q.Add("tag", P.Tag)
+	}
+	if P.MatchAnyTag {
+		q.Add("matchAnyTag", "1")
+	}
 
 	q.Add("user", P.Username)
 	if withPassword {
@@ -268,12 +543,60 @@ func (P ConnectionParams) string(class, withPassword bool) string {
 		return "0"
 	}
 	q.Add("noTimezoneCheck", B(P.NoTZCheck))
+	if P.RecoverPanics {
+		q.Add("recoverPanics", "1")
+	}
+	if P.KeepAliveInterval != 0 {
+		q.Add("keepAliveInterval", P.KeepAliveInterval.String())
+	}
 	if P.StmtCacheSize != 0 {
 		q.Add("stmtCacheSize", strconv.Itoa(int(P.StmtCacheSize)))
 	}
+	if P.MaxOpenCursors != 0 {
+		q.Add("maxOpenCursors", strconv.Itoa(P.MaxOpenCursors))
+	}
+	if P.FetchArraySize != 0 {
+		q.Add("fetchArraySize", strconv.Itoa(P.FetchArraySize))
+	}
+	if P.PrefetchCount != 0 {
+		q.Add("prefetchCount", strconv.Itoa(P.PrefetchCount))
+	}
+	if P.MaxSQLLength != 0 {
+		q.Add("maxSQLLength", strconv.Itoa(P.MaxSQLLength))
+	}
+	if P.MaxBinds != 0 {
+		q.Add("maxBinds", strconv.Itoa(P.MaxBinds))
+	}
+	if P.MaxArraySize != 0 {
+		q.Add("maxArraySize", strconv.Itoa(P.MaxArraySize))
+	}
 	if P.Charset != "" {
 		q.Add("charset", P.Charset)
 	}
+	if P.NCharset != "" {
+		q.Add("ncharset", P.NCharset)
+	}
+	if P.Compression != "" {
+		q.Add("compression", P.Compression)
+	}
+	if P.KerberosCCName != "" {
+		q.Add("kerberosCCName", P.KerberosCCName)
+	}
+	if P.KerberosPrincipal != "" {
+		q.Add("kerberosPrincipal", P.KerberosPrincipal)
+	}
+	if P.DriverName != "" {
+		q.Add("driverName", P.DriverName)
+	}
+	if P.CurrentSchema != "" {
+		q.Add("currentSchema", P.CurrentSchema)
+	}
+	if P.NLSComp != "" {
+		q.Add("nlsComp", P.NLSComp)
+	}
+	if P.NLSSort != "" {
+		q.Add("nlsSort", P.NLSSort)
+	}
 	q.Add("poolMinSessions", strconv.Itoa(P.MinSessions))
 	q.Add("poolMaxSessions", strconv.Itoa(P.MaxSessions))
 	if P.MaxSessionsPerShard != 0 {
@@ -291,6 +614,12 @@ func (P ConnectionParams) string(class, withPassword bool) string {
 	q.Add("poolWaitTimeout", P.WaitTimeout.String())
 	q.Add("poolSessionMaxLifetime", P.MaxLifeTime.String())
 	q.Add("poolSessionTimeout", P.SessionTimeout.String())
+	if P.PingInterval != 0 {
+		q.Add("pingInterval", P.PingInterval.String())
+	}
+	if P.ClockSkewInterval != 0 {
+		q.Add("clockSkewInterval", P.ClockSkewInterval.String())
+	}
 	as := newParamsArray(1)
 	for _, kv := range P.AlterSession {
 		as.Reset()
@@ -417,6 +746,15 @@ func Parse(dataSourceName string) (ConnectionParams, error) {
 	if vv, ok := q["connectionClass"]; ok {
 		P.ConnClass = vv[0]
 	}
+	if s := q.Get("purity"); s != "" {
+		var err error
+		if P.Purity, err = ParsePurity(s); err != nil {
+			return P, err
+		}
+	}
+	if vv, ok := q["tag"]; ok {
+		P.Tag = vv[0]
+	}
 	for _, task := range []struct {
 		Dest *bool
 		Key  string
@@ -425,6 +763,7 @@ func Parse(dataSourceName string) (ConnectionParams, error) {
 		{&P.IsSysOper, "sysoper"},
 		{&P.IsSysASM, "sysasm"},
 		{&P.IsPrelim, "prelim"},
+		{&P.MatchAnyTag, "matchAnyTag"},
 
 		{&P.EnableEvents, "enableEvents"},
 		{&P.Heterogeneous, "heterogeneousPool"},
@@ -432,6 +771,7 @@ func Parse(dataSourceName string) (ConnectionParams, error) {
 		{&P.StandaloneConnection, "standaloneConnection"},
 
 		{&P.NoTZCheck, "noTimezoneCheck"},
+		{&P.RecoverPanics, "recoverPanics"},
 	} {
 		s := q.Get(task.Key)
 		if s == "" {
@@ -480,6 +820,12 @@ func Parse(dataSourceName string) (ConnectionParams, error) {
 		{&P.SessionIncrement, "poolIncrement"},
 		{&P.SessionIncrement, "sessionIncrement"},
 		{&P.StmtCacheSize, "stmtCacheSize"},
+		{&P.MaxOpenCursors, "maxOpenCursors"},
+		{&P.FetchArraySize, "fetchArraySize"},
+		{&P.PrefetchCount, "prefetchCount"},
+		{&P.MaxSQLLength, "maxSQLLength"},
+		{&P.MaxBinds, "maxBinds"},
+		{&P.MaxArraySize, "maxArraySize"},
 	} {
 		s := q.Get(task.Key)
 		if s == "" {
@@ -500,6 +846,9 @@ func Parse(dataSourceName string) (ConnectionParams, error) {
 		{&P.WaitTimeout, "poolWaitTimeout"},
 		{&P.MaxLifeTime, "poolSessionMaxLifetime"},
 		{&P.PingInterval, "pingInterval"},
+		{&P.PingInterval, "poolPingInterval"},
+		{&P.ClockSkewInterval, "clockSkewInterval"},
+		{&P.KeepAliveInterval, "keepAliveInterval"},
 	} {
 		s := q.Get(task.Key)
 		if s == "" {
@@ -548,12 +897,38 @@ func Parse(dataSourceName string) (ConnectionParams, error) {
 	P.NewPassword.Set(q.Get("newPassword"))
 	P.ConfigDir = q.Get("configDir")
 	P.LibDir = q.Get("libDir")
+	if s := q.Get("charset"); s != "" {
+		P.Charset = s
+	}
+	P.NCharset = q.Get("ncharset")
+	P.Compression = q.Get("compression")
+	if _, err := compressionLevel(P.Compression); err != nil {
+		return P, err
+	}
+	P.KerberosCCName, P.KerberosPrincipal = q.Get("kerberosCCName"), q.Get("kerberosPrincipal")
+	if (P.KerberosCCName != "" || P.KerberosPrincipal != "") && (P.Username != "" || !P.Password.IsZero() || P.Heterogeneous) {
+		return P, ErrKerberosNeedsExternalAuth
+	}
+	P.DriverName = q.Get("driverName")
+	P.CurrentSchema = q.Get("currentSchema")
+	if s := q.Get("nlsComp"); s != "" {
+		switch s = strings.ToUpper(s); s {
+		case "BINARY", "LINGUISTIC", "ANSI":
+			P.NLSComp = s
+		default:
+			return P, fmt.Errorf("unknown nlsComp %q (wanted BINARY, LINGUISTIC or ANSI)", s)
+		}
+	}
+	P.NLSSort = q.Get("nlsSort")
 
 	//fmt.Printf("cs1=%q\n", P.ConnectString)
 	P.comb()
 
 	//fmt.Printf("cs2=%q\n", P.ConnectString)
 
+	if _, err := P.NetConnectString(); err != nil {
+		return P, err
+	}
 	return P, nil
 }
 
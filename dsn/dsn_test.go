@@ -6,7 +6,9 @@
 package dsn
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
@@ -298,3 +300,345 @@ func ExampleConnectString() {
 	// poolSessionMaxLifetime=0s poolSessionTimeout=42s poolWaitTimeout=0s prelim=0
 	// standaloneConnection=0 sysasm=0 sysdba=0 sysoper=0 timezone=
 }
+
+func TestNetConnectString(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		ConnectString, Compression, Want string
+	}{
+		{"localhost/orclpdb", "", "localhost/orclpdb"},
+		{"localhost/orclpdb", "on", "localhost/orclpdb?compression=on&compression_levels=(LEVEL=low)"},
+		{"localhost/orclpdb?connect_timeout=10", "high", "localhost/orclpdb?connect_timeout=10&compression=on&compression_levels=(LEVEL=high)"},
+		{"orcl", "", "orcl"},
+		{"(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=h)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=s)))", "on",
+			"(DESCRIPTION=(COMPRESSION=ON)(COMPRESSION_LEVELS=(LEVEL=low))(ADDRESS=(PROTOCOL=TCP)(HOST=h)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=s)))"},
+	} {
+		P := CommonParams{ConnectString: tc.ConnectString, Compression: tc.Compression}
+		if got, err := P.NetConnectString(); err != nil || got != tc.Want {
+			t.Errorf("%q/%q: got %q, %v, wanted %q", tc.ConnectString, tc.Compression, got, err, tc.Want)
+		}
+	}
+	// a TNS alias cannot get the compression
+	alias := CommonParams{ConnectString: "orcl", Compression: "on"}
+	if _, err := alias.NetConnectString(); !errors.Is(err, ErrCompressionNeedsDescriptor) {
+		t.Errorf("alias: got %v, wanted ErrCompressionNeedsDescriptor", err)
+	}
+	if _, err := Parse(`user=a password=b connectString=orcl compression=on`); !errors.Is(err, ErrCompressionNeedsDescriptor) {
+		t.Errorf("Parse alias: got %v, wanted ErrCompressionNeedsDescriptor", err)
+	}
+
+	P, err := Parse(`user=a password=b connectString=localhost/orclpdb compression=high`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.Compression != "high" {
+		t.Errorf("got %q, wanted high", P.Compression)
+	}
+	if s := P.String(); !strings.Contains(s, "compression=high") {
+		t.Errorf("compression is missing from %q", s)
+	}
+
+	// only the known levels get into the connect string
+	if _, err := Parse("oracle://a:b@localhost/orclpdb?compression=LOW"); err != nil {
+		t.Errorf("Parse LOW: %+v", err)
+	}
+	for _, compression := range []string{
+		"medium",
+		"low))(ADDRESS=(HOST=evil)",
+		"high&kerberos5_cc_name=x",
+		" on",
+	} {
+		for _, cs := range []string{"localhost/orclpdb", "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=h)(PORT=1521)))"} {
+			P := CommonParams{ConnectString: cs, Compression: compression}
+			if got, err := P.NetConnectString(); err == nil {
+				t.Errorf("%q/%q: got %q, wanted error", cs, compression, got)
+			}
+		}
+		if _, err := Parse("oracle://a:b@localhost/orclpdb?compression=" + url.QueryEscape(compression)); err == nil {
+			t.Errorf("Parse %q: wanted error", compression)
+		}
+	}
+}
+
+func TestParseKerberos(t *testing.T) {
+	t.Parallel()
+	P, err := Parse(`connectString=localhost/orclpdb kerberosCCName=/tmp/krb5cc_1000 kerberosPrincipal=scott@EXAMPLE.COM`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.KerberosCCName != "/tmp/krb5cc_1000" || P.KerberosPrincipal != "scott@EXAMPLE.COM" {
+		t.Errorf("got %q, %q", P.KerberosCCName, P.KerberosPrincipal)
+	}
+	if !P.ExternalAuth {
+		t.Error("Kerberos needs external authentication")
+	}
+	if s := P.CommonParams.String(); !strings.Contains(s, "kerberosCCName=/tmp/krb5cc_1000") || !strings.Contains(s, "kerberosPrincipal=scott@EXAMPLE.COM") {
+		t.Errorf("Kerberos is missing from %q", s)
+	}
+	Q, err := Parse(P.StringWithPassword())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Q.KerberosCCName != P.KerberosCCName || Q.KerberosPrincipal != P.KerberosPrincipal {
+		t.Errorf("roundtrip: got %q, %q", Q.KerberosCCName, Q.KerberosPrincipal)
+	}
+	if cs, err := P.NetConnectString(); err != nil || cs != "localhost/orclpdb?kerberos5_cc_name=/tmp/krb5cc_1000&kerberos5_principal=scott@EXAMPLE.COM" {
+		t.Errorf("got %q, %v", cs, err)
+	}
+
+	for _, tc := range []struct {
+		ConnectString, Want string
+	}{
+		{"(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=h)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=s)))",
+			"(DESCRIPTION=(SECURITY=(KERBEROS5_CC_NAME=/tmp/cc))(ADDRESS=(PROTOCOL=TCP)(HOST=h)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=s)))"},
+		{"(DESCRIPTION=(ADDRESS=(PROTOCOL=TCPS)(HOST=h)(PORT=2484))(SECURITY=(SSL_SERVER_DN_MATCH=ON)))",
+			"(DESCRIPTION=(ADDRESS=(PROTOCOL=TCPS)(HOST=h)(PORT=2484))(SECURITY=(KERBEROS5_CC_NAME=/tmp/cc)(SSL_SERVER_DN_MATCH=ON)))"},
+	} {
+		P := CommonParams{ConnectString: tc.ConnectString, KerberosCCName: "/tmp/cc"}
+		if got, err := P.NetConnectString(); err != nil || got != tc.Want {
+			t.Errorf("%q: got %q, %v, wanted %q", tc.ConnectString, got, err, tc.Want)
+		}
+	}
+
+	if _, err := Parse(`connectString=orcl kerberosCCName=/tmp/cc`); !errors.Is(err, ErrKerberosNeedsDescriptor) {
+		t.Errorf("alias: got %v, wanted ErrKerberosNeedsDescriptor", err)
+	}
+	if _, err := Parse(`user=scott password=tiger connectString=localhost/orclpdb kerberosCCName=/tmp/cc`); !errors.Is(err, ErrKerberosNeedsExternalAuth) {
+		t.Errorf("with password: got %v, wanted ErrKerberosNeedsExternalAuth", err)
+	}
+}
+
+func TestParseCharset(t *testing.T) {
+	t.Parallel()
+	for _, s := range []string{
+		`user=a password=b connectString=localhost/orclpdb charset=WE8ISO8859P1 ncharset=AL16UTF16`,
+		`oracle://a:b@localhost/orclpdb?charset=WE8ISO8859P1&ncharset=AL16UTF16`,
+	} {
+		P, err := Parse(s)
+		if err != nil {
+			t.Fatalf("%q: %+v", s, err)
+		}
+		if P.Charset != "WE8ISO8859P1" || P.NCharset != "AL16UTF16" {
+			t.Errorf("%q: got charset=%q ncharset=%q", s, P.Charset, P.NCharset)
+		}
+		if got := P.String(); !strings.Contains(got, "ncharset=AL16UTF16") {
+			t.Errorf("ncharset is missing from %q", got)
+		}
+	}
+}
+
+func TestParseDriverName(t *testing.T) {
+	t.Parallel()
+	const s = `user=a password=b connectString=localhost/orclpdb driverName="billing : v1.2"`
+	P, err := Parse(s)
+	if err != nil {
+		t.Fatalf("%q: %+v", s, err)
+	}
+	if P.DriverName != "billing : v1.2" {
+		t.Errorf("%q: got driverName=%q", s, P.DriverName)
+	}
+	Q, err := Parse(P.StringWithPassword())
+	if err != nil {
+		t.Fatalf("%q: %+v", P.StringWithPassword(), err)
+	}
+	if Q.DriverName != P.DriverName {
+		t.Errorf("roundtrip: got %q, wanted %q", Q.DriverName, P.DriverName)
+	}
+}
+
+func TestParseKeepAliveInterval(t *testing.T) {
+	const s = `user=a password=b connectString=localhost/orclpdb keepAliveInterval=5m recoverPanics=1`
+	P, err := Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.KeepAliveInterval != 5*time.Minute || !P.RecoverPanics {
+		t.Errorf("%q: got keepAliveInterval=%s recoverPanics=%t", s, P.KeepAliveInterval, P.RecoverPanics)
+	}
+	if got := P.String(); !strings.Contains(got, "keepAliveInterval=5m0s") || !strings.Contains(got, "recoverPanics=1") {
+		t.Errorf("String: got %q", got)
+	}
+}
+
+func TestParseExternalPasswordStore(t *testing.T) {
+	for _, s := range []string{"/@sales_db", "connectString=sales_db configDir=/opt/oracle/network/admin"} {
+		P, err := Parse(s)
+		if err != nil {
+			t.Fatalf("%q: %+v", s, err)
+		}
+		if P.Username != "" || !P.Password.IsZero() || !P.ExternalAuth || P.ConnectString != "sales_db" {
+			t.Errorf("%q: got user=%q externalAuth=%t connectString=%q", s, P.Username, P.ExternalAuth, P.ConnectString)
+		}
+	}
+}
+
+func TestParsePurity(t *testing.T) {
+	const s = `user=a password=b connectString=localhost/orclpdb purity=new`
+	P, err := Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.Purity != PurityNew {
+		t.Errorf("%q: got purity=%s", s, P.Purity)
+	}
+	if got := P.String(); !strings.Contains(got, "purity=new") {
+		t.Errorf("String: got %q", got)
+	}
+	if _, err := Parse(`connectString=localhost/orclpdb purity=dirty`); err == nil {
+		t.Error("wanted error for unknown purity")
+	}
+}
+
+func TestParseTag(t *testing.T) {
+	const s = `user=a password=b connectString=localhost/orclpdb tag="NLS=GERMAN" matchAnyTag=1`
+	P, err := Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.Tag != "NLS=GERMAN" || !P.MatchAnyTag {
+		t.Errorf("%q: got tag=%q matchAnyTag=%t", s, P.Tag, P.MatchAnyTag)
+	}
+	Q, err := Parse(P.StringWithPassword())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Q.Tag != P.Tag || Q.MatchAnyTag != P.MatchAnyTag {
+		t.Errorf("roundtrip: got tag=%q matchAnyTag=%t", Q.Tag, Q.MatchAnyTag)
+	}
+}
+
+func TestParseAdminRole(t *testing.T) {
+	for s, want := range map[string][3]bool{
+		"sys/pw@db as sysdba":                             {true, false, false},
+		"sys/pw@db AS SYSOPER":                            {false, true, false},
+		"sys/pw@db as sysasm":                             {false, false, true},
+		"user=sys password=pw connectString=db sysoper=1": {false, true, false},
+		"oracle://sys:pw@db?sysasm=1":                     {false, false, true},
+	} {
+		P, err := Parse(s)
+		if err != nil {
+			t.Fatalf("%q: %+v", s, err)
+		}
+		if got := [3]bool{P.IsSysDBA, P.IsSysOper, P.IsSysASM}; got != want {
+			t.Errorf("%q: got sysdba/sysoper/sysasm=%v, wanted %v", s, got, want)
+		}
+		if P.ConnectString != "db" {
+			t.Errorf("%q: got connectString=%q", s, P.ConnectString)
+		}
+		Q, err := Parse(P.StringWithPassword())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := [3]bool{Q.IsSysDBA, Q.IsSysOper, Q.IsSysASM}; got != want {
+			t.Errorf("roundtrip %q: got sysdba/sysoper/sysasm=%v, wanted %v", s, got, want)
+		}
+	}
+}
+
+func TestParseCurrentSchema(t *testing.T) {
+	const s = `user=a password=b connectString=localhost/orclpdb currentSchema=APP_OWNER`
+	P, err := Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.CurrentSchema != "APP_OWNER" {
+		t.Errorf("%q: got currentSchema=%q", s, P.CurrentSchema)
+	}
+	Q, err := Parse(P.StringWithPassword())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Q.CurrentSchema != P.CurrentSchema {
+		t.Errorf("roundtrip: got currentSchema=%q", Q.CurrentSchema)
+	}
+}
+
+func TestParseNLSCollation(t *testing.T) {
+	const s = `user=a password=b connectString=localhost/orclpdb nlsComp=linguistic nlsSort=BINARY_AI`
+	P, err := Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.NLSComp != "LINGUISTIC" || P.NLSSort != "BINARY_AI" {
+		t.Errorf("%q: got nlsComp=%q nlsSort=%q", s, P.NLSComp, P.NLSSort)
+	}
+	Q, err := Parse(P.StringWithPassword())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Q.NLSComp != P.NLSComp || Q.NLSSort != P.NLSSort {
+		t.Errorf("roundtrip: got nlsComp=%q nlsSort=%q", Q.NLSComp, Q.NLSSort)
+	}
+	if _, err := Parse(`connectString=localhost/orclpdb nlsComp=fuzzy`); err == nil {
+		t.Error("wanted error for unknown nlsComp")
+	}
+}
+
+func TestParseStandalone(t *testing.T) {
+	for s, want := range map[string]bool{
+		"user=a password=b connectString=db":                                                       DefaultStandaloneConnection,
+		"user=a password=b connectString=db standaloneConnection=1":                                true,
+		"user=a password=b connectString=db connectionClass=" + NoConnectionPoolingConnectionClass: true,
+		"user=sys password=b connectString=db sysdba=1":                                            true,
+		"user=sys password=b connectString=db prelim=1 sysdba=1":                                   true,
+	} {
+		P, err := Parse(s)
+		if err != nil {
+			t.Fatalf("%q: %+v", s, err)
+		}
+		if got := P.IsStandalone(); got != want {
+			t.Errorf("%q: got standalone=%t, wanted %t", s, got, want)
+		}
+		if P.IsStandalone() && P.ConnClass == NoConnectionPoolingConnectionClass {
+			t.Errorf("%q: connectionClass kept for standalone connection", s)
+		}
+	}
+}
+
+func TestParsePingInterval(t *testing.T) {
+	for s, want := range map[string]time.Duration{
+		"connectString=db pingInterval=10s":       10 * time.Second,
+		"connectString=db poolPingInterval=-1s":   -time.Second,
+		"connectString=db poolPingInterval=500ms": 500 * time.Millisecond,
+	} {
+		P, err := Parse(s)
+		if err != nil {
+			t.Fatalf("%q: %+v", s, err)
+		}
+		if P.PingInterval != want {
+			t.Errorf("%q: got %s, wanted %s", s, P.PingInterval, want)
+		}
+	}
+}
+
+func TestParseIntervalsRoundTrip(t *testing.T) {
+	const s = `user=a password=b connectString=localhost/orclpdb pingInterval=10s clockSkewInterval=1h`
+	P, err := Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.PingInterval != 10*time.Second || P.ClockSkewInterval != time.Hour {
+		t.Errorf("%q: got pingInterval=%s clockSkewInterval=%s", s, P.PingInterval, P.ClockSkewInterval)
+	}
+	Q, err := Parse(P.StringWithPassword())
+	if err != nil {
+		t.Fatalf("%q: %+v", P.StringWithPassword(), err)
+	}
+	if Q.PingInterval != P.PingInterval || Q.ClockSkewInterval != P.ClockSkewInterval {
+		t.Errorf("round trip %q: got pingInterval=%s clockSkewInterval=%s", P.StringWithPassword(), Q.PingInterval, Q.ClockSkewInterval)
+	}
+}
+
+func TestParseFetch(t *testing.T) {
+	const s = `connectString=db fetchArraySize=1000 prefetchCount=-1`
+	P, err := Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.FetchArraySize != 1000 || P.PrefetchCount != -1 {
+		t.Errorf("%q: got fetchArraySize=%d prefetchCount=%d", s, P.FetchArraySize, P.PrefetchCount)
+	}
+	if got := P.String(); !strings.Contains(got, "fetchArraySize=1000") || !strings.Contains(got, "prefetchCount=-1") {
+		t.Errorf("String: got %q", got)
+	}
+}
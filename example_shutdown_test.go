@@ -98,3 +98,27 @@ func exampleShutdown(db *sql.DB, shutdownMode godror.ShutdownMode) error {
 		return oraDB.Shutdown(godror.ShutdownFinal)
 	})
 }
+
+// ExampleStartupDatabase starts and opens the database, then shuts it down.
+func ExampleStartupDatabase() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	P, err := godror.ParseDSN("oracle://?sysdba=1") // equivalent to "/ as sysdba"
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = godror.StartupDatabase(ctx, P, godror.StartupDefault, godror.StartupOpen); err != nil {
+		log.Fatal(err)
+	}
+
+	db := sql.OpenDB(godror.NewConnector(P))
+	defer db.Close()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+	if err = godror.ShutdownDatabase(ctx, conn, godror.ShutdownImmediate); err != nil {
+		log.Fatal(err)
+	}
+}
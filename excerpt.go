@@ -0,0 +1,98 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+var sqlExcerptLength int32
+
+// SetSQLExcerptLength sets how many bytes of the SQL are shown before and after
+// the offset of a parse error, in the returned *SQLExcerptError. 0 disables the excerpt.
+//
+// The default is 0, as the excerpt may contain literals (and so sensitive data) of the SQL,
+// ending up in the logs.
+func SetSQLExcerptLength(n int) {
+	if n < 0 {
+		n = 0
+	}
+	atomic.StoreInt32(&sqlExcerptLength, int32(n))
+}
+
+// SQLExcerptError is returned when the statement fails at a given offset (such as a parse error),
+// it contains the offset and an excerpt of the SQL around it.
+//
+// It unwraps to the original error, so AsOraErr works on it.
+type SQLExcerptError struct {
+	Err error
+	// Excerpt is the part of the SQL around Offset, with "<*>" marking the offset.
+	Excerpt string
+	// Offset is the error's offset (in bytes) in the SQL.
+	Offset int
+}
+
+func (se *SQLExcerptError) Error() string {
+	return fmt.Sprintf("%v (at offset %d: %q)", se.Err, se.Offset, se.Excerpt)
+}
+func (se *SQLExcerptError) Unwrap() error { return se.Err }
+
+// withSQLExcerpt returns err wrapped in an *SQLExcerptError, iff it is an *OraErr with an offset in qry.
+//
+// Must not be used for executeMany, where the offset is the row offset.
+func withSQLExcerpt(err error, qry string) error {
+	n := int(atomic.LoadInt32(&sqlExcerptLength))
+	if err == nil || n == 0 {
+		return err
+	}
+	oe, ok := AsOraErr(err)
+	if !ok || oe.Offset() <= 0 || oe.Offset() > len(qry) {
+		return err
+	}
+	offset := oe.Offset()
+	if atomic.LoadUint32(&redactSQL) != 0 {
+		qry, offset = redactSQLOffset(qry, offset)
+	}
+	return &SQLExcerptError{Err: err, Offset: oe.Offset(), Excerpt: sqlExcerpt(qry, offset, n)}
+}
+
+// sqlExcerpt returns at most n bytes before and after offset of qry, with "<*>" marking the offset.
+func sqlExcerpt(qry string, offset, n int) string {
+	start, end := offset-n, offset+n
+	if start < 0 {
+		start = 0
+	}
+	if end > len(qry) {
+		end = len(qry)
+	}
+	// do not split runes
+	for start > 0 && !utf8.RuneStart(qry[start]) {
+		start--
+	}
+	for end < len(qry) && !utf8.RuneStart(qry[end]) {
+		end++
+	}
+	for offset < len(qry) && !utf8.RuneStart(qry[offset]) {
+		offset++
+	}
+	if end < offset {
+		end = offset
+	}
+	var buf strings.Builder
+	if start > 0 {
+		buf.WriteString("...")
+	}
+	buf.WriteString(qry[start:offset])
+	buf.WriteString("<*>")
+	buf.WriteString(qry[offset:end])
+	if end < len(qry) {
+		buf.WriteString("...")
+	}
+	return buf.String()
+}
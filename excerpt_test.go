@@ -0,0 +1,53 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestSQLExcerpt(t *testing.T) {
+	const qry = "SELECT ename FRM emp WHERE empno = :1"
+	for _, tc := range []struct {
+		Offset, N int
+		Want      string
+	}{
+		{Offset: 13, N: 5, Want: "...name <*>FRM e..."},
+		{Offset: 13, N: 100, Want: "SELECT ename <*>FRM emp WHERE empno = :1"},
+		{Offset: len(qry), N: 3, Want: "... :1<*>"},
+	} {
+		if got := sqlExcerpt(qry, tc.Offset, tc.N); got != tc.Want {
+			t.Errorf("%d/%d: got %q, wanted %q", tc.Offset, tc.N, got, tc.Want)
+		}
+	}
+	if got := sqlExcerpt("SELECT 'árvíz' FRM", 9, 1); got != "...á<*>..." {
+		t.Errorf("got %q", got)
+	}
+
+	oraErr := fmt.Errorf("execute: %w", &OraErr{code: 923, offset: 13, message: "FROM keyword not found where expected"})
+	var se *SQLExcerptError
+	// off by default
+	if err := withSQLExcerpt(oraErr, qry); errors.As(err, &se) {
+		t.Errorf("got %#v, wanted no excerpt by default", err)
+	}
+	SetSQLExcerptLength(40)
+	defer SetSQLExcerptLength(0)
+	err := withSQLExcerpt(oraErr, qry)
+	if !errors.As(err, &se) || se.Offset != 13 {
+		t.Fatalf("got %#v, wanted SQLExcerptError", err)
+	}
+	if oe, ok := AsOraErr(err); !ok || oe.Code() != 923 {
+		t.Errorf("got %v, wanted ORA-00923", oe)
+	}
+	t.Log(err)
+	if err := withSQLExcerpt(&OraErr{code: 1}, qry); !errors.Is(err, err) {
+		t.Error(err)
+	} else if errors.As(err, &se) {
+		t.Error("wanted no excerpt for offset 0")
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// DefaultExportBatchSize is the default number of rows per batch of ExportRows.
+const DefaultExportBatchSize = 1024
+
+// LogicalType is the format-neutral logical type of a result column,
+// as used by the columnar formats (Parquet, Arrow, ORC).
+type LogicalType uint8
+
+const (
+	// LogicalString is a UTF-8 string (VARCHAR2, CHAR, CLOB, LONG, ROWID, INTERVAL). Column slice: []sql.NullString.
+	LogicalString = LogicalType(iota)
+	// LogicalBinary is a byte array (RAW, BLOB, LONG RAW). Column slice: [][]byte.
+	LogicalBinary
+	// LogicalInt64 is a NUMBER(p) with p <= 18, or BINARY_INTEGER. Column slice: []sql.NullInt64.
+	LogicalInt64
+	// LogicalDouble is BINARY_FLOAT, BINARY_DOUBLE. Column slice: []sql.NullFloat64.
+	LogicalDouble
+	// LogicalDecimal is a NUMBER with Precision and Scale, or unconstrained NUMBER with zero Precision.
+	// Column slice: []sql.NullString, in decimal notation.
+	LogicalDecimal
+	// LogicalDate is DATE - which has a time part in Oracle, so it should be written as a timestamp with second precision.
+	// Column slice: []sql.NullTime.
+	LogicalDate
+	// LogicalTimestamp is a TIMESTAMP without time zone. Column slice: []sql.NullTime.
+	LogicalTimestamp
+	// LogicalTimestampTZ is a TIMESTAMP WITH (LOCAL) TIME ZONE - an instant. Column slice: []sql.NullTime.
+	LogicalTimestampTZ
+	// LogicalBool is BOOLEAN. Column slice: []sql.NullBool.
+	LogicalBool
+)
+
+var logicalTypeNames = [...]string{
+	"STRING", "BINARY", "INT64", "DOUBLE", "DECIMAL", "DATE", "TIMESTAMP", "TIMESTAMP_TZ", "BOOL",
+}
+
+func (lt LogicalType) String() string {
+	if int(lt) < len(logicalTypeNames) {
+		return logicalTypeNames[lt]
+	}
+	return fmt.Sprintf("LogicalType(%d)", lt)
+}
+
+// ExportColumn describes a result column for a ColumnarWriter.
+type ExportColumn struct {
+	Name, DatabaseType       string
+	Length, Precision, Scale int
+	Type                     LogicalType
+	Nullable                 bool
+}
+
+// ExportColumns returns the ExportColumns of the result's column types.
+func ExportColumns(types []*sql.ColumnType) []ExportColumn {
+	cols := make([]ExportColumn, len(types))
+	for i, ct := range types {
+		col := ExportColumn{Name: ct.Name(), DatabaseType: ct.DatabaseTypeName(), Nullable: true}
+		if nullable, ok := ct.Nullable(); ok {
+			col.Nullable = nullable
+		}
+		if length, ok := ct.Length(); ok && length < 1<<31 {
+			col.Length = int(length)
+		}
+		precision, scale, _ := ct.DecimalSize()
+		col.Type, col.Precision, col.Scale = logicalType(col.DatabaseType, precision, scale)
+		cols[i] = col
+	}
+	return cols
+}
+
+func logicalType(dbType string, precision, scale int64) (LogicalType, int, int) {
+	switch dbType {
+	case "NUMBER":
+		// NUMBER without precision has zero precision and -127 scale.
+		if precision <= 0 || scale < 0 {
+			return LogicalDecimal, 0, 0
+		}
+		if scale == 0 && precision <= 18 {
+			return LogicalInt64, int(precision), 0
+		}
+		return LogicalDecimal, int(precision), int(scale)
+	case "BINARY_INTEGER":
+		return LogicalInt64, 0, 0
+	case "FLOAT", "DOUBLE":
+		return LogicalDouble, 0, 0
+	case "DATE":
+		return LogicalDate, 0, 0
+	case "TIMESTAMP":
+		return LogicalTimestamp, 0, 0
+	case "TIMESTAMP WITH TIME ZONE", "TIMESTAMP WITH LOCAL TIME ZONE":
+		return LogicalTimestampTZ, 0, 0
+	case "RAW", "LONG RAW", "BLOB", "BFILE":
+		return LogicalBinary, 0, 0
+	case "BOOLEAN":
+		return LogicalBool, 0, 0
+	default:
+		return LogicalString, 0, 0
+	}
+}
+
+// ColumnarWriter is implemented by columnar format writers, such as the Parquet file writer
+// of the github.com/godror/godror/parquet package.
+type ColumnarWriter interface {
+	// WriteSchema is called once, before any WriteColumns.
+	WriteSchema([]ExportColumn) error
+	// WriteColumns writes a batch of rowCount rows, as column slices of the types documented at LogicalType.
+	// The slices are reused for the next batch, so they must not be retained.
+	WriteColumns(columns []interface{}, rowCount int) error
+}
+
+// ExportRows reads the rows in batches of batchSize (DefaultExportBatchSize if <= 0)
+// and writes them column-wise to w, returning the number of rows written.
+//
+// For efficient fetching, query with FetchArraySize(batchSize).
+// LOBs are read into memory, so use ClobAsString (the default).
+func ExportRows(ctx context.Context, rows *sql.Rows, w ColumnarWriter, batchSize int) (int64, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultExportBatchSize
+	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, err
+	}
+	cols := ExportColumns(types)
+	if err = w.WriteSchema(cols); err != nil {
+		return 0, err
+	}
+
+	columns := make([]interface{}, len(cols))
+	for i, col := range cols {
+		switch col.Type {
+		case LogicalBinary:
+			columns[i] = make([][]byte, batchSize)
+		case LogicalInt64:
+			columns[i] = make([]sql.NullInt64, batchSize)
+		case LogicalDouble:
+			columns[i] = make([]sql.NullFloat64, batchSize)
+		case LogicalDate, LogicalTimestamp, LogicalTimestampTZ:
+			columns[i] = make([]sql.NullTime, batchSize)
+		case LogicalBool:
+			columns[i] = make([]sql.NullBool, batchSize)
+		default:
+			columns[i] = make([]sql.NullString, batchSize)
+		}
+	}
+	dest := make([]interface{}, len(cols))
+	var n int
+	var written int64
+	flush := func() error {
+		if n == 0 {
+			return nil
+		}
+		batch := make([]interface{}, len(columns))
+		for i, c := range columns {
+			switch x := c.(type) {
+			case [][]byte:
+				batch[i] = x[:n]
+			case []sql.NullInt64:
+				batch[i] = x[:n]
+			case []sql.NullFloat64:
+				batch[i] = x[:n]
+			case []sql.NullTime:
+				batch[i] = x[:n]
+			case []sql.NullBool:
+				batch[i] = x[:n]
+			case []sql.NullString:
+				batch[i] = x[:n]
+			}
+		}
+		if err := w.WriteColumns(batch, n); err != nil {
+			return err
+		}
+		written += int64(n)
+		n = 0
+		return ctx.Err()
+	}
+	for rows.Next() {
+		for i, c := range columns {
+			switch x := c.(type) {
+			case [][]byte:
+				x[n] = nil
+				dest[i] = &x[n]
+			case []sql.NullInt64:
+				dest[i] = &x[n]
+			case []sql.NullFloat64:
+				dest[i] = &x[n]
+			case []sql.NullTime:
+				dest[i] = &x[n]
+			case []sql.NullBool:
+				dest[i] = &x[n]
+			case []sql.NullString:
+				dest[i] = &x[n]
+			}
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return written, fmt.Errorf("scan into %s: %w", describeExportColumns(cols), err)
+		}
+		if n++; n == batchSize {
+			if err = flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return written, err
+	}
+	return written, flush()
+}
+
+func describeExportColumns(cols []ExportColumn) string {
+	var buf strings.Builder
+	for i, col := range cols {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(col.Name + " " + col.Type.String())
+	}
+	return buf.String()
+}
@@ -0,0 +1,33 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import "testing"
+
+func TestLogicalType(t *testing.T) {
+	for _, tc := range []struct {
+		DBType                string
+		Precision, Scale      int64
+		Want                  LogicalType
+		WantPrecision, WantSc int
+	}{
+		{DBType: "NUMBER", Scale: -127, Want: LogicalDecimal},
+		{DBType: "NUMBER", Precision: 9, Want: LogicalInt64, WantPrecision: 9},
+		{DBType: "NUMBER", Precision: 19, Want: LogicalDecimal, WantPrecision: 19},
+		{DBType: "NUMBER", Precision: 12, Scale: 2, Want: LogicalDecimal, WantPrecision: 12, WantSc: 2},
+		{DBType: "DOUBLE", Want: LogicalDouble},
+		{DBType: "DATE", Want: LogicalDate},
+		{DBType: "TIMESTAMP WITH LOCAL TIME ZONE", Want: LogicalTimestampTZ},
+		{DBType: "BLOB", Want: LogicalBinary},
+		{DBType: "VARCHAR2", Want: LogicalString},
+	} {
+		got, p, s := logicalType(tc.DBType, tc.Precision, tc.Scale)
+		if got != tc.Want || p != tc.WantPrecision || s != tc.WantSc {
+			t.Errorf("%s(%d,%d): got %s(%d,%d), wanted %s(%d,%d)",
+				tc.DBType, tc.Precision, tc.Scale, got, p, s, tc.Want, tc.WantPrecision, tc.WantSc)
+		}
+	}
+}
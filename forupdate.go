@@ -0,0 +1,86 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrResourceBusy is ORA-00054: resource busy and acquire with NOWAIT specified or timeout expired.
+	//
+	// Use errors.Is(err, ErrResourceBusy) to check for it.
+	ErrResourceBusy = errors.New("resource busy")
+	// ErrLockWaitTimeout is ORA-30006: resource busy; acquire with WAIT timeout expired.
+	//
+	// Use errors.Is(err, ErrLockWaitTimeout) to check for it.
+	ErrLockWaitTimeout = errors.New("lock wait timeout expired")
+)
+
+// LockMode specifies how SELECT ... FOR UPDATE waits for locked rows.
+type LockMode uint8
+
+const (
+	// LockWait waits for the locked rows - indefinitely, or for the given Wait time.
+	LockWait = LockMode(iota)
+	// LockNoWait returns ORA-00054 (ErrResourceBusy) immediately if a row is locked.
+	LockNoWait
+	// LockSkipLocked skips the rows locked by other sessions.
+	LockSkipLocked
+)
+
+// ForUpdate is the locking clause of a SELECT ... FOR UPDATE statement.
+//
+// A typical job-queue consumer on a plain table uses
+//
+//	qry := godror.ForUpdate{Mode: godror.LockSkipLocked}.AppendTo("SELECT id, payload FROM jobs WHERE state = 'NEW'")
+type ForUpdate struct {
+	// Of lists the columns for FOR UPDATE OF - this determines which tables' rows are locked in a join.
+	Of []string
+	// Wait is the maximum time to wait for the lock with LockWait, rounded up to seconds.
+	// Zero means wait indefinitely.
+	Wait time.Duration
+	Mode LockMode
+}
+
+// String returns the " FOR UPDATE ..." clause.
+func (fu ForUpdate) String() string {
+	var buf strings.Builder
+	buf.WriteString(" FOR UPDATE")
+	if len(fu.Of) != 0 {
+		buf.WriteString(" OF ")
+		buf.WriteString(strings.Join(fu.Of, ", "))
+	}
+	switch fu.Mode {
+	case LockNoWait:
+		buf.WriteString(" NOWAIT")
+	case LockSkipLocked:
+		buf.WriteString(" SKIP LOCKED")
+	default:
+		if fu.Wait > 0 {
+			secs := int64((fu.Wait + time.Second - 1) / time.Second)
+			buf.WriteString(" WAIT ")
+			buf.WriteString(strconv.FormatInt(secs, 10))
+		}
+	}
+	return buf.String()
+}
+
+// AppendTo appends the locking clause to the query, after trimming the trailing semicolon and spaces.
+func (fu ForUpdate) AppendTo(qry string) string {
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(qry), ";")) + fu.String()
+}
+
+// oraErrSentinels maps the ORA error codes to the sentinel errors OraErr.Is reports.
+var oraErrSentinels = map[int]error{
+	54:    ErrResourceBusy,
+	30006: ErrLockWaitTimeout,
+	29275: ErrCharsetConversion,
+	12713: ErrCharsetConversion,
+}
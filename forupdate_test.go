@@ -0,0 +1,44 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestForUpdate(t *testing.T) {
+	const qry = "SELECT id FROM jobs"
+	for i, tc := range []struct {
+		ForUpdate
+		want string
+	}{
+		{ForUpdate{}, qry + " FOR UPDATE"},
+		{ForUpdate{Mode: LockNoWait}, qry + " FOR UPDATE NOWAIT"},
+		{ForUpdate{Mode: LockSkipLocked, Of: []string{"id"}}, qry + " FOR UPDATE OF id SKIP LOCKED"},
+		{ForUpdate{Wait: 1500 * time.Millisecond}, qry + " FOR UPDATE WAIT 2"},
+		{ForUpdate{Mode: LockSkipLocked, Wait: time.Second}, qry + " FOR UPDATE SKIP LOCKED"},
+	} {
+		if got := tc.ForUpdate.AppendTo(qry + " ;"); got != tc.want {
+			t.Errorf("%d. got %q, wanted %q", i, got, tc.want)
+		}
+	}
+}
+
+func TestOraErrIs(t *testing.T) {
+	err := fmt.Errorf("select: %w", &OraErr{code: 54, message: "resource busy"})
+	if !errors.Is(err, ErrResourceBusy) {
+		t.Errorf("%v is not ErrResourceBusy", err)
+	}
+	if errors.Is(err, ErrLockWaitTimeout) {
+		t.Errorf("%v is ErrLockWaitTimeout", err)
+	}
+	if err = fromErrorInfo(newErrorInfo(0, "ORA-30006: resource busy; acquire with WAIT timeout expired")); !errors.Is(err, ErrLockWaitTimeout) {
+		t.Errorf("%v is not ErrLockWaitTimeout", err)
+	}
+}
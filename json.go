@@ -370,6 +370,7 @@ func jsonNodeToData(data *Data, node *C.dpiJsonNode) {
 	}
 	data.dpiData.value = *node.value
 	data.NativeTypeNum = node.nativeTypeNum
+	data.oracleTypeNum = node.oracleTypeNum
 }
 
 // JSONStringFlags represents the input JSON string format.
@@ -0,0 +1,117 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+)
+
+// JSONSchemaOptions are the options of JSONSchema.
+type JSONSchemaOptions struct {
+	// Title of the schema.
+	Title string
+	// DecimalAsString describes the decimals as strings - as Number marshals to JSON.
+	DecimalAsString bool
+}
+
+// JSONSchema returns the JSON Schema (draft 2020-12, usable as an OpenAPI 3.1 schema)
+// of the result rows as an array of objects, keyed by the column names.
+//
+// The returned map is ready for json.Marshal.
+func JSONSchema(cols []ExportColumn, opts JSONSchemaOptions) map[string]interface{} {
+	props := make(map[string]interface{}, len(cols))
+	required := make([]string, 0, len(cols))
+	for _, col := range cols {
+		props[col.Name] = jsonSchemaColumn(col, opts)
+		required = append(required, col.Name)
+	}
+	item := map[string]interface{}{
+		"type":                 "object",
+		"properties":           props,
+		"required":             required,
+		"additionalProperties": false,
+	}
+	schema := map[string]interface{}{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type":    "array",
+		"items":   item,
+	}
+	if opts.Title != "" {
+		schema["title"] = opts.Title
+	}
+	return schema
+}
+
+func jsonSchemaColumn(col ExportColumn, opts JSONSchemaOptions) map[string]interface{} {
+	m := map[string]interface{}{"x-oracle-type": col.DatabaseType}
+	var typ string
+	switch col.Type {
+	case LogicalInt64:
+		typ = "integer"
+		if col.Precision > 0 {
+			bound := json.Number("1e" + strconv.Itoa(col.Precision))
+			m["exclusiveMaximum"], m["exclusiveMinimum"] = bound, "-"+bound
+		}
+	case LogicalDouble:
+		typ = "number"
+	case LogicalDecimal:
+		if opts.DecimalAsString {
+			typ = "string"
+			m["pattern"] = `^-?[0-9]*\.?[0-9]+([eE][-+]?[0-9]+)?$`
+		} else {
+			typ = "number"
+			if col.Precision > 0 {
+				bound := json.Number("1e" + strconv.Itoa(col.Precision-col.Scale))
+				m["exclusiveMaximum"], m["exclusiveMinimum"] = bound, "-"+bound
+			}
+			if col.Scale > 0 {
+				m["multipleOf"] = json.Number("1e-" + strconv.Itoa(col.Scale))
+			}
+		}
+		if col.Precision > 0 {
+			m["x-precision"], m["x-scale"] = col.Precision, col.Scale
+		}
+	case LogicalDate, LogicalTimestamp, LogicalTimestampTZ:
+		typ = "string"
+		m["format"] = "date-time"
+	case LogicalBinary:
+		typ = "string"
+		m["contentEncoding"] = "base64"
+	case LogicalBool:
+		typ = "boolean"
+	default:
+		typ = "string"
+		switch col.DatabaseType {
+		case "VARCHAR2", "NVARCHAR2", "CHAR", "NCHAR":
+			if col.Length > 0 {
+				m["maxLength"] = col.Length
+			}
+		}
+	}
+	if col.Nullable {
+		m["type"] = []string{typ, "null"}
+	} else {
+		m["type"] = typ
+	}
+	return m
+}
+
+// DescribeQueryJSONSchema describes the query (without executing it) and returns the JSONSchema of its result.
+func DescribeQueryJSONSchema(ctx context.Context, db Execer, qry string, opts JSONSchemaOptions) (map[string]interface{}, error) {
+	qcols, err := DescribeQuery(ctx, db, qry)
+	if err != nil {
+		return nil, err
+	}
+	cols := make([]ExportColumn, len(qcols))
+	for i, qc := range qcols {
+		col := ExportColumn{Name: qc.Name, DatabaseType: qc.DatabaseType, Length: qc.Length, Nullable: qc.Nullable}
+		col.Type, col.Precision, col.Scale = logicalType(qc.DatabaseType, int64(qc.Precision), int64(qc.Scale))
+		cols[i] = col
+	}
+	return JSONSchema(cols, opts), nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	cols := []ExportColumn{
+		{Name: "ID", DatabaseType: "NUMBER", Precision: 9, Type: LogicalInt64},
+		{Name: "AMOUNT", DatabaseType: "NUMBER", Precision: 12, Scale: 2, Type: LogicalDecimal, Nullable: true},
+		{Name: "NAME", DatabaseType: "VARCHAR2", Length: 30, Type: LogicalString, Nullable: true},
+		{Name: "CREATED", DatabaseType: "DATE", Type: LogicalDate},
+	}
+	b, err := json.Marshal(JSONSchema(cols, JSONSchemaOptions{Title: "t"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(b)
+	t.Log(s)
+	for _, want := range []string{
+		`"ID":{"exclusiveMaximum":1e9,"exclusiveMinimum":-1e9,"type":"integer"`,
+		`"multipleOf":1e-2`,
+		`"exclusiveMaximum":1e10`,
+		`"type":["number","null"]`,
+		`"maxLength":30`,
+		`"format":"date-time"`,
+		`"required":["ID","AMOUNT","NAME","CREATED"]`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("%q not found", want)
+		}
+	}
+
+	b, err = json.Marshal(JSONSchema(cols, JSONSchemaOptions{DecimalAsString: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s = string(b); !strings.Contains(s, `"type":["string","null"],"x-oracle-type":"NUMBER"`) {
+		t.Errorf("AMOUNT is not string: %s", s)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include "dpiImpl.h"
+*/
+import "C"
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// enter marks the start of op on the connection (for SetConcurrencyDebug and the keepalive),
+// and returns the func to mark its end.
+func (c *conn) enter(op string) (func(), error) {
+	atomic.AddInt32(&c.active, 1)
+	leave, err := c.use.enter(op)
+	if err != nil {
+		atomic.AddInt32(&c.active, -1)
+		return leave, err
+	}
+	return func() {
+		leave()
+		atomic.StoreInt64(&c.lastUsed, getClock().Now().UnixNano())
+		atomic.AddInt32(&c.active, -1)
+	}, nil
+}
+
+// startKeepAlive starts pinging the connection when it's been idle for interval,
+// to keep the session alive even with aggressive firewall idle timeouts.
+//
+// The pinging survives the session re-acquires of ResetSession, and stops when the connection is closed.
+func (c *conn) startKeepAlive(interval time.Duration) {
+	stop := make(chan struct{})
+	c.mu.Lock()
+	c.stopKeepAlive()
+	c.keepAlive = stop
+	c.mu.Unlock()
+	atomic.StoreInt64(&c.lastUsed, getClock().Now().UnixNano())
+	go func() {
+		ticks, stopTicker := getClock().NewTicker(interval / 2)
+		defer stopTicker()
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticks:
+				if now.Sub(time.Unix(0, atomic.LoadInt64(&c.lastUsed))) < interval || atomic.LoadInt32(&c.active) != 0 {
+					continue
+				}
+				c.keepAlivePing()
+			}
+		}
+	}()
+}
+
+// stopKeepAlive stops the keepalive pinging. c.mu must be held.
+func (c *conn) stopKeepAlive() {
+	if c.keepAlive != nil {
+		close(c.keepAlive)
+		c.keepAlive = nil
+	}
+}
+
+// keepAlivePing pings the idle connection, holding the connection lock so no statement can run meanwhile.
+func (c *conn) keepAlivePing() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.dpiConn == nil || atomic.LoadInt32(&c.active) != 0 {
+		return
+	}
+	runtime.LockOSThread()
+	rc := C.dpiConn_ping(c.dpiConn)
+	runtime.UnlockOSThread()
+	atomic.StoreInt64(&c.lastUsed, getClock().Now().UnixNano())
+	if logger := getLogger(); logger != nil {
+		logger.Log("msg", "keepAlive ping", "conn", c.dpiConn, "ok", rc != C.DPI_FAILURE)
+	}
+}
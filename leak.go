@@ -0,0 +1,65 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"fmt"
+	"runtime"
+	"sync/atomic"
+)
+
+// LeakStats counts the statements and rows garbage collected without being closed.
+type LeakStats struct {
+	Statements, Rows uint64
+}
+
+var (
+	leaks     LeakStats
+	leakDebug uint32
+)
+
+// GetLeakStats returns the number of statements and rows garbage collected without being closed.
+//
+// Unclosed statements are always counted, rows only after SetLeakDebug(true).
+func GetLeakStats() LeakStats {
+	return LeakStats{
+		Statements: atomic.LoadUint64(&leaks.Statements),
+		Rows:       atomic.LoadUint64(&leaks.Rows),
+	}
+}
+
+// SetLeakDebug enables (or disables) the leak detection for the rows opened afterwards:
+// the creation stack is saved, and printed (with the logger if set) when the rows are
+// garbage collected without being closed.
+//
+// This has a cost, so it is meant for debugging.
+func SetLeakDebug(enable bool) {
+	var u uint32
+	if enable {
+		u = 1
+	}
+	atomic.StoreUint32(&leakDebug, u)
+}
+
+func rowsSetFinalizer(r *rows) {
+	if atomic.LoadUint32(&leakDebug) == 0 {
+		return
+	}
+	var a [4096]byte
+	stack := string(a[:runtime.Stack(a[:], false)])
+	runtime.SetFinalizer(r, func(r *rows) {
+		if r == nil || r.statement == nil {
+			return
+		}
+		atomic.AddUint64(&leaks.Rows, 1)
+		if logger := getLogger(); logger != nil {
+			logger.Log("msg", "ERROR: rows are not closed!", "rows", fmt.Sprintf("%p", r), "stack", stack)
+		} else {
+			fmt.Printf("ERROR: rows %p are not closed!\n%s\n", r, stack)
+		}
+		_ = r.Close()
+	})
+}
@@ -440,6 +440,8 @@ type DirectLob struct {
 
 var _ = io.ReaderAt((*DirectLob)(nil))
 var _ = io.WriterAt((*DirectLob)(nil))
+var _ = io.ReaderFrom((*DirectLob)(nil))
+var _ = io.WriterTo((*DirectLob)(nil))
 
 // NewTempLob returns a temporary LOB as DirectLob.
 func (c *conn) NewTempLob(isClob bool) (*DirectLob, error) {
@@ -562,6 +564,105 @@ func (dl *DirectLob) WriteAt(p []byte, offset int64) (int, error) {
 	return int(n), nil
 }
 
+// ChunkSize returns the LOB's native chunk size. Reads/writes with a multiply of this size is the most performant.
+func (dl *DirectLob) ChunkSize() (int, error) {
+	var n C.uint32_t
+	if err := dl.drv.checkExec(func() C.int {
+		return C.dpiLob_getChunkSize(dl.dpiLob, &n)
+	}); err != nil {
+		return 0, fmt.Errorf("getChunkSize: %w", err)
+	}
+	return int(n), nil
+}
+
+// ReadFrom appends everything read from r to the end of the LOB,
+// in chunk-sized pieces, without holding all the data in memory.
+//
+// For CLOBs, r must provide UTF-8 text.
+func (dl *DirectLob) ReadFrom(r io.Reader) (n int64, err error) {
+	if dl.dpiLob == nil {
+		return 0, errors.New("ReadFrom on nil LOB")
+	}
+	offset, err := dl.Size()
+	if err != nil {
+		return 0, err
+	}
+	chunkSize, err := dl.ChunkSize()
+	if err != nil {
+		return 0, err
+	}
+	size := chunkSize
+	const minBufferSize = 1 << 20
+	if size <= 0 {
+		size = minBufferSize
+	} else {
+		for size < minBufferSize/2 { // at most 1M
+			size *= 2
+		}
+	}
+	buf := make([]byte, size)
+	var pending int // the incomplete UTF-8 sequence at the end of the previous read (CLOB)
+	for {
+		k, rErr := r.Read(buf[pending:])
+		k += pending
+		m := k
+		if dl.isClob && rErr == nil {
+			m = completeRunesLen(buf[:k])
+		}
+		if m != 0 {
+			if _, err = dl.WriteAt(buf[:m], offset); err != nil {
+				return n, err
+			}
+			n += int64(m)
+			if dl.isClob {
+				offset += int64(ucs2Len(buf[:m]))
+			} else {
+				offset += int64(m)
+			}
+		}
+		pending = copy(buf, buf[m:k])
+		if rErr == io.EOF {
+			return n, nil
+		} else if rErr != nil {
+			return n, rErr
+		}
+	}
+}
+
+// WriteTo writes the contents of the LOB to w, reading it in chunk-sized pieces.
+func (dl *DirectLob) WriteTo(w io.Writer) (n int64, err error) {
+	if dl.dpiLob == nil {
+		return 0, nil
+	}
+	return (&dpiLobReader{drv: dl.drv, dpiLob: dl.dpiLob, IsClob: dl.isClob}).WriteTo(w)
+}
+
+// completeRunesLen returns the length of the prefix of p which does not end in an incomplete UTF-8 sequence.
+func completeRunesLen(p []byte) int {
+	for i := len(p) - 1; i >= 0 && i >= len(p)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(p[i]) {
+			if utf8.FullRune(p[i:]) {
+				return len(p)
+			}
+			return i
+		}
+	}
+	return len(p)
+}
+
+// ucs2Len returns the number of UCS-2 codepoints in the UTF-8 p - the unit of CLOB offsets.
+func ucs2Len(p []byte) int {
+	var n int
+	for len(p) != 0 {
+		r, size := utf8.DecodeRune(p)
+		p = p[size:]
+		if n++; r > 0xFFFF {
+			n++
+		}
+	}
+	return n
+}
+
 // GetFileName Return directory alias and file name for a BFILE type LOB.
 func (dl *DirectLob) GetFileName() (dir, file string, err error) {
 	var directoryAliasLength, fileNameLength C.uint32_t
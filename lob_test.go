@@ -0,0 +1,31 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import "testing"
+
+func TestLobRunes(t *testing.T) {
+	for _, tc := range []struct {
+		In        string
+		Complete  int
+		UCS2Count int
+	}{
+		{In: "abc", Complete: 3, UCS2Count: 3},
+		{In: "árvíz", Complete: 7, UCS2Count: 5},
+		{In: "ab\xc3", Complete: 2, UCS2Count: 3},
+		{In: "a\xf0\x9f\x98", Complete: 1, UCS2Count: 2},
+		{In: "a😀", Complete: 5, UCS2Count: 3},
+	} {
+		if got := completeRunesLen([]byte(tc.In)); got != tc.Complete {
+			t.Errorf("%q: got complete=%d, wanted %d", tc.In, got, tc.Complete)
+		}
+		if tc.Complete == len(tc.In) {
+			if got := ucs2Len([]byte(tc.In)); got != tc.UCS2Count {
+				t.Errorf("%q: got ucs2Len=%d, wanted %d", tc.In, got, tc.UCS2Count)
+			}
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var lockDiagnostics uint32
+
+// SetLockDiagnostics enables (or disables) the collection of the blocking sessions
+// on ORA-00060 (deadlock) errors: they are returned in a *LockError, wrapping the original error.
+//
+// The blockers are the sessions of the deadlock cycle: those waiting for this session's locks
+// (the waits are still there, as only the statement is rolled back, not the transaction).
+// ORA-00054 (resource busy, NOWAIT) is not diagnosed, as no wait is recorded for it,
+// so the holder of the lock cannot be told apart from the unrelated blockers.
+//
+// The collection needs SELECT privilege on GV$SESSION, and is best effort:
+// if it fails, the original error is returned.
+func SetLockDiagnostics(enable bool) {
+	var u uint32
+	if enable {
+		u = 1
+	}
+	atomic.StoreUint32(&lockDiagnostics, u)
+}
+
+// BlockingSession is a session holding a lock others are waiting for.
+type BlockingSession struct {
+	Username, OSUser, Machine, Program, Module string
+	SQLID, Event                               string
+	SID, Serial                                int
+	// Waiters is the number of sessions waiting for this one.
+	Waiters int
+	// WaitTime is how long the blocking session itself has been in its current state.
+	WaitTime time.Duration
+}
+
+func (bs BlockingSession) String() string {
+	return fmt.Sprintf("sid=%d,%d user=%s osuser=%s machine=%s program=%s module=%s sql_id=%s event=%q wait=%s waiters=%d",
+		bs.SID, bs.Serial, bs.Username, bs.OSUser, bs.Machine, bs.Program, bs.Module, bs.SQLID, bs.Event, bs.WaitTime, bs.Waiters)
+}
+
+// LockError is returned (with SetLockDiagnostics(true)) on ORA-00060,
+// with the sessions of the deadlock at the time of the error.
+//
+// It unwraps to the original error, so AsOraErr works on it.
+type LockError struct {
+	Err      error
+	Blockers []BlockingSession
+	// SID of the session which got the error.
+	SID int
+}
+
+func (le *LockError) Error() string {
+	var buf strings.Builder
+	buf.WriteString(le.Err.Error())
+	fmt.Fprintf(&buf, " (sid=%d blockers:", le.SID)
+	if len(le.Blockers) == 0 {
+		buf.WriteString(" none")
+	}
+	for _, b := range le.Blockers {
+		buf.WriteString(" [")
+		buf.WriteString(b.String())
+		buf.WriteByte(']')
+	}
+	buf.WriteByte(')')
+	return buf.String()
+}
+func (le *LockError) Unwrap() error { return le.Err }
+
+// withLockDiagnostics returns err wrapped in a *LockError, iff SetLockDiagnostics(true)
+// and err is ORA-00060.
+//
+// Must be called with c.mu held.
+func (c *conn) withLockDiagnostics(err error) error {
+	if err == nil || atomic.LoadUint32(&lockDiagnostics) == 0 {
+		return err
+	}
+	oe, ok := AsOraErr(err)
+	if !ok || oe.Code() != 60 {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	le := LockError{Err: err}
+	var qErr error
+	if le.SID, le.Blockers, qErr = c.blockingSessions(ctx); qErr != nil {
+		if logger := getLogger(); logger != nil {
+			logger.Log("msg", "collect blocking sessions", "error", qErr)
+		}
+		return err
+	}
+	return &le
+}
+
+// blockingSessions returns the SID of the session, and the sessions waiting for it (the other members of the deadlock).
+//
+// GV$SESSION is used, as the other session may be on another instance of a RAC.
+func (c *conn) blockingSessions(ctx context.Context) (int, []BlockingSession, error) {
+	const qry = `SELECT TO_CHAR(SYS_CONTEXT('USERENV', 'SID')),
+       TO_CHAR(B.sid), TO_CHAR(B.serial#), B.username, B.osuser, B.machine, B.program, B.module,
+       B.sql_id, B.event, TO_CHAR(B.seconds_in_wait),
+       (SELECT TO_CHAR(COUNT(0)) FROM gv$session W
+          WHERE W.blocking_session = B.sid AND W.blocking_instance = B.inst_id)
+  FROM DUAL
+    LEFT OUTER JOIN gv$session B ON B.blocking_session = TO_NUMBER(SYS_CONTEXT('USERENV', 'SID')) AND
+                                    B.blocking_instance = TO_NUMBER(SYS_CONTEXT('USERENV', 'INSTANCE'))
+  ORDER BY B.seconds_in_wait DESC NULLS LAST`
+	st, err := c.prepareContextNotLocked(ctx, qry)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer st.Close()
+	rows, err := st.(*statement).queryContextNotLocked(ctx, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+	var sid int
+	var blockers []BlockingSession
+	vals := make([]driver.Value, 12)
+	for {
+		if err = rows.Next(vals); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return sid, blockers, err
+		}
+		s := func(i int) string { s, _ := vals[i].(string); return s }
+		n := func(i int) int { n, _ := strconv.Atoi(s(i)); return n }
+		sid = n(0)
+		if s(1) == "" {
+			continue
+		}
+		blockers = append(blockers, BlockingSession{
+			SID: n(1), Serial: n(2),
+			Username: s(3), OSUser: s(4), Machine: s(5), Program: s(6), Module: s(7),
+			SQLID: s(8), Event: s(9),
+			WaitTime: time.Duration(n(10)) * time.Second,
+			Waiters:  n(11),
+		})
+	}
+	return sid, blockers, nil
+}
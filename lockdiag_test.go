@@ -0,0 +1,44 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLockError(t *testing.T) {
+	oe := &OraErr{code: 60, message: "deadlock detected while waiting for resource"}
+	if err := (&conn{}).withLockDiagnostics(oe); err != error(oe) {
+		t.Errorf("got %v, wanted the original error when disabled", err)
+	}
+
+	SetLockDiagnostics(true)
+	busy := &OraErr{code: 54, message: "resource busy and acquire with NOWAIT specified or timeout expired"}
+	if err := (&conn{}).withLockDiagnostics(busy); err != error(busy) {
+		t.Errorf("got %v, wanted ORA-00054 undiagnosed", err)
+	}
+	SetLockDiagnostics(false)
+
+	le := &LockError{Err: oe, SID: 42, Blockers: []BlockingSession{{
+		SID: 13, Serial: 7, Username: "SCOTT", Program: "batch", Event: "SQL*Net message from client",
+		WaitTime: time.Minute, Waiters: 2,
+	}}}
+	if got, ok := AsOraErr(le); !ok || got.Code() != 60 {
+		t.Errorf("got %v, wanted ORA-00060", got)
+	}
+	var target *LockError
+	if !errors.As(error(le), &target) || len(target.Blockers) != 1 {
+		t.Errorf("errors.As: got %v", target)
+	}
+	s := le.Error()
+	t.Log(s)
+	if !strings.Contains(s, "sid=42") || !strings.Contains(s, "sid=13,7 user=SCOTT") {
+		t.Errorf("got %q", s)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include "dpiImpl.h"
+*/
+import "C"
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"unsafe"
+)
+
+// LTXID returns the logical transaction id of the connection (needs Transaction Guard:
+// a service with COMMIT_OUTCOME=TRUE), which is nil if it is not available.
+//
+// Get it before the commit, and in case of an ambiguous commit error,
+// check the outcome with GetTransactionOutcome on a new connection.
+func (c *conn) LTXID() ([]byte, error) {
+	var value *C.char
+	var length C.uint32_t
+	if err := c.checkExec(func() C.int { return C.dpiConn_getLTXID(c.dpiConn, &value, &length) }); err != nil {
+		return nil, fmt.Errorf("getLTXID: %w", err)
+	}
+	if length == 0 {
+		return nil, nil
+	}
+	return C.GoBytes(unsafe.Pointer(value), C.int(length)), nil
+}
+
+// GetLTXID returns the logical transaction id of the connection of ex - use a *sql.Conn or *sql.Tx!
+func GetLTXID(ctx context.Context, ex Execer) (ltxid []byte, err error) {
+	err = Raw(ctx, ex, func(c Conn) error {
+		ltxid, err = c.LTXID()
+		return err
+	})
+	return ltxid, err
+}
+
+// TransactionOutcome is the outcome of a logical transaction.
+type TransactionOutcome struct {
+	// Committed is true if the transaction has been committed.
+	Committed bool
+	// UserCallCompleted is true if the user call which committed has completed,
+	// so its results (OUT binds, affected rows) were complete.
+	UserCallCompleted bool
+}
+
+// GetTransactionOutcome determines whether the transaction with the given logical transaction id
+// (as returned by GetLTXID before the commit) has been committed, using DBMS_APP_CONT.GET_LTXID_OUTCOME.
+//
+// Call it on a new session (not the one that got the commit error),
+// which needs the EXECUTE privilege on DBMS_APP_CONT.
+// It also blocks the transaction of ltxid from committing later, so its outcome is final.
+func GetTransactionOutcome(ctx context.Context, ex Execer, ltxid []byte) (TransactionOutcome, error) {
+	const qry = `DECLARE
+  v_committed BOOLEAN;
+  v_completed BOOLEAN;
+BEGIN
+  DBMS_APP_CONT.GET_LTXID_OUTCOME(client_ltxid=>:1, committed=>v_committed, user_call_completed=>v_completed);
+  :2 := CASE WHEN v_committed THEN 1 ELSE 0 END;
+  :3 := CASE WHEN v_completed THEN 1 ELSE 0 END;
+END;`
+	var committed, completed int32
+	if _, err := ex.ExecContext(ctx, qry, ltxid, sql.Out{Dest: &committed}, sql.Out{Dest: &completed}); err != nil {
+		return TransactionOutcome{}, fmt.Errorf("%s: %w", qry, err)
+	}
+	return TransactionOutcome{Committed: committed == 1, UserCallCompleted: completed == 1}, nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// maxVarSize is the maximum size of a non-LOB VARCHAR2/RAW variable
+// (in PL/SQL, or in SQL with MAX_STRING_SIZE=EXTENDED).
+// Bigger variables would be bound piecewise, as LONG.
+const maxVarSize = 32767
+
+// Maximum VARCHAR2/RAW sizes in SQL, by MAX_STRING_SIZE.
+const (
+	StandardStringSize = 4000
+	ExtendedStringSize = maxVarSize
+)
+
+// MaxStringSize returns the maximum size of VARCHAR2/RAW in SQL:
+// ExtendedStringSize if the database has MAX_STRING_SIZE=EXTENDED, StandardStringSize otherwise.
+//
+// It does not need privileges on v$parameter, and is cached for the connection.
+func (c *conn) MaxStringSize(ctx context.Context) (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.maxStringSizeNotLocked(ctx)
+}
+
+// maxStringSizeNotLocked is MaxStringSize, with c.mu held.
+func (c *conn) maxStringSizeNotLocked(ctx context.Context) (int, error) {
+	if c.maxStringSize != 0 {
+		return c.maxStringSize, nil
+	}
+	const qry = "SELECT CAST(NULL AS VARCHAR2(32767)) FROM DUAL"
+	err := func() error {
+		st, err := c.prepareContextNotLocked(ctx, qry)
+		if err != nil {
+			return err
+		}
+		defer st.Close()
+		rows, err := st.(*statement).queryContextNotLocked(ctx, nil)
+		if err != nil {
+			return err
+		}
+		return rows.Close()
+	}()
+	if err == nil {
+		c.maxStringSize = ExtendedStringSize
+		return c.maxStringSize, nil
+	}
+	// ORA-00910: specified length too long for its datatype
+	if oe, ok := AsOraErr(err); ok && oe.Code() == 910 {
+		c.maxStringSize = StandardStringSize
+		return c.maxStringSize, nil
+	}
+	return 0, fmt.Errorf("%s: %w", qry, err)
+}
+
+// MaxStringSize returns the maximum size of VARCHAR2/RAW in SQL:
+// ExtendedStringSize (32767) if the database has MAX_STRING_SIZE=EXTENDED,
+// StandardStringSize (4000) otherwise.
+//
+// Binds and fetches of VARCHAR2/RAW up to this size are handled as such, without LOBs.
+func MaxStringSize(ctx context.Context, ex Execer) (int, error) {
+	var n int
+	err := Raw(ctx, ex, func(c Conn) error {
+		cx, ok := c.(*conn)
+		if !ok {
+			return fmt.Errorf("%T: %w", c, ErrNotGodror)
+		}
+		var err error
+		n, err = cx.MaxStringSize(ctx)
+		return err
+	})
+	return n, err
+}
+
+// bindStringLimit returns the maximum size of a VARCHAR2 bind variable:
+// maxVarSize in PL/SQL, MaxStringSize in SQL - maxVarSize if that's not detected yet.
+func bindStringLimit(isPLSQL bool, maxStringSize int) int {
+	if isPLSQL || maxStringSize == 0 {
+		return maxVarSize
+	}
+	return maxStringSize
+}
+
+// longStringsAsClob returns v as Lob (or []Lob) iff v is a string (or []string) too long for a VARCHAR2 bind.
+func (st *statement) longStringsAsClob(v interface{}) interface{} {
+	return longStringsAsClob(v, clobStringLimit(st.dpiStmtInfo.isPLSQL != 0, st.conn.maxStringSize))
+}
+
+// clobStringLimit returns the size above which LongStringAsClob binds a string as CLOB:
+// maxVarSize in PL/SQL, MaxStringSize in SQL - StandardStringSize if that's unknown.
+func clobStringLimit(isPLSQL bool, maxStringSize int) int {
+	if isPLSQL {
+		return maxVarSize
+	}
+	if maxStringSize == 0 {
+		return StandardStringSize
+	}
+	return maxStringSize
+}
+
+// longStringsAsClob returns v as Lob (or []Lob) iff v is a string (or []string) longer than limit.
+func longStringsAsClob(v interface{}, limit int) interface{} {
+	switch v := v.(type) {
+	case string:
+		if len(v) > limit {
+			return Lob{Reader: strings.NewReader(v), IsClob: true}
+		}
+	case []string:
+		for _, s := range v {
+			if len(s) > limit {
+				lobs := make([]Lob, len(v))
+				for i, s := range v {
+					lobs[i] = Lob{Reader: strings.NewReader(s), IsClob: true}
+				}
+				return lobs
+			}
+		}
+	}
+	return nil
+}
+
+// detectMaxStringSize detects MaxStringSize for LongStringAsClob, iff it's needed:
+// a SQL statement has a string argument longer than StandardStringSize.
+//
+// Must be called with st.conn.mu held.
+func (st *statement) detectMaxStringSize(ctx context.Context, args []driver.NamedValue) {
+	if !st.LongStringAsClob() || st.dpiStmtInfo.isPLSQL != 0 || st.conn.maxStringSize != 0 ||
+		!hasLongString(args, StandardStringSize) {
+		return
+	}
+	if _, err := st.conn.maxStringSizeNotLocked(ctx); err != nil {
+		if logger := ctxGetLog(ctx); logger != nil {
+			logger.Log("msg", "detect MaxStringSize", "error", err)
+		}
+	}
+}
+
+// hasLongString reports whether any of the IN args is a string (or []string) longer than limit.
+func hasLongString(args []driver.NamedValue, limit int) bool {
+	for _, a := range args {
+		if _, isOut := a.Value.(sql.Out); !isOut && longStringsAsClob(a.Value, limit) != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+func TestBindStringLimit(t *testing.T) {
+	for _, tc := range []struct {
+		IsPLSQL       bool
+		MaxStringSize int
+		Want          int
+	}{
+		{IsPLSQL: true, Want: maxVarSize},
+		{IsPLSQL: true, MaxStringSize: StandardStringSize, Want: maxVarSize},
+		{Want: maxVarSize},
+		{MaxStringSize: StandardStringSize, Want: StandardStringSize},
+		{MaxStringSize: ExtendedStringSize, Want: ExtendedStringSize},
+	} {
+		if got := bindStringLimit(tc.IsPLSQL, tc.MaxStringSize); got != tc.Want {
+			t.Errorf("%+v: got %d", tc, got)
+		}
+	}
+}
+
+func TestLongStringsAsClob(t *testing.T) {
+	for _, tc := range []struct {
+		IsPLSQL       bool
+		MaxStringSize int
+		Want          int
+	}{
+		{IsPLSQL: true, Want: maxVarSize},
+		{Want: StandardStringSize},
+		{MaxStringSize: StandardStringSize, Want: StandardStringSize},
+		{MaxStringSize: ExtendedStringSize, Want: ExtendedStringSize},
+	} {
+		if got := clobStringLimit(tc.IsPLSQL, tc.MaxStringSize); got != tc.Want {
+			t.Errorf("%+v: got %d", tc, got)
+		}
+	}
+
+	short, long := strings.Repeat("x", StandardStringSize), strings.Repeat("x", StandardStringSize+1)
+	if v := longStringsAsClob(short, StandardStringSize); v != nil {
+		t.Errorf("%d: got %T", len(short), v)
+	}
+	if v, ok := longStringsAsClob(long, StandardStringSize).(Lob); !ok || !v.IsClob {
+		t.Errorf("%d: got %#v", len(long), v)
+	}
+	if v := longStringsAsClob(long, ExtendedStringSize); v != nil {
+		t.Errorf("%d with extended: got %T", len(long), v)
+	}
+	if v, ok := longStringsAsClob([]string{short, long}, StandardStringSize).([]Lob); !ok || len(v) != 2 {
+		t.Errorf("[]string: got %#v", v)
+	}
+	if v := longStringsAsClob([]string{short, short}, StandardStringSize); v != nil {
+		t.Errorf("short []string: got %T", v)
+	}
+	if v := longStringsAsClob(42, 0); v != nil {
+		t.Errorf("int: got %T", v)
+	}
+
+	for _, tc := range []struct {
+		Args []driver.NamedValue
+		Want bool
+	}{
+		{Args: []driver.NamedValue{{Value: short}, {Value: 1}}},
+		{Args: []driver.NamedValue{{Value: short}, {Value: long}}, Want: true},
+		{Args: []driver.NamedValue{{Value: []string{short, long}}}, Want: true},
+		{Args: []driver.NamedValue{{Value: sql.Out{Dest: &long, In: true}}}},
+	} {
+		if got := hasLongString(tc.Args, StandardStringSize); got != tc.Want {
+			t.Errorf("%d args: got %t, wanted %t", len(tc.Args), got, tc.Want)
+		}
+	}
+}
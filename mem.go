@@ -0,0 +1,156 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include "dpiImpl.h"
+*/
+import "C"
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// MemStats is the memory allocated by the driver for the buffers of the variables.
+//
+// Buffers of dynamically sized (LONG, and CLOB fetched as string) variables are counted
+// only with their fixed part, and the server side of the temporary LOBs is not included.
+type MemStats struct {
+	// BindBytes and FetchBytes are the sizes of the bind variable and fetch array buffers.
+	BindBytes, FetchBytes int64
+	// LOBs is the number of temporary LOBs created for binds, held by the bind variables.
+	LOBs int64
+}
+
+func (m MemStats) String() string {
+	return fmt.Sprintf("bind=%d fetch=%d lobs=%d", m.BindBytes, m.FetchBytes, m.LOBs)
+}
+
+// memStats is the atomically updated MemStats of a pool (or of the standalone connections).
+//
+// The sizes are recorded at allocation, so the release subtracts exactly what has been added.
+type memStats struct {
+	bindBytes, fetchBytes, lobs int64
+
+	mu   sync.Mutex
+	vars map[unsafe.Pointer]*varMem
+}
+
+// varMem is what has been accounted for a variable.
+type varMem struct {
+	tempLobs map[int]struct{}
+	bytes    int64
+	fetch    bool
+}
+
+// standaloneMem accounts for the non-pooled connections.
+var standaloneMem memStats
+
+// GetStandaloneMemStats returns the memory allocated for the non-pooled connections.
+// For pools, see PoolStats.Memory.
+func GetStandaloneMemStats() MemStats { return standaloneMem.get() }
+
+func (m *memStats) get() MemStats {
+	return MemStats{
+		BindBytes:  atomic.LoadInt64(&m.bindBytes),
+		FetchBytes: atomic.LoadInt64(&m.fetchBytes),
+		LOBs:       atomic.LoadInt64(&m.lobs),
+	}
+}
+
+var dpiDataSize = int64(C.sizeof_dpiData)
+
+// varBytes returns the buffer size of a variable with sliceLen elements of sizeInBytes each.
+func varBytes(sliceLen int, sizeInBytes int64) int64 {
+	if sliceLen < 1 {
+		sliceLen = 1
+	}
+	return int64(sliceLen) * (sizeInBytes + dpiDataSize)
+}
+
+// addVar accounts for the allocation of the variable with sliceLen elements (the length of its data).
+func (m *memStats) addVar(fetch bool, v *C.dpiVar, sliceLen int) {
+	if m == nil || v == nil {
+		return
+	}
+	var size C.uint32_t
+	if C.dpiVar_getSizeInBytes(v, &size) == C.DPI_FAILURE {
+		size = 0
+	}
+	m.add(unsafe.Pointer(v), fetch, varBytes(sliceLen, int64(size)))
+}
+
+// addTempLob accounts for the temporary LOB set as the pos-th element of the variable.
+func (m *memStats) addTempLob(v *C.dpiVar, pos int) {
+	if m == nil || v == nil {
+		return
+	}
+	m.addLob(unsafe.Pointer(v), pos)
+}
+
+// releaseVar releases the variable, and what has been accounted for it.
+func (m *memStats) releaseVar(v *C.dpiVar) {
+	if v == nil {
+		return
+	}
+	m.remove(unsafe.Pointer(v))
+	C.dpiVar_release(v)
+}
+
+func (m *memStats) add(key unsafe.Pointer, fetch bool, bytes int64) {
+	m.remove(key) // a stale record of a variable freed at the same address
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.vars == nil {
+		m.vars = make(map[unsafe.Pointer]*varMem)
+	}
+	m.vars[key] = &varMem{fetch: fetch, bytes: bytes}
+	atomic.AddInt64(m.counter(fetch), bytes)
+}
+
+// addLob records a temporary LOB at the pos-th element of the variable.
+// A LOB replacing an earlier one at the same position is not counted again, as the variable frees the old one.
+func (m *memStats) addLob(key unsafe.Pointer, pos int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	vm := m.vars[key]
+	if vm == nil {
+		return
+	}
+	if _, ok := vm.tempLobs[pos]; ok {
+		return
+	}
+	if vm.tempLobs == nil {
+		vm.tempLobs = make(map[int]struct{})
+	}
+	vm.tempLobs[pos] = struct{}{}
+	atomic.AddInt64(&m.lobs, 1)
+}
+
+func (m *memStats) remove(key unsafe.Pointer) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	vm := m.vars[key]
+	if vm == nil {
+		return
+	}
+	delete(m.vars, key)
+	atomic.AddInt64(m.counter(vm.fetch), -vm.bytes)
+	atomic.AddInt64(&m.lobs, -int64(len(vm.tempLobs)))
+}
+
+func (m *memStats) counter(fetch bool) *int64 {
+	if fetch {
+		return &m.fetchBytes
+	}
+	return &m.bindBytes
+}
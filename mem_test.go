@@ -0,0 +1,54 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestMemStats(t *testing.T) {
+	if got, want := varBytes(0, 10), varBytes(1, 10); got != want {
+		t.Errorf("varBytes(0)=%d, wanted %d", got, want)
+	}
+	if got, want := varBytes(3, 10), 3*(10+dpiDataSize); got != want {
+		t.Errorf("varBytes(3)=%d, wanted %d", got, want)
+	}
+
+	var m memStats
+	var a, b [1]byte
+	bind, fetch := unsafe.Pointer(&a), unsafe.Pointer(&b)
+	m.add(bind, false, 100)
+	m.add(fetch, true, 30)
+	if got, want := m.get(), (MemStats{BindBytes: 100, FetchBytes: 30}); got != want {
+		t.Errorf("after add: got %v, wanted %v", got, want)
+	}
+
+	m.addLob(bind, 0)
+	m.addLob(bind, 0) // replaces the first
+	m.addLob(bind, 2)
+	m.addLob(unsafe.Pointer(&m), 0) // not accounted variable
+	if got := m.get().LOBs; got != 2 {
+		t.Errorf("LOBs=%d, wanted 2", got)
+	}
+
+	// re-adding at the same address replaces the stale record
+	m.add(bind, false, 50)
+	if got, want := m.get(), (MemStats{BindBytes: 50, FetchBytes: 30}); got != want {
+		t.Errorf("after re-add: got %v, wanted %v", got, want)
+	}
+
+	m.addLob(bind, 1)
+	m.remove(bind)
+	m.remove(bind)
+	m.remove(fetch)
+	if got := m.get(); got != (MemStats{}) {
+		t.Errorf("after remove: got %v, wanted zero", got)
+	}
+	if len(m.vars) != 0 {
+		t.Errorf("%d records left", len(m.vars))
+	}
+}
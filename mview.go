@@ -0,0 +1,103 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MViewRefreshMethod is the refresh method of DBMS_MVIEW.REFRESH.
+type MViewRefreshMethod byte
+
+const (
+	// MViewRefreshDefault uses the default method of the materialized view.
+	MViewRefreshDefault = MViewRefreshMethod(0)
+	// MViewRefreshComplete recomputes the whole materialized view.
+	MViewRefreshComplete = MViewRefreshMethod('C')
+	// MViewRefreshFast applies the changes from the materialized view logs.
+	MViewRefreshFast = MViewRefreshMethod('F')
+	// MViewRefreshForce is fast if possible, complete otherwise.
+	MViewRefreshForce = MViewRefreshMethod('?')
+	// MViewRefreshPartition uses Partition Change Tracking.
+	MViewRefreshPartition = MViewRefreshMethod('P')
+)
+
+// MViewRefreshOptions are the options of the materialized view refresh.
+type MViewRefreshOptions struct {
+	Method MViewRefreshMethod
+	// Parallelism is the degree of parallelism of the refresh.
+	Parallelism int
+	// NonAtomic allows truncating the materialized views before the refresh (faster, but the views are empty meanwhile),
+	// and commits after each view when more are refreshed together.
+	NonAtomic bool
+	// OutOfPlace builds the new data in an outside table, then switches (12c+).
+	OutOfPlace bool
+}
+
+// MViewRefreshResult is the result of a materialized view refresh.
+type MViewRefreshResult struct {
+	Err     error
+	Name    string
+	Elapsed time.Duration
+}
+
+// RefreshMViews refreshes the materialized views with one DBMS_MVIEW.REFRESH call -
+// atomically (all or nothing), unless opts.NonAtomic.
+func RefreshMViews(ctx context.Context, ex Execer, names []string, opts MViewRefreshOptions) error {
+	if len(names) == 0 {
+		return nil
+	}
+	qry, args := opts.plsql(names)
+	if _, err := ex.ExecContext(ctx, qry, args...); err != nil {
+		return fmt.Errorf("%s: %w", qry, err)
+	}
+	return nil
+}
+
+// RefreshMViewsEach refreshes the materialized views one-by-one, in the given order,
+// and returns the result of each: an error does not stop the refresh of the next one,
+// but a cancelled context does.
+func RefreshMViewsEach(ctx context.Context, ex Execer, names []string, opts MViewRefreshOptions) []MViewRefreshResult {
+	results := make([]MViewRefreshResult, len(names))
+	for i, name := range names {
+		results[i].Name = name
+		if err := ctx.Err(); err != nil {
+			results[i].Err = err
+			continue
+		}
+		start := time.Now()
+		results[i].Err = RefreshMViews(ctx, ex, names[i:i+1], opts)
+		results[i].Elapsed = time.Since(start)
+	}
+	return results
+}
+
+// plsql returns the DBMS_MVIEW.REFRESH call and its arguments.
+func (opts MViewRefreshOptions) plsql(names []string) (string, []interface{}) {
+	var buf strings.Builder
+	buf.WriteString("BEGIN DBMS_MVIEW.REFRESH(list=>:list")
+	args := []interface{}{sql.Named("list", strings.Join(names, ","))}
+	if opts.Method != MViewRefreshDefault {
+		buf.WriteString(", method=>:method")
+		args = append(args, sql.Named("method", strings.Repeat(string(rune(opts.Method)), len(names))))
+	}
+	if opts.Parallelism > 0 {
+		buf.WriteString(", parallelism=>:parallelism")
+		args = append(args, sql.Named("parallelism", opts.Parallelism))
+	}
+	if opts.NonAtomic {
+		buf.WriteString(", atomic_refresh=>FALSE")
+	}
+	if opts.OutOfPlace {
+		buf.WriteString(", out_of_place=>TRUE")
+	}
+	buf.WriteString("); END;")
+	return buf.String(), args
+}
@@ -0,0 +1,31 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestMViewRefreshPLSQL(t *testing.T) {
+	qry, args := MViewRefreshOptions{}.plsql([]string{"MV1"})
+	if want := "BEGIN DBMS_MVIEW.REFRESH(list=>:list); END;"; qry != want || len(args) != 1 {
+		t.Errorf("got %q %v, wanted %q", qry, args, want)
+	}
+	qry, args = MViewRefreshOptions{Method: MViewRefreshFast, NonAtomic: true}.plsql([]string{"MV1", "S.MV2"})
+	if want := "BEGIN DBMS_MVIEW.REFRESH(list=>:list, method=>:method, atomic_refresh=>FALSE); END;"; qry != want {
+		t.Errorf("got %q, wanted %q", qry, want)
+	}
+	if len(args) != 2 {
+		t.Fatalf("got %d args, wanted 2", len(args))
+	}
+	if list := args[0].(sql.NamedArg).Value; list != "MV1,S.MV2" {
+		t.Errorf("list: got %q", list)
+	}
+	if method := args[1].(sql.NamedArg).Value; method != "FF" {
+		t.Errorf("method: got %q", method)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// QueryNested executes qry with args, and appends its rows to dest (a pointer to a slice of structs),
+// mapping the columns to the fields by their `godror` tag (see InsertStruct).
+//
+// A CURSOR(...) column is mapped into a slice of structs field, recursively,
+// so a master-detail structure can be loaded in one round-trip:
+//
+//	type Dept struct {
+//		Name  string `godror:"DNAME"`
+//		Emps  []Emp  `godror:"EMPS"`
+//	}
+//	var depts []Dept
+//	err := QueryNested(ctx, db, &depts, `SELECT D.dname,
+//	    CURSOR(SELECT E.ename, E.sal FROM emp E WHERE E.deptno = D.deptno) AS emps
+//	  FROM dept D`)
+//
+// The columns without a matching field are skipped (the unmatched cursors closed).
+//
+// The nested cursors belong to the session of the main query, so for a *sql.DB
+// one connection is checked out for the whole run.
+func QueryNested(ctx context.Context, q Querier, dest interface{}, qry string, args ...interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("%T is not a pointer to a slice of structs", dest)
+	}
+	if db, ok := q.(*sql.DB); ok {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		q = conn
+	}
+	rows, err := q.QueryContext(ctx, qry, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", sqlForLog(qry), err)
+	}
+	defer rows.Close()
+	if err = scanNested(ctx, q, rows, rv.Elem()); err != nil {
+		return fmt.Errorf("%s: %w", sqlForLog(qry), err)
+	}
+	return nil
+}
+
+// scanNested appends the rows to slice, scanning the nested cursors into the slice fields.
+func scanNested(ctx context.Context, q Querier, rows *sql.Rows, slice reflect.Value) error {
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	fields, err := structFields(elemType)
+	if err != nil {
+		return err
+	}
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	colFields := make([]*structField, len(cols))
+	for i, col := range cols {
+		for j, f := range fields {
+			if strings.EqualFold(f.Column, col) {
+				colFields[i] = &fields[j]
+				break
+			}
+		}
+	}
+
+	dests := make([]interface{}, len(cols))
+	skipped := make([]interface{}, len(cols))
+	cursors := make([]driver.Rows, len(cols))
+	for rows.Next() {
+		row := reflect.New(elemType).Elem()
+		for i, f := range colFields {
+			cursors[i], skipped[i] = nil, nil
+			switch {
+			case f == nil:
+				dests[i] = &skipped[i]
+			case isNestedField(row.Field(f.Index).Type()):
+				dests[i] = &cursors[i]
+			default:
+				dests[i] = row.Field(f.Index).Addr().Interface()
+			}
+		}
+		err = rows.Scan(dests...)
+		for _, v := range skipped {
+			if dr, ok := v.(driver.Rows); ok {
+				dr.Close()
+			}
+		}
+		if err != nil {
+			closeCursors(cursors)
+			return err
+		}
+		for i, dr := range cursors {
+			if dr == nil {
+				continue
+			}
+			if err = scanCursor(ctx, q, dr, row.Field(colFields[i].Index)); err != nil {
+				closeCursors(cursors[i:])
+				return fmt.Errorf("%s: %w", cols[i], err)
+			}
+		}
+		if isPtr {
+			row = row.Addr()
+		}
+		slice.Set(reflect.Append(slice, row))
+	}
+	return rows.Err()
+}
+
+// scanCursor scans the nested cursor into the slice field, and closes it.
+func scanCursor(ctx context.Context, q Querier, dr driver.Rows, field reflect.Value) error {
+	defer dr.Close()
+	sub, err := WrapRows(ctx, q, dr)
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+	return scanNested(ctx, q, sub, field)
+}
+
+// isNestedField reports whether a field of type typ receives a nested cursor: []T or []*T, T being a struct.
+func isNestedField(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Slice {
+		return false
+	}
+	elem := typ.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	return elem.Kind() == reflect.Struct
+}
+
+func closeCursors(cursors []driver.Rows) {
+	for _, dr := range cursors {
+		if dr != nil {
+			dr.Close()
+		}
+	}
+}
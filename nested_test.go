@@ -0,0 +1,44 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIsNestedField(t *testing.T) {
+	type child struct{ A int }
+	for _, tC := range []struct {
+		V    interface{}
+		Want bool
+	}{
+		{V: []child{}, Want: true},
+		{V: []*child{}, Want: true},
+		{V: child{}},
+		{V: []byte{}},
+		{V: []string{}},
+		{V: time.Time{}},
+	} {
+		if got := isNestedField(reflect.TypeOf(tC.V)); got != tC.Want {
+			t.Errorf("%T: got %t, wanted %t", tC.V, got, tC.Want)
+		}
+	}
+}
+
+func TestQueryNestedDest(t *testing.T) {
+	type parent struct{ A int }
+	var p parent
+	var ps []parent
+	// the destination is checked before querying, so the nil Querier is not used
+	for _, dest := range []interface{}{nil, p, &p, ps} {
+		if err := QueryNested(context.Background(), nil, dest, "SELECT 1 FROM DUAL"); err == nil {
+			t.Errorf("%T: wanted error", dest)
+		}
+	}
+}
@@ -7,6 +7,9 @@
 package godror
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"math/big"
 	"strings"
 )
@@ -84,9 +87,12 @@ func (N *Number) Compose(form byte, negative bool, coefficient []byte, exponent
 	return nil
 }
 
-var _ = decimal((*Number)(nil))
+var _ = Decimal((*Number)(nil))
 
-// decimal composes or decomposes a decimal value to and from individual parts.
+// Decimal composes or decomposes a decimal value to and from individual parts.
+// NUMBER columns can be scanned into any Decimal (such as github.com/cockroachdb/apd.Decimal)
+// without loss of precision, as database/sql calls its Compose with the decomposed Number.
+//
 // There are four parts: a boolean negative flag, a form byte with three possible states
 // (finite=0, infinite=1, NaN=2), a base-2 big-endian integer
 // coefficient (also known as a significand) as a []byte, and an int32 exponent.
@@ -107,7 +113,7 @@ var _ = decimal((*Number)(nil))
 // are supported.
 //
 // NOTE(kardianos): This is an experimental interface. See https://golang.org/issue/30870
-type decimal interface {
+type Decimal interface {
 	decimalDecompose
 	decimalCompose
 }
@@ -124,3 +130,75 @@ type decimalCompose interface {
 	// represented then an error should be returned.
 	Compose(form byte, negative bool, coefficient []byte, exponent int32) error
 }
+
+// BigRat returns the Number as a *big.Rat, without loss of precision.
+func (N Number) BigRat() (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(string(N))
+	if !ok {
+		return nil, fmt.Errorf("%q: %w", string(N), errNotNumber)
+	}
+	return r, nil
+}
+
+// BigInt returns the Number as a *big.Int, or an error if it is not an integer.
+func (N Number) BigInt() (*big.Int, error) {
+	var i big.Int
+	if _, ok := i.SetString(string(N), 10); ok {
+		return &i, nil
+	}
+	r, err := N.BigRat()
+	if err != nil {
+		return nil, err
+	}
+	if !r.IsInt() {
+		return nil, fmt.Errorf("%q: %w", string(N), errNotInteger)
+	}
+	return i.Set(r.Num()), nil
+}
+
+var (
+	errNotNumber  = errors.New("not a number")
+	errNotInteger = errors.New("not an integer")
+)
+
+// NumberScanner returns an sql.Scanner which scans a NUMBER into dest, without loss of precision:
+// dest can be a *big.Int, a *big.Rat, a *Number or a Decimal.
+//
+//	var i big.Int
+//	err := db.QueryRowContext(ctx, "SELECT POWER(2, 100) FROM DUAL").Scan(godror.NumberScanner(&i))
+//
+// A NULL leaves dest untouched.
+func NumberScanner(dest interface{}) sql.Scanner { return numberScanner{dest: dest} }
+
+type numberScanner struct{ dest interface{} }
+
+func (ns numberScanner) Scan(v interface{}) error {
+	if v == nil {
+		return nil
+	}
+	var N Number
+	if err := N.Scan(v); err != nil {
+		return err
+	}
+	switch x := ns.dest.(type) {
+	case *big.Int:
+		i, err := N.BigInt()
+		if err != nil {
+			return err
+		}
+		x.Set(i)
+	case *big.Rat:
+		r, err := N.BigRat()
+		if err != nil {
+			return err
+		}
+		x.Set(r)
+	case *Number:
+		*x = N
+	case decimalCompose:
+		return x.Compose(N.Decompose(nil))
+	default:
+		return fmt.Errorf("%T: %w", ns.dest, errUnknownType)
+	}
+	return nil
+}
@@ -6,6 +6,7 @@
 package godror_test
 
 import (
+	"math/big"
 	"testing"
 
 	godror "github.com/godror/godror"
@@ -42,3 +43,34 @@ func TestNumberDeCompose(t *testing.T) {
 		}
 	}
 }
+
+func TestNumberScanner(t *testing.T) {
+	const s38 = "12345678901234567890123456789012345678"
+	var i big.Int
+	if err := godror.NumberScanner(&i).Scan(s38); err != nil {
+		t.Fatal(err)
+	} else if got := i.String(); got != s38 {
+		t.Errorf("big.Int: got %q, wanted %q", got, s38)
+	}
+	if err := godror.NumberScanner(&i).Scan(godror.Number("3.14")); err == nil {
+		t.Errorf("big.Int: wanted error for 3.14, got %s", &i)
+	}
+	if err := godror.NumberScanner(&i).Scan(godror.Number("1.000")); err != nil || i.Int64() != 1 {
+		t.Errorf("big.Int: got %s, %+v for 1.000", &i, err)
+	}
+
+	var r big.Rat
+	if err := godror.NumberScanner(&r).Scan(godror.Number("-0.0000000001")); err != nil {
+		t.Fatal(err)
+	} else if want := big.NewRat(-1, 10000000000); r.Cmp(want) != 0 {
+		t.Errorf("big.Rat: got %s, wanted %s", &r, want)
+	}
+	if err := godror.NumberScanner(&r).Scan(nil); err != nil || r.Sign() >= 0 {
+		t.Errorf("NULL: got %s, %+v", &r, err)
+	}
+
+	var f float64
+	if err := godror.NumberScanner(&f).Scan("1"); err == nil {
+		t.Error("float64: wanted error")
+	}
+}
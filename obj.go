@@ -195,6 +195,18 @@ func (O *Object) Collection() ObjectCollection {
 	return ObjectCollection{Object: O}
 }
 
+// Copy returns a copy of the object (or collection), which has to be closed separately.
+func (O *Object) Copy() (*Object, error) {
+	if O == nil || O.dpiObject == nil {
+		return nil, errors.New("object is nil")
+	}
+	var obj *C.dpiObject
+	if err := O.drv.checkExec(func() C.int { return C.dpiObject_copy(O.dpiObject, &obj) }); err != nil {
+		return nil, fmt.Errorf("copy %s: %w", O.ObjectType.FullName(), err)
+	}
+	return &Object{ObjectType: O.ObjectType, dpiObject: obj}, nil
+}
+
 // Close releases a reference to the object.
 func (O *Object) Close() error {
 	if O == nil {
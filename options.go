@@ -0,0 +1,51 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import "context"
+
+// Options returns an Option applying all the given options in order,
+// so a set of options can be composed, stored and passed as one.
+//
+// Use it "naked", without sql.Named!
+func Options(opts ...Option) Option {
+	return func(o *stmtOptions) {
+		for _, opt := range opts {
+			if opt != nil {
+				opt(o)
+			}
+		}
+	}
+}
+
+type optionsCtxKey struct{}
+
+// ContextWithOptions returns a context with the given options appended to the ones already in ctx,
+// which will be applied to the statements prepared with this context.
+//
+// The precedence is: the connection's defaults (such as fetchArraySize in the DSN),
+// then the context's options, then the options given as arguments of the call.
+//
+//	ctx = godror.ContextWithOptions(ctx, godror.FetchArraySize(1000), godror.CallTimeout(time.Minute))
+//	rows, err := db.QueryContext(ctx, qry, godror.LobAsReader())
+func ContextWithOptions(ctx context.Context, opts ...Option) context.Context {
+	if len(opts) == 0 {
+		return ctx
+	}
+	prev := ctxOptions(ctx)
+	all := make([]Option, 0, len(prev)+len(opts))
+	all = append(append(all, prev...), opts...)
+	return context.WithValue(ctx, optionsCtxKey{}, all)
+}
+
+// ctxOptions returns the options set with ContextWithOptions.
+func ctxOptions(ctx context.Context) []Option {
+	if ctx == nil {
+		return nil
+	}
+	opts, _ := ctx.Value(optionsCtxKey{}).([]Option)
+	return opts
+}
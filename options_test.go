@@ -0,0 +1,99 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+func TestLobOptions(t *testing.T) {
+	var o stmtOptions
+	if !o.ClobAsString() || o.LobAsReader() {
+		t.Error("LOBs should be fetched as string/[]byte by default")
+	}
+	LobAsReader()(&o)
+	if o.ClobAsString() || !o.LobAsReader() {
+		t.Error("LobAsReader should return LOBs as Lob")
+	}
+	ClobAsString()(&o)
+	if !o.ClobAsString() || o.LobAsReader() {
+		t.Error("ClobAsString should reset LobAsReader")
+	}
+}
+
+func TestContextWithOptions(t *testing.T) {
+	ctx := context.Background()
+	if opts := ctxOptions(ctx); len(opts) != 0 {
+		t.Errorf("got %d options from an empty context", len(opts))
+	}
+	ctx = ContextWithOptions(ctx, FetchArraySize(10), LobAsReader())
+	ctx2 := ContextWithOptions(ctx, FetchArraySize(20), CallTimeout(time.Second))
+	if n := len(ctxOptions(ctx)); n != 2 {
+		t.Errorf("parent context got %d options, wanted 2", n)
+	}
+
+	var o stmtOptions
+	Options(ctxOptions(ctx2)...)(&o)
+	if o.FetchArraySize() != 20 || !o.LobAsReader() || o.callTimeout != time.Second {
+		t.Errorf("got %+v, wanted the later FetchArraySize, LobAsReader and CallTimeout", o)
+	}
+	// the call's options override the context's
+	Options(nil, FetchArraySize(30), PlSQLArrays)(&o)
+	if o.FetchArraySize() != 30 || !o.PlSQLArrays() {
+		t.Errorf("got %+v, wanted FetchArraySize=30 and PlSQLArrays", o)
+	}
+}
+
+func TestPlSQLArraysInference(t *testing.T) {
+	var ss []string
+	var b []byte
+	var n int
+	for name, tC := range map[string]struct {
+		Args []driver.NamedValue
+		Want bool
+	}{
+		"slice":     {Args: []driver.NamedValue{{Value: 1}, {Value: sql.Out{Dest: &ss}}}, Want: true},
+		"bytes":     {Args: []driver.NamedValue{{Value: sql.Out{Dest: &b}}}},
+		"scalar":    {Args: []driver.NamedValue{{Value: sql.Out{Dest: &n}}, {Value: []string{"a"}}}},
+		"in-slice":  {Args: []driver.NamedValue{{Value: []string{"a"}}}},
+		"nil-out":   {Args: []driver.NamedValue{{Value: sql.Out{}}}},
+		"named-out": {Args: []driver.NamedValue{{Name: "x", Value: sql.Out{Dest: &ss, In: true}}}, Want: true},
+	} {
+		if got := hasSliceOut(tC.Args); got != tC.Want {
+			t.Errorf("%s: got %t, wanted %t", name, got, tC.Want)
+		}
+	}
+
+	o := stmtOptions{inferPlSQLArrays: true}
+	if !o.PlSQLArrays() {
+		t.Error("inferred PlSQLArrays is not effective")
+	}
+	NoPlSQLArrays(&o)
+	if o.PlSQLArrays() {
+		t.Error("NoPlSQLArrays does not override the inference")
+	}
+	PlSQLArrays(&o)
+	if !o.PlSQLArrays() {
+		t.Error("PlSQLArrays does not override NoPlSQLArrays")
+	}
+}
+
+func TestFetchNumberAsStringOption(t *testing.T) {
+	var o stmtOptions
+	NumberAsString()(&o)
+	if !o.NumberAsString() || o.FetchNumberAsString() {
+		t.Errorf("NumberAsString must not return the integers as string")
+	}
+	o = stmtOptions{}
+	FetchNumberAsString()(&o)
+	if !o.NumberAsString() || !o.FetchNumberAsString() {
+		t.Errorf("FetchNumberAsString must imply NumberAsString")
+	}
+}
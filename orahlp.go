@@ -16,6 +16,7 @@ import (
 	"io"
 	"math"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -256,7 +257,7 @@ func (n *Number) UnmarshalJSON(p []byte) error {
 
 // QueryColumn is the described column.
 type QueryColumn struct {
-	Name                           string
+	Name, DatabaseType             string
 	Type, Length, Precision, Scale int
 	Nullable                       bool
 	//Schema string
@@ -296,12 +297,13 @@ func DescribeQuery(ctx context.Context, db Execer, qry string) ([]QueryColumn, e
 		cols = make([]QueryColumn, len(r.columns))
 		for i, col := range r.columns {
 			cols[i] = QueryColumn{
-				Name:      col.Name,
-				Type:      int(col.OracleType),
-				Length:    int(col.Size),
-				Precision: int(col.Precision),
-				Scale:     int(col.Scale),
-				Nullable:  col.Nullable,
+				Name:         col.Name,
+				DatabaseType: r.ColumnTypeDatabaseTypeName(i),
+				Type:         int(col.OracleType),
+				Length:       int(col.Size),
+				Precision:    int(col.Precision),
+				Scale:        int(col.Scale),
+				Nullable:     col.Nullable,
 			}
 		}
 		return nil
@@ -330,10 +332,25 @@ func (ce CompileError) Error() string {
 //
 // If all is false, only errors are returned; otherwise, warnings, too.
 func GetCompileErrors(ctx context.Context, queryer Querier, all bool) ([]CompileError, error) {
-	rows, err := queryer.QueryContext(ctx, `
+	return getCompileErrors(ctx, queryer, "", all)
+}
+
+// getCompileErrors returns the errors in user_errors, or all_errors of owner, if it's not empty.
+func getCompileErrors(ctx context.Context, queryer Querier, owner string, all bool) ([]CompileError, error) {
+	qry := `
 	SELECT USER owner, name, type, line, position, message_number, text, attribute
 		FROM user_errors
-		ORDER BY name, sequence`)
+		ORDER BY name, sequence`
+	var args []interface{}
+	if owner != "" {
+		qry = `
+	SELECT owner, name, type, line, position, message_number, text, attribute
+		FROM all_errors
+		WHERE owner = :1
+		ORDER BY name, sequence`
+		args = append(args, owner)
+	}
+	rows, err := queryer.QueryContext(ctx, qry, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -353,6 +370,84 @@ func GetCompileErrors(ctx context.Context, queryer Querier, all bool) ([]Compile
 	return errors, rows.Err()
 }
 
+// TableColumn is a column of a table, as in all_tab_cols view.
+type TableColumn struct {
+	Name, DataType string
+	// Default is the DEFAULT expression, or the expression of a virtual column.
+	Default string
+	// IdentityGeneration is "ALWAYS" or "BY DEFAULT" for identity columns.
+	IdentityGeneration, IdentityOptions        string
+	Nullable, DefaultOnNull, Virtual, Identity bool
+}
+
+// Insertable reports whether the column can be included in an INSERT:
+// virtual and GENERATED ALWAYS identity columns cannot.
+func (tc TableColumn) Insertable() bool {
+	return !tc.Virtual && !(tc.Identity && tc.IdentityGeneration == "ALWAYS")
+}
+
+// GetTableColumns returns the (not hidden) columns of the table, with their defaults and
+// identity and virtual column properties. The owner defaults to the current schema.
+//
+// Needs Oracle Database 12c or later.
+func GetTableColumns(ctx context.Context, queryer Querier, owner, table string) ([]TableColumn, error) {
+	const qry = `SELECT c.column_name, c.data_type, c.nullable, c.data_default,
+		c.default_on_null, c.virtual_column, c.identity_column,
+		i.generation_type, i.identity_options
+	FROM all_tab_cols c
+	LEFT OUTER JOIN all_tab_identity_cols i ON
+		i.owner = c.owner AND i.table_name = c.table_name AND i.column_name = c.column_name
+	WHERE c.owner = NVL(:1, SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA')) AND c.table_name = :2 AND
+		c.hidden_column = 'NO'
+	ORDER BY c.column_id`
+	rows, err := queryer.QueryContext(ctx, qry, owner, table)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	var cols []TableColumn
+	for rows.Next() {
+		var tc TableColumn
+		var nullable, defOnNull, virtual, identity string
+		var def, gen, opts sql.NullString
+		if err = rows.Scan(&tc.Name, &tc.DataType, &nullable, &def,
+			&defOnNull, &virtual, &identity, &gen, &opts,
+		); err != nil {
+			return cols, err
+		}
+		tc.Default = strings.TrimSpace(def.String)
+		tc.IdentityGeneration, tc.IdentityOptions = gen.String, opts.String
+		tc.Nullable, tc.DefaultOnNull = nullable == "Y", defOnNull == "YES"
+		tc.Virtual, tc.Identity = virtual == "YES", identity == "YES"
+		cols = append(cols, tc)
+	}
+	return cols, rows.Err()
+}
+
+// GetColumnCollations returns the collations (such as "USING_NLS_COMP" or "BINARY_CI") of the
+// character columns of the table, by column name. The owner defaults to the current schema.
+//
+// Needs Oracle Database 12.2 or later.
+func GetColumnCollations(ctx context.Context, queryer Querier, owner, table string) (map[string]string, error) {
+	const qry = `SELECT column_name, collation FROM all_tab_cols
+	WHERE owner = NVL(:1, SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA')) AND table_name = :2 AND
+		hidden_column = 'NO' AND collation IS NOT NULL`
+	rows, err := queryer.QueryContext(ctx, qry, owner, table)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	collations := make(map[string]string)
+	for rows.Next() {
+		var col, collation string
+		if err = rows.Scan(&col, &collation); err != nil {
+			return collations, err
+		}
+		collations[col] = collation
+	}
+	return collations, rows.Err()
+}
+
 type preparer interface {
 	PrepareContext(ctx context.Context, qry string) (*sql.Stmt, error)
 }
@@ -539,6 +634,11 @@ type Conn interface {
 
 	Timezone() *time.Location
 	GetPoolStats() (PoolStats, error)
+	LTXID() ([]byte, error)
+	StmtCacheSize() (int, error)
+	SetStmtCacheSize(int) error
+	Tag() string
+	SetTag(string)
 }
 
 // WrapRows transforms a driver.Rows into an *sql.Rows.
@@ -546,6 +646,19 @@ func WrapRows(ctx context.Context, q Querier, rset driver.Rows) (*sql.Rows, erro
 	return q.QueryContext(ctx, wrapResultset, rset)
 }
 
+// GetPoolStats returns the statistics of the session pool behind ex (*sql.DB, *sql.Conn or *sql.Tx).
+//
+// For a *sql.DB, the session used for asking the stats is counted as busy.
+// Returns zero PoolStats for standalone connections.
+func GetPoolStats(ctx context.Context, ex Execer) (stats PoolStats, err error) {
+	err = Raw(ctx, ex, func(c Conn) error {
+		var gErr error
+		stats, gErr = c.GetPoolStats()
+		return gErr
+	})
+	return stats, err
+}
+
 // Timezone returns the timezone of the connection (database).
 func Timezone(ctx context.Context, ex Execer) (loc *time.Location, err error) {
 	err = Raw(ctx, ex, func(c Conn) error { loc = c.Timezone(); return nil })
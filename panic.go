@@ -0,0 +1,68 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+)
+
+// ErrPanic is the error PanicError unwraps to.
+var ErrPanic = errors.New("recovered panic")
+
+// PanicError is returned instead of crashing, when a panic happens in the driver
+// with the recoverPanics connection parameter set.
+//
+// Only Go panics can be recovered (such as a nil pointer dereference while converting data),
+// a crash in the C libraries still kills the process.
+// The connection is discarded by database/sql.
+type PanicError struct {
+	// Value is the recovered value.
+	Value interface{}
+	// Op is the operation that panicked, such as "Exec", Query the statement text.
+	Op, Query string
+	Stack     string
+}
+
+func (pe *PanicError) Error() string {
+	return fmt.Sprintf("%s in %s of %q: %v\n%s", ErrPanic, pe.Op, pe.Query, pe.Value, pe.Stack)
+}
+func (pe *PanicError) Unwrap() error { return ErrPanic }
+
+// standalonePanics counts the recovered panics of the non-pooled connections.
+var standalonePanics uint64
+
+// GetStandalonePanics returns the number of panics recovered in non-pooled connections.
+// For pools, see PoolStats.Panics.
+func GetStandalonePanics() uint64 { return atomic.LoadUint64(&standalonePanics) }
+
+// recoverPanic recovers the panic into *errp, counts it, and marks the connection as unusable.
+//
+// Must be deferred directly, and only if c.params.RecoverPanics is set.
+func (c *conn) recoverPanic(op, query string, errp *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	var a [4096]byte
+	pe := &PanicError{Value: r, Op: op, Query: sqlForLog(query), Stack: string(a[:runtime.Stack(a[:], false)])}
+	*errp = pe
+	atomic.StoreUint32(&c.panicked, 1)
+	counter := &standalonePanics
+	if c.poolKey != "" && c.drv != nil {
+		c.drv.mu.RLock()
+		if pool := c.drv.pools[c.poolKey]; pool != nil {
+			counter = &pool.panics
+		}
+		c.drv.mu.RUnlock()
+	}
+	atomic.AddUint64(counter, 1)
+	if logger := getLogger(); logger != nil {
+		logger.Log("msg", "recovered panic", "op", op, "query", pe.Query, "panic", r, "stack", pe.Stack)
+	}
+}
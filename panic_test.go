@@ -0,0 +1,36 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRecoverPanic(t *testing.T) {
+	var c conn
+	before := GetStandalonePanics()
+	err := func() (err error) {
+		defer c.recoverPanic("Exec", "SELECT 1 FROM DUAL", &err)
+		var m map[string]int
+		m["a"] = 1
+		return nil
+	}()
+	var pe *PanicError
+	if !errors.As(err, &pe) || !errors.Is(err, ErrPanic) {
+		t.Fatalf("wanted PanicError, got %+v", err)
+	}
+	if pe.Op != "Exec" || pe.Query != "SELECT 1 FROM DUAL" || pe.Stack == "" {
+		t.Errorf("got %+v", pe)
+	}
+	if atomic.LoadUint32(&c.panicked) == 0 {
+		t.Error("conn is not marked as panicked")
+	}
+	if after := GetStandalonePanics(); after != before+1 {
+		t.Errorf("panic counter: got %d, wanted %d", after, before+1)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ParallelOptions are the options of WithParallelDML.
+type ParallelOptions struct {
+	// Degree forces this degree of parallelism for DML and queries (FORCE PARALLEL DML PARALLEL n),
+	// if 0, parallel DML is just enabled, and the degree comes from the hints or table definitions.
+	Degree int
+	// TxOptions of the transaction.
+	TxOptions *sql.TxOptions
+}
+
+// WithParallelDML enables parallel DML for the session, runs fn in a new transaction,
+// commits it (or rolls back if fn returns an error), and restores the default
+// (parallel DML disabled, parallel query enabled).
+//
+// Parallel DML must be enabled before the transaction starts (or ORA-12841),
+// and a table modified by a parallel DML cannot be read or modified in the same transaction (ORA-12838),
+// so fn should execute only the bulk statements.
+//
+// If restoring the default fails, the connection is discarded.
+func WithParallelDML(ctx context.Context, conn *sql.Conn, opts ParallelOptions, fn func(context.Context, *sql.Tx) error) (err error) {
+	qrys := []string{"ALTER SESSION ENABLE PARALLEL DML"}
+	if opts.Degree > 0 {
+		n := strconv.Itoa(opts.Degree)
+		qrys = []string{
+			"ALTER SESSION FORCE PARALLEL DML PARALLEL " + n,
+			"ALTER SESSION FORCE PARALLEL QUERY PARALLEL " + n,
+		}
+	}
+	defer func() {
+		// restore even if ctx is cancelled
+		rctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		for _, qry := range []string{"ALTER SESSION DISABLE PARALLEL DML", "ALTER SESSION ENABLE PARALLEL QUERY"} {
+			if _, rErr := conn.ExecContext(rctx, qry); rErr != nil {
+				_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+				if err == nil {
+					err = fmt.Errorf("%s: %w", qry, rErr)
+				}
+				return
+			}
+		}
+	}()
+	for _, qry := range qrys {
+		if _, err = conn.ExecContext(ctx, qry); err != nil {
+			return fmt.Errorf("%s: %w", qry, err)
+		}
+	}
+
+	tx, err := conn.BeginTx(ctx, opts.TxOptions)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err = fn(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
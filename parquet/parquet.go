@@ -0,0 +1,560 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+// Package parquet writes the results exported by godror.ExportRows into a Parquet file,
+// without external dependencies.
+//
+// The file is written uncompressed, with PLAIN encoding and one data page per column chunk
+// (RowGroupSize rows are buffered in memory), which every Parquet reader understands.
+//
+// The logical types are mapped as
+//
+//	STRING        BYTE_ARRAY (STRING)
+//	BINARY        BYTE_ARRAY
+//	INT64         INT64
+//	DOUBLE        DOUBLE
+//	DECIMAL       INT64 (DECIMAL) up to 18 digits, FIXED_LEN_BYTE_ARRAY (DECIMAL) above;
+//	              BYTE_ARRAY (STRING) for NUMBER without precision, as it has no fixed scale
+//	DATE          INT64 (TIMESTAMP(MICROS, isAdjustedToUTC=false)), as the DATE has a time part
+//	TIMESTAMP     INT64 (TIMESTAMP(MICROS, isAdjustedToUTC=false)), truncated to microseconds
+//	TIMESTAMP_TZ  INT64 (TIMESTAMP(MICROS, isAdjustedToUTC=true)), truncated to microseconds
+//	BOOL          BOOLEAN
+//
+// Usage:
+//
+//	rows, err := db.QueryContext(ctx, qry, godror.FetchArraySize(1024))
+//	...
+//	pw := parquet.NewWriter(f)
+//	if _, err = godror.ExportRows(ctx, rows, pw, 1024); err != nil {
+//		return err
+//	}
+//	return pw.Close()
+package parquet
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"time"
+
+	godror "github.com/godror/godror"
+)
+
+// DefaultRowGroupSize is the default number of rows in a row group.
+const DefaultRowGroupSize = 64 * 1024
+
+var _ godror.ColumnarWriter = (*Writer)(nil)
+
+// Writer is a godror.ColumnarWriter writing a Parquet file.
+//
+// Close must be called after the last WriteColumns, to write the file footer.
+type Writer struct {
+	w io.Writer
+	// RowGroupSize is the number of rows buffered and written as a row group - DefaultRowGroupSize by default.
+	RowGroupSize int
+	// CreatedBy is recorded in the file metadata.
+	CreatedBy string
+
+	columns   []column
+	rowGroups []rowGroup
+	offset    int64
+	numRows   int64
+	rows      int
+	err       error
+}
+
+// NewWriter returns a Writer writing to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w, RowGroupSize: DefaultRowGroupSize, CreatedBy: "godror parquet"}
+}
+
+// The Parquet enums used.
+const (
+	typeBoolean           = 0
+	typeInt64             = 2
+	typeDouble            = 5
+	typeByteArray         = 6
+	typeFixedLenByteArray = 7
+
+	repetitionRequired = 0
+	repetitionOptional = 1
+
+	convertedUTF8            = 0
+	convertedDecimal         = 5
+	convertedTimestampMicros = 10
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	codecUncompressed = 0
+	pageTypeData      = 0
+)
+
+// column is the schema and the buffered values of a column of the current row group.
+type column struct {
+	godror.ExportColumn
+	physType   int32
+	typeLength int // of FIXED_LEN_BYTE_ARRAY
+	valid      []bool
+	values     []byte // PLAIN encoded, but BOOLEAN
+	bools      []bool
+	scale      *big.Int // 10^Scale for DECIMAL
+}
+
+type rowGroup struct {
+	chunks   []columnChunk
+	numRows  int64
+	byteSize int64
+}
+
+type columnChunk struct {
+	physType             int32
+	name                 string
+	numValues            int64
+	size, dataPageOffset int64
+}
+
+// WriteSchema writes the file header, and prepares the columns.
+func (w *Writer) WriteSchema(cols []godror.ExportColumn) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.columns != nil {
+		return errors.New("parquet: WriteSchema called twice")
+	}
+	w.columns = make([]column, len(cols))
+	for i, col := range cols {
+		c := column{ExportColumn: col}
+		switch col.Type {
+		case godror.LogicalBinary, godror.LogicalString:
+			c.physType = typeByteArray
+		case godror.LogicalInt64:
+			c.physType = typeInt64
+		case godror.LogicalDouble:
+			c.physType = typeDouble
+		case godror.LogicalDate, godror.LogicalTimestamp, godror.LogicalTimestampTZ:
+			c.physType = typeInt64
+		case godror.LogicalBool:
+			c.physType = typeBoolean
+		case godror.LogicalDecimal:
+			if col.Precision <= 0 {
+				c.physType = typeByteArray
+				break
+			}
+			if col.Scale < 0 || col.Scale > col.Precision || col.Precision > 38 {
+				return fmt.Errorf("parquet: %s: unsupported DECIMAL(%d,%d)", col.Name, col.Precision, col.Scale)
+			}
+			c.scale = new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(col.Scale)), nil)
+			if col.Precision <= 18 {
+				c.physType = typeInt64
+			} else {
+				c.physType, c.typeLength = typeFixedLenByteArray, decimalLength(col.Precision)
+			}
+		default:
+			return fmt.Errorf("parquet: %s: unknown logical type %s", col.Name, col.Type)
+		}
+		w.columns[i] = c
+	}
+	_, err := w.write([]byte("PAR1"))
+	return err
+}
+
+// decimalLength returns the number of bytes needed for the two's complement of a DECIMAL(precision).
+func decimalLength(precision int) int {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(precision)), nil)
+	return (max.BitLen() + 1 + 7) / 8 // plus the sign bit
+}
+
+// WriteColumns buffers the batch, and writes a row group when RowGroupSize rows have been buffered.
+func (w *Writer) WriteColumns(columns []interface{}, rowCount int) error {
+	if w.err != nil {
+		return w.err
+	}
+	if len(columns) != len(w.columns) {
+		return fmt.Errorf("parquet: got %d columns, wanted %d", len(columns), len(w.columns))
+	}
+	rowGroupSize := w.RowGroupSize
+	if rowGroupSize <= 0 {
+		rowGroupSize = DefaultRowGroupSize
+	}
+	for start := 0; start < rowCount; {
+		end := rowCount
+		if n := start + rowGroupSize - w.rows; n < end {
+			end = n
+		}
+		for i := range w.columns {
+			if err := w.columns[i].append(columns[i], start, end); err != nil {
+				w.err = err
+				return err
+			}
+		}
+		w.rows += end - start
+		start = end
+		if w.rows >= rowGroupSize {
+			if err := w.flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// append the [start:end] rows of the column slice.
+func (c *column) append(v interface{}, start, end int) error {
+	badType := func() error {
+		return fmt.Errorf("parquet: %s: got %T for %s", c.Name, v, c.Type)
+	}
+	for i := start; i < end; i++ {
+		var ok bool
+		switch c.Type {
+		case godror.LogicalBinary:
+			x, isType := v.([][]byte)
+			if !isType {
+				return badType()
+			}
+			if ok = x[i] != nil; ok {
+				c.appendBytes(x[i])
+			}
+		case godror.LogicalString:
+			x, isType := v.([]sql.NullString)
+			if !isType {
+				return badType()
+			}
+			if ok = x[i].Valid; ok {
+				c.appendBytes([]byte(x[i].String))
+			}
+		case godror.LogicalInt64:
+			x, isType := v.([]sql.NullInt64)
+			if !isType {
+				return badType()
+			}
+			if ok = x[i].Valid; ok {
+				c.values = appendUint64(c.values, uint64(x[i].Int64))
+			}
+		case godror.LogicalDouble:
+			x, isType := v.([]sql.NullFloat64)
+			if !isType {
+				return badType()
+			}
+			if ok = x[i].Valid; ok {
+				c.values = appendUint64(c.values, math.Float64bits(x[i].Float64))
+			}
+		case godror.LogicalDate, godror.LogicalTimestamp, godror.LogicalTimestampTZ:
+			x, isType := v.([]sql.NullTime)
+			if !isType {
+				return badType()
+			}
+			if ok = x[i].Valid; ok {
+				t := x[i].Time
+				if c.Type != godror.LogicalTimestampTZ {
+					// the wall clock, as if it were UTC
+					t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+				}
+				c.values = appendUint64(c.values, uint64(t.UnixMicro()))
+			}
+		case godror.LogicalBool:
+			x, isType := v.([]sql.NullBool)
+			if !isType {
+				return badType()
+			}
+			if ok = x[i].Valid; ok {
+				c.bools = append(c.bools, x[i].Bool)
+			}
+		case godror.LogicalDecimal:
+			x, isType := v.([]sql.NullString)
+			if !isType {
+				return badType()
+			}
+			if ok = x[i].Valid; ok {
+				if err := c.appendDecimal(x[i].String); err != nil {
+					return err
+				}
+			}
+		}
+		if !ok && !c.Nullable {
+			return fmt.Errorf("parquet: %s: NULL in a NOT NULL column", c.Name)
+		}
+		c.valid = append(c.valid, ok)
+	}
+	return nil
+}
+
+func (c *column) appendBytes(b []byte) {
+	c.values = appendUint32(c.values, uint32(len(b)))
+	c.values = append(c.values, b...)
+}
+
+// appendDecimal appends the unscaled value of the decimal number s.
+func (c *column) appendDecimal(s string) error {
+	if c.scale == nil { // no precision: as string
+		c.appendBytes([]byte(s))
+		return nil
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return fmt.Errorf("parquet: %s: bad number %q", c.Name, s)
+	}
+	r.Mul(r, new(big.Rat).SetInt(c.scale))
+	if !r.IsInt() {
+		return fmt.Errorf("parquet: %s: %q has more than %d decimals", c.Name, s, c.Scale)
+	}
+	u := r.Num()
+	if c.physType == typeInt64 {
+		if !u.IsInt64() {
+			return fmt.Errorf("parquet: %s: %q overflows DECIMAL(%d,%d)", c.Name, s, c.Precision, c.Scale)
+		}
+		c.values = appendUint64(c.values, uint64(u.Int64()))
+		return nil
+	}
+	// big-endian two's complement
+	b := make([]byte, c.typeLength)
+	if u.Sign() >= 0 {
+		if u.BitLen() >= 8*c.typeLength {
+			return fmt.Errorf("parquet: %s: %q overflows DECIMAL(%d,%d)", c.Name, s, c.Precision, c.Scale)
+		}
+		u.FillBytes(b)
+	} else {
+		// 2^(8*length) + u
+		m := new(big.Int).Lsh(big.NewInt(1), uint(8*c.typeLength))
+		m.Add(m, u)
+		if m.BitLen() < 8*c.typeLength {
+			return fmt.Errorf("parquet: %s: %q overflows DECIMAL(%d,%d)", c.Name, s, c.Precision, c.Scale)
+		}
+		m.FillBytes(b)
+	}
+	c.values = append(c.values, b...)
+	return nil
+}
+
+// page returns the data page of the buffered values: the definition levels (for an optional column),
+// then the PLAIN encoded values.
+func (c *column) page() []byte {
+	var page []byte
+	if c.Nullable {
+		levels := bitPackedLevels(c.valid)
+		page = appendUint32(page, uint32(len(levels)))
+		page = append(page, levels...)
+	}
+	if c.physType == typeBoolean {
+		return append(page, packBits(nil, c.bools)...)
+	}
+	return append(page, c.values...)
+}
+
+// bitPackedLevels encodes the 1 bit definition levels as a bit-packed run of the RLE/bit-packing hybrid encoding.
+func bitPackedLevels(valid []bool) []byte {
+	groups := (len(valid) + 7) / 8
+	var w compactWriter
+	w.uvarint(uint64(groups)<<1 | 1)
+	return packBits(w.buf, valid)
+}
+
+// packBits appends the bits, LSB first.
+func packBits(dst []byte, bits []bool) []byte {
+	for i := 0; i < len(bits); i += 8 {
+		var b byte
+		for j := 0; j < 8 && i+j < len(bits); j++ {
+			if bits[i+j] {
+				b |= 1 << j
+			}
+		}
+		dst = append(dst, b)
+	}
+	return dst
+}
+
+// flush writes the buffered rows as a row group.
+func (w *Writer) flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.rows == 0 {
+		return nil
+	}
+	rg := rowGroup{numRows: int64(w.rows), chunks: make([]columnChunk, len(w.columns))}
+	for i := range w.columns {
+		c := &w.columns[i]
+		page := c.page()
+		var h compactWriter
+		h.i32Field(1, pageTypeData)
+		h.i32Field(2, int32(len(page)))
+		h.i32Field(3, int32(len(page)))
+		h.structField(5, func() {
+			h.i32Field(1, int32(len(c.valid)))
+			h.i32Field(2, encodingPlain)
+			h.i32Field(3, encodingRLE)
+			h.i32Field(4, encodingRLE)
+		})
+		h.buf = append(h.buf, 0) // stop
+
+		chunk := columnChunk{
+			physType: c.physType, name: c.Name,
+			numValues: int64(len(c.valid)), dataPageOffset: w.offset,
+			size: int64(len(h.buf) + len(page)),
+		}
+		if _, err := w.write(h.buf); err != nil {
+			return err
+		}
+		if _, err := w.write(page); err != nil {
+			return err
+		}
+		rg.chunks[i] = chunk
+		rg.byteSize += chunk.size
+		c.valid, c.values, c.bools = c.valid[:0], c.values[:0], c.bools[:0]
+	}
+	w.rowGroups = append(w.rowGroups, rg)
+	w.numRows += rg.numRows
+	w.rows = 0
+	return nil
+}
+
+// Close writes the remaining rows and the file footer. It does not close the underlying io.Writer.
+func (w *Writer) Close() error {
+	if w.columns == nil {
+		if w.err == nil {
+			w.err = errors.New("parquet: Close without WriteSchema")
+		}
+		return w.err
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	meta := w.fileMetaData()
+	meta = appendUint32(meta, uint32(len(meta)))
+	meta = append(meta, "PAR1"...)
+	_, err := w.write(meta)
+	if err == nil {
+		w.err = errors.New("parquet: closed")
+	}
+	return err
+}
+
+// fileMetaData returns the Thrift encoded FileMetaData.
+func (w *Writer) fileMetaData() []byte {
+	var m compactWriter
+	m.i32Field(1, 1) // version
+	m.listField(2, thriftStruct, 1+len(w.columns), func() {
+		m.structElem(func() {
+			m.stringField(4, "schema")
+			m.i32Field(5, int32(len(w.columns)))
+		})
+		for i := range w.columns {
+			c := &w.columns[i]
+			m.structElem(func() { c.schemaElement(&m) })
+		}
+	})
+	m.i64Field(3, w.numRows)
+	m.listField(4, thriftStruct, len(w.rowGroups), func() {
+		for _, rg := range w.rowGroups {
+			m.structElem(func() {
+				m.listField(1, thriftStruct, len(rg.chunks), func() {
+					for _, chunk := range rg.chunks {
+						m.structElem(func() { chunk.write(&m) })
+					}
+				})
+				m.i64Field(2, rg.byteSize)
+				m.i64Field(3, rg.numRows)
+			})
+		}
+	})
+	if w.CreatedBy != "" {
+		m.stringField(6, w.CreatedBy)
+	}
+	m.buf = append(m.buf, 0) // stop
+	return m.buf
+}
+
+// schemaElement writes the fields of the column's SchemaElement.
+func (c *column) schemaElement(m *compactWriter) {
+	m.i32Field(1, c.physType)
+	if c.typeLength != 0 {
+		m.i32Field(2, int32(c.typeLength))
+	}
+	if c.Nullable {
+		m.i32Field(3, repetitionOptional)
+	} else {
+		m.i32Field(3, repetitionRequired)
+	}
+	m.stringField(4, c.Name)
+	timestamp := func(utc bool) {
+		m.structField(10, func() { // LogicalType
+			m.structField(8, func() { // TIMESTAMP
+				m.boolField(1, utc)
+				m.structField(2, func() { // unit
+					m.structField(2, func() {}) // MICROS
+				})
+			})
+		})
+	}
+	switch {
+	case c.Type == godror.LogicalString || c.Type == godror.LogicalDecimal && c.scale == nil:
+		m.i32Field(6, convertedUTF8)
+		m.structField(10, func() {
+			m.structField(1, func() {}) // STRING
+		})
+	case c.Type == godror.LogicalDecimal:
+		m.i32Field(6, convertedDecimal)
+		m.i32Field(7, int32(c.Scale))
+		m.i32Field(8, int32(c.Precision))
+		m.structField(10, func() {
+			m.structField(5, func() { // DECIMAL
+				m.i32Field(1, int32(c.Scale))
+				m.i32Field(2, int32(c.Precision))
+			})
+		})
+	case c.Type == godror.LogicalTimestampTZ:
+		m.i32Field(6, convertedTimestampMicros)
+		timestamp(true)
+	case c.Type == godror.LogicalDate || c.Type == godror.LogicalTimestamp:
+		// no converted type, as TIMESTAMP_MICROS means UTC
+		timestamp(false)
+	}
+}
+
+// write writes the ColumnChunk fields.
+func (chunk columnChunk) write(m *compactWriter) {
+	m.i64Field(2, chunk.dataPageOffset) // file_offset
+	m.structField(3, func() {           // ColumnMetaData
+		m.i32Field(1, chunk.physType)
+		m.listField(2, thriftI32, 2, func() {
+			m.varint(encodingPlain)
+			m.varint(encodingRLE)
+		})
+		m.listField(3, thriftBinary, 1, func() { m.binary(chunk.name) })
+		m.i32Field(4, codecUncompressed)
+		m.i64Field(5, chunk.numValues)
+		m.i64Field(6, chunk.size)
+		m.i64Field(7, chunk.size)
+		m.i64Field(9, chunk.dataPageOffset)
+	})
+}
+
+func (w *Writer) write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := w.w.Write(p)
+	w.offset += int64(n)
+	if err != nil {
+		w.err = err
+	}
+	return n, err
+}
+
+func appendUint32(b []byte, u uint32) []byte {
+	var a [4]byte
+	binary.LittleEndian.PutUint32(a[:], u)
+	return append(b, a[:]...)
+}
+
+func appendUint64(b []byte, u uint64) []byte {
+	var a [8]byte
+	binary.LittleEndian.PutUint64(a[:], u)
+	return append(b, a[:]...)
+}
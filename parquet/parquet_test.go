@@ -0,0 +1,351 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package parquet
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	godror "github.com/godror/godror"
+)
+
+func TestWriter(t *testing.T) {
+	cols := []godror.ExportColumn{
+		{Name: "ID", Type: godror.LogicalInt64},
+		{Name: "NAME", Type: godror.LogicalString, Nullable: true},
+		{Name: "AMOUNT", Type: godror.LogicalDecimal, Precision: 10, Scale: 2, Nullable: true},
+		{Name: "BIG", Type: godror.LogicalDecimal, Precision: 30, Scale: 5, Nullable: true},
+		{Name: "NUM", Type: godror.LogicalDecimal, Nullable: true},
+		{Name: "RAW", Type: godror.LogicalBinary, Nullable: true},
+		{Name: "TS", Type: godror.LogicalTimestamp, Nullable: true},
+		{Name: "TSTZ", Type: godror.LogicalTimestampTZ, Nullable: true},
+		{Name: "FLAG", Type: godror.LogicalBool, Nullable: true},
+		{Name: "D", Type: godror.LogicalDouble, Nullable: true},
+	}
+	budapest := time.FixedZone("CET", 3600)
+	ts := time.Date(2022, 3, 4, 5, 6, 7, 8000, budapest)
+	batches := [][]interface{}{
+		{
+			[]sql.NullInt64{{Int64: 1, Valid: true}, {Int64: 2, Valid: true}},
+			[]sql.NullString{{String: "a", Valid: true}, {}},
+			[]sql.NullString{{String: "-12.3", Valid: true}, {}},
+			[]sql.NullString{{String: "-1", Valid: true}, {String: "123456789012345678901234.5", Valid: true}},
+			[]sql.NullString{{String: "1E+40", Valid: true}, {}},
+			[][]byte{{1, 2}, nil},
+			[]sql.NullTime{{Time: ts, Valid: true}, {}},
+			[]sql.NullTime{{Time: ts, Valid: true}, {}},
+			[]sql.NullBool{{Bool: true, Valid: true}, {Bool: false, Valid: true}},
+			[]sql.NullFloat64{{Float64: 0.5, Valid: true}, {}},
+		},
+		{
+			[]sql.NullInt64{{Int64: 3, Valid: true}},
+			[]sql.NullString{{String: "", Valid: true}},
+			[]sql.NullString{{String: "99999999.99", Valid: true}},
+			[]sql.NullString{{}},
+			[]sql.NullString{{}},
+			[][]byte{{}},
+			[]sql.NullTime{{}},
+			[]sql.NullTime{{}},
+			[]sql.NullBool{{}},
+			[]sql.NullFloat64{{Float64: -1, Valid: true}},
+		},
+	}
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.RowGroupSize = 2
+	if err := w.WriteSchema(cols); err != nil {
+		t.Fatal(err)
+	}
+	for _, batch := range batches {
+		if err := w.WriteColumns(batch, len(batch[0].([]sql.NullInt64))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b := buf.Bytes()
+	if !bytes.HasPrefix(b, []byte("PAR1")) || !bytes.HasSuffix(b, []byte("PAR1")) {
+		t.Fatalf("no magic: %q", b)
+	}
+	metaLen := int(binary.LittleEndian.Uint32(b[len(b)-8:]))
+	r := compactReader{b: b[len(b)-8-metaLen : len(b)-8]}
+	meta := r.readStruct()
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	if meta[1] != int64(1) || meta[3] != int64(3) {
+		t.Errorf("version=%v num_rows=%v", meta[1], meta[3])
+	}
+	schema := meta[2].([]interface{})
+	if len(schema) != 1+len(cols) || schema[0].(map[int16]interface{})[5] != int64(len(cols)) {
+		t.Fatalf("schema: %v", schema)
+	}
+	wantSchema := []struct {
+		Type, Repetition int64
+		Converted        interface{}
+	}{
+		{typeInt64, repetitionRequired, nil},
+		{typeByteArray, repetitionOptional, int64(convertedUTF8)},
+		{typeInt64, repetitionOptional, int64(convertedDecimal)},
+		{typeFixedLenByteArray, repetitionOptional, int64(convertedDecimal)},
+		{typeByteArray, repetitionOptional, int64(convertedUTF8)},
+		{typeByteArray, repetitionOptional, nil},
+		{typeInt64, repetitionOptional, nil},
+		{typeInt64, repetitionOptional, int64(convertedTimestampMicros)},
+		{typeBoolean, repetitionOptional, nil},
+		{typeDouble, repetitionOptional, nil},
+	}
+	for i, want := range wantSchema {
+		se := schema[1+i].(map[int16]interface{})
+		if se[4] != cols[i].Name || se[1] != want.Type || se[3] != want.Repetition || se[6] != want.Converted {
+			t.Errorf("%d. got %v, wanted %+v", i, se, want)
+		}
+	}
+	if se := schema[4].(map[int16]interface{}); se[2] != int64(13) || se[7] != int64(5) || se[8] != int64(30) {
+		t.Errorf("BIG: got %v, wanted FIXED_LEN_BYTE_ARRAY(13), DECIMAL(30,5)", se)
+	}
+	if lt := schema[7].(map[int16]interface{})[10].(map[int16]interface{})[8].(map[int16]interface{}); lt[1] != false {
+		t.Errorf("TS: got %v, wanted not adjusted to UTC", lt)
+	}
+
+	rowGroups := meta[4].([]interface{})
+	if len(rowGroups) != 2 {
+		t.Fatalf("got %d row groups, wanted 2", len(rowGroups))
+	}
+	got := make([][]interface{}, len(cols))
+	for _, rg := range rowGroups {
+		chunks := rg.(map[int16]interface{})[1].([]interface{})
+		for i, chunk := range chunks {
+			md := chunk.(map[int16]interface{})[3].(map[int16]interface{})
+			if path := md[3].([]interface{}); len(path) != 1 || path[0] != cols[i].Name {
+				t.Errorf("path: %v", path)
+			}
+			values, err := readPage(b, md, cols[i], schema[1+i].(map[int16]interface{}))
+			if err != nil {
+				t.Fatalf("%s: %+v", cols[i].Name, err)
+			}
+			got[i] = append(got[i], values...)
+		}
+	}
+	twelve3 := big.NewInt(-1230)
+	bigNum, _ := new(big.Int).SetString("12345678901234567890123450000", 10)
+	wantMicros := time.Date(2022, 3, 4, 5, 6, 7, 8000, time.UTC).UnixMicro()
+	want := [][]interface{}{
+		{int64(1), int64(2), int64(3)},
+		{"a", nil, ""},
+		{twelve3.Int64(), nil, int64(9999999999)},
+		{big.NewInt(-100000).String(), bigNum.String(), nil},
+		{"1E+40", nil, nil},
+		{"\x01\x02", nil, ""},
+		{wantMicros, nil, nil},
+		{ts.UnixMicro(), nil, nil},
+		{true, false, nil},
+		{0.5, nil, -1.0},
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("%s: got %#v, wanted %#v", cols[i].Name, got[i], want[i])
+		}
+	}
+}
+
+func TestWriterErrors(t *testing.T) {
+	w := NewWriter(&bytes.Buffer{})
+	if err := w.WriteSchema([]godror.ExportColumn{
+		{Name: "ID", Type: godror.LogicalInt64},
+		{Name: "AMOUNT", Type: godror.LogicalDecimal, Precision: 4, Scale: 2, Nullable: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		Name    string
+		Columns []interface{}
+	}{
+		{"NULL in NOT NULL", []interface{}{[]sql.NullInt64{{}}, []sql.NullString{{}}}},
+		{"bad type", []interface{}{[]sql.NullInt64{{Valid: true}}, []sql.NullInt64{{}}}},
+		{"too many decimals", []interface{}{[]sql.NullInt64{{Valid: true}}, []sql.NullString{{String: "1.234", Valid: true}}}},
+		{"not a number", []interface{}{[]sql.NullInt64{{Valid: true}}, []sql.NullString{{String: "x", Valid: true}}}},
+	} {
+		w := *w
+		w.columns = append([]column(nil), w.columns...)
+		if err := w.WriteColumns(tc.Columns, 1); err == nil {
+			t.Errorf("%s: wanted error", tc.Name)
+		}
+	}
+	if err := NewWriter(&bytes.Buffer{}).WriteSchema([]godror.ExportColumn{{Name: "X", Type: godror.LogicalDecimal, Precision: 40}}); err == nil {
+		t.Error("DECIMAL(40): wanted error")
+	}
+	if err := NewWriter(&bytes.Buffer{}).Close(); err == nil {
+		t.Error("Close without WriteSchema: wanted error")
+	}
+}
+
+func TestDecimalLength(t *testing.T) {
+	for precision, want := range map[int]int{1: 1, 2: 1, 3: 2, 9: 4, 18: 8, 19: 9, 30: 13, 38: 16} {
+		if got := decimalLength(precision); got != want {
+			t.Errorf("%d: got %d, wanted %d", precision, got, want)
+		}
+	}
+}
+
+// readPage decodes the only data page of the column chunk.
+func readPage(b []byte, md map[int16]interface{}, col godror.ExportColumn, se map[int16]interface{}) ([]interface{}, error) {
+	off := md[9].(int64)
+	r := compactReader{b: b[off:]}
+	h := r.readStruct()
+	if r.err != nil {
+		return nil, r.err
+	}
+	dph := h[5].(map[int16]interface{})
+	n := int(dph[1].(int64))
+	if n != int(md[5].(int64)) {
+		return nil, fmt.Errorf("page has %d values, chunk %d", n, md[5])
+	}
+	page := r.b[r.off : r.off+int(h[2].(int64))]
+	if size := int64(r.off) + h[3].(int64); size != md[7].(int64) {
+		return nil, fmt.Errorf("chunk size is %d, wanted %d", md[7], size)
+	}
+	valid := make([]bool, n)
+	if col.Nullable {
+		length := int(binary.LittleEndian.Uint32(page))
+		levels := compactReader{b: page[4 : 4+length]}
+		header := levels.readUvarint()
+		if header&1 != 1 || int(header>>1) != (n+7)/8 {
+			return nil, fmt.Errorf("levels header %x", header)
+		}
+		for i := range valid {
+			valid[i] = levels.b[levels.off+i/8]&(1<<(i%8)) != 0
+		}
+		page = page[4+length:]
+	} else {
+		for i := range valid {
+			valid[i] = true
+		}
+	}
+	values := make([]interface{}, n)
+	var bit int
+	for i := range values {
+		if !valid[i] {
+			continue
+		}
+		switch se[1].(int64) {
+		case typeInt64:
+			values[i], page = int64(binary.LittleEndian.Uint64(page)), page[8:]
+		case typeDouble:
+			values[i], page = math.Float64frombits(binary.LittleEndian.Uint64(page)), page[8:]
+		case typeBoolean:
+			values[i] = page[bit/8]&(1<<(bit%8)) != 0
+			bit++
+		case typeByteArray:
+			length := int(binary.LittleEndian.Uint32(page))
+			values[i], page = string(page[4:4+length]), page[4+length:]
+		case typeFixedLenByteArray:
+			length := int(se[2].(int64))
+			u := new(big.Int).SetBytes(page[:length])
+			if page[0]&0x80 != 0 {
+				u.Sub(u, new(big.Int).Lsh(big.NewInt(1), uint(8*length)))
+			}
+			values[i], page = u.String(), page[length:]
+		}
+	}
+	return values, nil
+}
+
+// compactReader decodes Thrift compact protocol structs into maps of field id to value.
+type compactReader struct {
+	b   []byte
+	off int
+	err error
+}
+
+func (r *compactReader) readByte() byte {
+	if r.off >= len(r.b) {
+		if r.err == nil {
+			r.err = fmt.Errorf("read after end at %d", r.off)
+		}
+		return 0
+	}
+	r.off++
+	return r.b[r.off-1]
+}
+
+func (r *compactReader) readUvarint() uint64 {
+	var u uint64
+	for shift := uint(0); ; shift += 7 {
+		c := r.readByte()
+		u |= uint64(c&0x7f) << shift
+		if c < 0x80 || r.err != nil {
+			return u
+		}
+	}
+}
+
+func (r *compactReader) readVarint() int64 {
+	u := r.readUvarint()
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+func (r *compactReader) readValue(typ byte) interface{} {
+	switch typ {
+	case thriftBoolTrue:
+		return true
+	case thriftBoolFalse:
+		return false
+	case thriftI32, thriftI64:
+		return r.readVarint()
+	case thriftBinary:
+		n := int(r.readUvarint())
+		if r.off+n > len(r.b) {
+			r.err = fmt.Errorf("binary of %d at %d", n, r.off)
+			return ""
+		}
+		r.off += n
+		return string(r.b[r.off-n : r.off])
+	case thriftList:
+		h := r.readByte()
+		n, elemType := int(h>>4), h&0x0f
+		if n == 15 {
+			n = int(r.readUvarint())
+		}
+		list := make([]interface{}, n)
+		for i := range list {
+			list[i] = r.readValue(elemType)
+		}
+		return list
+	case thriftStruct:
+		return r.readStruct()
+	}
+	r.err = fmt.Errorf("unknown type %d at %d", typ, r.off)
+	return nil
+}
+
+func (r *compactReader) readStruct() map[int16]interface{} {
+	m := make(map[int16]interface{})
+	var last int16
+	for r.err == nil {
+		h := r.readByte()
+		if h == 0 {
+			break
+		}
+		typ := h & 0x0f
+		if delta := int16(h >> 4); delta != 0 {
+			last += delta
+		} else {
+			last = int16(r.readVarint())
+		}
+		m[last] = r.readValue(typ)
+	}
+	return m
+}
@@ -0,0 +1,104 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package parquet
+
+// The Thrift compact protocol types, as needed by the Parquet metadata.
+const (
+	thriftBoolTrue  = 1
+	thriftBoolFalse = 2
+	thriftI32       = 5
+	thriftI64       = 6
+	thriftBinary    = 8
+	thriftList      = 9
+	thriftStruct    = 12
+)
+
+// compactWriter encodes Thrift structs with the compact protocol.
+//
+// The fields of a struct must be written in increasing field id order,
+// the nested structs and list elements with the given funcs.
+type compactWriter struct {
+	buf   []byte
+	last  int16
+	stack []int16
+}
+
+func (w *compactWriter) uvarint(u uint64) {
+	for u >= 0x80 {
+		w.buf = append(w.buf, byte(u)|0x80)
+		u >>= 7
+	}
+	w.buf = append(w.buf, byte(u))
+}
+
+func (w *compactWriter) varint(i int64) { w.uvarint(uint64((i << 1) ^ (i >> 63))) }
+
+func (w *compactWriter) binary(s string) {
+	w.uvarint(uint64(len(s)))
+	w.buf = append(w.buf, s...)
+}
+
+func (w *compactWriter) fieldHeader(typ byte, id int16) {
+	if delta := id - w.last; 0 < delta && delta <= 15 {
+		w.buf = append(w.buf, byte(delta)<<4|typ)
+	} else {
+		w.buf = append(w.buf, typ)
+		w.varint(int64(id))
+	}
+	w.last = id
+}
+
+func (w *compactWriter) i32Field(id int16, i int32) {
+	w.fieldHeader(thriftI32, id)
+	w.varint(int64(i))
+}
+
+func (w *compactWriter) i64Field(id int16, i int64) {
+	w.fieldHeader(thriftI64, id)
+	w.varint(i)
+}
+
+func (w *compactWriter) stringField(id int16, s string) {
+	w.fieldHeader(thriftBinary, id)
+	w.binary(s)
+}
+
+func (w *compactWriter) boolField(id int16, b bool) {
+	if b {
+		w.fieldHeader(thriftBoolTrue, id)
+	} else {
+		w.fieldHeader(thriftBoolFalse, id)
+	}
+}
+
+// structField writes the struct field, whose fields are written by fields.
+func (w *compactWriter) structField(id int16, fields func()) {
+	w.fieldHeader(thriftStruct, id)
+	w.structElem(fields)
+}
+
+// structElem writes a struct (as a list element), whose fields are written by fields.
+func (w *compactWriter) structElem(fields func()) {
+	w.stack = append(w.stack, w.last)
+	w.last = 0
+	fields()
+	w.buf = append(w.buf, 0) // stop
+	w.last = w.stack[len(w.stack)-1]
+	w.stack = w.stack[:len(w.stack)-1]
+}
+
+// listField writes the header of a list of n elements of elemType,
+// the elements must be written by elems.
+func (w *compactWriter) listField(id int16, elemType byte, n int, elems func()) {
+	w.fieldHeader(thriftList, id)
+	if n < 15 {
+		w.buf = append(w.buf, byte(n)<<4|elemType)
+	} else {
+		w.buf = append(w.buf, 0xf0|elemType)
+		w.uvarint(uint64(n))
+	}
+	elems()
+}
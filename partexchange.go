@@ -0,0 +1,88 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// PartitionExchange loads a partition with the load-into-staging + exchange partition pattern:
+// the staging table is created with the same shape as Table, loaded by Load,
+// then exchanged with Partition, and dropped.
+type PartitionExchange struct {
+	// Load loads the staging table, such as with CopyTable or an /*+ APPEND_VALUES */ array insert.
+	// With IncludingIndexes, it must create the indexes matching the local indexes of Table, too.
+	Load func(ctx context.Context, stagingTable string) error
+	// Table is the partitioned target table, Partition is the partition to replace.
+	Table, Partition string
+	// StagingTable is the name of the staging table, Table+"_XCHG" by default.
+	StagingTable string
+	// IncludingIndexes exchanges the local indexes, too.
+	IncludingIndexes bool
+	// WithoutValidation skips checking that the staging rows belong to the partition.
+	WithoutValidation bool
+	// KeepStaging keeps the staging table (which holds the old partition's rows after the exchange).
+	KeepStaging bool
+}
+
+// Run executes the flow: create staging, Load, exchange, drop staging.
+//
+// The staging table is created with CREATE TABLE ... FOR EXCHANGE WITH TABLE (18c+),
+// or as an empty copy of Table with older databases.
+// On error, the staging table is dropped, too (except with KeepStaging).
+func (pe PartitionExchange) Run(ctx context.Context, db Execer) error {
+	if pe.Table == "" || pe.Partition == "" || pe.Load == nil {
+		return errors.New("Table, Partition and Load are required")
+	}
+	staging := pe.StagingTable
+	if staging == "" {
+		staging = pe.Table + "_XCHG"
+	}
+
+	qry := "CREATE TABLE " + staging + " FOR EXCHANGE WITH TABLE " + pe.Table
+	if _, err := db.ExecContext(ctx, qry); err != nil {
+		// ORA-00922: missing or invalid option - before 18c
+		if oerr, ok := AsOraErr(err); !ok || oerr.Code() != 922 {
+			return fmt.Errorf("%s: %w", qry, err)
+		}
+		qry = "CREATE TABLE " + staging + " AS SELECT * FROM " + pe.Table + " WHERE 1=0"
+		if _, err = db.ExecContext(ctx, qry); err != nil {
+			return fmt.Errorf("%s: %w", qry, err)
+		}
+	}
+	dropped := pe.KeepStaging
+	defer func() {
+		if !dropped {
+			_, _ = db.ExecContext(context.Background(), "DROP TABLE "+staging+" PURGE")
+		}
+	}()
+
+	if err := pe.Load(ctx, staging); err != nil {
+		return fmt.Errorf("load %s: %w", staging, err)
+	}
+
+	qry = "ALTER TABLE " + pe.Table + " EXCHANGE PARTITION " + pe.Partition + " WITH TABLE " + staging
+	if pe.IncludingIndexes {
+		qry += " INCLUDING INDEXES"
+	}
+	if pe.WithoutValidation {
+		qry += " WITHOUT VALIDATION"
+	}
+	if _, err := db.ExecContext(ctx, qry); err != nil {
+		return fmt.Errorf("%s: %w", qry, err)
+	}
+
+	if !pe.KeepStaging {
+		qry = "DROP TABLE " + staging + " PURGE"
+		if _, err := db.ExecContext(ctx, qry); err != nil {
+			return fmt.Errorf("%s: %w", qry, err)
+		}
+		dropped = true
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPartitionExchangeRequired(t *testing.T) {
+	load := func(context.Context, string) error { return nil }
+	for _, pe := range []PartitionExchange{
+		{Partition: "p1", Load: load},
+		{Table: "t", Load: load},
+		{Table: "t", Partition: "p1"},
+	} {
+		// checked before executing anything, so the nil Execer is not used
+		if err := pe.Run(context.Background(), nil); err == nil {
+			t.Errorf("%+v: wanted error", pe)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TablePartition is a partition of a table, as in all_tab_partitions view.
+type TablePartition struct {
+	// HighTime is the decoded DATE or TIMESTAMP high value - its wall clock, in time.UTC.
+	HighTime time.Time
+	Name     string
+	// HighValue is the high value expression, as is.
+	HighValue string
+	// HighNumber is the decoded numeric high value.
+	HighNumber Number
+	Position   int
+	// Interval is true for the partitions created automatically by interval partitioning.
+	Interval bool
+	// MaxValue is true for the MAXVALUE partition.
+	MaxValue bool
+}
+
+// GetTablePartitions returns the partitions of the table, with the high values decoded
+// into HighTime or HighNumber (for single-column range partitions).
+func GetTablePartitions(ctx context.Context, q Querier, owner, table string) ([]TablePartition, error) {
+	const qry = `SELECT partition_name, partition_position, high_value, interval
+  FROM all_tab_partitions
+  WHERE table_owner = NVL(:1, USER) AND table_name = :2
+  ORDER BY partition_position`
+	rows, err := q.QueryContext(ctx, qry, owner, table)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	var parts []TablePartition
+	for rows.Next() {
+		var p TablePartition
+		var interval string
+		if err = rows.Scan(&p.Name, &p.Position, &p.HighValue, &interval); err != nil {
+			return parts, fmt.Errorf("%s: %w", qry, err)
+		}
+		p.Interval = interval == "YES"
+		if err = p.decodeHighValue(); err != nil {
+			return parts, fmt.Errorf("%s: %w", p.Name, err)
+		}
+		parts = append(parts, p)
+	}
+	return parts, rows.Err()
+}
+
+// decodeHighValue sets HighTime, HighNumber or MaxValue from HighValue.
+func (p *TablePartition) decodeHighValue() error {
+	s := strings.TrimSpace(p.HighValue)
+	switch {
+	case s == "MAXVALUE":
+		p.MaxValue = true
+	case strings.HasPrefix(s, "TO_DATE('"), strings.HasPrefix(s, "TIMESTAMP'"):
+		s = s[strings.IndexByte(s, '\'')+1:]
+		i := strings.IndexByte(s, '\'')
+		if i < 0 {
+			return fmt.Errorf("cannot decode high value %q", p.HighValue)
+		}
+		s = strings.TrimSpace(s[:i])
+		for _, layout := range []string{"2006-01-02 15:04:05.999999999", "2006-01-02 15:04:05.999999999 -07:00"} {
+			if t, err := time.Parse(layout, s); err == nil {
+				p.HighTime = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+				return nil
+			}
+		}
+		return fmt.Errorf("cannot decode high value %q", p.HighValue)
+	default:
+		if _, err := strconv.ParseFloat(s, 64); err == nil {
+			p.HighNumber = Number(s)
+		}
+	}
+	return nil
+}
+
+// DropPartitionsBefore drops (or truncates, if truncate is true) the partitions whose rows are all
+// older than before (HighTime <= before, compared by the wall clock), with UPDATE GLOBAL INDEXES,
+// and returns the names of the affected partitions.
+//
+// The last partition of the range section of an interval partitioned table cannot be dropped
+// (ORA-14758), so it is skipped.
+func DropPartitionsBefore(ctx context.Context, db interface {
+	Execer
+	Querier
+}, owner, table string, before time.Time, truncate bool) ([]string, error) {
+	parts, err := GetTablePartitions(ctx, db, owner, table)
+	if err != nil {
+		return nil, err
+	}
+	before = time.Date(before.Year(), before.Month(), before.Day(), before.Hour(), before.Minute(), before.Second(), before.Nanosecond(), time.UTC)
+	fullName := table
+	if owner != "" {
+		fullName = owner + "." + table
+	}
+	verb := "DROP"
+	if truncate {
+		verb = "TRUNCATE"
+	}
+	var names []string
+	for _, p := range parts {
+		if p.HighTime.IsZero() || p.HighTime.After(before) {
+			continue
+		}
+		qry := "ALTER TABLE " + fullName + " " + verb + ` PARTITION "` + p.Name + `" UPDATE GLOBAL INDEXES`
+		if _, err = db.ExecContext(ctx, qry); err != nil {
+			if oerr, ok := AsOraErr(err); ok && oerr.Code() == 14758 {
+				continue
+			}
+			return names, fmt.Errorf("%s: %w", qry, err)
+		}
+		names = append(names, p.Name)
+	}
+	return names, nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecodeHighValue(t *testing.T) {
+	jan := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		In     string
+		Time   time.Time
+		Number Number
+		Max    bool
+	}{
+		{In: "TO_DATE(' 2022-01-01 00:00:00', 'SYYYY-MM-DD HH24:MI:SS', 'NLS_CALENDAR=GREGORIAN')", Time: jan},
+		{In: "TIMESTAMP' 2022-01-01 00:00:00'", Time: jan},
+		{In: "TIMESTAMP' 2022-01-01 00:00:00.5'", Time: jan.Add(500 * time.Millisecond)},
+		{In: "TIMESTAMP' 2022-01-01 01:00:00 +01:00'", Time: jan.Add(time.Hour)},
+		{In: "1000", Number: "1000"},
+		{In: "MAXVALUE", Max: true},
+		{In: "'A', 'B'"},
+	} {
+		p := TablePartition{HighValue: tc.In}
+		if err := p.decodeHighValue(); err != nil {
+			t.Errorf("%q: %+v", tc.In, err)
+			continue
+		}
+		if !p.HighTime.Equal(tc.Time) || p.HighNumber != tc.Number || p.MaxValue != tc.Max {
+			t.Errorf("%q: got %+v", tc.In, p)
+		}
+	}
+	for _, in := range []string{"TIMESTAMP' 2022-01-01", "TO_DATE('", "TO_DATE('x', 'SYYYY')"} {
+		p := TablePartition{HighValue: in}
+		if err := p.decodeHighValue(); err == nil {
+			t.Errorf("%q: wanted error, got %+v", in, p)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/godror/godror/dsn"
+)
+
+// ErrProxyNeedsHeterogeneous is returned when a proxy user is requested (with ContextWithProxyUser)
+// from a homogeneous pool, which can only give sessions of its own user.
+var ErrProxyNeedsHeterogeneous = errors.New("proxy user needs heterogeneousPool=1 or standaloneConnection=1")
+
+type proxyUserCtxKey struct{}
+
+// ContextWithProxyUser returns a context for acquiring a session authenticated as the given end user,
+// through the connection's user as the proxy (the end user needs "ALTER USER proxied GRANT CONNECT THROUGH proxy").
+//
+// This needs a heterogeneous pool (heterogeneousPool=1) or a standalone connection,
+// else ErrProxyNeedsHeterogeneous is returned.
+// For the whole pool, the "proxy[proxied]" user syntax of the connection string can be used, too.
+//
+// Also, you should disable the Go connection pool with DB.SetMaxIdleConns(0).
+func ContextWithProxyUser(ctx context.Context, proxied string) context.Context {
+	return context.WithValue(ctx, proxyUserCtxKey{}, proxied)
+}
+
+// applyProxyUser sets the user and password for acquiring a session of the proxied user (from ctx).
+//
+// For a standalone connection, the user is "proxy[proxied]" with the password of proxy,
+// for a heterogeneous pool, the user is the proxied user without password, as the pool authenticates.
+func applyProxyUser(ctx context.Context, P *dsn.CommonParams, standalone, heterogeneous bool) error {
+	proxied, _ := ctx.Value(proxyUserCtxKey{}).(string)
+	if proxied == "" {
+		return nil
+	}
+	proxy := P.Username
+	if i := strings.IndexByte(proxy, '['); i >= 0 {
+		proxy = proxy[:i]
+	}
+	switch {
+	case standalone:
+		P.Username = proxy + "[" + proxied + "]"
+	case heterogeneous:
+		P.Username, P.Password = proxied, dsn.Password{}
+	default:
+		return fmt.Errorf("%q: %w", proxied, ErrProxyNeedsHeterogeneous)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/godror/godror/dsn"
+)
+
+func TestApplyProxyUser(t *testing.T) {
+	ctx := context.Background()
+	P := dsn.CommonParams{Username: "app", Password: dsn.NewPassword("secret")}
+	if err := applyProxyUser(ctx, &P, false, false); err != nil || P.Username != "app" {
+		t.Errorf("without proxy user: got %q, %v", P.Username, err)
+	}
+
+	ctx = ContextWithProxyUser(ctx, "scott")
+	for name, tC := range map[string]struct {
+		User, WantUser         string
+		Standalone, Hetero     bool
+		WantPassword, WantFail bool
+	}{
+		"standalone":         {User: "app", Standalone: true, WantUser: "app[scott]", WantPassword: true},
+		"standalone-proxied": {User: "app[other]", Standalone: true, WantUser: "app[scott]", WantPassword: true},
+		"heterogeneous":      {User: "app", Hetero: true, WantUser: "scott"},
+		"homogeneous":        {User: "app", WantFail: true},
+	} {
+		P := dsn.CommonParams{Username: tC.User, Password: dsn.NewPassword("secret")}
+		err := applyProxyUser(ctx, &P, tC.Standalone, tC.Hetero)
+		if tC.WantFail {
+			if !errors.Is(err, ErrProxyNeedsHeterogeneous) {
+				t.Errorf("%s: got %v, wanted ErrProxyNeedsHeterogeneous", name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %+v", name, err)
+			continue
+		}
+		if P.Username != tC.WantUser || P.Password.IsZero() == tC.WantPassword {
+			t.Errorf("%s: got user=%q password=%t, wanted %q, %t", name, P.Username, !P.Password.IsZero(), tC.WantUser, tC.WantPassword)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CreateTopic creates a multi-consumer queue (with its queue table named name+"_QT") and starts it.
+func CreateTopic(ctx context.Context, ex Execer, name, payloadType string) error {
+	queueTable := name + "_QT"
+	if err := CreateQueueTable(ctx, ex, queueTable, payloadType, QueueTableOptions{MultipleConsumers: true}); err != nil {
+		return err
+	}
+	if err := CreateQueue(ctx, ex, name, queueTable, QueueOptions{}); err != nil {
+		return err
+	}
+	return StartQueue(ctx, ex, name)
+}
+
+// AddSubscriber adds a named consumer to the topic (multi-consumer queue):
+// it receives all the messages published after this.
+func AddSubscriber(ctx context.Context, ex Execer, topic, consumer string) error {
+	const qry = "BEGIN SYS.DBMS_AQADM.ADD_SUBSCRIBER(queue_name=>:1, subscriber=>SYS.AQ$_AGENT(:2, NULL, NULL)); END;"
+	if _, err := ex.ExecContext(ctx, qry, topic, consumer); err != nil {
+		return fmt.Errorf("%s: %w", qry, err)
+	}
+	return nil
+}
+
+// RemoveSubscriber removes the named consumer from the topic.
+func RemoveSubscriber(ctx context.Context, ex Execer, topic, consumer string) error {
+	const qry = "BEGIN SYS.DBMS_AQADM.REMOVE_SUBSCRIBER(queue_name=>:1, subscriber=>SYS.AQ$_AGENT(:2, NULL, NULL)); END;"
+	if _, err := ex.ExecContext(ctx, qry, topic, consumer); err != nil {
+		return fmt.Errorf("%s: %w", qry, err)
+	}
+	return nil
+}
+
+// Publisher publishes messages to a topic (multi-consumer queue).
+type Publisher struct {
+	q *Queue
+}
+
+// NewPublisher returns a Publisher for the topic.
+//
+// With visibility 0 or VisibleImmediate, the messages are published in their own transaction.
+// VisibleOnCommit makes the publication part of the transaction of ex (a *sql.Tx or *sql.Conn).
+func NewPublisher(ctx context.Context, ex Execer, topic, payloadObjectTypeName string, visibility Visibility) (*Publisher, error) {
+	enqOpts := DefaultEnqOptions
+	enqOpts.Visibility = VisibleImmediate
+	if visibility != 0 {
+		enqOpts.Visibility = visibility
+	}
+	q, err := NewQueue(ctx, ex, topic, payloadObjectTypeName, WithEnqOptions(enqOpts))
+	if err != nil {
+		return nil, err
+	}
+	return &Publisher{q: q}, nil
+}
+
+// Publish the messages to all the subscribers of the topic.
+func (p *Publisher) Publish(messages ...Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	return p.q.Enqueue(messages)
+}
+
+// Close the publisher.
+func (p *Publisher) Close() error { return p.q.Close() }
+
+// Subscriber receives the messages of a topic as a named consumer.
+//
+// The received messages are removed only with Ack (commit), Nack (rollback) makes them
+// available again (with Redelivered() true), till the queue's max_retries.
+type Subscriber struct {
+	q        *Queue
+	consumer string
+}
+
+// NewSubscriber returns a Subscriber for the topic, as the consumer (see AddSubscriber),
+// waiting at most wait for messages in Receive.
+//
+// Ack and Nack commit or rollback the whole transaction of the connection,
+// so use a *sql.DB (the subscriber gets its own connection), or a dedicated *sql.Conn.
+func NewSubscriber(ctx context.Context, ex Execer, topic, consumer, payloadObjectTypeName string, wait time.Duration) (*Subscriber, error) {
+	if consumer == "" {
+		return nil, errors.New("NewSubscriber: empty consumer")
+	}
+	deqOpts := DefaultDeqOptions
+	deqOpts.Consumer, deqOpts.Wait = consumer, wait
+	q, err := NewQueue(ctx, ex, topic, payloadObjectTypeName, WithDeqOptions(deqOpts))
+	if err != nil {
+		return nil, err
+	}
+	return &Subscriber{q: q, consumer: consumer}, nil
+}
+
+// Consumer returns the name of the consumer.
+func (s *Subscriber) Consumer() string { return s.consumer }
+
+// Receive at most len(messages) messages, returns the number of received messages.
+func (s *Subscriber) Receive(messages []Message) (int, error) { return s.q.Dequeue(messages) }
+
+// Ack acknowledges (commits) the received messages.
+func (s *Subscriber) Ack() error { return s.q.conn.Commit() }
+
+// Nack rolls back the receipt of the messages, so they will be received again.
+func (s *Subscriber) Nack() error { return s.q.conn.Rollback() }
+
+// Close the subscriber. The unacknowledged messages are rolled back if the connection is owned.
+func (s *Subscriber) Close() error {
+	if s.q.connIsOwned && s.q.conn != nil {
+		_ = s.q.conn.Rollback()
+	}
+	return s.q.Close()
+}
@@ -0,0 +1,18 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNewSubscriberEmptyConsumer(t *testing.T) {
+	// checked before opening the queue, so the nil Execer is not used
+	if _, err := NewSubscriber(context.Background(), nil, "topic", "", "", 0); err == nil {
+		t.Error("wanted error for empty consumer")
+	}
+}
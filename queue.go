@@ -255,6 +255,43 @@ func (Q *Queue) Dequeue(messages []Message) (int, error) {
 	return int(num), firstErr
 }
 
+// DequeueContext is like Dequeue, but returns when ctx is done,
+// breaking the dequeue wait (DeqOptions.Wait) if needed.
+func (Q *Queue) DequeueContext(ctx context.Context, messages []Message) (int, error) {
+	done := make(chan struct{})
+	defer close(done)
+	resetTimeout, err := Q.conn.handleDeadline(ctx, done)
+	if err != nil {
+		return 0, err
+	}
+	defer resetTimeout()
+	n, err := Q.Dequeue(messages)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return n, fmt.Errorf("%w: %v", ctxErr, err)
+		}
+	}
+	return n, err
+}
+
+// EnqueueContext is like Enqueue, but returns when ctx is done.
+func (Q *Queue) EnqueueContext(ctx context.Context, messages []Message) error {
+	done := make(chan struct{})
+	defer close(done)
+	resetTimeout, err := Q.conn.handleDeadline(ctx, done)
+	if err != nil {
+		return err
+	}
+	defer resetTimeout()
+	err = Q.Enqueue(messages)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("%w: %v", ctxErr, err)
+		}
+	}
+	return err
+}
+
 // Enqueue all the messages given.
 //
 // WARNING: calling this function in parallel on different connections acquired from the same pool may fail due to Oracle bug 29928074.
@@ -344,6 +381,18 @@ func (M Message) Deadline() time.Time {
 	}
 	return M.Enqueued.Add(M.Delay + M.Expiration)
 }
+
+// Redelivered reports whether the message has been dequeued before, and that was rolled back.
+//
+// After the queue's max_retries attempts, the message is moved to its exception queue (ExceptionQ),
+// or use MoveTo to move a poison message sooner.
+func (M Message) Redelivered() bool { return M.NumAttempts > 0 }
+
+// Expired reports whether the message's Deadline has passed at now (never without Expiration).
+func (M Message) Expired(now time.Time) bool {
+	return M.Expiration > 0 && !M.Enqueued.IsZero() && now.After(M.Deadline())
+}
+
 func (M *Message) toOra(d *drv, props *C.dpiMsgProps) error {
 	runtime.LockOSThread()
 	defer runtime.UnlockOSThread()
@@ -433,11 +482,8 @@ func (M *Message) fromOra(c *conn, props *C.dpiMsgProps, objType *ObjectType) er
 	var ts C.dpiTimestamp
 	M.Enqueued = time.Time{}
 	if OK(C.dpiMsgProps_getEnqTime(props, &ts), "getEnqTime") {
-		M.Enqueued = time.Date(
-			int(ts.year), time.Month(ts.month), int(ts.day),
-			int(ts.hour), int(ts.minute), int(ts.second), int(ts.fsecond),
-			timeZoneFor(ts.tzHourOffset, ts.tzMinuteOffset, c.params.Timezone),
-		)
+		// the enqueue time is an OCIDate, without time zone
+		M.Enqueued = timeFromTimestamp(ts, false, c.params.Timezone)
 	}
 
 	M.Expiration = 0
@@ -778,3 +824,27 @@ const (
 	// NavNext  	Retrieves the next available message that matches the search criteria. This is the default method.
 	NavNext = DeqNavigation(C.DPI_DEQ_NAV_NEXT_MSG)
 )
+
+// MoveTo enqueues the copies of the (dequeued) messages into dst - such as a dead letter queue for poison messages -
+// with OriginalMsgID set to their MsgID (if not set already), keeping the payload, correlation, priority and ExceptionQ.
+//
+// dst must use the same connection (use a *sql.Conn for both NewQueue),
+// so the dequeue and the move are committed (or rolled back) together.
+func (Q *Queue) MoveTo(dst *Queue, messages []Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	if Q.conn == nil || dst.conn == nil || Q.conn.dpiConn != dst.conn.dpiConn {
+		return errors.New("MoveTo: the queues must use the same connection")
+	}
+	copies := make([]Message, len(messages))
+	for i, M := range messages {
+		if M.OriginalMsgID == zeroMsgID {
+			M.OriginalMsgID = M.MsgID
+		}
+		M.MsgID, M.Enqueued, M.Delay = zeroMsgID, time.Time{}, 0
+		M.State, M.NumAttempts = 0, 0
+		copies[i] = M
+	}
+	return dst.Enqueue(copies)
+}
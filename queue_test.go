@@ -501,3 +501,34 @@ func testQueue(
 	}
 
 }
+
+func TestMessageRedelivery(t *testing.T) {
+	enq := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	M := godror.Message{Enqueued: enq, Delay: time.Second, Expiration: time.Minute}
+	if M.Redelivered() {
+		t.Error("new message is redelivered")
+	}
+	if want := enq.Add(time.Minute + time.Second); !M.Deadline().Equal(want) {
+		t.Errorf("Deadline: got %v, wanted %v", M.Deadline(), want)
+	}
+	if M.Expired(M.Deadline()) || !M.Expired(M.Deadline().Add(time.Nanosecond)) {
+		t.Error("Expired should be true only after the Deadline")
+	}
+	M.NumAttempts = 1
+	if !M.Redelivered() {
+		t.Error("rolled back message is not redelivered")
+	}
+	for _, M := range []godror.Message{{Enqueued: enq}, {Expiration: time.Minute}} {
+		if M.Expired(enq.Add(24 * time.Hour)) {
+			t.Errorf("%+v expired", M)
+		}
+	}
+
+	var src, dst godror.Queue
+	if err := src.MoveTo(&dst, nil); err != nil {
+		t.Errorf("MoveTo without messages: %+v", err)
+	}
+	if err := src.MoveTo(&dst, []godror.Message{M}); err == nil {
+		t.Error("MoveTo without connection: wanted error")
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// XSSessionOptions are the options of a Real Application Security (RAS) session attach.
+type XSSessionOptions struct {
+	// EnableRoles and DisableRoles are the dynamic application roles to enable/disable in the session.
+	EnableRoles, DisableRoles []string
+}
+
+// CreateXSSession creates a Real Application Security lightweight session for the application user,
+// with DBMS_XS_SESSIONS.CREATE_SESSION, and returns its ID.
+//
+// The database user needs the CREATE_SESSION application privilege (such as the XS_SESSION_ADMIN role).
+func CreateXSSession(ctx context.Context, ex Execer, user string) ([]byte, error) {
+	const qry = "BEGIN DBMS_XS_SESSIONS.create_session(username=>:1, sessionid=>:2); END;"
+	var id []byte
+	if _, err := ex.ExecContext(ctx, qry, user, sql.Out{Dest: &id}); err != nil {
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	return id, nil
+}
+
+// AttachXSSession attaches the RAS session to the current database session,
+// so the following statements run with the application user's privileges.
+//
+// ex must be the same database session all along, such as an *sql.Conn or *sql.Tx.
+func AttachXSSession(ctx context.Context, ex Execer, id []byte, opts XSSessionOptions) error {
+	args := []interface{}{id}
+	nameList := func(names []string) string {
+		if names == nil {
+			return "NULL"
+		}
+		var buf strings.Builder
+		buf.WriteString("XS$NAME_LIST(")
+		for i, nm := range names {
+			if i != 0 {
+				buf.WriteString(", ")
+			}
+			args = append(args, nm)
+			buf.WriteString(":" + strconv.Itoa(len(args)))
+		}
+		buf.WriteByte(')')
+		return buf.String()
+	}
+	qry := "BEGIN DBMS_XS_SESSIONS.attach_session(sessionid=>:1, enable_dynamic_roles=>" + nameList(opts.EnableRoles) +
+		", disable_dynamic_roles=>" + nameList(opts.DisableRoles) + "); END;"
+	if _, err := ex.ExecContext(ctx, qry, args...); err != nil {
+		return fmt.Errorf("%s: %w", qry, err)
+	}
+	return nil
+}
+
+// DetachXSSession detaches the RAS session from the current database session.
+// With abort, the changes made in the RAS session are rolled back.
+func DetachXSSession(ctx context.Context, ex Execer, abort bool) error {
+	qry := "BEGIN DBMS_XS_SESSIONS.detach_session(abort=>FALSE); END;"
+	if abort {
+		qry = "BEGIN DBMS_XS_SESSIONS.detach_session(abort=>TRUE); END;"
+	}
+	if _, err := ex.ExecContext(ctx, qry); err != nil {
+		return fmt.Errorf("%s: %w", qry, err)
+	}
+	return nil
+}
+
+// DestroyXSSession destroys the (detached) RAS session.
+func DestroyXSSession(ctx context.Context, ex Execer, id []byte) error {
+	const qry = "BEGIN DBMS_XS_SESSIONS.destroy_session(sessionid=>:1); END;"
+	if _, err := ex.ExecContext(ctx, qry, id); err != nil {
+		return fmt.Errorf("%s: %w", qry, err)
+	}
+	return nil
+}
+
+// WithXSSession attaches the RAS session to the pooled connection, calls fn, and detaches it -
+// even if fn returns an error or panics.
+//
+// This way a few database sessions can serve many application users.
+// If the detach fails, the connection is discarded, so it won't return to the pool
+// with the application user attached.
+func WithXSSession(ctx context.Context, conn *sql.Conn, id []byte, opts XSSessionOptions, fn func(context.Context) error) (err error) {
+	if err = AttachXSSession(ctx, conn, id, opts); err != nil {
+		return err
+	}
+	defer func() {
+		// detach even if ctx is cancelled
+		dctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if dErr := DetachXSSession(dctx, conn, false); dErr != nil {
+			_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+			if err == nil {
+				err = dErr
+			}
+		}
+	}()
+	return fn(ctx)
+}
@@ -0,0 +1,26 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAttachXSSession(t *testing.T) {
+	var ex recordingExecer
+	id := []byte{1, 2, 3}
+	if err := AttachXSSession(context.Background(), &ex, id, XSSessionOptions{EnableRoles: []string{"HR_ROLE", "EMP_ROLE"}}); err != nil {
+		t.Fatal(err)
+	}
+	const want = "BEGIN DBMS_XS_SESSIONS.attach_session(sessionid=>:1, enable_dynamic_roles=>XS$NAME_LIST(:2, :3), disable_dynamic_roles=>NULL); END;"
+	if ex.qry != want {
+		t.Errorf("got %q, wanted %q", ex.qry, want)
+	}
+	if len(ex.args) != 3 || ex.args[1] != "HR_ROLE" || ex.args[2] != "EMP_ROLE" {
+		t.Errorf("got args %#v", ex.args)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include "dpiImpl.h"
+*/
+import "C"
+
+import (
+	"fmt"
+	"io"
+)
+
+// SizedReader is an IN bind of Size bytes read from the Reader,
+// as RAW, or VARCHAR2 if IsString.
+//
+// The Reader is streamed chunk by chunk into a temporary LOB right before executing,
+// which Oracle converts to RAW or VARCHAR2 - there's no need to allocate a []byte or string for it,
+// and the bind variable does not depend on Size, so it can be reused between executions.
+//
+// A nil Reader or zero Size binds NULL. For values larger than 32767 bytes use Lob.
+type SizedReader struct {
+	io.Reader
+	Size     int
+	IsString bool
+}
+
+func (c *conn) dataSetSizedReader(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	sr := vv.(SizedReader)
+	if sr.Reader == nil || sr.Size == 0 {
+		data[0].isNull = 1
+		return nil
+	}
+	data[0].isNull = 0
+	written, err := c.setTempLob(dv, 0, io.LimitReader(sr.Reader, int64(sr.Size)), sr.IsString)
+	if err == nil && written != int64(sr.Size) {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return fmt.Errorf("read %d bytes (got %d): %w", sr.Size, written, err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// RecompileOptions are the options of RecompileInvalid.
+type RecompileOptions struct {
+	// Owner is the schema, the current user by default.
+	Owner string
+	// UTLRecomp uses UTL_RECOMP.RECOMP_SERIAL (needs SYS privileges),
+	// instead of ALTER ... COMPILE for each invalid object.
+	UTLRecomp bool
+	// MaxPasses limits the ALTER ... COMPILE rounds (as compiling an object may invalidate its dependents),
+	// 5 by default.
+	MaxPasses int
+}
+
+// RecompileInvalid recompiles the invalid objects of the schema,
+// and returns the remaining compile errors (see GetCompileErrors).
+//
+// The ALTER ... COMPILE rounds are repeated until all objects are valid,
+// or a round could not fix any of them.
+func RecompileInvalid(ctx context.Context, db interface {
+	Execer
+	Querier
+}, opts RecompileOptions) ([]CompileError, error) {
+	owner := opts.Owner
+	if opts.UTLRecomp {
+		const qry = "BEGIN UTL_RECOMP.RECOMP_SERIAL(schema=>NVL(:1, USER)); END;"
+		if _, err := db.ExecContext(ctx, qry, owner); err != nil {
+			return nil, fmt.Errorf("%s: %w", qry, err)
+		}
+		return getCompileErrors(ctx, db, owner, false)
+	}
+
+	maxPasses := opts.MaxPasses
+	if maxPasses <= 0 {
+		maxPasses = 5
+	}
+	const qry = `SELECT object_name, object_type FROM all_objects
+  WHERE owner = NVL(:1, USER) AND status = 'INVALID'
+  ORDER BY DECODE(object_type, 'TYPE', 1, 'PACKAGE', 2, 'FUNCTION', 3, 'PROCEDURE', 3, 'VIEW', 4, 9), object_name`
+	prev := -1
+	for pass := 0; pass < maxPasses; pass++ {
+		rows, err := db.QueryContext(ctx, qry, owner)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", qry, err)
+		}
+		var stmts []string
+		for rows.Next() {
+			var name, typ string
+			if err = rows.Scan(&name, &typ); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("%s: %w", qry, err)
+			}
+			if stmt := compileStmt(owner, name, typ); stmt != "" {
+				stmts = append(stmts, stmt)
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", qry, err)
+		}
+		if len(stmts) == 0 || len(stmts) == prev {
+			break
+		}
+		prev = len(stmts)
+		for _, stmt := range stmts {
+			if err = ctx.Err(); err != nil {
+				return nil, err
+			}
+			// ORA-24344: success with compilation error - see the compile errors at the end
+			if _, err = db.ExecContext(ctx, stmt); err != nil {
+				if oerr, ok := AsOraErr(err); !ok || oerr.Code() != 24344 {
+					return nil, fmt.Errorf("%s: %w", stmt, err)
+				}
+			}
+		}
+	}
+	return getCompileErrors(ctx, db, owner, false)
+}
+
+// compileStmt returns the ALTER ... COMPILE statement for the object, or "" if it cannot be compiled that way.
+// An empty owner means the current schema.
+func compileStmt(owner, name, typ string) string {
+	var body bool
+	switch typ {
+	case "PACKAGE BODY", "TYPE BODY":
+		typ, body = strings.TrimSuffix(typ, " BODY"), true
+	case "PACKAGE", "TYPE", "PROCEDURE", "FUNCTION", "TRIGGER", "VIEW", "MATERIALIZED VIEW", "SYNONYM":
+	default:
+		return ""
+	}
+	stmt := "ALTER " + typ + ` "` + name + `" COMPILE`
+	if owner != "" {
+		stmt = "ALTER " + typ + ` "` + owner + `"."` + name + `" COMPILE`
+	}
+	if body {
+		stmt += " BODY"
+	}
+	return stmt
+}
@@ -0,0 +1,24 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import "testing"
+
+func TestCompileStmt(t *testing.T) {
+	for _, tc := range []struct {
+		Owner, Name, Type, Want string
+	}{
+		{"", "PKG", "PACKAGE BODY", `ALTER PACKAGE "PKG" COMPILE BODY`},
+		{"APP", "PKG", "PACKAGE", `ALTER PACKAGE "APP"."PKG" COMPILE`},
+		{"APP", "V_X", "VIEW", `ALTER VIEW "APP"."V_X" COMPILE`},
+		{"APP", "T", "TYPE BODY", `ALTER TYPE "APP"."T" COMPILE BODY`},
+		{"APP", "J", "JAVA CLASS", ""},
+	} {
+		if got := compileStmt(tc.Owner, tc.Name, tc.Type); got != tc.Want {
+			t.Errorf("%q.%q %s: got %q, wanted %q", tc.Owner, tc.Name, tc.Type, got, tc.Want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+var redactSQL uint32
+
+// SetRedactSQL enables (or disables) the redaction of the SQL texts included in errors and logs:
+// string and number literals are replaced by "?".
+//
+// Bind variables are not affected, as their values never get into the SQL text.
+func SetRedactSQL(enable bool) {
+	var u uint32
+	if enable {
+		u = 1
+	}
+	atomic.StoreUint32(&redactSQL, u)
+}
+
+// sqlForLog returns qry as it can be included in errors and logs - redacted iff SetRedactSQL(true).
+func sqlForLog(qry string) string {
+	if atomic.LoadUint32(&redactSQL) == 0 {
+		return qry
+	}
+	return RedactSQL(qry)
+}
+
+// RedactSQL returns qry with the string literals ('...', N'...', q'[...]') and number literals replaced by "?".
+// An unterminated string literal is redacted till the end.
+func RedactSQL(qry string) string {
+	s, _ := redactSQLOffset(qry, -1)
+	return s
+}
+
+// redactSQLOffset redacts qry as RedactSQL, and returns the position of offset in the redacted text, too.
+func redactSQLOffset(qry string, offset int) (string, int) {
+	var buf strings.Builder
+	buf.Grow(len(qry))
+	newOffset := -1
+	for i := 0; i < len(qry); {
+		if i >= offset && newOffset < 0 && offset >= 0 {
+			newOffset = buf.Len()
+		}
+		c := qry[i]
+		switch {
+		case c == '-' && strings.HasPrefix(qry[i:], "--"):
+			j := strings.IndexByte(qry[i:], '\n')
+			if j < 0 {
+				j = len(qry) - i
+			}
+			buf.WriteString(qry[i : i+j])
+			i += j
+			continue
+		case c == '/' && strings.HasPrefix(qry[i:], "/*"):
+			j := strings.Index(qry[i+2:], "*/")
+			if j < 0 {
+				j = len(qry) - i
+			} else {
+				j += 4
+			}
+			buf.WriteString(qry[i : i+j])
+			i += j
+			continue
+		case c == '"':
+			// quoted identifier
+			j := strings.IndexByte(qry[i+1:], '"')
+			if j < 0 {
+				j = len(qry) - i
+			} else {
+				j += 2
+			}
+			buf.WriteString(qry[i : i+j])
+			i += j
+			continue
+		case c == ':':
+			// bind placeholder, such as :1
+			j := i + 1
+			for j < len(qry) && isIdentChar(qry[j]) {
+				j++
+			}
+			buf.WriteString(qry[i:j])
+			i = j
+			continue
+		case (c == 'q' || c == 'Q') && i+2 < len(qry) && qry[i+1] == '\'' && (i == 0 || !isIdentChar(qry[i-1])):
+			// alternative quoting: q'[...]'
+			end := qry[i+2]
+			switch end {
+			case '[':
+				end = ']'
+			case '{':
+				end = '}'
+			case '(':
+				end = ')'
+			case '<':
+				end = '>'
+			}
+			j := strings.Index(qry[i+3:], string([]byte{end, '\''}))
+			if j < 0 {
+				j = len(qry) - i
+			} else {
+				j += 5
+			}
+			buf.WriteByte('?')
+			i += j
+			continue
+		case (c == 'n' || c == 'N') && i+1 < len(qry) && qry[i+1] == '\'' && (i == 0 || !isIdentChar(qry[i-1])):
+			i++
+			fallthrough
+		case c == '\'':
+			j := i + 1
+			for j < len(qry) {
+				if qry[j] == '\'' {
+					if j+1 < len(qry) && qry[j+1] == '\'' {
+						j += 2
+						continue
+					}
+					j++
+					break
+				}
+				j++
+			}
+			buf.WriteByte('?')
+			i = j
+			continue
+		case '0' <= c && c <= '9' || c == '.' && i+1 < len(qry) && '0' <= qry[i+1] && qry[i+1] <= '9':
+			if i != 0 && isIdentChar(qry[i-1]) {
+				break
+			}
+			j := i + 1
+			for j < len(qry) && (isIdentChar(qry[j]) || qry[j] == '.' ||
+				(qry[j] == '+' || qry[j] == '-') && (qry[j-1] == 'e' || qry[j-1] == 'E')) {
+				j++
+			}
+			buf.WriteByte('?')
+			i = j
+			continue
+		case isIdentChar(c):
+			j := i + 1
+			for j < len(qry) && isIdentChar(qry[j]) {
+				j++
+			}
+			buf.WriteString(qry[i:j])
+			i = j
+			continue
+		}
+		buf.WriteByte(c)
+		i++
+	}
+	if newOffset < 0 && offset >= 0 {
+		newOffset = buf.Len()
+	}
+	return buf.String(), newOffset
+}
+
+func isIdentChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == '$' || c == '#' || c >= 0x80
+}
@@ -0,0 +1,49 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRedactSQL(t *testing.T) {
+	for in, want := range map[string]string{
+		"SELECT * FROM emp WHERE ename = 'SCOTT' AND sal > 1000.5": "SELECT * FROM emp WHERE ename = ? AND sal > ?",
+		"SELECT emp2.x FROM emp2 WHERE id = :1 AND y = 1e-3":       "SELECT emp2.x FROM emp2 WHERE id = :1 AND y = ?",
+		"UPDATE t SET s = 'it''s', n = N'árvíz' -- 'comment'":      "UPDATE t SET s = ?, n = ? -- 'comment'",
+		`SELECT "Col'1" FROM t WHERE a = q'[x'y]' AND b = .5`:      `SELECT "Col'1" FROM t WHERE a = ? AND b = ?`,
+		"ALTER USER scott IDENTIFIED BY \"tiger\" /* 'x' */":       "ALTER USER scott IDENTIFIED BY \"tiger\" /* 'x' */",
+		"SELECT 'unterminated FROM dual":                           "SELECT ?",
+	} {
+		if got := RedactSQL(in); got != want {
+			t.Errorf("%q: got %q, wanted %q", in, got, want)
+		}
+	}
+
+	qry, offset := redactSQLOffset("SELECT 'secret' FRM dual", 16)
+	if qry[offset:] != "FRM dual" {
+		t.Errorf("got %q at %d", qry, offset)
+	}
+}
+
+func TestQueryChecksumRedacted(t *testing.T) {
+	SetRedactSQL(true)
+	defer SetRedactSQL(false)
+	var rec recordingExecer
+	const qry = "SELECT * FROM emp WHERE ename = 'SCOTT'"
+	_, _, err := QueryChecksum(context.Background(), &rec, qry)
+	if err == nil {
+		t.Fatal("wanted error")
+	}
+	if rec.qry != qry {
+		t.Errorf("executed %q, wanted %q", rec.qry, qry)
+	}
+	if s := err.Error(); strings.Contains(s, "SCOTT") || !strings.Contains(s, "ename = ?") {
+		t.Errorf("error not redacted: %q", s)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+)
+
+// ExecRefCursors executes qry (a PL/SQL block) with args,
+// where each *(*sql.Rows) argument (as is, or as the Dest of an sql.Out, even in an sql.NamedArg)
+// receives the OUT REF CURSOR parameter as a regular *sql.Rows.
+//
+//	var rows *sql.Rows
+//	_, err := ExecRefCursors(ctx, conn, "BEGIN pkg.get_emps(:1, :2); END;", 10, sql.Out{Dest: &rows})
+//
+// Use an *sql.Conn or *sql.Tx, as the returned Rows use the session of ex:
+// with an *sql.DB, that session may be given to someone else meanwhile.
+// The returned Rows must be closed.
+func ExecRefCursors(ctx context.Context, ex interface {
+	Execer
+	Querier
+}, qry string, args ...interface{}) (sql.Result, error) {
+	type refCursor struct {
+		dest *(*sql.Rows)
+		rset driver.Rows
+	}
+	var cursors []*refCursor
+	outOf := func(dest **sql.Rows) sql.Out {
+		rc := refCursor{dest: dest}
+		cursors = append(cursors, &rc)
+		return sql.Out{Dest: &rc.rset}
+	}
+	args = append(make([]interface{}, 0, len(args)), args...)
+	for i, a := range args {
+		switch x := a.(type) {
+		case **sql.Rows:
+			args[i] = outOf(x)
+		case sql.Out:
+			if dest, ok := x.Dest.(**sql.Rows); ok {
+				args[i] = outOf(dest)
+			}
+		case sql.NamedArg:
+			if out, ok := x.Value.(sql.Out); ok {
+				if dest, ok := out.Dest.(**sql.Rows); ok {
+					x.Value = outOf(dest)
+					args[i] = x
+				}
+			}
+		}
+	}
+
+	res, err := ex.ExecContext(ctx, qry, args...)
+	if err != nil {
+		for _, rc := range cursors {
+			if rc.rset != nil {
+				rc.rset.Close()
+			}
+		}
+		return res, err
+	}
+	for i, rc := range cursors {
+		if rc.rset == nil {
+			*rc.dest = nil
+			continue
+		}
+		if *rc.dest, err = WrapRows(ctx, ex, rc.rset); err != nil {
+			for _, rc := range cursors[:i] {
+				if *rc.dest != nil {
+					(*rc.dest).Close()
+				}
+			}
+			for _, rc := range cursors[i:] {
+				if rc.rset != nil {
+					rc.rset.Close()
+				}
+			}
+			return res, fmt.Errorf("wrap REF CURSOR %d: %w", i+1, err)
+		}
+	}
+	return res, nil
+}
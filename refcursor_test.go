@@ -0,0 +1,39 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestExecRefCursorsArgs(t *testing.T) {
+	var ex recordingExecer
+	var rows1, rows2, rows3 *sql.Rows
+	if _, err := ExecRefCursors(context.Background(), &ex, "BEGIN p(:1, :2, :3, :4); END;",
+		10, sql.Out{Dest: &rows1}, sql.Named("x", sql.Out{Dest: &rows2}), &rows3,
+	); err != nil {
+		t.Fatal(err)
+	}
+	if len(ex.args) != 4 || ex.args[0] != 10 {
+		t.Fatalf("got %#v", ex.args)
+	}
+	for i, a := range ex.args[1:] {
+		if na, ok := a.(sql.NamedArg); ok {
+			a = na.Value
+		}
+		if out, ok := a.(sql.Out); !ok {
+			t.Errorf("%d. got %T, wanted sql.Out", i+1, a)
+		} else if _, ok := out.Dest.(*driver.Rows); !ok {
+			t.Errorf("%d. got %T, wanted *driver.Rows", i+1, out.Dest)
+		}
+	}
+	if rows1 != nil || rows2 != nil || rows3 != nil {
+		t.Error("wanted nil rows for NULL cursors")
+	}
+}
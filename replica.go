@@ -0,0 +1,135 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReplicaLag is the replication state of the database, as seen from the connected instance.
+type ReplicaLag struct {
+	// Role is the DATABASE_ROLE: PRIMARY, PHYSICAL STANDBY, LOGICAL STANDBY, SNAPSHOT STANDBY.
+	Role string
+	// OpenMode is the OPEN_MODE, "READ ONLY WITH APPLY" for an Active Data Guard standby.
+	OpenMode string
+	// CurrentSCN is the current SCN on the primary, the last applied SCN on a standby.
+	CurrentSCN uint64
+	// ApplyLag and TransportLag are from V$DATAGUARD_STATS, zero on the primary.
+	ApplyLag, TransportLag time.Duration
+	// LagUnknown is true on a standby when the lag hasn't been computed (no value in V$DATAGUARD_STATS).
+	LagUnknown bool
+}
+
+// IsStandby reports whether the connected database is a standby.
+func (L ReplicaLag) IsStandby() bool { return strings.HasSuffix(L.Role, "STANDBY") }
+
+// Stale reports whether reads from this database may be older than threshold:
+// the apply lag is greater than threshold, or unknown.
+// It is always false on the primary.
+func (L ReplicaLag) Stale(threshold time.Duration) bool {
+	return L.IsStandby() && (L.LagUnknown || L.ApplyLag > threshold)
+}
+
+// GetReplicaLag returns the replication state of the database: role, current (last applied) SCN and lags.
+//
+// Needs SELECT privilege on V$DATABASE and V$DATAGUARD_STATS (such as SELECT_CATALOG_ROLE).
+func GetReplicaLag(ctx context.Context, q Querier) (ReplicaLag, error) {
+	var L ReplicaLag
+	const qry = "SELECT database_role, open_mode, TO_CHAR(current_scn) FROM v$database"
+	rows, err := q.QueryContext(ctx, qry)
+	if err != nil {
+		return L, fmt.Errorf("%s: %w", sqlForLog(qry), err)
+	}
+	var scn string
+	if rows.Next() {
+		err = rows.Scan(&L.Role, &L.OpenMode, &scn)
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	rows.Close()
+	if err != nil {
+		return L, fmt.Errorf("%s: %w", sqlForLog(qry), err)
+	}
+	if L.CurrentSCN, err = strconv.ParseUint(scn, 10, 64); err != nil {
+		return L, fmt.Errorf("parse SCN %q: %w", scn, err)
+	}
+	if !L.IsStandby() {
+		return L, nil
+	}
+
+	const statsQry = "SELECT name, value FROM v$dataguard_stats WHERE name IN ('apply lag', 'transport lag')"
+	if rows, err = q.QueryContext(ctx, statsQry); err != nil {
+		return L, fmt.Errorf("%s: %w", statsQry, err)
+	}
+	defer rows.Close()
+	L.LagUnknown = true
+	for rows.Next() {
+		var name string
+		var value sql.NullString
+		if err = rows.Scan(&name, &value); err != nil {
+			return L, fmt.Errorf("%s: %w", statsQry, err)
+		}
+		if !value.Valid || value.String == "" {
+			continue
+		}
+		d, err := parseDataGuardInterval(value.String)
+		if err != nil {
+			return L, fmt.Errorf("parse %s %q: %w", name, value.String, err)
+		}
+		if name == "apply lag" {
+			L.ApplyLag, L.LagUnknown = d, false
+		} else {
+			L.TransportLag = d
+		}
+	}
+	return L, rows.Err()
+}
+
+var errBadDataGuardInterval = errors.New("interval must be [+-]DD HH:MI:SS[.FF]")
+
+// parseDataGuardInterval parses the "+DD HH:MI:SS" format of V$DATAGUARD_STATS.
+func parseDataGuardInterval(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimLeft(s, "+-")
+	i := strings.IndexByte(s, ' ')
+	if i < 0 {
+		return 0, errBadDataGuardInterval
+	}
+	days, err := strconv.ParseUint(s[:i], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", s, errBadDataGuardInterval)
+	}
+	parts := strings.Split(s[i+1:], ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("%s: %w", s, errBadDataGuardInterval)
+	}
+	hours, err := strconv.ParseUint(parts[0], 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", s, errBadDataGuardInterval)
+	}
+	minutes, err := strconv.ParseUint(parts[1], 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", s, errBadDataGuardInterval)
+	}
+	seconds, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", s, errBadDataGuardInterval)
+	}
+	d := time.Duration(days)*24*time.Hour + time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute + time.Duration(seconds*float64(time.Second))
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
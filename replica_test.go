@@ -0,0 +1,53 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDataGuardInterval(t *testing.T) {
+	for _, tc := range []struct {
+		In   string
+		Want time.Duration
+		Err  bool
+	}{
+		{In: "+00 00:00:00", Want: 0},
+		{In: "+00 00:00:07", Want: 7 * time.Second},
+		{In: "+01 02:03:04", Want: 26*time.Hour + 3*time.Minute + 4*time.Second},
+		{In: "+00 00:00:01.5", Want: 1500 * time.Millisecond},
+		{In: "-00 00:01:00", Want: -time.Minute},
+		{In: "00:00:01", Err: true},
+		{In: "+00 00:01", Err: true},
+	} {
+		got, err := parseDataGuardInterval(tc.In)
+		if tc.Err {
+			if err == nil {
+				t.Errorf("%q: wanted error, got %v", tc.In, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %+v", tc.In, err)
+		} else if got != tc.Want {
+			t.Errorf("%q: got %v, wanted %v", tc.In, got, tc.Want)
+		}
+	}
+}
+
+func TestReplicaLagStale(t *testing.T) {
+	if (ReplicaLag{Role: "PRIMARY"}).Stale(0) {
+		t.Error("primary is stale")
+	}
+	if !(ReplicaLag{Role: "PHYSICAL STANDBY", LagUnknown: true}).Stale(time.Hour) {
+		t.Error("unknown lag is not stale")
+	}
+	L := ReplicaLag{Role: "PHYSICAL STANDBY", ApplyLag: 5 * time.Second}
+	if L.Stale(10*time.Second) || !L.Stale(time.Second) {
+		t.Errorf("%+v", L)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ResilientSubscription is a Subscription which survives the loss of its connection
+// (such as a failover): it checks the connection periodically, and on failure (or deregistration)
+// it subscribes again, on a new connection, re-registers the queries,
+// and sends an EvtPossiblyMissed event, so the consumer can reconcile its state.
+type ResilientSubscription struct {
+	db       *sql.DB
+	conn     *sql.Conn
+	subscr   *Subscription
+	callback func(Event)
+	reset    chan struct{}
+	done     chan struct{}
+	cancel   context.CancelFunc
+	name     string
+	queries  []string
+	options  []SubscriptionOption
+	// connect and ping are subscribe and pingConn, replaceable in tests.
+	connect, ping func(context.Context) error
+	mu            sync.Mutex
+}
+
+// NewResilientSubscription subscribes on a dedicated connection of db (which needs enableEvents=1),
+// registers the queries, and checks the connection every checkInterval (a minute by default).
+func NewResilientSubscription(ctx context.Context, db *sql.DB, name string, callback func(Event), queries []string, checkInterval time.Duration, options ...SubscriptionOption) (*ResilientSubscription, error) {
+	if checkInterval <= 0 {
+		checkInterval = time.Minute
+	}
+	rs := ResilientSubscription{
+		db: db, name: name, callback: callback,
+		queries: queries, options: options,
+		reset: make(chan struct{}, 1), done: make(chan struct{}),
+	}
+	rs.connect, rs.ping = rs.subscribe, rs.pingConn
+	if err := rs.subscribe(ctx); err != nil {
+		return nil, err
+	}
+	var loopCtx context.Context
+	loopCtx, rs.cancel = context.WithCancel(context.Background())
+	go rs.loop(loopCtx, checkInterval)
+	return &rs, nil
+}
+
+func (rs *ResilientSubscription) onEvent(e Event) {
+	if e.Type == EvtDereg || e.Type == EvtShutdown || e.Type == EvtShutdownAny {
+		select {
+		case rs.reset <- struct{}{}:
+		default:
+		}
+	}
+	rs.callback(e)
+}
+
+// subscribe on a new connection.
+func (rs *ResilientSubscription) subscribe(ctx context.Context) error {
+	conn, err := rs.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	var subscr *Subscription
+	if err = Raw(ctx, conn, func(c Conn) error {
+		var err error
+		if subscr, err = c.NewSubscription(rs.name, rs.onEvent, rs.options...); err != nil {
+			return err
+		}
+		for _, qry := range rs.queries {
+			if err = subscr.Register(qry); err != nil {
+				return fmt.Errorf("register %s: %w", sqlForLog(qry), err)
+			}
+		}
+		return nil
+	}); err != nil {
+		if subscr != nil {
+			_ = subscr.Close()
+		}
+		conn.Close()
+		return err
+	}
+	rs.mu.Lock()
+	rs.conn, rs.subscr = conn, subscr
+	rs.mu.Unlock()
+	return nil
+}
+
+// pingConn pings the connection of the subscription.
+func (rs *ResilientSubscription) pingConn(ctx context.Context) error {
+	rs.mu.Lock()
+	conn := rs.conn
+	rs.mu.Unlock()
+	if conn == nil {
+		return driver.ErrBadConn
+	}
+	return conn.PingContext(ctx)
+}
+
+// unsubscribe and close the connection.
+func (rs *ResilientSubscription) unsubscribe() error {
+	rs.mu.Lock()
+	conn, subscr := rs.conn, rs.subscr
+	rs.conn, rs.subscr = nil, nil
+	rs.mu.Unlock()
+	var err error
+	if subscr != nil {
+		err = subscr.Close()
+	}
+	if conn != nil {
+		if cErr := conn.Close(); cErr != nil && err == nil {
+			err = cErr
+		}
+	}
+	return err
+}
+
+func (rs *ResilientSubscription) loop(ctx context.Context, checkInterval time.Duration) {
+	defer close(rs.done)
+	clock := getClock()
+	ticks, stopTicker := clock.NewTicker(checkInterval)
+	defer stopTicker()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-rs.reset:
+		case <-ticks:
+			pctx, cancel := context.WithTimeout(ctx, checkInterval)
+			err := rs.ping(pctx)
+			cancel()
+			if err == nil || ctx.Err() != nil {
+				continue
+			}
+		}
+
+		logger := getLogger()
+		_ = rs.unsubscribe()
+		for wait := time.Second; ; wait *= 2 {
+			err := rs.connect(ctx)
+			if err == nil {
+				break
+			}
+			if logger != nil {
+				logger.Log("msg", "resubscribe", "name", rs.name, "error", err)
+			}
+			if wait > checkInterval {
+				wait = checkInterval
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-clock.After(wait):
+			}
+		}
+		rs.callback(Event{Type: EvtPossiblyMissed})
+	}
+}
+
+// Close the subscription and its connection.
+func (rs *ResilientSubscription) Close() error {
+	if rs == nil || rs.cancel == nil {
+		return errors.New("not subscribed")
+	}
+	rs.cancel()
+	<-rs.done
+	return rs.unsubscribe()
+}
@@ -0,0 +1,88 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestResilientSubscriptionLoop(t *testing.T) {
+	fc := newFakeClock()
+	defer setClock(fc)()
+	waiters := func(want int) {
+		t.Helper()
+		for i := 0; i < 1000; i++ {
+			fc.mu.Lock()
+			n := len(fc.waiters)
+			fc.mu.Unlock()
+			if n == want {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+		t.Fatalf("wanted %d waiters", want)
+	}
+	events := make(chan Event, 4)
+	pings, connects := make(chan error), make(chan error)
+	rs := ResilientSubscription{
+		callback: func(e Event) { events <- e },
+		reset:    make(chan struct{}, 1), done: make(chan struct{}),
+		ping:    func(context.Context) error { return <-pings },
+		connect: func(context.Context) error { return <-connects },
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go rs.loop(ctx, time.Minute)
+	waiters(1)
+	expect := func(want EventType) {
+		t.Helper()
+		select {
+		case e := <-events:
+			if e.Type != want {
+				t.Errorf("got %v, wanted %v", e.Type, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no %v event", want)
+		}
+	}
+
+	// a successful check does not resubscribe
+	fc.Advance(time.Minute)
+	pings <- nil
+	select {
+	case e := <-events:
+		t.Errorf("got %v after a successful ping", e.Type)
+	case connects <- nil:
+		t.Error("resubscribed after a successful ping")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// a failed check resubscribes, retrying after a second
+	fc.Advance(time.Minute)
+	pings <- errors.New("connection lost")
+	connects <- errors.New("still down")
+	waiters(2)
+	fc.Advance(time.Second)
+	connects <- nil
+	expect(EvtPossiblyMissed)
+
+	// deregistration resubscribes at once
+	rs.onEvent(Event{Type: EvtDereg})
+	expect(EvtDereg)
+	connects <- nil
+	expect(EvtPossiblyMissed)
+
+	cancel()
+	select {
+	case <-rs.done:
+	case <-time.After(time.Second):
+		t.Fatal("loop did not stop")
+	}
+	waiters(0)
+}
@@ -0,0 +1,108 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// HashRows writes the canonicalized values of all the rows into h, and returns the number of rows.
+//
+// The canonical form does not depend on the session settings or column definitions:
+// NUMBERs are compared by value (1.50 = 1.5 = 3/2, and an integer column equals a NUMBER(*,2) with the same values),
+// times are in UTC, and NULL differs from the empty string and zero.
+// LOBs are read into memory, so do not use LobAsReader.
+//
+// The order of the rows matters, so use a deterministic ORDER BY!
+// The rows are not closed.
+func HashRows(rows *sql.Rows, h hash.Hash) (int64, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	values := make([]interface{}, len(cols))
+	dest := make([]interface{}, len(cols))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	var n int64
+	var buf []byte
+	for rows.Next() {
+		if err = rows.Scan(dest...); err != nil {
+			return n, err
+		}
+		buf = buf[:0]
+		for i, v := range values {
+			if buf, err = appendCanonical(buf, v); err != nil {
+				return n, fmt.Errorf("row %d column %d (%s): %w", n+1, i, cols[i], err)
+			}
+		}
+		if _, err = h.Write(buf); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, rows.Err()
+}
+
+// QueryChecksum returns the SHA-256 checksum of the results of the query (see HashRows), and the number of rows.
+func QueryChecksum(ctx context.Context, q Querier, qry string, args ...interface{}) ([]byte, int64, error) {
+	rows, err := q.QueryContext(ctx, qry, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", sqlForLog(qry), err)
+	}
+	defer rows.Close()
+	h := sha256.New()
+	n, err := HashRows(rows, h)
+	if err != nil {
+		return nil, n, fmt.Errorf("%s: %w", sqlForLog(qry), err)
+	}
+	return h.Sum(nil), n, nil
+}
+
+// appendCanonical appends the type tag and the length-prefixed canonical form of v.
+func appendCanonical(dst []byte, v interface{}) ([]byte, error) {
+	var tag byte
+	var s string
+	switch x := v.(type) {
+	case nil:
+		return append(dst, 'N'), nil
+	case string:
+		tag, s = 's', x
+	case []byte:
+		tag, s = 'b', string(x)
+	case Number:
+		r, ok := new(big.Rat).SetString(string(x))
+		if !ok {
+			return dst, fmt.Errorf("bad number %q", x)
+		}
+		tag, s = 'n', r.RatString()
+	case int64:
+		tag, s = 'n', strconv.FormatInt(x, 10)
+	case float64:
+		tag, s = 'f', strconv.FormatFloat(x, 'g', -1, 64)
+	case bool:
+		tag, s = 'B', strconv.FormatBool(x)
+	case time.Time:
+		tag, s = 't', x.UTC().Format(time.RFC3339Nano)
+	case time.Duration:
+		tag, s = 'd', strconv.FormatInt(int64(x), 10)
+	default:
+		return dst, fmt.Errorf("cannot canonicalize %T", v)
+	}
+	var a [binary.MaxVarintLen64 + 1]byte
+	a[0] = tag
+	dst = append(dst, a[:1+binary.PutUvarint(a[1:], uint64(len(s)))]...)
+	return append(dst, s...), nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestAppendCanonical(t *testing.T) {
+	canon := func(v interface{}) []byte {
+		b, err := appendCanonical(nil, v)
+		if err != nil {
+			t.Fatalf("%#v: %+v", v, err)
+		}
+		return b
+	}
+	for _, tc := range []struct {
+		A, B interface{}
+	}{
+		{Number("1.50"), Number("1.5")},
+		{Number("15E-1"), Number("1.5")},
+		{Number("-0"), Number("0")},
+		{Number("42"), int64(42)},
+		{time.Date(2022, 1, 2, 3, 4, 5, 0, time.FixedZone("+01", 3600)), time.Date(2022, 1, 2, 2, 4, 5, 0, time.UTC)},
+	} {
+		if a, b := canon(tc.A), canon(tc.B); !bytes.Equal(a, b) {
+			t.Errorf("%#v=%q != %#v=%q", tc.A, a, tc.B, b)
+		}
+	}
+	for _, tc := range []struct {
+		A, B interface{}
+	}{
+		{nil, ""},
+		{"", []byte{}},
+		{"1", Number("1")},
+		{Number("0"), nil},
+	} {
+		if a, b := canon(tc.A), canon(tc.B); bytes.Equal(a, b) {
+			t.Errorf("%#v == %#v (%q)", tc.A, tc.B, a)
+		}
+	}
+	// the length prefix keeps the column boundaries
+	ab, _ := appendCanonical(canon("a"), "bc")
+	abc, _ := appendCanonical(canon("ab"), "c")
+	if bytes.Equal(ab, abc) {
+		t.Errorf("%q == %q", ab, abc)
+	}
+	if _, err := appendCanonical(nil, Number("x")); err == nil {
+		t.Error("wanted error for bad number")
+	}
+}
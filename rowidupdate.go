@@ -0,0 +1,240 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultRowIDUpdateBatchSize is the default number of rows read and written back at once by RowIDUpdate.
+const DefaultRowIDUpdateBatchSize = 1024
+
+// RowIDUpdate reads the rows of a table in ROWID order, passes them to a transform function,
+// and writes the changed ones back with an array UPDATE ... WHERE ROWID = :rid.
+//
+// Each batch is committed in its own transaction, and Checkpoint is called with the last ROWID,
+// so an interrupted run can be restarted from there.
+//
+// The rows are not locked while being transformed, so this is meant for migrations,
+// not concurrently modified tables.
+type RowIDUpdate struct {
+	// Checkpoint is called after each committed batch with the last processed ROWID.
+	Checkpoint func(ctx context.Context, lastRowID string) error
+	// Table to update.
+	Table string
+	// Where is an optional additional filter for the rows.
+	Where string
+	// Columns to read and write back.
+	Columns []string
+	// BatchSize is DefaultRowIDUpdateBatchSize by default.
+	BatchSize int
+}
+
+// Run the pipeline, starting after the startAfter ROWID (from the beginning if empty),
+// till all the rows are processed.
+//
+// transform gets the column values, modifies them in place, and reports whether the row has changed.
+// The values are as returned by Scan into an interface{} (string, Number, time.Time, []byte, nil ...).
+//
+// Returns the last processed ROWID, even on error.
+func (U RowIDUpdate) Run(ctx context.Context, db TxBeginner, startAfter string,
+	transform func(ctx context.Context, values []interface{}) (changed bool, err error),
+) (string, error) {
+	if U.Table == "" || len(U.Columns) == 0 {
+		return startAfter, errors.New("RowIDUpdate: empty Table or Columns")
+	}
+	batchSize := U.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultRowIDUpdateBatchSize
+	}
+	cols := strings.Join(U.Columns, ", ")
+	where := ""
+	if U.Where != "" {
+		where = " AND (" + U.Where + ")"
+	}
+	qryFirst := "SELECT * FROM (SELECT ROWIDTOCHAR(ROWID), " + cols + " FROM " + U.Table +
+		" WHERE 1=1" + where + " ORDER BY ROWID) WHERE ROWNUM <= :1"
+	qryNext := "SELECT * FROM (SELECT ROWIDTOCHAR(ROWID), " + cols + " FROM " + U.Table +
+		" WHERE ROWID > CHARTOROWID(:1)" + where + " ORDER BY ROWID) WHERE ROWNUM <= :2"
+	var buf strings.Builder
+	buf.WriteString("UPDATE " + U.Table + " SET ")
+	for i, col := range U.Columns {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, "%s = :%d", col, i+1)
+	}
+	fmt.Fprintf(&buf, " WHERE ROWID = CHARTOROWID(:%d)", len(U.Columns)+1)
+	qryUpd := buf.String()
+
+	last := startAfter
+	for {
+		n, err := U.runBatch(ctx, db, batchSize, last, qryFirst, qryNext, qryUpd, transform)
+		if err != nil || n.rowid == "" {
+			return last, err
+		}
+		last = n.rowid
+		if U.Checkpoint != nil {
+			if err = U.Checkpoint(ctx, last); err != nil {
+				return last, err
+			}
+		}
+		if n.count < batchSize {
+			return last, nil
+		}
+	}
+}
+
+type rowIDBatchResult struct {
+	rowid string
+	count int
+}
+
+func (U RowIDUpdate) runBatch(ctx context.Context, db TxBeginner,
+	batchSize int, last, qryFirst, qryNext, qryUpd string,
+	transform func(ctx context.Context, values []interface{}) (changed bool, err error),
+) (rowIDBatchResult, error) {
+	var res rowIDBatchResult
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	qry, params := qryNext, []interface{}{last, batchSize}
+	if last == "" {
+		qry, params = qryFirst, []interface{}{batchSize}
+	}
+	params = append(params, FetchArraySize(batchSize), PrefetchCount(batchSize+1))
+	rows, err := tx.QueryContext(ctx, qry, params...)
+	if err != nil {
+		return res, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	var changed [][]interface{}
+	var rowids []string
+	for rows.Next() {
+		var rowid string
+		values := make([]interface{}, len(U.Columns))
+		dest := make([]interface{}, 1+len(values))
+		dest[0] = &rowid
+		for i := range values {
+			dest[i+1] = &values[i]
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return res, fmt.Errorf("scan %s: %w", qry, err)
+		}
+		res.rowid, res.count = rowid, res.count+1
+		ok, err := transform(ctx, values)
+		if err != nil {
+			return rowIDBatchResult{}, fmt.Errorf("transform %s: %w", rowid, err)
+		}
+		if ok {
+			changed = append(changed, values)
+			rowids = append(rowids, rowid)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return rowIDBatchResult{}, fmt.Errorf("%s: %w", qry, err)
+	}
+	rows.Close()
+
+	if len(changed) != 0 {
+		columns, err := columnSlices(changed, len(U.Columns))
+		if err != nil {
+			return rowIDBatchResult{}, err
+		}
+		if _, err = tx.ExecContext(ctx, qryUpd, append(columns, rowids)...); err != nil {
+			return rowIDBatchResult{}, fmt.Errorf("%s: %w", qryUpd, err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return rowIDBatchResult{}, err
+	}
+	return res, nil
+}
+
+// columnSlices converts the rows into typed column slices, usable for array DML.
+//
+// A nil is bound as NULL, a column with only nils is bound as []string.
+func columnSlices(rows [][]interface{}, n int) ([]interface{}, error) {
+	columns := make([]interface{}, n)
+	for j := 0; j < n; j++ {
+		var col interface{}
+		for i, row := range rows {
+			v := row[j]
+			if v == nil {
+				continue
+			}
+			var ok bool
+			switch x := v.(type) {
+			case string:
+				if col == nil {
+					col = make([]string, len(rows))
+				}
+				var a []string
+				if a, ok = col.([]string); ok {
+					a[i] = x
+				}
+			case Number:
+				if col == nil {
+					col = make([]Number, len(rows))
+				}
+				var a []Number
+				if a, ok = col.([]Number); ok {
+					a[i] = x
+				}
+			case []byte:
+				if col == nil {
+					col = make([][]byte, len(rows))
+				}
+				var a [][]byte
+				if a, ok = col.([][]byte); ok {
+					a[i] = x
+				}
+			case time.Time:
+				if col == nil {
+					col = make([]time.Time, len(rows))
+				}
+				var a []time.Time
+				if a, ok = col.([]time.Time); ok {
+					a[i] = x
+				}
+			case int64:
+				if col == nil {
+					col = make([]sql.NullInt64, len(rows))
+				}
+				var a []sql.NullInt64
+				if a, ok = col.([]sql.NullInt64); ok {
+					a[i] = sql.NullInt64{Int64: x, Valid: true}
+				}
+			case float64:
+				if col == nil {
+					col = make([]sql.NullFloat64, len(rows))
+				}
+				var a []sql.NullFloat64
+				if a, ok = col.([]sql.NullFloat64); ok {
+					a[i] = sql.NullFloat64{Float64: x, Valid: true}
+				}
+			default:
+				return nil, fmt.Errorf("column %d: unsupported type %T", j, v)
+			}
+			if !ok {
+				return nil, fmt.Errorf("column %d: mixed types %T and %T", j, col, v)
+			}
+		}
+		if col == nil {
+			col = make([]string, len(rows))
+		}
+		columns[j] = col
+	}
+	return columns, nil
+}
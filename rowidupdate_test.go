@@ -0,0 +1,44 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestColumnSlices(t *testing.T) {
+	now := time.Now()
+	rows := [][]interface{}{
+		{"a", Number("1"), int64(2), nil, now, []byte("x")},
+		{nil, nil, nil, nil, time.Time{}, nil},
+		{"c", Number("3"), int64(4), nil, now, []byte("z")},
+	}
+	got, err := columnSlices(rows, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{
+		[]string{"a", "", "c"},
+		[]Number{"1", "", "3"},
+		[]sql.NullInt64{{Int64: 2, Valid: true}, {}, {Int64: 4, Valid: true}},
+		[]string{"", "", ""},
+		[]time.Time{now, {}, now},
+		[][]byte{[]byte("x"), nil, []byte("z")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, wanted %#v", got, want)
+	}
+
+	if _, err = columnSlices([][]interface{}{{"a"}, {int64(1)}}, 1); err == nil {
+		t.Error("wanted error for mixed types")
+	}
+	if _, err = columnSlices([][]interface{}{{true}}, 1); err == nil {
+		t.Error("wanted error for unsupported type")
+	}
+}
@@ -42,6 +42,8 @@ type rows struct {
 	err       error
 	nextRsErr error
 	done      chan struct{}
+	// resetTimeout resets the call timeout of the fetch deadline, set with done.
+	resetTimeout func()
 	*statement
 	origSt         *statement
 	nextRs         *C.dpiStmt
@@ -50,7 +52,14 @@ type rows struct {
 	vars           []*C.dpiVar
 	bufferRowIndex C.uint32_t
 	fetched        C.uint32_t
-	fromData       bool
+	// lastFetchAt is the time of the last fetch, with AdaptiveFetch.
+	lastFetchAt time.Time
+	// fetchVis describe the fetch variables, for re-allocating them with AdaptiveFetch.
+	fetchVis []varInfo
+	// fetchSize and maxFetchSize are the current and maximal fetch array size with AdaptiveFetch,
+	// lastFetched is the number of rows of the last fetch, if it filled the fetch array.
+	fetchSize, maxFetchSize, lastFetched C.uint32_t
+	fromData                             bool
 }
 
 // Columns returns the names of the columns. The number of
@@ -70,17 +79,22 @@ func (r *rows) Close() error {
 	if r == nil {
 		return nil
 	}
-	vars, st, nextRs, done := r.vars, r.statement, r.nextRs, r.done
-	r.columns, r.vars, r.data, r.statement, r.nextRs, r.done = nil, nil, nil, nil, nil, nil
+	vars, st, nextRs, done, resetTimeout := r.vars, r.statement, r.nextRs, r.done, r.resetTimeout
+	r.columns, r.vars, r.data, r.statement, r.nextRs, r.done, r.resetTimeout = nil, nil, nil, nil, nil, nil, nil
+	if resetTimeout != nil {
+		resetTimeout()
+	}
 	if done != nil {
 		close(done)
 	}
 	fromData := r.fromData
 	r.fromData = false
+	var mem *memStats
+	if st != nil && st.conn != nil {
+		mem = st.mem
+	}
 	for _, v := range vars[:cap(vars)] {
-		if v != nil {
-			C.dpiVar_release(v)
-		}
+		mem.releaseVar(v)
 	}
 	if nextRs != nil {
 		if logger := getLogger(); logger != nil {
@@ -230,6 +244,19 @@ func (r *rows) ColumnTypeScanType(index int) reflect.Type {
 		C.DPI_ORACLE_TYPE_LONG_RAW:
 		return reflect.TypeOf([]byte(nil))
 	case C.DPI_ORACLE_TYPE_NUMBER:
+		switch r.statement.NumberColumnAs(index) {
+		case NumberScanString:
+			return reflect.TypeOf("")
+		case NumberScanNumber:
+			return reflect.TypeOf(Number(""))
+		case NumberScanInt64:
+			return reflect.TypeOf(int64(0))
+		case NumberScanFloat64:
+			return reflect.TypeOf(float64(0))
+		}
+		if r.statement.FetchNumberAsString() {
+			return reflect.TypeOf("")
+		}
 		switch col.NativeType {
 		case C.DPI_NATIVE_TYPE_INT64:
 			return reflect.TypeOf(int64(0))
@@ -284,15 +311,30 @@ const debugRowsNext = false
 // Next should return io.EOF when there are no more rows.
 //
 // As with all Objects, you MUST call Close on the returned Object instances when they're not needed anymore!
-func (r *rows) Next(dest []driver.Value) error {
+func (r *rows) Next(dest []driver.Value) (retErr error) {
 	if r.err != nil {
 		return r.err
 	}
 	if len(dest) != len(r.columns) {
 		return fmt.Errorf("column count mismatch: we have %d columns, but given %d destination", len(r.columns), len(dest))
 	}
+	if len(r.columns) == 0 {
+		// not a query (a PL/SQL block returning implicit results): no rows, only NextResultSet
+		return io.EOF
+	}
 	logger := getLogger()
 
+	if r.statement != nil && r.conn != nil {
+		leave, err := r.conn.enter("Next")
+		if err != nil {
+			return err
+		}
+		defer leave()
+		if r.conn.params.RecoverPanics {
+			defer r.conn.recoverPanic("Next", r.query, &retErr)
+		}
+	}
+
 	runtime.LockOSThread()
 	defer runtime.UnlockOSThread()
 
@@ -307,17 +349,20 @@ func (r *rows) Next(dest []driver.Value) error {
 				if _, hasDeadline := r.statement.ctx.Deadline(); hasDeadline {
 					r.done = make(chan struct{})
 					// handle deadline for dpiStmt_fetchRows. context reused from stmt
-					if err := r.statement.handleDeadline(ctx, r.done); err != nil {
+					resetTimeout, err := r.statement.handleDeadline(ctx, r.done)
+					if err != nil {
 						return err
 					}
+					r.resetTimeout = resetTimeout
 				}
 			}
 		}
 		if r.done != nil {
 			defer func() {
 				if r.err != nil && r.done != nil {
+					r.resetTimeout()
 					close(r.done)
-					r.done = nil
+					r.done, r.resetTimeout = nil, nil
 				}
 			}()
 		}
@@ -325,7 +370,17 @@ func (r *rows) Next(dest []driver.Value) error {
 		var moreRows C.int
 		var start time.Time
 		maxRows := C.uint32_t(r.statement.FetchArraySize())
+		if r.fetchSize != 0 {
+			maxRows = r.fetchSize
+		}
 		r.statement.Lock()
+		if r.fetchSize != 0 && r.fetchSize < r.maxFetchSize {
+			// all the rows of the previous fetch have been consumed, so grow for the consumption rate
+			if err := r.growFetchSize(); err != nil && logger != nil {
+				logger.Log("msg", "growFetchSize", "error", err)
+			}
+			maxRows = r.fetchSize
+		}
 		if debugRowsNext {
 			fmt.Printf("fetching max=%d\n", maxRows)
 			start = time.Now()
@@ -334,6 +389,12 @@ func (r *rows) Next(dest []driver.Value) error {
 			return C.dpiStmt_fetchRows(r.dpiStmt, maxRows, &r.bufferRowIndex, &r.fetched, &moreRows)
 		})
 		failed := err != nil
+		if r.fetchSize != 0 {
+			r.lastFetched, r.lastFetchAt = 0, getClock().Now()
+			if !failed && r.fetched == maxRows {
+				r.lastFetched = r.fetched
+			}
+		}
 		if debugRowsNext {
 			fmt.Printf("failed=%t bri=%d fetched=%d more=%d data=%d cols=%d dur=%s\n", failed, r.bufferRowIndex, r.fetched, moreRows, len(r.data), len(r.columns), time.Since(start))
 		}
@@ -342,11 +403,12 @@ func (r *rows) Next(dest []driver.Value) error {
 			if logger != nil {
 				logger.Log("msg", "fetch", "error", err)
 			}
+			cols := r.columns
 			_ = r.Close()
 			if strings.Contains(err.Error(), "DPI-1039: statement was already closed") {
 				r.err = io.EOF
 			} else {
-				r.err = fmt.Errorf("Next: %w", err)
+				r.err = fmt.Errorf("Next: %w", charsetConversionError(err, cols))
 			}
 			return r.err
 		}
@@ -377,7 +439,7 @@ func (r *rows) Next(dest []driver.Value) error {
 	//fmt.Printf("data=%#v\n", r.data)
 
 	nullDate := r.statement.NullDate()
-	nass := r.statement.NumberAsString()
+	nass, fnas := r.statement.NumberAsString(), r.statement.FetchNumberAsString()
 
 	//fmt.Printf("bri=%d fetched=%d\n", r.bufferRowIndex, r.fetched)
 	//fmt.Printf("data=%#v\n", r.data[0][r.bufferRowIndex])
@@ -405,11 +467,16 @@ func (r *rows) Next(dest []driver.Value) error {
 				dest[i] = ""
 				continue
 			}
+			var s string
 			if b.length < 10 {
 				bb := ((*[1 << 30]byte)((unsafe.Pointer(b.ptr))))[:int(b.length):int(b.length)]
-				dest[i] = internBytes(bb)
+				s = internBytes(bb)
 			} else {
-				dest[i] = C.GoStringN(b.ptr, C.int(b.length))
+				s = C.GoStringN(b.ptr, C.int(b.length))
+			}
+			var err error
+			if dest[i], err = r.statement.checkUTF8(i, col.Name, s); err != nil {
+				return err
 			}
 
 		case C.DPI_ORACLE_TYPE_NUMBER:
@@ -420,10 +487,20 @@ func (r *rows) Next(dest []driver.Value) error {
 			switch col.NativeType {
 			case C.DPI_NATIVE_TYPE_INT64:
 				//dest[i] = int64(C.dpiData_getInt64(d))
-				dest[i] = *((*int64)(unsafe.Pointer(&d.value)))
+				n := *((*int64)(unsafe.Pointer(&d.value)))
+				if fnas {
+					dest[i] = strconv.FormatInt(n, 10)
+				} else {
+					dest[i] = n
+				}
 			case C.DPI_NATIVE_TYPE_UINT64:
 				//dest[i] = uint64(C.dpiData_getUint64(d))
-				dest[i] = *((*uint64)(unsafe.Pointer(&d.value)))
+				n := *((*uint64)(unsafe.Pointer(&d.value)))
+				if fnas {
+					dest[i] = strconv.FormatUint(n, 10)
+				} else {
+					dest[i] = n
+				}
 			case C.DPI_NATIVE_TYPE_FLOAT:
 				//dest[i] = float32(C.dpiData_getFloat(d))
 				//dest[i] = printFloat(float64(C.dpiData_getFloat(d)))
@@ -449,6 +526,12 @@ func (r *rows) Next(dest []driver.Value) error {
 					logger.Log("msg", "b", "i", i, "ptr", b.ptr, "length", b.length, "typ", col.NativeType, "int64", C.dpiData_getInt64(d), "dest", dest[i])
 				}
 			}
+			if typ := r.statement.NumberColumnAs(i); typ != NumberScanDefault {
+				var err error
+				if dest[i], err = typ.convert(dest[i]); err != nil {
+					return fmt.Errorf("column %d (%s): %w", i, col.Name, err)
+				}
+			}
 			if false && logger != nil {
 				logger.Log("msg", "num", "t", col.NativeType, "i", i, "dest", fmt.Sprintf("%T %+v", dest[i], dest[i]))
 			}
@@ -520,18 +603,8 @@ func (r *rows) Next(dest []driver.Value) error {
 			}
 			//ts := C.dpiData_getTimestamp(d)
 			ts := *((*C.dpiTimestamp)(unsafe.Pointer(&d.value)))
-			tz := r.conn.Timezone()
-			if col.OracleType == C.DPI_ORACLE_TYPE_TIMESTAMP_TZ || col.OracleType == C.DPI_ORACLE_TYPE_TIMESTAMP_LTZ {
-				tz = timeZoneFor(ts.tzHourOffset, ts.tzMinuteOffset,
-					nil, // just obey to what's included in the data
-				)
-			}
-			if tz == nil {
-				if logger != nil {
-					logger.Log("msg", "DATE", "i", i, "tz", tz, "params", r.conn.params)
-				}
-			}
-			dest[i] = time.Date(int(ts.year), time.Month(ts.month), int(ts.day), int(ts.hour), int(ts.minute), int(ts.second), int(ts.fsecond), tz)
+			// obey the offset included in the data, in the session's time zone if that has the same offset
+			dest[i] = timeFromTimestamp(ts, isTZType(col.OracleType), r.conn.Timezone())
 		case C.DPI_ORACLE_TYPE_INTERVAL_DS, C.DPI_NATIVE_TYPE_INTERVAL_DS:
 			if isNull {
 				dest[i] = nil
@@ -571,8 +644,11 @@ func (r *rows) Next(dest []driver.Value) error {
 					stringBuilders.Put(sb)
 					return err
 				}
-				dest[i] = sb.String()
+				s := sb.String()
 				stringBuilders.Put(sb)
+				if dest[i], err = r.statement.checkUTF8(i, col.Name, s); err != nil {
+					return err
+				}
 				continue
 			}
 			dest[i] = &Lob{Reader: rdr, IsClob: rdr.IsClob}
@@ -710,7 +786,7 @@ func (dr *directRow) Close() error {
 func (dr *directRow) Next(dest []driver.Value) error {
 	logger := getLogger()
 	if logger != nil {
-		logger.Log("directRow", "Next", "query", dr.query, "dest", dest)
+		logger.Log("directRow", "Next", "query", sqlForLog(dr.query), "dest", dest)
 	}
 	switch dr.query {
 	case getConnection:
@@ -732,7 +808,9 @@ func (r *rows) getImplicitResult() {
 	if err := r.checkExec(func() C.int { return C.dpiStmt_getImplicitResult(st.dpiStmt, &r.nextRs) }); err != nil {
 		r.nextRsErr = fmt.Errorf("getImplicitResult: %w", err)
 	}
-	C.dpiStmt_addRef(r.nextRs)
+	if r.nextRs != nil {
+		C.dpiStmt_addRef(r.nextRs)
+	}
 }
 func (r *rows) HasNextResultSet() bool {
 	if r == nil || r.statement == nil || r.conn == nil {
@@ -758,7 +836,9 @@ func (r *rows) NextResultSet() error {
 		}
 		return fmt.Errorf("getImplicitResult: %w", io.EOF)
 	}
-	st := &statement{conn: r.conn, dpiStmt: r.nextRs}
+	st := &statement{conn: r.conn, dpiStmt: r.nextRs,
+		stmtOptions: r.statement.stmtOptions, // inherit the original statement's options
+	}
 
 	var n C.uint32_t
 	logger := getLogger()
@@ -0,0 +1,96 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WithSessionParams sets the session parameters with ALTER SESSION SET (such as optimizer_mode),
+// calls fn, and restores the previous values - even if fn returns an error or panics.
+//
+// The previous values are read from NLS_SESSION_PARAMETERS (for NLS_*), SYS_CONTEXT (for CURRENT_SCHEMA)
+// and V$PARAMETER (needs SELECT privilege on it).
+// If the restore fails, the connection is discarded, so it won't return to the pool with the altered settings.
+func WithSessionParams(ctx context.Context, conn *sql.Conn, params map[string]string, fn func(context.Context) error) (err error) {
+	if len(params) == 0 {
+		return fn(ctx)
+	}
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	prev := make(map[string]string, len(params))
+	for _, k := range keys {
+		var qry string
+		var args []interface{}
+		switch K := strings.ToUpper(k); {
+		case K == "CURRENT_SCHEMA":
+			qry = "SELECT SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA') FROM DUAL"
+		case strings.HasPrefix(K, "NLS_"):
+			qry, args = "SELECT value FROM nls_session_parameters WHERE parameter = :1", []interface{}{K}
+		default:
+			qry, args = "SELECT value FROM v$parameter WHERE name = :1", []interface{}{strings.ToLower(k)}
+		}
+		var value sql.NullString
+		if err = conn.QueryRowContext(ctx, qry, args...).Scan(&value); err != nil {
+			return fmt.Errorf("get previous %s: %s: %w", k, qry, err)
+		}
+		prev[k] = value.String
+	}
+
+	set := func(ctx context.Context, m map[string]string) error {
+		var buf strings.Builder
+		buf.WriteString("ALTER SESSION SET")
+		for _, k := range keys {
+			buf.WriteByte(' ')
+			buf.WriteString(k)
+			buf.WriteByte('=')
+			buf.WriteString(sessionParamValue(k, m[k]))
+		}
+		qry := buf.String()
+		if _, err := conn.ExecContext(ctx, qry); err != nil {
+			return fmt.Errorf("%s: %w", qry, err)
+		}
+		return nil
+	}
+	if err = set(ctx, params); err != nil {
+		return err
+	}
+	defer func() {
+		// restore even if ctx is cancelled
+		rctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if rErr := set(rctx, prev); rErr != nil {
+			_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+			if err == nil {
+				err = fmt.Errorf("restore session params: %w", rErr)
+			}
+		}
+	}()
+	return fn(ctx)
+}
+
+// sessionParamValue returns the value as it can be used in ALTER SESSION SET:
+// CURRENT_SCHEMA, numbers and booleans as is, anything else quoted.
+func sessionParamValue(key, value string) string {
+	if strings.EqualFold(key, "CURRENT_SCHEMA") || strings.EqualFold(value, "TRUE") || strings.EqualFold(value, "FALSE") {
+		return value
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	return "'" + strings.Replace(value, "'", "''", -1) + "'"
+}
@@ -0,0 +1,22 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import "testing"
+
+func TestSessionParamValue(t *testing.T) {
+	for _, tc := range [][3]string{
+		{"optimizer_mode", "FIRST_ROWS_10", "'FIRST_ROWS_10'"},
+		{"optimizer_index_cost_adj", "50", "50"},
+		{"current_schema", "APP", "APP"},
+		{"skip_unusable_indexes", "false", "false"},
+		{"nls_date_format", "YYYY-MM-DD\"T\"HH24:MI:SS 'x'", "'YYYY-MM-DD\"T\"HH24:MI:SS ''x'''"},
+	} {
+		if got := sessionParamValue(tc[0], tc[1]); got != tc[2] {
+			t.Errorf("%s=%q: got %q, wanted %q", tc[0], tc[1], got, tc[2])
+		}
+	}
+}
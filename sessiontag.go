@@ -0,0 +1,105 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include <stdlib.h>
+#include "dpiImpl.h"
+*/
+import "C"
+
+import (
+	"context"
+	"fmt"
+	"unsafe"
+
+	"github.com/godror/godror/dsn"
+)
+
+type sessionTagCtxKey struct{}
+
+// ContextWithSessionTag returns a context with the specified session tag (such as "NLS=GERMAN"),
+// which overrides the Tag connection parameter, for acquiring a session from the pool.
+//
+// A session without the tag is set up by the TagCallback connection parameter,
+// and is released to the pool with the tag.
+//
+// If a standalone connection is being used this will have no effect.
+//
+// Also, you should disable the Go connection pool with DB.SetMaxIdleConns(0).
+func ContextWithSessionTag(ctx context.Context, tag string) context.Context {
+	return context.WithValue(ctx, sessionTagCtxKey{}, tag)
+}
+
+// applyTag records the tag of the acquired session,
+// and calls TagCallback iff the session does not have the requested tag.
+//
+// Must be called without c.mu held.
+func (c *conn) applyTag(ctx context.Context, P dsn.ConnParams, at acquiredTag) error {
+	c.tag, c.retag, c.retagging = at.Tag, "", false
+	if P.Tag == "" || c.poolKey == "" || at.Found && at.Tag == P.Tag {
+		return nil
+	}
+	if P.TagCallback != nil {
+		if err := P.TagCallback(ctx, c, P.Tag, at.Tag); err != nil {
+			return fmt.Errorf("TagCallback(%q, %q): %w", P.Tag, at.Tag, err)
+		}
+	}
+	c.retag, c.retagging = P.Tag, true
+	return nil
+}
+
+// Tag returns the tag of the session, which it will be released to the pool with.
+func (c *conn) Tag() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.retagging {
+		return c.retag
+	}
+	return c.tag
+}
+
+// SetTag sets the tag the session will be released to the pool with - the empty string clears the tag.
+//
+// Has no effect on standalone connections.
+func (c *conn) SetTag(tag string) {
+	c.mu.Lock()
+	c.retag, c.retagging = tag, true
+	c.mu.Unlock()
+}
+
+// releaseTagged returns the session to the pool with the new tag.
+// It fails if statements or LOBs are still open on it - then the tag stays.
+//
+// Must be called with c.mu held.
+func (c *conn) releaseTagged(dpiConn *C.dpiConn) {
+	var cTag *C.char
+	if c.retag != "" {
+		cTag = C.CString(c.retag)
+		defer C.free(unsafe.Pointer(cTag))
+	}
+	if err := c.checkExec(func() C.int {
+		return C.dpiConn_close(dpiConn, C.DPI_MODE_CONN_CLOSE_RETAG, cTag, C.uint32_t(len(c.retag)))
+	}); err != nil {
+		if logger := getLogger(); logger != nil {
+			logger.Log("msg", "release with tag", "tag", c.retag, "error", err)
+		}
+	}
+}
+
+// GetSessionTag returns the tag of the session of ex - use a *sql.Conn!
+func GetSessionTag(ctx context.Context, ex Execer) (tag string, err error) {
+	err = Raw(ctx, ex, func(c Conn) error { tag = c.Tag(); return nil })
+	return tag, err
+}
+
+// SetSessionTag sets the tag the session of ex will be released to the pool with - use a *sql.Conn!
+//
+// Set it after changing the session state (such as NLS parameters),
+// so the session can be acquired by this tag later.
+func SetSessionTag(ctx context.Context, ex Execer, tag string) error {
+	return Raw(ctx, ex, func(c Conn) error { c.SetTag(tag); return nil })
+}
@@ -0,0 +1,331 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// SnapshotColumn is the metadata of a ResultSnapshot column.
+type SnapshotColumn struct {
+	Name, DatabaseType       string
+	Length, Precision, Scale int64
+	Nullable                 bool
+}
+
+// ResultSnapshot is a materialized result set: the column metadata and the values,
+// which can be serialized (MarshalBinary), and read again as rows (DriverRows).
+//
+// The values are as Scan into an interface{} returns them:
+// nil, string, Number, int64, float64, bool, []byte, time.Time, time.Duration.
+type ResultSnapshot struct {
+	Columns []SnapshotColumn
+	Rows    [][]interface{}
+}
+
+// TakeSnapshot reads at most maxRows (all if maxRows <= 0) rows into a ResultSnapshot.
+//
+// The rows are not closed. LOBs are read into memory, so do not use LobAsReader.
+func TakeSnapshot(rows *sql.Rows, maxRows int) (*ResultSnapshot, error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	rs := ResultSnapshot{Columns: make([]SnapshotColumn, len(types))}
+	for i, ct := range types {
+		col := SnapshotColumn{Name: ct.Name(), DatabaseType: ct.DatabaseTypeName(), Nullable: true}
+		col.Length, _ = ct.Length()
+		col.Precision, col.Scale, _ = ct.DecimalSize()
+		if nullable, ok := ct.Nullable(); ok {
+			col.Nullable = nullable
+		}
+		rs.Columns[i] = col
+	}
+	dest := make([]interface{}, len(types))
+	for (maxRows <= 0 || len(rs.Rows) < maxRows) && rows.Next() {
+		values := make([]interface{}, len(types))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return &rs, err
+		}
+		for i, v := range values {
+			switch v.(type) {
+			case nil, string, Number, int64, float64, bool, []byte, time.Time, time.Duration:
+			default:
+				return &rs, fmt.Errorf("column %d (%s): cannot snapshot %T", i, rs.Columns[i].Name, v)
+			}
+		}
+		rs.Rows = append(rs.Rows, values)
+	}
+	return &rs, rows.Err()
+}
+
+// snapshotVersion is the first byte of the MarshalBinary format.
+const snapshotVersion = 1
+
+// the value tags of the MarshalBinary format.
+const (
+	snapNil = byte(iota)
+	snapString
+	snapNumber
+	snapInt64
+	snapFloat64
+	snapFalse
+	snapTrue
+	snapBytes
+	snapTime
+	snapDuration
+)
+
+// ErrBadSnapshot is returned by UnmarshalBinary for malformed input.
+var ErrBadSnapshot = errors.New("bad snapshot")
+
+// MarshalBinary encodes the snapshot in a compact, self-contained binary format:
+// a version byte, the columns, then the values, each with a type tag.
+func (rs *ResultSnapshot) MarshalBinary() ([]byte, error) {
+	b := []byte{snapshotVersion}
+	appendString := func(s string) {
+		b = appendUvarint(b, uint64(len(s)))
+		b = append(b, s...)
+	}
+	b = appendUvarint(b, uint64(len(rs.Columns)))
+	for _, col := range rs.Columns {
+		appendString(col.Name)
+		appendString(col.DatabaseType)
+		b = appendVarint(b, col.Length)
+		b = appendVarint(b, col.Precision)
+		b = appendVarint(b, col.Scale)
+		b = append(b, byte(b2i(col.Nullable)))
+	}
+	b = appendUvarint(b, uint64(len(rs.Rows)))
+	for i, row := range rs.Rows {
+		if len(row) != len(rs.Columns) {
+			return nil, fmt.Errorf("row %d has %d values, wanted %d", i, len(row), len(rs.Columns))
+		}
+		for j, v := range row {
+			switch x := v.(type) {
+			case nil:
+				b = append(b, snapNil)
+			case string:
+				b = append(b, snapString)
+				appendString(x)
+			case Number:
+				b = append(b, snapNumber)
+				appendString(string(x))
+			case int64:
+				b = append(b, snapInt64)
+				b = appendVarint(b, x)
+			case float64:
+				b = append(b, snapFloat64)
+				b = appendUint64(b, math.Float64bits(x))
+			case bool:
+				b = append(b, snapFalse+byte(b2i(x)))
+			case []byte:
+				b = append(b, snapBytes)
+				appendString(string(x))
+			case time.Time:
+				p, err := x.MarshalBinary()
+				if err != nil {
+					return nil, fmt.Errorf("row %d column %d: %w", i, j, err)
+				}
+				b = append(b, snapTime)
+				appendString(string(p))
+			case time.Duration:
+				b = append(b, snapDuration)
+				b = appendVarint(b, int64(x))
+			default:
+				return nil, fmt.Errorf("row %d column %d: cannot snapshot %T", i, j, v)
+			}
+		}
+	}
+	return b, nil
+}
+
+func appendUvarint(b []byte, u uint64) []byte {
+	var a [binary.MaxVarintLen64]byte
+	return append(b, a[:binary.PutUvarint(a[:], u)]...)
+}
+func appendVarint(b []byte, i int64) []byte {
+	var a [binary.MaxVarintLen64]byte
+	return append(b, a[:binary.PutVarint(a[:], i)]...)
+}
+func appendUint64(b []byte, u uint64) []byte {
+	var a [8]byte
+	binary.BigEndian.PutUint64(a[:], u)
+	return append(b, a[:]...)
+}
+
+// UnmarshalBinary decodes the snapshot encoded by MarshalBinary.
+func (rs *ResultSnapshot) UnmarshalBinary(p []byte) error {
+	*rs = ResultSnapshot{}
+	if len(p) == 0 || p[0] != snapshotVersion {
+		return fmt.Errorf("%w: unknown version", ErrBadSnapshot)
+	}
+	d := snapshotDecoder{p: p[1:]}
+	rs.Columns = make([]SnapshotColumn, d.count())
+	for i := range rs.Columns {
+		col := &rs.Columns[i]
+		col.Name, col.DatabaseType = d.string(), d.string()
+		col.Length, col.Precision, col.Scale = d.varint(), d.varint(), d.varint()
+		col.Nullable = d.byte() != 0
+	}
+	if n := d.count(); n != 0 && len(rs.Columns) != 0 {
+		rs.Rows = make([][]interface{}, n)
+	}
+	for i := range rs.Rows {
+		row := make([]interface{}, len(rs.Columns))
+		for j := range row {
+			switch tag := d.byte(); tag {
+			case snapNil:
+			case snapString:
+				row[j] = d.string()
+			case snapNumber:
+				row[j] = Number(d.string())
+			case snapInt64:
+				row[j] = d.varint()
+			case snapFloat64:
+				row[j] = math.Float64frombits(binary.BigEndian.Uint64(d.bytes(8)))
+			case snapFalse, snapTrue:
+				row[j] = tag == snapTrue
+			case snapBytes:
+				row[j] = append([]byte{}, d.bytes(d.count())...)
+			case snapTime:
+				var t time.Time
+				if err := t.UnmarshalBinary(d.bytes(d.count())); err != nil && d.err == nil {
+					d.err = fmt.Errorf("%w: row %d column %d: %v", ErrBadSnapshot, i, j, err)
+				}
+				row[j] = t
+			case snapDuration:
+				row[j] = time.Duration(d.varint())
+			default:
+				if d.err == nil {
+					d.err = fmt.Errorf("%w: row %d column %d: unknown tag %d", ErrBadSnapshot, i, j, tag)
+				}
+			}
+			if d.err != nil {
+				*rs = ResultSnapshot{}
+				return d.err
+			}
+		}
+		rs.Rows[i] = row
+	}
+	if d.err == nil && len(d.p) != 0 {
+		d.err = fmt.Errorf("%w: %d trailing bytes", ErrBadSnapshot, len(d.p))
+	}
+	if d.err != nil {
+		*rs = ResultSnapshot{}
+	}
+	return d.err
+}
+
+// snapshotDecoder reads the MarshalBinary format, recording the first error -
+// after that, it returns zero values.
+type snapshotDecoder struct {
+	err error
+	p   []byte
+}
+
+func (d *snapshotDecoder) truncated() {
+	if d.err == nil {
+		d.err = fmt.Errorf("%w: truncated", ErrBadSnapshot)
+	}
+	d.p = nil
+}
+func (d *snapshotDecoder) uvarint() uint64 {
+	u, n := binary.Uvarint(d.p)
+	if n <= 0 {
+		d.truncated()
+		return 0
+	}
+	d.p = d.p[n:]
+	return u
+}
+func (d *snapshotDecoder) varint() int64 {
+	i, n := binary.Varint(d.p)
+	if n <= 0 {
+		d.truncated()
+		return 0
+	}
+	d.p = d.p[n:]
+	return i
+}
+
+// count returns a length - which cannot be more than the remaining bytes,
+// as each element takes at least one.
+func (d *snapshotDecoder) count() int {
+	if n := d.uvarint(); n <= uint64(len(d.p)) {
+		return int(n)
+	}
+	d.truncated()
+	return 0
+}
+func (d *snapshotDecoder) bytes(n int) []byte {
+	if len(d.p) < n {
+		d.truncated()
+		return make([]byte, n)
+	}
+	b := d.p[:n:n]
+	d.p = d.p[n:]
+	return b
+}
+func (d *snapshotDecoder) byte() byte     { return d.bytes(1)[0] }
+func (d *snapshotDecoder) string() string { return string(d.bytes(d.count())) }
+
+// DriverRows returns the snapshot as driver.Rows - use WrapRows to have an *sql.Rows.
+func (rs *ResultSnapshot) DriverRows() driver.Rows { return &snapshotRows{ResultSnapshot: rs} }
+
+var _ driver.RowsColumnTypeDatabaseTypeName = (*snapshotRows)(nil)
+var _ driver.RowsColumnTypeLength = (*snapshotRows)(nil)
+var _ driver.RowsColumnTypeNullable = (*snapshotRows)(nil)
+var _ driver.RowsColumnTypePrecisionScale = (*snapshotRows)(nil)
+
+type snapshotRows struct {
+	*ResultSnapshot
+	pos int
+}
+
+func (r *snapshotRows) Columns() []string {
+	names := make([]string, len(r.ResultSnapshot.Columns))
+	for i, col := range r.ResultSnapshot.Columns {
+		names[i] = col.Name
+	}
+	return names
+}
+func (r *snapshotRows) Close() error { r.pos = len(r.ResultSnapshot.Rows); return nil }
+func (r *snapshotRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.ResultSnapshot.Rows) {
+		return io.EOF
+	}
+	row := r.ResultSnapshot.Rows[r.pos]
+	r.pos++
+	for i, v := range row {
+		dest[i] = v
+	}
+	return nil
+}
+func (r *snapshotRows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.ResultSnapshot.Columns[index].DatabaseType
+}
+func (r *snapshotRows) ColumnTypeLength(index int) (int64, bool) {
+	col := r.ResultSnapshot.Columns[index]
+	return col.Length, col.Length != 0
+}
+func (r *snapshotRows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	return r.ResultSnapshot.Columns[index].Nullable, true
+}
+func (r *snapshotRows) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
+	col := r.ResultSnapshot.Columns[index]
+	return col.Precision, col.Scale, col.DatabaseType == "NUMBER"
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestResultSnapshotBinary(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	rs := ResultSnapshot{
+		Columns: []SnapshotColumn{
+			{Name: "ID", DatabaseType: "NUMBER", Precision: 9},
+			{Name: "NAME", DatabaseType: "VARCHAR2", Length: 30, Nullable: true},
+			{Name: "CREATED", DatabaseType: "DATE", Nullable: true},
+		},
+		Rows: [][]interface{}{
+			{Number("1"), "a", now},
+			{Number("2"), nil, nil},
+		},
+	}
+	b, err := rs.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("%d bytes", len(b))
+	var got ResultSnapshot
+	if err = got.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Columns, rs.Columns) {
+		t.Errorf("got %#v, wanted %#v", got.Columns, rs.Columns)
+	}
+
+	dr := got.DriverRows()
+	if cols := dr.Columns(); !reflect.DeepEqual(cols, []string{"ID", "NAME", "CREATED"}) {
+		t.Errorf("columns: %v", cols)
+	}
+	dest := make([]driver.Value, 3)
+	for i, want := range rs.Rows {
+		if err = dr.Next(dest); err != nil {
+			t.Fatal(i, err)
+		}
+		for j, v := range dest {
+			if tm, ok := v.(time.Time); ok {
+				if !tm.Equal(want[j].(time.Time)) {
+					t.Errorf("%d/%d: got %v, wanted %v", i, j, v, want[j])
+				}
+			} else if v != want[j] {
+				t.Errorf("%d/%d: got %#v, wanted %#v", i, j, v, want[j])
+			}
+		}
+	}
+	if err = dr.Next(dest); err != io.EOF {
+		t.Errorf("wanted EOF, got %+v", err)
+	}
+}
+
+func TestResultSnapshotTypes(t *testing.T) {
+	tm := time.Date(2022, 1, 2, 3, 4, 5, 6, time.FixedZone("", 3600))
+	rs := ResultSnapshot{
+		Columns: []SnapshotColumn{{Name: "V", DatabaseType: "ANY", Length: -1, Precision: 38, Scale: -127}},
+		Rows: [][]interface{}{
+			{nil}, {""}, {"árvíz"}, {Number("-1.5")}, {int64(-1 << 62)}, {3.25}, {true}, {false},
+			{[]byte{}}, {[]byte{0, 1, 2}}, {tm}, {-time.Hour},
+		},
+	}
+	b, err := rs.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got ResultSnapshot
+	if err = got.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Columns, rs.Columns) || len(got.Rows) != len(rs.Rows) {
+		t.Fatalf("got %#v", got)
+	}
+	for i, row := range rs.Rows {
+		want, v := row[0], got.Rows[i][0]
+		if w, ok := want.(time.Time); ok {
+			if g, ok := v.(time.Time); !ok || !g.Equal(w) || g.Format(time.RFC3339Nano) != w.Format(time.RFC3339Nano) {
+				t.Errorf("%d. got %v, wanted %v", i, v, w)
+			}
+		} else if !reflect.DeepEqual(v, want) {
+			t.Errorf("%d. got %#v, wanted %#v", i, v, want)
+		}
+	}
+
+	for i := 0; i < len(b); i++ {
+		if err := got.UnmarshalBinary(b[:i]); !errors.Is(err, ErrBadSnapshot) {
+			t.Errorf("truncated at %d: got %v", i, err)
+		}
+	}
+	if err := got.UnmarshalBinary(append(b, 0)); !errors.Is(err, ErrBadSnapshot) {
+		t.Errorf("trailing byte: got %v", err)
+	}
+	if _, err := (&ResultSnapshot{Columns: rs.Columns, Rows: [][]interface{}{{uint8(1)}}}).MarshalBinary(); err == nil {
+		t.Error("wanted error for uint8")
+	}
+}
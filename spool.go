@@ -0,0 +1,273 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+)
+
+// SpooledRows is a result set spooled to a temporary file, encrypted with a random key
+// that lives only in memory.
+//
+// It allows random access (Row) and any number of passes (Rows) over a result
+// that is too big for memory, without keeping the cursor open.
+// Only the offsets of the rows (8 bytes per row) are held in memory.
+type SpooledRows struct {
+	file    *os.File
+	aead    cipher.AEAD
+	columns []string
+	offsets []int64
+}
+
+// SpoolRows reads all the rows into a temporary file in dir (os.TempDir if empty), and closes rows.
+//
+// The values must be nil, int64, float64, bool, string, []byte, time.Time or Number.
+func SpoolRows(ctx context.Context, rows *sql.Rows, dir string) (*SpooledRows, error) {
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	key := make([]byte, 32)
+	if _, err = io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	fh, err := os.CreateTemp(dir, "godror-spool-*")
+	if err != nil {
+		return nil, err
+	}
+	s := SpooledRows{file: fh, aead: aead, columns: columns}
+	if err = s.spool(ctx, rows); err != nil {
+		_ = s.Close()
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (s *SpooledRows) spool(ctx context.Context, rows *sql.Rows) error {
+	bw := bufio.NewWriterSize(s.file, 1<<16)
+	vals := make([]interface{}, len(s.columns))
+	dests := make([]interface{}, len(vals))
+	for i := range vals {
+		dests[i] = &vals[i]
+	}
+	var plain, sealed []byte
+	var offset int64
+	for rows.Next() {
+		if len(s.offsets)%1024 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		if err := rows.Scan(dests...); err != nil {
+			return err
+		}
+		plain = plain[:0]
+		for i, v := range vals {
+			var err error
+			if plain, err = appendSpoolValue(plain, v); err != nil {
+				return fmt.Errorf("%d. row %q: %w", len(s.offsets)+1, s.columns[i], err)
+			}
+		}
+		sealed = s.aead.Seal(sealed[:0], s.nonce(len(s.offsets)), plain, nil)
+		if _, err := bw.Write(sealed); err != nil {
+			return err
+		}
+		s.offsets = append(s.offsets, offset)
+		offset += int64(len(sealed))
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	s.offsets = append(s.offsets, offset)
+	return bw.Flush()
+}
+
+// nonce returns the nonce of the i-th row: as each row is sealed only once with the key,
+// the row index is unique.
+func (s *SpooledRows) nonce(i int) []byte {
+	nonce := make([]byte, s.aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], uint64(i))
+	return nonce
+}
+
+// Columns returns the column names.
+func (s *SpooledRows) Columns() []string { return s.columns }
+
+// Len returns the number of rows.
+func (s *SpooledRows) Len() int {
+	if len(s.offsets) == 0 {
+		return 0
+	}
+	return len(s.offsets) - 1
+}
+
+// Row returns the values of the i-th (0-based) row.
+//
+// It is safe to call concurrently.
+func (s *SpooledRows) Row(i int) ([]driver.Value, error) {
+	dest := make([]driver.Value, len(s.columns))
+	return dest, s.readRow(i, dest)
+}
+
+func (s *SpooledRows) readRow(i int, dest []driver.Value) error {
+	if i < 0 || i >= s.Len() {
+		return fmt.Errorf("row %d out of range [0, %d)", i, s.Len())
+	}
+	if s.file == nil {
+		return errors.New("spool is closed")
+	}
+	b := make([]byte, s.offsets[i+1]-s.offsets[i])
+	if _, err := s.file.ReadAt(b, s.offsets[i]); err != nil {
+		return err
+	}
+	b, err := s.aead.Open(b[:0], s.nonce(i), b, nil)
+	if err != nil {
+		return fmt.Errorf("row %d: %w", i, err)
+	}
+	for j := range dest {
+		if dest[j], b, err = readSpoolValue(b); err != nil {
+			return fmt.Errorf("row %d column %q: %w", i, s.columns[j], err)
+		}
+	}
+	return nil
+}
+
+// Rows returns a new iterator over all the rows - use WrapRows to have an *sql.Rows.
+func (s *SpooledRows) Rows() driver.Rows { return &spooledRowsIter{SpooledRows: s} }
+
+// Close closes and removes the temporary file.
+func (s *SpooledRows) Close() error {
+	fh := s.file
+	s.file = nil
+	if fh == nil {
+		return nil
+	}
+	err := fh.Close()
+	if rmErr := os.Remove(fh.Name()); rmErr != nil && err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+type spooledRowsIter struct {
+	*SpooledRows
+	next int
+}
+
+func (it *spooledRowsIter) Close() error { it.next = it.Len(); return nil }
+func (it *spooledRowsIter) Next(dest []driver.Value) error {
+	if it.next >= it.Len() {
+		return io.EOF
+	}
+	it.next++
+	return it.readRow(it.next-1, dest)
+}
+
+// appendSpoolValue appends the type tag and the value to dst.
+func appendSpoolValue(dst []byte, v interface{}) ([]byte, error) {
+	var a [binary.MaxVarintLen64]byte
+	appendBytes := func(dst []byte, tag byte, b []byte) []byte {
+		dst = append(dst, tag)
+		dst = append(dst, a[:binary.PutUvarint(a[:], uint64(len(b)))]...)
+		return append(dst, b...)
+	}
+	switch x := v.(type) {
+	case nil:
+		return append(dst, 'z'), nil
+	case bool:
+		if x {
+			return append(dst, 'T'), nil
+		}
+		return append(dst, 'F'), nil
+	case int64:
+		dst = append(dst, 'i')
+		return append(dst, a[:binary.PutVarint(a[:], x)]...), nil
+	case float64:
+		dst = append(dst, 'f')
+		binary.BigEndian.PutUint64(a[:8], math.Float64bits(x))
+		return append(dst, a[:8]...), nil
+	case string:
+		return appendBytes(dst, 's', []byte(x)), nil
+	case Number:
+		return appendBytes(dst, 'N', []byte(x)), nil
+	case []byte:
+		return appendBytes(dst, 'b', x), nil
+	case time.Time:
+		b, err := x.MarshalBinary()
+		if err != nil {
+			return dst, err
+		}
+		return appendBytes(dst, 't', b), nil
+	}
+	return dst, fmt.Errorf("cannot spool %T", v)
+}
+
+// readSpoolValue reads a value written by appendSpoolValue, and returns the rest of b.
+func readSpoolValue(b []byte) (driver.Value, []byte, error) {
+	errShort := io.ErrUnexpectedEOF
+	if len(b) == 0 {
+		return nil, b, errShort
+	}
+	tag, b := b[0], b[1:]
+	switch tag {
+	case 'z':
+		return nil, b, nil
+	case 'T', 'F':
+		return tag == 'T', b, nil
+	case 'i':
+		x, n := binary.Varint(b)
+		if n <= 0 {
+			return nil, b, errShort
+		}
+		return x, b[n:], nil
+	case 'f':
+		if len(b) < 8 {
+			return nil, b, errShort
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), b[8:], nil
+	case 's', 'N', 'b', 't':
+		length, n := binary.Uvarint(b)
+		if n <= 0 || uint64(len(b)-n) < length {
+			return nil, b, errShort
+		}
+		p, b := b[n:n+int(length)], b[n+int(length):]
+		switch tag {
+		case 's':
+			return string(p), b, nil
+		case 'N':
+			return Number(p), b, nil
+		case 'b':
+			return append([]byte(nil), p...), b, nil
+		default:
+			var t time.Time
+			err := t.UnmarshalBinary(p)
+			return t, b, err
+		}
+	}
+	return nil, b, fmt.Errorf("unknown tag %q", tag)
+}
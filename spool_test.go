@@ -0,0 +1,46 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSpoolValue(t *testing.T) {
+	values := []interface{}{
+		nil, true, false, int64(-1234567), 3.14, "árvíztűrő", Number("-1.5e-10"),
+		[]byte{0, 1, 2}, time.Date(2022, 10, 17, 12, 34, 56, 789, time.FixedZone("X", 3600)),
+	}
+	var b []byte
+	for _, v := range values {
+		var err error
+		if b, err = appendSpoolValue(b, v); err != nil {
+			t.Fatalf("%#v: %+v", v, err)
+		}
+	}
+	for i, want := range values {
+		got, rest, err := readSpoolValue(b)
+		if err != nil {
+			t.Fatalf("%d: %+v", i, err)
+		}
+		b = rest
+		if wt, ok := want.(time.Time); ok {
+			if gt, ok := got.(time.Time); !ok || !gt.Equal(wt) {
+				t.Errorf("%d. got %#v, wanted %#v", i, got, want)
+			}
+		} else if !reflect.DeepEqual(got, want) {
+			t.Errorf("%d. got %#v, wanted %#v", i, got, want)
+		}
+	}
+	if len(b) != 0 {
+		t.Errorf("%d bytes remained", len(b))
+	}
+	if _, err := appendSpoolValue(nil, struct{}{}); err == nil {
+		t.Error("wanted error for struct")
+	}
+}
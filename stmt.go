@@ -36,6 +36,7 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+	"unicode/utf8"
 	"unsafe"
 
 	"github.com/godror/knownpb/timestamppb"
@@ -56,10 +57,21 @@ type stmtOptions struct {
 	callTimeout        time.Duration
 	execMode           C.dpiExecMode
 	plSQLArrays        bool
+	noPlSQLArrays      bool
+	inferPlSQLArrays   bool // set per execution by bindVars
+	batchErrors        bool
 	lobAsReader        bool
+	longStringAsClob   bool
+	adaptiveFetchBytes int
 	nullDateAsZeroTime bool
 	deleteFromCache    bool
+	bindOnly           bool
 	numberAsString     bool
+	fetchNumAsString   bool
+	keepTimeZone       bool
+	numberColumnAs     map[int]NumberScanType
+	utf8Report         func(InvalidUTF8Error)
+	utf8Action         UTF8Action
 }
 
 type boolString struct {
@@ -111,6 +123,7 @@ func (o stmtOptions) PrefetchCount() int {
 		return n
 	}
 }
+func (o stmtOptions) AdaptiveFetchBytes() int { return o.adaptiveFetchBytes }
 func (o stmtOptions) FetchArraySize() int {
 	n := o.fetchArraySize
 	if n <= 0 {
@@ -118,10 +131,14 @@ func (o stmtOptions) FetchArraySize() int {
 	}
 	return n
 }
-func (o stmtOptions) PlSQLArrays() bool { return o.plSQLArrays }
+func (o stmtOptions) PlSQLArrays() bool {
+	return o.plSQLArrays || o.inferPlSQLArrays && !o.noPlSQLArrays
+}
+func (o stmtOptions) BatchErrors() bool { return o.batchErrors }
 
-func (o stmtOptions) ClobAsString() bool { return !o.lobAsReader }
-func (o stmtOptions) LobAsReader() bool  { return o.lobAsReader }
+func (o stmtOptions) ClobAsString() bool     { return !o.lobAsReader }
+func (o stmtOptions) LobAsReader() bool      { return o.lobAsReader }
+func (o stmtOptions) LongStringAsClob() bool { return o.longStringAsClob }
 func (o stmtOptions) NullDate() interface{} {
 	if o.nullDateAsZeroTime {
 		return time.Time{}
@@ -129,7 +146,18 @@ func (o stmtOptions) NullDate() interface{} {
 	return nullTime
 }
 func (o stmtOptions) DeleteFromCache() bool { return o.deleteFromCache }
+func (o stmtOptions) BindOnly() bool        { return o.bindOnly }
 func (o stmtOptions) NumberAsString() bool  { return o.numberAsString }
+func (o stmtOptions) KeepTimeZone() bool    { return o.keepTimeZone }
+func (o stmtOptions) FetchNumberAsString() bool {
+	return o.fetchNumAsString
+}
+func (o stmtOptions) NumberColumnAs(col int) NumberScanType {
+	if o.numberColumnAs == nil {
+		return NumberScanDefault
+	}
+	return o.numberColumnAs[col]
+}
 
 // Option holds statement options.
 //
@@ -164,7 +192,16 @@ func BoolToString(trueVal, falseVal string) Option {
 // be left as is - the default is to treat them as arguments for ExecMany.
 //
 // Use it "naked", without sql.Named!
-var PlSQLArrays Option = func(o *stmtOptions) { o.plSQLArrays = true }
+//
+// For a PL/SQL block with a slice as an sql.Out destination, it is inferred -
+// use NoPlSQLArrays to call the block for each element of the slices instead.
+var PlSQLArrays Option = func(o *stmtOptions) { o.plSQLArrays, o.noPlSQLArrays = true, false }
+
+// NoPlSQLArrays is to prevent the inference of PlSQLArrays for a PL/SQL block
+// with slice sql.Out destinations: the slices will be treated as arguments for ExecMany.
+//
+// Use it "naked", without sql.Named!
+var NoPlSQLArrays Option = func(o *stmtOptions) { o.plSQLArrays, o.noPlSQLArrays = false, true }
 
 // FetchRowCount is DEPRECATED, use FetchArraySize.
 //
@@ -188,6 +225,23 @@ func FetchArraySize(rowCount int) Option {
 	}
 }
 
+// AdaptiveFetch returns an option to size the fetch array adaptively:
+// the first fetch gets one row only, and each time all the rows of a full fetch are consumed,
+// the fetch array (and its buffers) grows to hold the rows consumed in about 100ms at the observed rate,
+// till the buffers reach maxBytes (computed from the column sizes, at least one row).
+//
+// This gives good performance for both the few-rows and the many-rows queries, without per-query tuning.
+// It overrides FetchArraySize.
+//
+// Use it "naked", without sql.Named!
+func AdaptiveFetch(maxBytes int) Option {
+	return func(o *stmtOptions) {
+		if maxBytes >= 0 {
+			o.adaptiveFetchBytes = maxBytes
+		}
+	}
+}
+
 // PrefetchCount returns an option to set the rows to be fetched, overriding DefaultPrefetchCount.
 //
 // For choosing FetchArraySize and PrefetchCount, see https://cx-oracle.readthedocs.io/en/latest/user_guide/tuning.html#choosing-values-for-arraysize-and-prefetchrows
@@ -207,6 +261,9 @@ func PrefetchCount(rowCount int) Option {
 
 // ArraySize returns an option to set the array size to be used, overriding DefaultArraySize.
 //
+// This is the maximum number of elements of the PL/SQL array parameters,
+// but an OUT PL/SQL array can have more: its destination slice's capacity (up to 65536).
+//
 // Use it "naked", without sql.Named!
 func ArraySize(arraySize int) Option {
 	if arraySize <= 0 {
@@ -258,6 +315,16 @@ func ClobAsString() Option { return func(o *stmtOptions) { o.lobAsReader = false
 // Use it "naked", without sql.Named!
 func LobAsReader() Option { return func(o *stmtOptions) { o.lobAsReader = true } }
 
+// LongStringAsClob is an option to bind IN strings longer than the VARCHAR2 limit as temporary CLOBs,
+// instead of failing with ORA-01461 or ORA-06502.
+//
+// The limit is 32767 bytes for PL/SQL, and MaxStringSize for SQL - detected (once per connection)
+// when a string longer than 4000 bytes is bound, 4000 bytes if the detection fails.
+// If any element of a []string is too long, all of them are bound as CLOB.
+//
+// Use it "naked", without sql.Named!
+func LongStringAsClob() Option { return func(o *stmtOptions) { o.longStringAsClob = true } }
+
 // CallTimeout sets the round-trip timeout (OCI_ATTR_CALL_TIMEOUT).
 //
 // See https://docs.oracle.com/en/database/oracle/oracle-database/18/lnoci/handle-and-descriptor-attributes.html#GUID-D8EE68EB-7E38-4068-B06E-DF5686379E5E
@@ -272,9 +339,156 @@ func NullDateAsZeroTime() Option { return func(o *stmtOptions) { o.nullDateAsZer
 // DeleteFromCache is an option to delete the statement from the statement cache.
 func DeleteFromCache() Option { return func(o *stmtOptions) { o.deleteFromCache = true } }
 
+// BindOnly is an option to only allocate and bind the variables for the arguments, without executing the statement.
+//
+// Prepare the hot statements once (on a *sql.Conn or in a *sql.Tx), pre-bind them with
+// the first arguments with BindOnly, and the later executions with arguments of the same types
+// only copy the new values into the bound variables.
+//
+// Use it "naked", without sql.Named!
+func BindOnly() Option { return func(o *stmtOptions) { o.bindOnly = true } }
+
 // NumberAsString is an option to return numbers a string, not Number.
 func NumberAsString() Option { return func(o *stmtOptions) { o.numberAsString = true } }
 
+// KeepTimeZone is an option to bind the time.Time values with their own time zone offset,
+// instead of converting them to the session's time zone - for TIMESTAMP WITH TIME ZONE columns,
+// to keep the original offset. Beware: DATE and TIMESTAMP columns get the wall clock of that zone!
+//
+// Use it "naked", without sql.Named!
+func KeepTimeZone() Option { return func(o *stmtOptions) { o.keepTimeZone = true } }
+
+// FetchNumberAsString is an option to return all NUMBER columns as string, in their exact textual form,
+// even the integer ones (which are int64 with NumberAsString), like cx_Oracle's numbersAsStrings.
+//
+// Use it "naked", without sql.Named!
+func FetchNumberAsString() Option {
+	return func(o *stmtOptions) { o.numberAsString, o.fetchNumAsString = true, true }
+}
+
+// UTF8Action specifies what to do with the invalid UTF-8 strings fetched - see ValidateUTF8.
+type UTF8Action uint8
+
+const (
+	// UTF8NoCheck does not validate the strings.
+	UTF8NoCheck = UTF8Action(iota)
+	// UTF8Error returns an *InvalidUTF8Error.
+	UTF8Error
+	// UTF8Replace replaces the invalid sequences with the Unicode replacement character (U+FFFD).
+	UTF8Replace
+	// UTF8Report returns the string as is, just reports it.
+	UTF8Report
+)
+
+// InvalidUTF8Error is the error for invalid UTF-8 strings fetched, with the offending column.
+type InvalidUTF8Error struct {
+	Name   string
+	Value  string
+	Column int
+}
+
+func (e *InvalidUTF8Error) Error() string {
+	return fmt.Sprintf("column %d (%s): invalid UTF-8 %q", e.Column, e.Name, e.Value)
+}
+
+// ValidateUTF8 is an option to validate the fetched strings (CHAR, VARCHAR2, LONG and CLOB columns) for UTF-8,
+// and return an *InvalidUTF8Error, or replace the invalid sequences, or just report them.
+//
+// The report function (if not nil) is called for each invalid string with UTF8Replace and UTF8Report,
+// otherwise they are logged.
+//
+// Use it "naked", without sql.Named!
+func ValidateUTF8(action UTF8Action, report func(InvalidUTF8Error)) Option {
+	return func(o *stmtOptions) { o.utf8Action, o.utf8Report = action, report }
+}
+
+// checkUTF8 validates the string fetched into column col per the UTF8Action.
+func (o stmtOptions) checkUTF8(col int, name, s string) (string, error) {
+	if o.utf8Action == UTF8NoCheck || utf8.ValidString(s) {
+		return s, nil
+	}
+	e := InvalidUTF8Error{Column: col, Name: name, Value: s}
+	if o.utf8Action == UTF8Error {
+		return s, &e
+	}
+	if o.utf8Report != nil {
+		o.utf8Report(e)
+	} else if logger := getLogger(); logger != nil {
+		logger.Log("msg", "invalid UTF-8", "column", col, "name", name, "value", s)
+	}
+	if o.utf8Action == UTF8Replace {
+		return strings.ToValidUTF8(s, string(utf8.RuneError)), nil
+	}
+	return s, nil
+}
+
+// NumberScanType is the Go type a NUMBER column is returned as - see NumberColumnAs.
+type NumberScanType uint8
+
+const (
+	// NumberScanDefault returns Number (or string with NumberAsString).
+	NumberScanDefault = NumberScanType(iota)
+	// NumberScanString returns string.
+	NumberScanString
+	// NumberScanNumber returns Number.
+	NumberScanNumber
+	// NumberScanInt64 returns int64, or an error if the number is not an integer.
+	NumberScanInt64
+	// NumberScanFloat64 returns float64 - beware of the precision loss!
+	NumberScanFloat64
+)
+
+// NumberColumnAs is an option to return the NUMBER column at the given index (starting with 0)
+// as the given type, overriding NumberAsString for that column.
+// Can be given multiple times, for different columns.
+//
+// Use it "naked", without sql.Named!
+func NumberColumnAs(col int, typ NumberScanType) Option {
+	return func(o *stmtOptions) {
+		m := make(map[int]NumberScanType, len(o.numberColumnAs)+1)
+		for k, v := range o.numberColumnAs {
+			m[k] = v
+		}
+		m[col] = typ
+		o.numberColumnAs = m
+	}
+}
+
+// convert the number (int64, uint64, string or Number) to the given type.
+func (typ NumberScanType) convert(v driver.Value) (driver.Value, error) {
+	var s string
+	switch x := v.(type) {
+	case int64:
+		switch typ {
+		case NumberScanInt64:
+			return x, nil
+		case NumberScanFloat64:
+			return float64(x), nil
+		}
+		s = strconv.FormatInt(x, 10)
+	case uint64:
+		s = strconv.FormatUint(x, 10)
+	case string:
+		s = x
+	case Number:
+		s = string(x)
+	default:
+		return v, nil
+	}
+	switch typ {
+	case NumberScanString:
+		return s, nil
+	case NumberScanNumber:
+		return Number(s), nil
+	case NumberScanInt64:
+		return strconv.ParseInt(s, 10, 64)
+	case NumberScanFloat64:
+		return strconv.ParseFloat(s, 64)
+	default:
+		return v, nil
+	}
+}
+
 const minChunkSize = 1 << 16
 
 var _ driver.Stmt = (*statement)(nil)
@@ -294,6 +508,10 @@ type statement struct {
 	data     [][]C.dpiData
 	vars     []*C.dpiVar
 	varInfos []varInfo
+	// bound records the variables bound in the previous executions, to skip re-binding the unchanged ones.
+	bound bindCache
+	// bindNamesCache caches the bind variable names of the statement.
+	bindNamesCache []string
 	stmtOptions
 	arrLen      int
 	dpiStmtInfo C.dpiStmtInfo
@@ -320,11 +538,15 @@ func (st *statement) closeNotLocking() error {
 	}
 
 	c, dpiStmt, vars := st.conn, st.dpiStmt, st.vars
+	if c != nil && c.params.MaxOpenCursors > 0 {
+		c.openStmts.remove(st.query)
+	}
 	st.vars = nil
 	st.isSlice = nil
 	st.query = ""
 	st.data = nil
 	st.varInfos = nil
+	st.bound, st.bindNamesCache = bindCache{}, nil
 	st.gets = nil
 	st.dests = nil
 	st.columns = nil
@@ -341,10 +563,12 @@ func (st *statement) closeNotLocking() error {
 			logger.Log("msg", "closeNotLocking", "stack", string(stack))
 		}
 	}
+	var mem *memStats
+	if c != nil {
+		mem = c.mem
+	}
 	for _, v := range vars[:cap(vars)] {
-		if v != nil {
-			C.dpiVar_release(v)
-		}
+		mem.releaseVar(v)
 	}
 	if dpiStmt.refCount > 0 {
 		C.dpiStmt_release(dpiStmt)
@@ -392,7 +616,7 @@ func newDoneCh() (<-chan struct{}, func()) {
 // ExecContext must honor the context timeout and return when it is canceled.
 //
 // Cancelation/timeout is honored, execution is broken, but you may have to disable out-of-bound execution - see https://github.com/oracle/odpi/issues/116 for details.
-func (st *statement) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+func (st *statement) ExecContext(ctx context.Context, args []driver.NamedValue) (_ driver.Result, retErr error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
@@ -406,6 +630,14 @@ func (st *statement) ExecContext(ctx context.Context, args []driver.NamedValue)
 	if st.conn == nil {
 		return nil, driver.ErrBadConn
 	}
+	leave, useErr := st.conn.enter("Exec")
+	if useErr != nil {
+		return nil, useErr
+	}
+	defer leave()
+	if st.conn.params.RecoverPanics {
+		defer st.conn.recoverPanic("Exec", st.query, &retErr)
+	}
 	st.ctx = ctx
 
 	if st.dpiStmt == nil && st.query == getConnection {
@@ -416,13 +648,17 @@ func (st *statement) ExecContext(ctx context.Context, args []driver.NamedValue)
 	st.conn.mu.RLock()
 	defer st.conn.mu.RUnlock()
 
+	// before the deadline handling, as it runs its own statement
+	st.detectMaxStringSize(ctx, args)
+
 	// HandleDeadline for all ODPI calls called below
 	done, closeDone := newDoneCh()
 	defer closeDone()
-	if err := st.handleDeadline(ctx, done); err != nil {
+	resetTimeout, err := st.handleDeadline(ctx, done)
+	if err != nil {
 		return nil, err
 	}
-	var err error
+	defer resetTimeout()
 	closeIfBadConn := func(err error) error {
 		closeDone()
 		if err == nil {
@@ -437,6 +673,10 @@ func (st *statement) ExecContext(ctx context.Context, args []driver.NamedValue)
 	if err = st.bindVars(args, logger); err != nil {
 		return nil, closeIfBadConn(err)
 	}
+	if st.BindOnly() {
+		st.bindOnly = false // for this execution only
+		return driver.ResultNoRows, nil
+	}
 
 	mode := st.ExecMode()
 	//fmt.Printf("%p.%p: inTran? %t\n%s\n", st.conn, st, st.inTransaction, st.query)
@@ -450,6 +690,9 @@ func (st *statement) ExecContext(ctx context.Context, args []driver.NamedValue)
 	var f func() C.int
 	many := !st.PlSQLArrays() && st.arrLen > 0
 	if many {
+		if st.BatchErrors() {
+			mode |= C.DPI_MODE_EXEC_BATCH_ERRORS
+		}
 		f = func() C.int { return C.dpiStmt_executeMany(st.dpiStmt, mode, C.uint32_t(st.arrLen)) }
 	} else {
 		f = func() C.int { return C.dpiStmt_execute(st.dpiStmt, mode, nil) }
@@ -469,6 +712,10 @@ func (st *statement) ExecContext(ctx context.Context, args []driver.NamedValue)
 		}
 	}
 	if err != nil {
+		err = st.conn.withLockDiagnostics(err)
+		if !many {
+			err = withSQLExcerpt(st.withArrayCapacity(err), st.query)
+		}
 		return nil, closeIfBadConn(err) //fmt.Errorf("dpiStmt_execute(mode=%d arrLen=%d): %w", mode, arrLen, err))
 	}
 
@@ -480,21 +727,17 @@ func (st *statement) ExecContext(ctx context.Context, args []driver.NamedValue)
 			continue
 		}
 		i := i
-		if st.dpiStmtInfo.isReturning == 1 {
-			var n C.uint32_t
-			data := &st.data[i][0]
-			if err := st.checkExec(func() C.int { return C.dpiVar_getReturnedData(st.vars[i], 0, &n, &data) }); err != nil {
-				return nil, closeIfBadConn(fmt.Errorf("%d.getReturnedData: %w", i, err))
-			}
-			if n == 0 {
-				st.data[i] = st.data[i][:0]
-			} else {
-				st.data[i] = unsafe.Slice(data, n)
+		data := st.data[i]
+		isReturning := st.dpiStmtInfo.isReturning == 1
+		if isReturning {
+			var err error
+			if data, err = st.returnedData(i, many); err != nil {
+				return nil, closeIfBadConn(err)
 			}
 		}
 		dest := st.dests[i]
 		if !st.isSlice[i] {
-			if err := get(dest, st.data[i]); err != nil {
+			if err := get(dest, data); err != nil {
 				if logger != nil {
 					logger.Log("get", i, "error", err)
 				}
@@ -505,15 +748,17 @@ func (st *statement) ExecContext(ctx context.Context, args []driver.NamedValue)
 			}
 			continue
 		}
-		var n C.uint32_t = 1
-		if err := st.checkExec(func() C.int { return C.dpiVar_getNumElementsInArray(st.vars[i], &n) }); err != nil {
-			if logger != nil {
-				logger.Log("msg", "getNumElementsInArray", "i", i, "error", err)
+		n := C.uint32_t(len(data))
+		if !isReturning {
+			if err := st.checkExec(func() C.int { return C.dpiVar_getNumElementsInArray(st.vars[i], &n) }); err != nil {
+				if logger != nil {
+					logger.Log("msg", "getNumElementsInArray", "i", i, "error", err)
+				}
+				return nil, closeIfBadConn(fmt.Errorf("%d.getNumElementsInArray: %w", i, err))
 			}
-			return nil, closeIfBadConn(fmt.Errorf("%d.getNumElementsInArray: %w", i, err))
 		}
 		//fmt.Printf("i=%d dest=%T %#v\n", i, dest, dest)
-		if err := get(dest, st.data[i][:n]); err != nil {
+		if err := get(dest, data[:n]); err != nil {
 			if logger != nil {
 				logger.Log("msg", "get", "i", i, "n", n, "error", err)
 			}
@@ -527,15 +772,45 @@ func (st *statement) ExecContext(ctx context.Context, args []driver.NamedValue)
 	if st.checkExec(func() C.int { return C.dpiStmt_getRowCount(st.dpiStmt, &count) }) != nil {
 		return nil, nil
 	}
+	if many && st.BatchErrors() {
+		if err := st.getBatchErrors(int64(count)); err != nil {
+			return nil, err
+		}
+	}
 	return driver.RowsAffected(count), nil
 }
 
+// returnedData returns the data returned into the i-th variable by a DML RETURNING statement:
+// for executeMany (many), the values returned for all the rows, in order.
+func (st *statement) returnedData(i int, many bool) ([]C.dpiData, error) {
+	rows := 1
+	if many {
+		rows = st.arrLen
+	}
+	var all []C.dpiData
+	for pos := 0; pos < rows; pos++ {
+		var n C.uint32_t
+		var data *C.dpiData
+		if err := st.checkExec(func() C.int { return C.dpiVar_getReturnedData(st.vars[i], C.uint32_t(pos), &n, &data) }); err != nil {
+			return nil, fmt.Errorf("%d.getReturnedData(%d): %w", i, pos, err)
+		}
+		if n == 0 {
+			continue
+		}
+		if rows == 1 {
+			return unsafe.Slice(data, n), nil
+		}
+		all = append(all, unsafe.Slice(data, n)...)
+	}
+	return all, nil
+}
+
 // QueryContext executes a query that may return rows, such as a SELECT.
 //
 // QueryContext must honor the context timeout and return when it is canceled.
 //
 // Cancelation/timeout is honored, execution is broken, but you may have to disable out-of-bound execution - see https://github.com/oracle/odpi/issues/116 for details.
-func (st *statement) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+func (st *statement) QueryContext(ctx context.Context, args []driver.NamedValue) (_ driver.Rows, retErr error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
@@ -545,6 +820,14 @@ func (st *statement) QueryContext(ctx context.Context, args []driver.NamedValue)
 	if st.conn == nil {
 		return nil, driver.ErrBadConn
 	}
+	leave, err := st.conn.enter("Query")
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+	if st.conn.params.RecoverPanics {
+		defer st.conn.recoverPanic("Query", st.query, &retErr)
+	}
 	st.conn.mu.RLock()
 	defer st.conn.mu.RUnlock()
 	return st.queryContextNotLocked(ctx, args)
@@ -571,12 +854,16 @@ func (st *statement) queryContextNotLocked(ctx context.Context, args []driver.Na
 		return args[0].Value.(driver.Rows), nil
 	}
 
+	// before the deadline handling, as it runs its own statement
+	st.detectMaxStringSize(ctx, args)
+
 	done, closeDone := newDoneCh()
 	defer closeDone()
-	if err := st.handleDeadline(ctx, done); err != nil {
+	resetTimeout, err := st.handleDeadline(ctx, done)
+	if err != nil {
 		return nil, err
 	}
-	var err error
+	defer resetTimeout()
 	closeIfBadConn := func(err error) error {
 		closeDone()
 		if err == nil {
@@ -618,7 +905,7 @@ func (st *statement) queryContextNotLocked(ctx context.Context, args []driver.Na
 		}
 	}
 	if err != nil {
-		return nil, closeIfBadConn(fmt.Errorf("dpiStmt_execute: %w", err))
+		return nil, closeIfBadConn(withSQLExcerpt(st.conn.withLockDiagnostics(fmt.Errorf("dpiStmt_execute: %w", err)), st.query))
 	}
 
 	rows, err := st.openRows(int(colCount))
@@ -637,7 +924,7 @@ func (st *statement) queryContextNotLocked(ctx context.Context, args []driver.Na
 func (st *statement) NumInput() int {
 	logger := getLogger()
 	if logger != nil {
-		logger.Log("msg", "NumInput", "stmt", fmt.Sprintf("%p", st), "dpiStmt", fmt.Sprintf("%p", st.dpiStmt), "query", st.query)
+		logger.Log("msg", "NumInput", "stmt", fmt.Sprintf("%p", st), "dpiStmt", fmt.Sprintf("%p", st.dpiStmt), "query", sqlForLog(st.query))
 	}
 	if st.query == wrapResultset {
 		return 1
@@ -716,12 +1003,31 @@ type argInfo struct {
 	isIn, isOut bool
 }
 
+// hasSliceOut reports whether any of the args is an sql.Out with a slice (but not []byte) destination.
+func hasSliceOut(args []driver.NamedValue) bool {
+	for _, a := range args {
+		out, ok := a.Value.(sql.Out)
+		if !ok || out.Dest == nil {
+			continue
+		}
+		t := reflect.TypeOf(out.Dest)
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+			return true
+		}
+	}
+	return false
+}
+
 // bindVars binds the given args into new variables.
 func (st *statement) bindVars(args []driver.NamedValue, logger Logger) error {
 	if logger != nil {
 		logger.Log("enter", "bindVars", "st", fmt.Sprintf("%p", st), "args", args)
 	}
 	var named bool
+	st.inferPlSQLArrays = st.dpiStmtInfo.isPLSQL == 1 && hasSliceOut(args)
 	if cap(st.vars) < len(args) {
 		st.vars = make([]*C.dpiVar, len(args))
 	} else {
@@ -756,6 +1062,13 @@ func (st *statement) bindVars(args []driver.NamedValue, logger Logger) error {
 	rArgs := make([]reflect.Value, len(args))
 	minArrLen, maxArrLen := -1, -1
 
+	var maxBinds, maxSliceLen int
+	if st.conn != nil {
+		maxBinds, maxSliceLen = st.conn.params.MaxBinds, st.conn.params.MaxArraySize
+	}
+	if maxBinds > 0 && len(args) > maxBinds {
+		return &LimitError{Limit: "maxBinds", Value: len(args), Max: maxBinds}
+	}
 	st.arrLen = minArrLen
 	maxArraySize := st.ArraySize()
 
@@ -796,7 +1109,13 @@ func (st *statement) bindVars(args []driver.NamedValue, logger Logger) error {
 		}
 		if _, isByteSlice := value.([]byte); !isByteSlice {
 			st.isSlice[i] = rArgs[i].Kind() == reflect.Slice
-			if !st.PlSQLArrays() && st.isSlice[i] {
+			if st.isSlice[i] && maxSliceLen > 0 {
+				if n := rArgs[i].Len(); n > maxSliceLen {
+					return fmt.Errorf("%d. arg: %w", i+1, &LimitError{Limit: "maxArraySize", Value: n, Max: maxSliceLen})
+				}
+			}
+			// OUT-only slices (such as RETURNING INTO) are filled, their length does not count.
+			if !st.PlSQLArrays() && st.isSlice[i] && info.isIn {
 				n := rArgs[i].Len()
 				if minArrLen == -1 || n < minArrLen {
 					minArrLen = n
@@ -852,7 +1171,10 @@ func (st *statement) bindVars(args []driver.NamedValue, logger Logger) error {
 		if st.PlSQLArrays() && st.isSlice[i] {
 			n = rv.Len()
 			if info.isOut {
-				n = rv.Cap()
+				// the destination's capacity is the maximum number of elements returned
+				if n = rv.Cap(); n == 0 {
+					n = maxArraySize
+				}
 			}
 		}
 		if logger != nil {
@@ -865,18 +1187,26 @@ func (st *statement) bindVars(args []driver.NamedValue, logger Logger) error {
 			SliceLen: n, BufSize: info.bufSize,
 			ObjectType: info.objType,
 		}
-		if vi.IsPLSArray && vi.SliceLen > maxArraySize {
-			return fmt.Errorf("maximum array size allowed is %d", maxArraySize)
+		if vi.IsPLSArray {
+			limit := maxArraySize
+			if info.isOut {
+				limit = maxPlSQLArraySize
+			}
+			if vi.SliceLen > limit {
+				return fmt.Errorf("%d. arg: maximum array size allowed is %d", i+1, limit)
+			}
 		}
 		mustAllocate := st.vars[i] == nil || st.data[i] == nil
 		if !mustAllocate && st.varInfos[i] != vi {
-			C.dpiVar_release(st.vars[i])
+			st.mem.releaseVar(st.vars[i])
+			st.bound.forget(i) // the new variable may get the same address
 			mustAllocate = true
 		}
 		if mustAllocate {
 			if st.vars[i], st.data[i], err = st.newVar(vi); err != nil {
 				return fmt.Errorf("%d: %w", i, err)
 			}
+			st.mem.addVar(false, st.vars[i], len(st.data[i]))
 			st.varInfos[i] = vi
 		}
 
@@ -922,29 +1252,74 @@ func (st *statement) bindVars(args []driver.NamedValue, logger Logger) error {
 
 	if !named {
 		for i, v := range st.vars {
+			if st.bound.isBound(i, unsafe.Pointer(v), "") {
+				continue
+			}
 			i, v := i, v
 			if err := st.checkExecNoLOT(func() C.int { return C.dpiStmt_bindByPos(st.dpiStmt, C.uint32_t(i+1), v) }); err != nil {
+				st.bound.reset()
 				return fmt.Errorf("bindByPos[%d]: %w", i, err)
 			}
+			st.bound.set(i, unsafe.Pointer(v), "")
 		}
 		return nil
 	}
-	for i, a := range args {
-		name := a.Name
-		if name == "" {
-			name = strconv.Itoa(a.Ordinal)
+	if st.bindNamesCache == nil {
+		var err error
+		if st.bindNamesCache, err = st.bindNames(); err != nil {
+			return err
+		}
+	}
+	names, err := resolveBindNames(st.bindNamesCache, args)
+	if err != nil {
+		return err
+	}
+	for i, name := range names {
+		if st.bound.isBound(i, unsafe.Pointer(st.vars[i]), name) {
+			continue
 		}
 		//fmt.Printf("bindByName(%q)\n", name)
 		cName := C.CString(name)
 		err := st.checkExecNoLOT(func() C.int { return C.dpiStmt_bindByName(st.dpiStmt, cName, C.uint32_t(len(name)), st.vars[i]) })
 		C.free(unsafe.Pointer(cName))
 		if err != nil {
+			st.bound.reset()
 			return fmt.Errorf("bindByName[%q]: %w", name, err)
 		}
+		st.bound.set(i, unsafe.Pointer(st.vars[i]), name)
 	}
 	return nil
 }
 
+// bindCache records the variables bound to the places of a statement (with their names for named binding).
+type bindCache struct {
+	vars  []unsafe.Pointer
+	names []string
+}
+
+// isBound reports whether v is bound to the i-th place, with the same name (empty for positional binding).
+func (bc *bindCache) isBound(i int, v unsafe.Pointer, name string) bool {
+	return v != nil && i < len(bc.vars) && bc.vars[i] == v && bc.names[i] == name
+}
+
+// set records that v is bound to the i-th place.
+func (bc *bindCache) set(i int, v unsafe.Pointer, name string) {
+	for len(bc.vars) <= i {
+		bc.vars, bc.names = append(bc.vars, nil), append(bc.names, "")
+	}
+	bc.vars[i], bc.names[i] = v, name
+}
+
+// forget the variable bound to the i-th place.
+func (bc *bindCache) forget(i int) {
+	if i < len(bc.vars) {
+		bc.vars[i] = nil
+	}
+}
+
+// reset forgets all the bound variables.
+func (bc *bindCache) reset() { bc.vars, bc.names = bc.vars[:0], bc.names[:0] }
+
 func (st *statement) bindVarTypeSwitch(info *argInfo, get *dataGetter, value interface{}) (interface{}, error) {
 	nilPtr := false
 	logger := getLogger()
@@ -972,6 +1347,19 @@ func (st *statement) bindVarTypeSwitch(info *argInfo, get *dataGetter, value int
 	}
 
 	switch v := value.(type) {
+	case SizedReader:
+		if info.isOut {
+			return value, errors.New("SizedReader can only be used as IN bind")
+		}
+		if v.Size < 0 || v.Size > maxVarSize {
+			return value, fmt.Errorf("SizedReader size %d is out of range [0, %d], use Lob", v.Size, maxVarSize)
+		}
+		// streamed into a temporary LOB, which Oracle converts to RAW/VARCHAR2 implicitly
+		info.typ, info.natTyp = C.DPI_ORACLE_TYPE_BLOB, C.DPI_NATIVE_TYPE_LOB
+		if v.IsString {
+			info.typ = C.DPI_ORACLE_TYPE_CLOB
+		}
+		info.set = st.dataSetSizedReader
 	case Lob, []Lob:
 		info.typ, info.natTyp = C.DPI_ORACLE_TYPE_BLOB, C.DPI_NATIVE_TYPE_LOB
 		var isClob bool
@@ -979,7 +1367,8 @@ func (st *statement) bindVarTypeSwitch(info *argInfo, get *dataGetter, value int
 		case Lob:
 			isClob = v.IsClob
 		case []Lob:
-			isClob = len(v) > 0 && v[0].IsClob
+			// an OUT-only slice may be empty: look at the first element of its capacity
+			isClob = cap(v) > 0 && v[:1][0].IsClob
 		}
 		if isClob {
 			info.typ = C.DPI_ORACLE_TYPE_CLOB
@@ -1150,49 +1539,92 @@ func (st *statement) bindVarTypeSwitch(info *argInfo, get *dataGetter, value int
 			}
 		}
 
+	case Rowid, []Rowid:
+		info.typ, info.natTyp = C.DPI_ORACLE_TYPE_VARCHAR, C.DPI_NATIVE_TYPE_BYTES
+		info.set = dataSetBytes
+		if info.isOut {
+			info.bufSize = 4000
+			*get = dataGetBytes
+		} else {
+			switch v := v.(type) {
+			case Rowid:
+				info.bufSize = len(v)
+			case []Rowid:
+				for _, s := range v {
+					if n := len(s); n > info.bufSize {
+						info.bufSize = n
+					}
+				}
+			}
+		}
+
 	case string, []string, nil:
+		if !info.isOut && st.LongStringAsClob() {
+			if lobs := st.longStringsAsClob(v); lobs != nil {
+				return st.bindVarTypeSwitch(info, get, lobs)
+			}
+		}
 		info.typ, info.natTyp = C.DPI_ORACLE_TYPE_VARCHAR, C.DPI_NATIVE_TYPE_BYTES
 		info.set = dataSetBytes
 		if info.isOut {
 			info.bufSize = 32767
 			*get = dataGetBytes
 		} else {
+			// bigger variables would be bound as LONG (ORA-01461 in SQL)
+			limit := bindStringLimit(st.dpiStmtInfo.isPLSQL != 0, st.conn.maxStringSize)
 			switch v := v.(type) {
 			case string:
 				info.bufSize = 4 * len(v)
+				if info.bufSize > limit && len(v) <= limit {
+					info.bufSize = limit
+				}
 			case []string:
+				var maxLen int
 				for _, s := range v {
-					if n := 4 * len(s); n > info.bufSize {
-						info.bufSize = n
+					if len(s) > maxLen {
+						maxLen = len(s)
 					}
 				}
+				if info.bufSize = 4 * maxLen; info.bufSize > limit && maxLen <= limit {
+					info.bufSize = limit
+				}
 			}
 		}
 
 	case time.Time, NullTime, *timestamppb.Timestamp:
 		info.typ, info.natTyp = C.DPI_ORACLE_TYPE_TIMESTAMP_TZ, C.DPI_NATIVE_TYPE_TIMESTAMP
-		info.set = st.conn.dataSetTime
+		info.set = st.dataSetTime
 		if info.isOut {
 			*get = st.conn.dataGetTime
 		}
 
 	case []time.Time, []NullTime, []*timestamppb.Timestamp:
 		info.typ, info.natTyp = C.DPI_ORACLE_TYPE_TIMESTAMP_TZ, C.DPI_NATIVE_TYPE_TIMESTAMP
-		if st.plSQLArrays {
-			info.typ = C.DPI_ORACLE_TYPE_DATE
-		}
-		info.set = st.conn.dataSetTime
+		info.set = st.dataSetTime
 		if info.isOut {
 			*get = st.conn.dataGetTime
 		}
+		if st.PlSQLArrays() {
+			info.typ = C.DPI_ORACLE_TYPE_DATE
+			if info.isOut {
+				*get = st.conn.dataGetDate
+			}
+		}
 
-	case time.Duration, []time.Duration:
+	case time.Duration, []time.Duration, IntervalDS, []IntervalDS:
 		info.typ, info.natTyp = C.DPI_ORACLE_TYPE_INTERVAL_DS, C.DPI_NATIVE_TYPE_INTERVAL_DS
 		info.set = st.conn.dataSetIntervalDS
 		if info.isOut {
 			*get = st.conn.dataGetIntervalDS
 		}
 
+	case IntervalYM, []IntervalYM:
+		info.typ, info.natTyp = C.DPI_ORACLE_TYPE_INTERVAL_YM, C.DPI_NATIVE_TYPE_INTERVAL_YM
+		info.set = dataSetIntervalYM
+		if info.isOut {
+			*get = dataGetIntervalYM
+		}
+
 	case Object:
 		info.objType = v.ObjectType.dpiObjectType
 		info.typ, info.natTyp = C.DPI_ORACLE_TYPE_OBJECT, C.DPI_NATIVE_TYPE_OBJECT
@@ -1316,20 +1748,13 @@ func dataSetBool(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
 	if vv == nil {
 		return dataSetNull(dv, data, nil)
 	}
-	b := C.int(0)
 	if v, ok := vv.(bool); ok {
-		if v {
-			b = 1
-		}
-		C.dpiData_setBool(&data[0], b)
+		C.dpiData_setBool(&data[0], C.int(b2i(v)))
 		return nil
 	}
 	if bb, ok := vv.([]bool); ok {
 		for i, v := range bb {
-			if v {
-				b = 1
-			}
-			C.dpiData_setBool(&data[i], b)
+			C.dpiData_setBool(&data[i], C.int(b2i(v)))
 		}
 		return nil
 	}
@@ -1341,21 +1766,32 @@ func dataSetBool(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
 
 var _ = sql.Scanner((*NullTime)(nil))
 
+// dataGetTime gets the times from a TIMESTAMP WITH TIME ZONE variable.
 func (c *conn) dataGetTime(v interface{}, data []C.dpiData) error {
+	return c.dataGetTimeTZ(v, data, true)
+}
+
+// dataGetDate gets the times from a DATE variable.
+func (c *conn) dataGetDate(v interface{}, data []C.dpiData) error {
+	return c.dataGetTimeTZ(v, data, false)
+}
+
+// dataGetTimeTZ gets the times, with their offset iff hasTZ, in the connection's time zone otherwise.
+func (c *conn) dataGetTimeTZ(v interface{}, data []C.dpiData, hasTZ bool) error {
 	switch x := v.(type) {
 	case *time.Time:
 		if len(data) == 0 || data[0].isNull == 1 {
 			*x = time.Time{}
 			return nil
 		}
-		c.dataGetTimeC(x, &data[0])
+		c.dataGetTimeC(x, &data[0], hasTZ)
 	case *timestamppb.Timestamp:
 		if len(data) == 0 || data[0].isNull == 1 {
 			x.Reset()
 			return nil
 		}
 		var t time.Time
-		c.dataGetTimeC(&t, &data[0])
+		c.dataGetTimeC(&t, &data[0], hasTZ)
 		if t.IsZero() {
 			x.Reset()
 		} else {
@@ -1364,7 +1800,7 @@ func (c *conn) dataGetTime(v interface{}, data []C.dpiData) error {
 
 	case *NullTime:
 		if x.Valid = !(len(data) == 0 || data[0].isNull == 1); x.Valid {
-			c.dataGetTimeC(&x.Time, &data[0])
+			c.dataGetTimeC(&x.Time, &data[0], hasTZ)
 		}
 
 	case *[]time.Time:
@@ -1375,7 +1811,7 @@ func (c *conn) dataGetTime(v interface{}, data []C.dpiData) error {
 			*x = make([]time.Time, n)
 		}
 		for i := range data {
-			c.dataGetTimeC(&((*x)[i]), &data[i])
+			c.dataGetTimeC(&((*x)[i]), &data[i], hasTZ)
 		}
 	case *[]*timestamppb.Timestamp:
 		n := len(data)
@@ -1386,7 +1822,7 @@ func (c *conn) dataGetTime(v interface{}, data []C.dpiData) error {
 		}
 		for i := range data {
 			var t time.Time
-			c.dataGetTimeC(&t, &data[i])
+			c.dataGetTimeC(&t, &data[i], hasTZ)
 			if t.IsZero() {
 				(*x)[i].Reset()
 			} else {
@@ -1406,7 +1842,7 @@ func (c *conn) dataGetTime(v interface{}, data []C.dpiData) error {
 		}
 		for i := range data {
 			if (*x)[i].Valid = !(data[i].isNull == 1); (*x)[i].Valid {
-				c.dataGetTimeC(&((*x)[i].Time), &data[i])
+				c.dataGetTimeC(&((*x)[i].Time), &data[i], hasTZ)
 			}
 		}
 
@@ -1418,23 +1854,25 @@ func (c *conn) dataGetTime(v interface{}, data []C.dpiData) error {
 
 var errUnknownType = errors.New("unknown type")
 
-func (c *conn) dataGetTimeC(t *time.Time, data *C.dpiData) {
+func (c *conn) dataGetTimeC(t *time.Time, data *C.dpiData, hasTZ bool) {
 	if data.isNull == 1 {
 		*t = time.Time{}
 		return
 	}
 	//ts := C.dpiData_getTimestamp(data)
 	ts := *((*C.dpiTimestamp)(unsafe.Pointer(&data.value)))
-	*t = time.Date(
-		int(ts.year), time.Month(ts.month), int(ts.day),
-		int(ts.hour), int(ts.minute), int(ts.second), int(ts.fsecond),
-		timeZoneFor(ts.tzHourOffset, ts.tzMinuteOffset, c.Timezone()),
-	)
+	*t = timeFromTimestamp(ts, hasTZ, c.Timezone())
 }
 
 var date8192begin, date8192end = time.Date(0, time.December, 31, 0, 0, 0, 0, time.UTC), time.Date(1, time.January, 2, 0, 0, 0, 0, time.UTC)
 
-func (c *conn) dataSetTime(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
+// dataSetTime sets the times, in their own time zone with the KeepTimeZone option.
+func (st *statement) dataSetTime(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
+	return st.conn.dataSetTimeIn(dv, data, vv, st.KeepTimeZone())
+}
+
+// dataSetTimeIn sets the times converted to the session's time zone, or in their own with keepTZ.
+func (c *conn) dataSetTimeIn(dv *C.dpiVar, data []C.dpiData, vv interface{}, keepTZ bool) error {
 	if vv == nil {
 		return dataSetNull(dv, data, nil)
 	}
@@ -1493,6 +1931,9 @@ func (c *conn) dataSetTime(dv *C.dpiVar, data []C.dpiData, vv interface{}) error
 			continue
 		}
 		tz, tzOff := tz, 0
+		if keepTZ {
+			tz = t.Location()
+		}
 		if tz != time.UTC && // Against ORA-08192
 			date8192begin.Before(t) && date8192end.After(t) {
 			tz = time.UTC
@@ -1547,6 +1988,24 @@ func (c *conn) dataGetIntervalDS(v interface{}, data []C.dpiData) error {
 		for i := range data {
 			dataGetIntervalDS(&((*x)[i]), &data[i])
 		}
+
+	case *IntervalDS:
+		if len(data) == 0 || data[0].isNull == 1 {
+			*x = 0
+			return nil
+		}
+		dataGetIntervalDS((*time.Duration)(x), &data[0])
+
+	case *[]IntervalDS:
+		n := len(data)
+		if cap(*x) >= n {
+			*x = (*x)[:n]
+		} else {
+			*x = make([]IntervalDS, n)
+		}
+		for i := range data {
+			dataGetIntervalDS((*time.Duration)(&((*x)[i])), &data[i])
+		}
 	}
 	return nil
 }
@@ -1580,6 +2039,17 @@ func (c *conn) dataSetIntervalDS(dv *C.dpiVar, data []C.dpiData, vv interface{})
 			data[i].isNull = C.int(b2i(t == 0))
 		}
 
+	case IntervalDS:
+		times[0] = time.Duration(x)
+		data[0].isNull = C.int(b2i(x == 0))
+
+	case []IntervalDS:
+		times = make([]time.Duration, len(x))
+		for i, t := range x {
+			times[i] = time.Duration(t)
+			data[i].isNull = C.int(b2i(t == 0))
+		}
+
 	default:
 		for i := range data {
 			data[i].isNull = 1
@@ -1615,6 +2085,62 @@ func (c *conn) dataSetIntervalDS(dv *C.dpiVar, data []C.dpiData, vv interface{})
 	return nil
 }
 
+func dataGetIntervalYM(v interface{}, data []C.dpiData) error {
+	switch x := v.(type) {
+	case *IntervalYM:
+		if len(data) == 0 || data[0].isNull == 1 {
+			*x = IntervalYM{}
+			return nil
+		}
+		dataGetIntervalYMOne(x, &data[0])
+
+	case *[]IntervalYM:
+		n := len(data)
+		if cap(*x) >= n {
+			*x = (*x)[:n]
+		} else {
+			*x = make([]IntervalYM, n)
+		}
+		for i := range data {
+			if data[i].isNull == 1 {
+				(*x)[i] = IntervalYM{}
+				continue
+			}
+			dataGetIntervalYMOne(&((*x)[i]), &data[i])
+		}
+	}
+	return nil
+}
+
+func dataGetIntervalYMOne(ym *IntervalYM, d *C.dpiData) {
+	//v := C.dpiData_getIntervalYM(d)
+	v := *((*C.dpiIntervalYM)(unsafe.Pointer(&d.value)))
+	ym.Years, ym.Months = int(v.years), int(v.months)
+}
+
+func dataSetIntervalYM(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
+	if vv == nil {
+		return dataSetNull(dv, data, nil)
+	}
+	yms := []IntervalYM{{}}
+	switch x := vv.(type) {
+	case IntervalYM:
+		yms[0] = x
+	case []IntervalYM:
+		yms = x
+	default:
+		for i := range data {
+			data[i].isNull = 1
+		}
+		return nil
+	}
+	for i, ym := range yms {
+		data[i].isNull = 0
+		C.dpiData_setIntervalYM(&data[i], C.int32_t(ym.Years), C.int32_t(ym.Months))
+	}
+	return nil
+}
+
 func dataGetNumber(v interface{}, data []C.dpiData) error {
 	switch x := v.(type) {
 	case *int:
@@ -2126,6 +2652,22 @@ func dataGetBytes(v interface{}, data []C.dpiData) error {
 			*x = append(*x, string(dpiData_getBytes(&data[i])))
 		}
 
+	case *Rowid:
+		if len(data) == 0 || data[0].isNull == 1 {
+			*x = ""
+			return nil
+		}
+		*x = Rowid(dpiData_getBytes(&data[0]))
+	case *[]Rowid:
+		*x = (*x)[:0]
+		for i := range data {
+			if data[i].isNull == 1 {
+				*x = append(*x, "")
+				continue
+			}
+			*x = append(*x, Rowid(dpiData_getBytes(&data[i])))
+		}
+
 	case *sql.NullInt32:
 		if len(data) == 0 || data[0].isNull == 1 {
 			x.Int32, x.Valid = 0, false
@@ -2325,6 +2867,24 @@ func dataSetBytes(dv *C.dpiVar, data []C.dpiData, vv interface{}) error {
 			dpiSetFromString(dv, C.uint32_t(i), x)
 		}
 
+	case Rowid:
+		i, x := 0, slice
+		if len(x) == 0 {
+			data[i].isNull = 1
+			return nil
+		}
+		data[i].isNull = 0
+		dpiSetFromString(dv, C.uint32_t(i), string(x))
+	case []Rowid:
+		for i, x := range slice {
+			if len(x) == 0 {
+				data[i].isNull = 1
+				continue
+			}
+			data[i].isNull = 0
+			dpiSetFromString(dv, C.uint32_t(i), string(x))
+		}
+
 	default:
 		return fmt.Errorf("awaited [][]byte/[]string/[]Number, got %T (%#v)", vv, vv)
 	}
@@ -2485,6 +3045,11 @@ func (c *conn) dataGetLOBC(L *Lob, data *C.dpiData) {
 	if lob == nil {
 		return
 	}
+	// the elements of a []Lob may be new, so ask the LOB whether it is a CLOB
+	var lobType C.dpiOracleTypeNum
+	if C.dpiLob_getType(lob, &lobType) != C.DPI_FAILURE {
+		L.IsClob = lobType == C.DPI_ORACLE_TYPE_CLOB || lobType == C.DPI_ORACLE_TYPE_NCLOB
+	}
 	L.Reader = &dpiLobReader{drv: c.drv, dpiLob: lob, IsClob: L.IsClob}
 }
 
@@ -2522,6 +3087,7 @@ func (c *conn) dataSetLOBOne(dv *C.dpiVar, data []C.dpiData, i int, L Lob) error
 		if err := c.checkExec(func() C.int { return C.dpiVar_setFromLob(dv, C.uint32_t(i), r.dpiLob) }); err != nil {
 			return fmt.Errorf("dpiVar_setFromLob(%p): %w", r.dpiLob, err)
 		}
+		return nil
 	}
 	logger := getLogger()
 
@@ -2540,15 +3106,23 @@ func (c *conn) dataSetLOBOne(dv *C.dpiVar, data []C.dpiData, i int, L Lob) error
 		return nil
 	}
 
-	L.Reader = io.MultiReader(bytes.NewReader(a[:n]), L.Reader)
+	_, err := c.setTempLob(dv, i, io.MultiReader(bytes.NewReader(a[:n]), L.Reader), L.IsClob)
+	return err
+}
+
+// setTempLob copies r into a new temporary LOB, chunk by chunk, and sets it as the i-th element of dv.
+//
+// Returns the number of bytes written.
+func (c *conn) setTempLob(dv *C.dpiVar, i int, r io.Reader, isClob bool) (int64, error) {
+	logger := getLogger()
 	typ := C.dpiOracleTypeNum(C.DPI_ORACLE_TYPE_BLOB)
-	if L.IsClob {
+	if isClob {
 		typ = C.DPI_ORACLE_TYPE_CLOB
 	}
 
 	var lob *C.dpiLob
 	if err := c.checkExec(func() C.int { return C.dpiConn_newTempLob(c.dpiConn, typ, &lob) }); err != nil {
-		return fmt.Errorf("newTempLob(typ=%d): %w", typ, err)
+		return 0, fmt.Errorf("newTempLob(typ=%d): %w", typ, err)
 	}
 	var chunkSize C.uint32_t
 	_ = C.dpiLob_getChunkSize(lob, &chunkSize)
@@ -2558,14 +3132,14 @@ func (c *conn) dataSetLOBOne(dv *C.dpiVar, data []C.dpiData, i int, L Lob) error
 	for chunkSize < minChunkSize {
 		chunkSize <<= 1
 	}
-	lw := &dpiLobWriter{dpiLob: lob, drv: c.drv, isClob: L.IsClob}
+	lw := &dpiLobWriter{dpiLob: lob, drv: c.drv, isClob: isClob}
 	defer lw.Close() // Do NOT close before dpiVar_setFromLob !
-	written, err := io.CopyBuffer(lw, L, make([]byte, int(chunkSize)))
+	written, err := io.CopyBuffer(lw, r, make([]byte, int(chunkSize)))
 	if logger != nil {
-		logger.Log("msg", "setLOB", "written", n, "tempLob", fmt.Sprintf("%p", lob), "chunkSize", chunkSize, "error", err)
+		logger.Log("msg", "setLOB", "written", written, "tempLob", fmt.Sprintf("%p", lob), "chunkSize", chunkSize, "error", err)
 	}
 	if err != nil {
-		return err
+		return written, err
 	}
 	{
 		var lobType C.dpiOracleTypeNum
@@ -2579,14 +3153,16 @@ func (c *conn) dataSetLOBOne(dv *C.dpiVar, data []C.dpiData, i int, L Lob) error
 		if logger != nil {
 			logger.Log("msg", "setLOB", "type", lobType, "size", lobSize, "error", err)
 		}
-		if int64(lobSize) != int64(written) {
-			return fmt.Errorf("lobSize=%d, wanted %d", lobSize, written)
+		// the size of a CLOB is in characters
+		if !isClob && int64(lobSize) != int64(written) {
+			return written, fmt.Errorf("lobSize=%d, wanted %d", lobSize, written)
 		}
 	}
 	if err = c.checkExec(func() C.int { return C.dpiVar_setFromLob(dv, C.uint32_t(i), lob) }); err != nil {
-		return fmt.Errorf("dpiVar_setFromLob(%d. %p): %w", i, lob, err)
+		return written, fmt.Errorf("dpiVar_setFromLob(%d. %p): %w", i, lob, err)
 	}
-	return nil
+	c.mem.addTempLob(dv, i)
+	return written, nil
 }
 
 type userType interface {
@@ -3402,6 +3978,7 @@ func (st *statement) openRows(colCount int) (*rows, error) {
 	var info C.dpiQueryInfo
 	var ti C.dpiDataTypeInfo
 	logger := getLogger()
+	vis := make([]varInfo, colCount)
 	for i := 0; i < colCount; i++ {
 		if err := st.checkExecNoLOT(func() C.int {
 			return C.dpiStmt_getQueryInfo(st.dpiStmt, C.uint32_t(i+1), &info)
@@ -3450,18 +4027,24 @@ func (st *statement) openRows(colCount int) (*rows, error) {
 			SizeInChars:    ti.sizeInChars,
 			DBSize:         ti.dbSizeInBytes,
 		}
-		var err error
 		//fmt.Printf("%d. %+v\n", i, r.columns[i])
-		vi := varInfo{
+		vis[i] = varInfo{
 			Typ:        effTypeNum,
 			NatTyp:     ti.defaultNativeTypeNum,
 			ObjectType: ti.objectType,
 			BufSize:    bufSize,
-			SliceLen:   sliceLen,
 		}
+	}
+	if maxBytes := st.AdaptiveFetchBytes(); maxBytes > 0 {
+		sliceLen = adaptiveFetchSizes(vis, maxBytes, &r)
+	}
+	for i, vi := range vis {
+		vi.SliceLen = sliceLen
+		var err error
 		if r.vars[i], r.data[i], err = st.newVar(vi); err != nil {
 			return nil, err
 		}
+		st.mem.addVar(true, r.vars[i], len(r.data[i]))
 
 		if err = st.checkExecNoLOT(func() C.int {
 			return C.dpiStmt_define(st.dpiStmt, C.uint32_t(i+1), r.vars[i])
@@ -3469,12 +4052,20 @@ func (st *statement) openRows(colCount int) (*rows, error) {
 			return nil, fmt.Errorf("define[%d]: %w", i, err)
 		}
 	}
+	if r.fetchSize != 0 {
+		if err := st.checkExecNoLOT(func() C.int {
+			return C.dpiStmt_setFetchArraySize(st.dpiStmt, r.fetchSize)
+		}); err != nil {
+			return nil, fmt.Errorf("setFetchArraySize(%d): %w", r.fetchSize, err)
+		}
+	}
 	if err := st.checkExecNoLOT(func() C.int {
 		return C.dpiStmt_addRef(st.dpiStmt)
 	}); err != nil {
 		return &r, fmt.Errorf("dpiStmt_addRef: %w", err)
 	}
 	st.columns = r.columns
+	rowsSetFinalizer(&r)
 	return &r, nil
 }
 
@@ -3558,6 +4149,7 @@ func stmtSetFinalizer(st *statement, tag string) {
 
 	runtime.SetFinalizer(st, func(st *statement) {
 		if st != nil && st.dpiStmt != nil {
+			atomic.AddUint64(&leaks.Statements, 1)
 			if logger := getLogger(); logger != nil {
 				logger.Log("msg", "ERROR: statement is not closed!", "stmt", st, "tag", tag, "stack", string(stack))
 			} else {
@@ -0,0 +1,46 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestBindCache(t *testing.T) {
+	var a, b [1]byte
+	va, vb := unsafe.Pointer(&a), unsafe.Pointer(&b)
+	var bc bindCache
+	if bc.isBound(0, va, "") {
+		t.Error("empty cache has va")
+	}
+	bc.set(0, va, "")
+	bc.set(1, vb, "")
+	// the second execution with the same variables skips the re-bind
+	if !bc.isBound(0, va, "") || !bc.isBound(1, vb, "") {
+		t.Errorf("%+v: the same variables should be bound", bc)
+	}
+	if bc.isBound(0, vb, "") || bc.isBound(2, va, "") || bc.isBound(0, nil, "") {
+		t.Errorf("%+v: other variables/places should not be bound", bc)
+	}
+	// named binding after positional
+	if bc.isBound(0, va, "a") {
+		t.Error("positional binding is not named")
+	}
+	bc.set(0, va, "a")
+	if !bc.isBound(0, va, "a") || bc.isBound(0, va, "b") || bc.isBound(0, va, "") {
+		t.Errorf("%+v: named", bc)
+	}
+	// a re-allocated variable may get the same address
+	bc.forget(1)
+	if bc.isBound(1, vb, "") {
+		t.Error("forgotten variable is still bound")
+	}
+	bc.reset()
+	if bc.isBound(0, va, "a") {
+		t.Error("reset cache has va")
+	}
+}
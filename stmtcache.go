@@ -0,0 +1,78 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include "dpiImpl.h"
+*/
+import "C"
+
+import (
+	"context"
+	"fmt"
+)
+
+// StmtCacheSize returns the size of the connection's statement cache.
+func (c *conn) StmtCacheSize() (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var size C.uint32_t
+	if err := c.checkExec(func() C.int { return C.dpiConn_getStmtCacheSize(c.dpiConn, &size) }); err != nil {
+		return 0, fmt.Errorf("getStmtCacheSize: %w", err)
+	}
+	return int(size), nil
+}
+
+// SetStmtCacheSize sets the size of the connection's statement cache - 0 disables it.
+func (c *conn) SetStmtCacheSize(size int) error {
+	if size < 0 {
+		size = 0
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if err := c.checkExec(func() C.int { return C.dpiConn_setStmtCacheSize(c.dpiConn, C.uint32_t(size)) }); err != nil {
+		return fmt.Errorf("setStmtCacheSize(%d): %w", size, err)
+	}
+	return nil
+}
+
+// GetStmtCacheSize returns the statement cache size of the connection of ex.
+func GetStmtCacheSize(ctx context.Context, ex Execer) (size int, err error) {
+	err = Raw(ctx, ex, func(c Conn) error {
+		size, err = c.StmtCacheSize()
+		return err
+	})
+	return size, err
+}
+
+// SetStmtCacheSize sets the statement cache size of the connection of ex - use a *sql.Conn!
+//
+// For a pooled connection, this lasts till the session is released to the pool;
+// use the stmtCacheSize connection parameter to set it for all the sessions.
+func SetStmtCacheSize(ctx context.Context, ex Execer, size int) error {
+	return Raw(ctx, ex, func(c Conn) error { return c.SetStmtCacheSize(size) })
+}
+
+// PurgeStmtCache removes the statement with the text qry from the statement cache
+// of the connection of ex, so its next execution will be parsed again.
+//
+// To purge it while executing, use the DeleteFromCache option.
+func PurgeStmtCache(ctx context.Context, ex Execer, qry string) error {
+	return Raw(ctx, ex, func(c Conn) error {
+		st, err := c.PrepareContext(ctx, qry)
+		if err != nil {
+			return err
+		}
+		if s, ok := st.(*statement); ok {
+			s.Lock()
+			if s.dpiStmt != nil {
+				C.dpiStmt_deleteFromCache(s.dpiStmt)
+			}
+			s.Unlock()
+		}
+		return st.Close()
+	})
+}
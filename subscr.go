@@ -22,6 +22,8 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 	"unsafe"
 )
 
@@ -54,6 +56,24 @@ func SubscrClientInitiated(b bool) SubscriptionOption {
 	}
 }
 
+// SubscrGrouping is a SubscriptionOption that groups the notifications for the given interval,
+// delivering only a summary of them (summary is true), or the last one.
+//
+// Use it for high-churn tables, so the notification storms are collapsed.
+func SubscrGrouping(interval time.Duration, summary bool) SubscriptionOption {
+	return func(p *subscriptionParams) {
+		p.GroupingInterval, p.GroupingSummary = interval, summary
+	}
+}
+
+// SubscrRowIDs is a SubscriptionOption that sets whether the ROWIDs of the changed rows are delivered (the default),
+// or only the changed tables - which is much less for big changes.
+func SubscrRowIDs(b bool) SubscriptionOption {
+	return func(p *subscriptionParams) {
+		p.NoRowIDs = !b
+	}
+}
+
 // subscrParams are parameters for a new Subscription.
 type subscriptionParams struct {
 	// IPAddress on which the subscription listens to receive notifications,
@@ -74,6 +94,13 @@ type subscriptionParams struct {
 	// This feature is only available when Oracle Client 19.4
 	// and Oracle Database 19.4 or higher are being used.
 	ClientInitiated bool
+
+	// GroupingInterval groups the notifications for this interval, if not 0.
+	GroupingInterval time.Duration
+	// GroupingSummary delivers a summary of the grouped notifications, instead of the last one.
+	GroupingSummary bool
+	// NoRowIDs does not deliver the ROWIDs of the changed rows.
+	NoRowIDs bool
 }
 
 // Cannot pass *Subscription to C, so pass an uint64 that points to this map entry
@@ -208,7 +235,18 @@ func (c *conn) NewSubscription(name string, cb func(Event), options ...Subscript
 	C.dpiContext_initSubscrCreateParams(c.drv.dpiContext, params)
 	params.subscrNamespace = C.DPI_SUBSCR_NAMESPACE_DBCHANGE
 	params.protocol = C.DPI_SUBSCR_PROTO_CALLBACK
-	params.qos = C.DPI_SUBSCR_QOS_BEST_EFFORT | C.DPI_SUBSCR_QOS_QUERY | C.DPI_SUBSCR_QOS_ROWIDS
+	params.qos = C.DPI_SUBSCR_QOS_BEST_EFFORT | C.DPI_SUBSCR_QOS_QUERY
+	if !p.NoRowIDs {
+		params.qos |= C.DPI_SUBSCR_QOS_ROWIDS
+	}
+	if p.GroupingInterval > 0 {
+		params.groupingClass = C.DPI_SUBSCR_GROUPING_CLASS_TIME
+		params.groupingValue = C.uint32_t((p.GroupingInterval + time.Second - 1) / time.Second)
+		params.groupingType = C.DPI_SUBSCR_GROUPING_TYPE_LAST
+		if p.GroupingSummary {
+			params.groupingType = C.DPI_SUBSCR_GROUPING_TYPE_SUMMARY
+		}
+	}
 	params.operations = C.DPI_OPCODE_ALL_OPS
 	if name != "" || p.IPAddress != "" {
 		if name != "" {
@@ -289,7 +327,7 @@ func (s *Subscription) Register(qry string, params ...interface{}) error {
 	}
 	logger := getLogger()
 	if logger != nil {
-		logger.Log("msg", "subscribed", "query", qry, "id", queryID)
+		logger.Log("msg", "subscribed", "query", sqlForLog(qry), "id", queryID)
 	}
 
 	return nil
@@ -316,6 +354,55 @@ func (s *Subscription) Close() error {
 	return nil
 }
 
+// EventOverflowPolicy is what EventChannel does when the channel is full.
+type EventOverflowPolicy uint8
+
+const (
+	// EventBlock blocks the notification (the callback) till there is room in the channel.
+	EventBlock = EventOverflowPolicy(iota)
+	// EventDropNewest drops the new event.
+	EventDropNewest
+	// EventDropOldest drops the oldest event in the channel, to make room for the new one.
+	EventDropOldest
+)
+
+// EventChannel returns a callback usable in NewSubscription, which sends the events
+// into the returned channel of the given size, so a notification storm cannot exhaust the memory
+// of the subscriber; and a function returning the number of the dropped events.
+//
+// If events were dropped, the consumer should reconcile its state.
+func EventChannel(size int, policy EventOverflowPolicy) (callback func(Event), events <-chan Event, dropped func() uint64) {
+	ch := make(chan Event, size)
+	var n uint64
+	dropped = func() uint64 { return atomic.LoadUint64(&n) }
+	callback = func(e Event) {
+		switch policy {
+		case EventDropNewest:
+			select {
+			case ch <- e:
+			default:
+				atomic.AddUint64(&n, 1)
+			}
+		case EventDropOldest:
+			for {
+				select {
+				case ch <- e:
+					return
+				default:
+				}
+				select {
+				case <-ch:
+					atomic.AddUint64(&n, 1)
+				default:
+				}
+			}
+		default:
+			ch <- e
+		}
+	}
+	return callback, ch, dropped
+}
+
 // EventType is the type of an event.
 type EventType C.dpiEventType
 
@@ -328,6 +415,10 @@ const (
 	EvtObjChange   = EventType(C.DPI_EVENT_OBJCHANGE)
 	EvtQueryChange = EventType(C.DPI_EVENT_QUERYCHANGE)
 	EvtAQ          = EventType(C.DPI_EVENT_AQ)
+
+	// EvtPossiblyMissed is sent by ResilientSubscription after re-registering,
+	// as the events between the connection loss and the re-registration are lost.
+	EvtPossiblyMissed = EventType(0x8000)
 )
 
 // Operation in the DB.
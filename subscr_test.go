@@ -0,0 +1,31 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import "testing"
+
+func TestEventChannel(t *testing.T) {
+	cb, events, dropped := EventChannel(2, EventDropOldest)
+	for _, db := range []string{"a", "b", "c"} {
+		cb(Event{DB: db})
+	}
+	if got := dropped(); got != 1 {
+		t.Errorf("dropped: got %d, wanted 1", got)
+	}
+	if e := <-events; e.DB != "b" {
+		t.Errorf("got %q, wanted b", e.DB)
+	}
+
+	cb, events, dropped = EventChannel(1, EventDropNewest)
+	cb(Event{DB: "a"})
+	cb(Event{DB: "b"})
+	if got := dropped(); got != 1 {
+		t.Errorf("dropped: got %d, wanted 1", got)
+	}
+	if e := <-events; e.DB != "a" {
+		t.Errorf("got %q, wanted a", e.DB)
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTableQueueBatchSize is the default maximum number of jobs claimed at once by TableQueue.
+	DefaultTableQueueBatchSize = 100
+	// DefaultVisibilityTimeout is the default time a claimed job is invisible for the other consumers of a TableQueue.
+	DefaultVisibilityTimeout = 30 * time.Second
+	// DefaultTableQueuePollInterval is the default wait between claims of TableQueue.Run when the queue is empty.
+	DefaultTableQueuePollInterval = time.Second
+)
+
+// TxBeginner is the BeginTx of sql.DB and sql.Conn.
+type TxBeginner interface {
+	BeginTx(context.Context, *sql.TxOptions) (*sql.Tx, error)
+}
+
+// TableQueue is a simple job queue over a plain table, an alternative to AQ for simple workloads.
+//
+// Jobs are claimed with SELECT ... FOR UPDATE SKIP LOCKED, so concurrent consumers
+// do not block each other. A claimed job is invisible to the other consumers
+// till VisibilityTimeout passes (its VisibleAtColumn is set to the current UTC time + VisibilityTimeout),
+// so a job that is not acknowledged (the consumer fails or dies) will be redelivered.
+//
+// The table needs a TIMESTAMP column (VISIBLE_AT by default), NULL for new jobs.
+// It holds UTC time (SYS_EXTRACT_UTC(SYSTIMESTAMP)), so the visibility does not depend
+// on the time zone of the database or the session:
+//
+//	CREATE TABLE jobs (payload VARCHAR2(1000), visible_at TIMESTAMP);
+//	CREATE INDEX jobs_visible_at ON jobs(visible_at);
+//
+// Acknowledged jobs are deleted.
+type TableQueue struct {
+	// Table is the name of the job table.
+	Table string
+	// Columns are returned in TableJob.Values.
+	Columns []string
+	// Where is an optional additional filter for the claimable rows.
+	Where string
+	// VisibleAtColumn is the TIMESTAMP column holding the time (in UTC) till the job is invisible - "VISIBLE_AT" by default.
+	VisibleAtColumn string
+	// VisibilityTimeout is DefaultVisibilityTimeout by default.
+	VisibilityTimeout time.Duration
+	// BatchSize is DefaultTableQueueBatchSize by default.
+	BatchSize int
+	// PollInterval is DefaultTableQueuePollInterval by default.
+	PollInterval time.Duration
+}
+
+// TableJob is a claimed row of a TableQueue.
+type TableJob struct {
+	// RowID of the job's row.
+	RowID string
+	// Values of the TableQueue.Columns.
+	Values []interface{}
+}
+
+func (Q TableQueue) visibleAt() string {
+	if Q.VisibleAtColumn == "" {
+		return "VISIBLE_AT"
+	}
+	return Q.VisibleAtColumn
+}
+
+// tableQueueNow is the current time in UTC, as a plain TIMESTAMP, to compare with the VisibleAtColumn without time zone conversion.
+const tableQueueNow = "SYS_EXTRACT_UTC(SYSTIMESTAMP)"
+
+// claimQuery returns the SELECT of the claimable rows.
+func (Q TableQueue) claimQuery() string {
+	visibleAt := Q.visibleAt()
+	var buf strings.Builder
+	buf.WriteString("SELECT ROWIDTOCHAR(ROWID)")
+	for _, col := range Q.Columns {
+		buf.WriteString(", ")
+		buf.WriteString(col)
+	}
+	buf.WriteString(" FROM " + Q.Table +
+		" WHERE (" + visibleAt + " IS NULL OR " + visibleAt + " <= " + tableQueueNow + ")")
+	if Q.Where != "" {
+		buf.WriteString(" AND (" + Q.Where + ")")
+	}
+	return ForUpdate{Mode: LockSkipLocked}.AppendTo(buf.String())
+}
+
+// hideQuery returns the UPDATE making the claimed rows invisible for the timeout.
+func (Q TableQueue) hideQuery(timeout time.Duration) string {
+	return "UPDATE " + Q.Table + " SET " + Q.visibleAt() +
+		" = " + tableQueueNow + " + NUMTODSINTERVAL(" +
+		strconv.FormatFloat(timeout.Seconds(), 'f', -1, 64) +
+		", 'SECOND') WHERE ROWID = CHARTOROWID(:1)"
+}
+
+func (Q TableQueue) batchSize() int {
+	if Q.BatchSize <= 0 {
+		return DefaultTableQueueBatchSize
+	}
+	return Q.BatchSize
+}
+
+// Claim claims at most BatchSize jobs, making them invisible for VisibilityTimeout.
+//
+// Process the jobs in time, and Ack (or Release) them!
+func (Q TableQueue) Claim(ctx context.Context, db TxBeginner) ([]TableJob, error) {
+	if Q.Table == "" {
+		return nil, errors.New("TableQueue: empty Table")
+	}
+	batchSize := Q.batchSize()
+	timeout := Q.VisibilityTimeout
+	if timeout <= 0 {
+		timeout = DefaultVisibilityTimeout
+	}
+	qry := Q.claimQuery()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	rows, err := tx.QueryContext(ctx, qry, FetchArraySize(batchSize), PrefetchCount(batchSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	jobs := make([]TableJob, 0, batchSize)
+	rowids := make([]string, 0, batchSize)
+	for len(jobs) < batchSize && rows.Next() {
+		job := TableJob{Values: make([]interface{}, len(Q.Columns))}
+		dest := make([]interface{}, 1+len(Q.Columns))
+		dest[0] = &job.RowID
+		for i := range job.Values {
+			dest[i+1] = &job.Values[i]
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("scan %s: %w", qry, err)
+		}
+		jobs = append(jobs, job)
+		rowids = append(rowids, job.RowID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	rows.Close()
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+
+	qry = Q.hideQuery(timeout)
+	if _, err = tx.ExecContext(ctx, qry, rowids); err != nil {
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	return jobs, tx.Commit()
+}
+
+// Ack acknowledges (deletes) the jobs.
+//
+// The job must be acknowledged before its VisibilityTimeout passes,
+// otherwise it may have been redelivered to another consumer.
+func (Q TableQueue) Ack(ctx context.Context, ex Execer, jobs ...TableJob) error {
+	return Q.execRowIDs(ctx, ex, "DELETE FROM "+Q.Table+" WHERE ROWID = CHARTOROWID(:1)", jobs)
+}
+
+// Release makes the jobs visible again, without waiting for the VisibilityTimeout.
+func (Q TableQueue) Release(ctx context.Context, ex Execer, jobs ...TableJob) error {
+	return Q.execRowIDs(ctx, ex, "UPDATE "+Q.Table+" SET "+Q.visibleAt()+" = NULL WHERE ROWID = CHARTOROWID(:1)", jobs)
+}
+
+func (Q TableQueue) execRowIDs(ctx context.Context, ex Execer, qry string, jobs []TableJob) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+	rowids := make([]string, len(jobs))
+	for i, job := range jobs {
+		rowids[i] = job.RowID
+	}
+	if _, err := ex.ExecContext(ctx, qry, rowids); err != nil {
+		return fmt.Errorf("%s: %w", qry, err)
+	}
+	return nil
+}
+
+// Run the claim-process-ack loop till the context is canceled.
+//
+// The jobs processed without error are acknowledged (deleted) in batches,
+// the failed ones are left to be redelivered after the VisibilityTimeout.
+func (Q TableQueue) Run(ctx context.Context, db interface {
+	TxBeginner
+	Execer
+}, process func(context.Context, TableJob) error) error {
+	pollInterval := Q.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultTableQueuePollInterval
+	}
+	logger := ctxGetLog(ctx)
+	done := make([]TableJob, 0, Q.batchSize())
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		jobs, err := Q.Claim(ctx, db)
+		if err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-getClock().After(pollInterval):
+			}
+			continue
+		}
+		done = done[:0]
+		for _, job := range jobs {
+			if err := process(ctx, job); err != nil {
+				if logger != nil {
+					logger.Log("msg", "TableQueue.process", "table", Q.Table, "rowid", job.RowID, "error", err)
+				}
+				continue
+			}
+			done = append(done, job)
+		}
+		if err = Q.Ack(ctx, db, done...); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTableQueueDefaults(t *testing.T) {
+	var Q TableQueue
+	if got := Q.visibleAt(); got != "VISIBLE_AT" {
+		t.Errorf("visibleAt: got %q", got)
+	}
+	if got := Q.batchSize(); got != DefaultTableQueueBatchSize {
+		t.Errorf("batchSize: got %d", got)
+	}
+	// the empty table is rejected before beginning a transaction
+	if _, err := Q.Claim(context.Background(), nil); err == nil {
+		t.Error("wanted error for empty Table")
+	}
+	if err := Q.Ack(context.Background(), nil); err != nil {
+		t.Errorf("Ack without jobs: %+v", err)
+	}
+}
+
+func TestTableQueueUTC(t *testing.T) {
+	Q := TableQueue{Table: "jobs", Columns: []string{"payload"}, Where: "kind = 1"}
+	claim := Q.claimQuery()
+	const want = "SELECT ROWIDTOCHAR(ROWID), payload FROM jobs WHERE (VISIBLE_AT IS NULL OR VISIBLE_AT <= SYS_EXTRACT_UTC(SYSTIMESTAMP)) AND (kind = 1) FOR UPDATE SKIP LOCKED"
+	if claim != want {
+		t.Errorf("claim: got\n\t%s\nwanted\n\t%s", claim, want)
+	}
+	hide := Q.hideQuery(1500 * time.Millisecond)
+	if want := "SET VISIBLE_AT = SYS_EXTRACT_UTC(SYSTIMESTAMP) + NUMTODSINTERVAL(1.5, 'SECOND')"; !strings.Contains(hide, want) {
+		t.Errorf("hide: got %q, wanted %q", hide, want)
+	}
+	// a bare SYSTIMESTAMP would be compared in the session's time zone
+	for _, qry := range []string{claim, hide} {
+		if strings.Count(qry, "SYSTIMESTAMP") != strings.Count(qry, "SYS_EXTRACT_UTC(SYSTIMESTAMP)") {
+			t.Errorf("not UTC: %s", qry)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+/*
+#include "dpiImpl.h"
+*/
+import "C"
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// ErrNotGodror is returned when the given connection or statement is not of this driver.
+var ErrNotGodror = errors.New("not a godror connection or statement")
+
+// UnsafeDPIConn calls f with the ODPI-C handles (*dpiConn and *dpiContext) of the Conn got in Raw,
+// to call the ODPI-C functions the driver does not wrap yet - with your own cgo code,
+// including the same ODPI-C headers.
+//
+// The handles are valid only inside f, and must not be released.
+// The connection is locked and f runs on a locked OS thread (ODPI-C error info is per thread),
+// so f must not call the methods of the Conn.
+//
+// You're on your own: misuse can crash the process!
+func UnsafeDPIConn(c Conn, f func(dpiConn, dpiContext unsafe.Pointer) error) error {
+	cx, ok := c.(*conn)
+	if !ok || cx == nil {
+		return fmt.Errorf("%T: %w", c, ErrNotGodror)
+	}
+	cx.mu.Lock()
+	defer cx.mu.Unlock()
+	if cx.dpiConn == nil {
+		return driver.ErrBadConn
+	}
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	return f(unsafe.Pointer(cx.dpiConn), unsafe.Pointer(cx.drv.dpiContext))
+}
+
+// UnsafeDPIStmt calls f with the ODPI-C handle (*dpiStmt) of the statement prepared
+// with the PrepareContext of the Conn got in Raw.
+//
+// The same warnings apply as for UnsafeDPIConn.
+func UnsafeDPIStmt(st driver.Stmt, f func(dpiStmt unsafe.Pointer) error) error {
+	stmt, ok := st.(*statement)
+	if !ok || stmt == nil {
+		return fmt.Errorf("%T: %w", st, ErrNotGodror)
+	}
+	stmt.Lock()
+	defer stmt.Unlock()
+	if stmt.dpiStmt == nil {
+		return driver.ErrBadConn
+	}
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	return f(unsafe.Pointer(stmt.dpiStmt))
+}
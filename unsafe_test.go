@@ -0,0 +1,35 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"unsafe"
+)
+
+func TestUnsafeDPINotGodror(t *testing.T) {
+	called := false
+	fConn := func(unsafe.Pointer, unsafe.Pointer) error { called = true; return nil }
+	fStmt := func(unsafe.Pointer) error { called = true; return nil }
+	if err := UnsafeDPIConn(nil, fConn); !errors.Is(err, ErrNotGodror) {
+		t.Errorf("nil Conn: got %+v, wanted %v", err, ErrNotGodror)
+	}
+	if err := UnsafeDPIStmt(nil, fStmt); !errors.Is(err, ErrNotGodror) {
+		t.Errorf("nil Stmt: got %+v, wanted %v", err, ErrNotGodror)
+	}
+	// closed connection and statement
+	if err := UnsafeDPIConn(&conn{}, fConn); !errors.Is(err, driver.ErrBadConn) {
+		t.Errorf("closed Conn: got %+v, wanted %v", err, driver.ErrBadConn)
+	}
+	if err := UnsafeDPIStmt(&statement{}, fStmt); !errors.Is(err, driver.ErrBadConn) {
+		t.Errorf("closed Stmt: got %+v, wanted %v", err, driver.ErrBadConn)
+	}
+	if called {
+		t.Error("f called")
+	}
+}
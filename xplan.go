@@ -0,0 +1,74 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultPlanFormat is the DBMS_XPLAN.DISPLAY_CURSOR format used by DisplayCursor for an empty format:
+// the plan with the predicates, and the estimated (E-Rows) vs. actual (A-Rows) rows of the last execution.
+const DefaultPlanFormat = "ALLSTATS LAST"
+
+// ErrPlanNotFound is returned by DisplayCursor when the cursor is not in the cursor cache (anymore).
+var ErrPlanNotFound = errors.New("cursor not found in the cursor cache")
+
+// DisplayCursor returns the actual execution plan of the statement from the cursor cache,
+// with DBMS_XPLAN.DISPLAY_CURSOR, as lines of text.
+//
+// sqlID is the statement's SQL_ID (see SQLID); if empty, the statement executed last
+// in the session of q is shown - use the same *sql.Conn for the statement and for DisplayCursor,
+// after the statement's rows are consumed and closed!
+// A negative child shows all the child cursors.
+//
+// The actual row counts (A-Rows) need the statistics to be gathered during the execution:
+// use the /*+ gather_plan_statistics */ hint, or ALTER SESSION SET statistics_level = ALL.
+//
+// Needs SELECT privilege on V$SQL_PLAN_STATISTICS_ALL, V$SQL and V$SESSION.
+func DisplayCursor(ctx context.Context, q Querier, sqlID string, child int, format string) ([]string, error) {
+	if format == "" {
+		format = DefaultPlanFormat
+	}
+	var sqlIDArg, childArg interface{}
+	if sqlID != "" {
+		sqlIDArg = sqlID
+	}
+	if child >= 0 {
+		childArg = child
+	}
+	const qry = "SELECT plan_table_output FROM TABLE(DBMS_XPLAN.DISPLAY_CURSOR(:1, :2, :3))"
+	rows, err := q.QueryContext(ctx, qry, sqlIDArg, childArg, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", qry, err)
+	}
+	defer rows.Close()
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err = rows.Scan(&line); err != nil {
+			return lines, fmt.Errorf("scan %s: %w", qry, err)
+		}
+		lines = append(lines, line)
+	}
+	if err = rows.Err(); err != nil {
+		return lines, err
+	}
+	return lines, planNotFound(lines)
+}
+
+// planNotFound returns ErrPlanNotFound if the DISPLAY_CURSOR output says so,
+// as it does not fail for a missing cursor.
+func planNotFound(lines []string) error {
+	for _, line := range lines {
+		if strings.Contains(line, "cannot be found") || strings.Contains(line, "cannot fetch plan") {
+			return fmt.Errorf("%s: %w", strings.Join(lines, "\n"), ErrPlanNotFound)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+// Copyright 2022 The Godror Authors
+//
+//
+// SPDX-License-Identifier: UPL-1.0 OR Apache-2.0
+
+package godror
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPlanNotFound(t *testing.T) {
+	found := []string{
+		"SQL_ID  a5ks9fhw2v9s1, child number 0",
+		"-------------------------------------",
+		"select * from dual",
+		"",
+		"Plan hash value: 272002086",
+	}
+	if err := planNotFound(found); err != nil {
+		t.Errorf("found: %+v", err)
+	}
+	for _, lines := range [][]string{
+		{"SQL_ID: a5ks9fhw2v9s1, child number: 1 cannot be found"},
+		{"SQL_ID  a5ks9fhw2v9s1, child number 0", "", "An uncaught error happened in prepare_sql_statement : ORA-01403: no data found", "", "NOTE: cannot fetch plan for SQL_ID: a5ks9fhw2v9s1, CHILD_NUMBER: 0"},
+	} {
+		if err := planNotFound(lines); !errors.Is(err, ErrPlanNotFound) {
+			t.Errorf("%q: got %+v, wanted %v", lines, err, ErrPlanNotFound)
+		}
+	}
+}
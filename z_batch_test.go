@@ -8,6 +8,7 @@ package godror_test
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -69,3 +70,31 @@ func TestBatch(t *testing.T) {
 		t.Errorf("wanted %d rows, got %d", 3, i)
 	}
 }
+
+func TestBatchErrors(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("BatchErrors"), time.Minute)
+	defer cancel()
+
+	const create = `CREATE TABLE test_batch_errors (F_int NUMBER(9) PRIMARY KEY)`
+	if _, err := testDb.ExecContext(ctx, create); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		const del = "DROP TABLE test_batch_errors"
+		_, _ = testDb.ExecContext(context.Background(), del)
+	}()
+	const insQry = `INSERT INTO test_batch_errors (F_int) VALUES (:1)`
+	_, err := testDb.ExecContext(ctx, insQry, []int32{1, 2, 1, 3, 2}, godror.BatchErrors())
+	var be *godror.BatchError
+	if !errors.As(err, &be) {
+		t.Fatalf("got %+v, wanted BatchError", err)
+	}
+	t.Log(be)
+	if be.RowsAffected != 3 || fmt.Sprintf("%v", be.Rows()) != "[2 4]" {
+		t.Errorf("got %d affected, failed rows %v; wanted 3, [2 4]", be.RowsAffected, be.Rows())
+	}
+	if be.Errors[0].Code() != 1 {
+		t.Errorf("got %v, wanted ORA-00001", be.Errors[0])
+	}
+}
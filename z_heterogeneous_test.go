@@ -118,6 +118,7 @@ func TestHeterogeneousPoolIntegration(t *testing.T) {
 		"proxyUser":             {In: godror.ContextWithUserPassw(ctx, proxyUser, proxyPassword, ""), Want: proxyUser},
 		"proxyUserNoPass":       {In: godror.ContextWithUserPassw(ctx, proxyUser, "", ""), Want: proxyUser},
 		"proxyUserwithBrackets": {In: godror.ContextWithUserPassw(ctx, "["+proxyUser+"]", "", ""), Want: proxyUser},
+		"proxyUserCtx":          {In: godror.ContextWithProxyUser(ctx, proxyUser), Want: proxyUser},
 	}
 	if cs.IsStandalone() {
 		delete(testCases, "proxyUser")
@@ -245,3 +246,54 @@ func TestContextWithUserPassw(t *testing.T) {
 		}
 	}
 }
+
+// The first session of the pool is for a proxy user, and that must not change the pool's own user.
+func TestHeterogeneousPoolProxyFirst(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("HeterogeneousPoolProxyFirst"), 30*time.Second)
+	defer cancel()
+
+	const proxiedUser = "test_proxied_first"
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.IsStandalone() {
+		t.Skip("needs a pool")
+	}
+	// a new pool, not shared with the other tests
+	P.Heterogeneous, P.MinSessions, P.MaxSessions = true, 0, 3
+	username := P.Username
+
+	testDb.ExecContext(ctx, "DROP USER "+proxiedUser)
+	for _, qry := range []string{
+		"CREATE USER " + proxiedUser + " IDENTIFIED BY myPassword666myPassword",
+		"GRANT CREATE SESSION TO " + proxiedUser,
+		"ALTER USER " + proxiedUser + " GRANT CONNECT THROUGH " + username,
+	} {
+		if _, err := testDb.ExecContext(ctx, qry); err != nil {
+			t.Skip(fmt.Errorf("%s: %w", qry, err))
+		}
+	}
+	defer testDb.ExecContext(testContext("HeterogeneousPoolProxyFirst-drop"), "DROP USER "+proxiedUser)
+
+	db := sql.OpenDB(godror.NewConnector(P))
+	defer db.Close()
+	db.SetMaxIdleConns(0)
+	for _, tC := range []struct {
+		Ctx  context.Context
+		Want string
+	}{
+		{Ctx: godror.ContextWithProxyUser(ctx, proxiedUser), Want: proxiedUser},
+		{Ctx: ctx, Want: username},
+		{Ctx: godror.ContextWithProxyUser(ctx, proxiedUser), Want: proxiedUser},
+	} {
+		var got string
+		if err := db.QueryRowContext(tC.Ctx, "SELECT USER FROM DUAL").Scan(&got); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.EqualFold(got, tC.Want) {
+			t.Errorf("got %q, wanted %q", got, tC.Want)
+		}
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"math/big"
 	"math/rand"
 	"os"
 	"path/filepath"
@@ -28,6 +29,8 @@ import (
 	"sync/atomic"
 	"testing"
 	"time"
+	"unicode/utf8"
+	"unsafe"
 
 	"github.com/go-logfmt/logfmt"
 	"github.com/google/go-cmp/cmp"
@@ -35,6 +38,7 @@ import (
 
 	godror "github.com/godror/godror"
 	"github.com/godror/godror/dsn"
+	"github.com/godror/godror/parquet"
 )
 
 var (
@@ -625,6 +629,7 @@ PROCEDURE inout_int(p_int IN OUT int_tab_typ);
 PROCEDURE inout_num(p_num IN OUT num_tab_typ);
 PROCEDURE inout_vc(p_vc IN OUT vc_tab_typ);
 PROCEDURE inout_dt(p_dt IN OUT dt_tab_typ);
+PROCEDURE inout_lob(p_lob IN OUT lob_tab_typ);
 PROCEDURE p2(
 	--p_int IN OUT int_tab_typ,
 	p_num IN OUT num_tab_typ, p_vc IN OUT vc_tab_typ, p_dt IN OUT dt_tab_typ);
@@ -686,6 +691,17 @@ BEGIN
   DBMS_OUTPUT.PUT_LINE('p_dt.COUNT='||p_dt.COUNT||' FIRST='||p_dt.FIRST||' LAST='||p_dt.LAST);
 END;
 
+PROCEDURE inout_lob(p_lob IN OUT lob_tab_typ) IS
+  v_idx PLS_INTEGER;
+BEGIN
+  v_idx := p_lob.FIRST;
+  WHILE v_idx IS NOT NULL LOOP
+    p_lob(v_idx) := NVL(p_lob(v_idx) ||' +', '-');
+	v_idx := p_lob.NEXT(v_idx);
+  END LOOP;
+  p_lob(NVL(p_lob.LAST, 0)+1) := TO_CLOB(p_lob.COUNT);
+END;
+
 PROCEDURE p2(
 	--p_int IN OUT int_tab_typ,
 	p_num IN OUT num_tab_typ,
@@ -803,7 +819,59 @@ END;
 		})
 	}
 
-	// lob := []godror.Lob{godror.Lob{IsClob: true, Reader: strings.NewReader("abcdef")}}
+	t.Run("inout_vc_inferred", func(t *testing.T) {
+		// PlSQLArrays is inferred from the slice sql.Out destination
+		qry = "BEGIN " + pkg + ".inout_vc(:1); END;"
+		dst := copySlice(vc)
+		if _, err := conn.ExecContext(ctx, qry, sql.Out{Dest: dst, In: true}); err != nil {
+			t.Fatalf("%s\n%#v\n%+v", qry, dst, err)
+		}
+		if got := reflect.ValueOf(dst).Elem().Interface(); !cmp.Equal(got, vcWant) {
+			t.Errorf("got %v, wanted %v", got, vcWant)
+		}
+	})
+
+	t.Run("inout_lob", func(t *testing.T) {
+		stmt, err := conn.PrepareContext(ctx, "BEGIN "+pkg+".inout_lob(:1); END;")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the LOBs are valid till the statement is closed
+		defer stmt.Close()
+		for _, tC := range []struct {
+			In   []godror.Lob
+			Want []string
+		}{
+			{
+				In: append(make([]godror.Lob, 0, 3),
+					godror.Lob{IsClob: true, Reader: strings.NewReader("abcdef")},
+					godror.Lob{IsClob: true, Reader: strings.NewReader(strings.Repeat("x", 1<<20))}),
+				Want: []string{"abcdef +", strings.Repeat("x", 1<<20) + " +", "2"},
+			},
+			// OUT-only: IsClob is taken from the first element of the capacity
+			{In: append(make([]godror.Lob, 0, 2), godror.Lob{IsClob: true})[:0], Want: []string{"0"}},
+		} {
+			lobs := tC.In
+			if _, err := stmt.ExecContext(ctx, godror.PlSQLArrays, sql.Out{Dest: &lobs, In: len(lobs) != 0}); err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, len(lobs))
+			for i, L := range lobs {
+				if !L.IsClob {
+					t.Errorf("%d. not CLOB", i)
+				}
+				b, err := io.ReadAll(L)
+				if err != nil {
+					t.Fatalf("%d. %+v", i, err)
+				}
+				got[i] = string(b)
+			}
+			if !cmp.Equal(got, tC.Want) {
+				t.Errorf("got %d elements, wanted %d: %s", len(got), len(tC.Want), cmp.Diff(got, tC.Want))
+			}
+		}
+	})
+
 	t.Run("p2", func(t *testing.T) {
 		if _, err := conn.ExecContext(ctx,
 			"BEGIN "+pkg+".p2(:1, :2, :3); END;",
@@ -2041,6 +2109,31 @@ func TestReturning(t *testing.T) {
 	t.Logf("RETURNING (zero set): %v", got)
 }
 
+func TestReturningMany(t *testing.T) {
+	t.Parallel()
+	testDb.Exec("DROP TABLE test_returning_many")
+	if _, err := testDb.Exec("CREATE TABLE test_returning_many (id NUMBER(9) GENERATED ALWAYS AS IDENTITY, a VARCHAR2(20))"); err != nil {
+		t.Skip(err)
+	}
+	defer testDb.Exec("DROP TABLE test_returning_many")
+
+	var ids []int64
+	var as []string
+	if _, err := testDb.Exec(
+		`INSERT INTO test_returning_many (a) VALUES (UPPER(:1)) RETURNING id, a INTO :2, :3`,
+		[]string{"a", "b", "c"}, sql.Out{Dest: &ids}, sql.Out{Dest: &as},
+	); err != nil {
+		t.Fatal(err)
+	}
+	t.Log("ids:", ids, "as:", as)
+	if len(ids) != 3 || ids[0] >= ids[1] || ids[1] >= ids[2] {
+		t.Errorf("got ids %v, wanted 3 ascending", ids)
+	}
+	if strings.Join(as, ",") != "A,B,C" {
+		t.Errorf("got %q, wanted A,B,C", as)
+	}
+}
+
 func TestMaxOpenCursorsORA1000(t *testing.T) {
 	ctx, cancel := context.WithCancel(testContext("ORA1000"))
 	defer cancel()
@@ -2441,6 +2534,45 @@ func TestImplicitResults(t *testing.T) {
 	}
 }
 
+func TestImplicitResultsQuery(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("ImplicitResultsQuery"), 10*time.Second)
+	defer cancel()
+	const qry = `declare
+            c1 sys_refcursor;
+            c2 sys_refcursor;
+        begin
+            open c1 for select 1 from DUAL;
+            dbms_sql.return_result(c1);
+            open c2 for select 'A' from DUAL UNION ALL select 'B' from DUAL;
+            dbms_sql.return_result(c2);
+        end;`
+	rows, err := testDb.QueryContext(ctx, qry)
+	if err != nil {
+		if strings.Contains(err.Error(), "PLS-00302:") {
+			t.Skip()
+		}
+		t.Fatal(fmt.Errorf("%s: %w", qry, err))
+	}
+	defer rows.Close()
+	var got []string
+	for rows.NextResultSet() {
+		for rows.Next() {
+			var s string
+			if err := rows.Scan(&s); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, s)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		t.Error(err)
+	}
+	if strings.Join(got, ",") != "1,A,B" {
+		t.Errorf("got %q, wanted 1,A,B", got)
+	}
+}
+
 func TestStartupShutdown(t *testing.T) {
 	if os.Getenv("GODROR_DB_SHUTDOWN") != "1" {
 		t.Skip("GODROR_DB_SHUTDOWN != 1, skipping shutdown/startup test")
@@ -4686,3 +4818,1696 @@ func TestSelectText(t *testing.T) {
 		}
 	}
 }
+
+func TestGetTableColumns(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("GetTableColumns"), 10*time.Second)
+	defer cancel()
+
+	tbl := "test_tablecols" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	qry := "CREATE TABLE " + tbl + ` (
+		f_id NUMBER GENERATED ALWAYS AS IDENTITY,
+		f_num NUMBER DEFAULT ON NULL 1 NOT NULL,
+		f_double NUMBER GENERATED ALWAYS AS (f_num * 2) VIRTUAL)`
+	if _, err := testDb.ExecContext(ctx, qry); err != nil {
+		if strings.Contains(err.Error(), "ORA-00905:") || strings.Contains(err.Error(), "ORA-02000:") {
+			t.Skip(err)
+		}
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	defer func() { _, _ = testDb.Exec("DROP TABLE " + tbl) }()
+
+	cols, err := godror.GetTableColumns(ctx, testDb, "", strings.ToUpper(tbl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("%+v", cols)
+	if len(cols) != 3 {
+		t.Fatalf("got %d columns, wanted 3", len(cols))
+	}
+	if c := cols[0]; !c.Identity || c.IdentityGeneration != "ALWAYS" || c.Insertable() {
+		t.Errorf("F_ID: %+v", c)
+	}
+	if c := cols[1]; !c.DefaultOnNull || c.Default != "1" || !c.Insertable() {
+		t.Errorf("F_NUM: %+v", c)
+	}
+	if c := cols[2]; !c.Virtual || c.Insertable() {
+		t.Errorf("F_DOUBLE: %+v", c)
+	}
+}
+
+func TestStmtCacheSize(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("StmtCacheSize"), 10*time.Second)
+	defer cancel()
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	orig, err := godror.GetStmtCacheSize(ctx, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("original:", orig)
+	defer godror.SetStmtCacheSize(ctx, conn, orig)
+	if err = godror.SetStmtCacheSize(ctx, conn, orig+3); err != nil {
+		t.Fatal(err)
+	}
+	if size, err := godror.GetStmtCacheSize(ctx, conn); err != nil {
+		t.Fatal(err)
+	} else if size != orig+3 {
+		t.Errorf("got %d, wanted %d", size, orig+3)
+	}
+
+	const qry = "SELECT 1 FROM DUAL"
+	var n int
+	if err = conn.QueryRowContext(ctx, qry).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if err = godror.PurgeStmtCache(ctx, conn, qry); err != nil {
+		t.Fatal(err)
+	}
+	if err = conn.QueryRowContext(ctx, qry).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSessionTag(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("SessionTag"), 30*time.Second)
+	defer cancel()
+	P, err := godror.ParseConnString(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.IsStandalone() {
+		t.Skip("session tags need a pool")
+	}
+	var callbacks int32
+	P.TagCallback = func(ctx context.Context, conn driver.ConnPrepareContext, requested, actual string) error {
+		atomic.AddInt32(&callbacks, 1)
+		t.Logf("TagCallback(%q, %q)", requested, actual)
+		st, err := conn.PrepareContext(ctx, "ALTER SESSION SET NLS_LANGUAGE=GERMAN")
+		if err != nil {
+			return err
+		}
+		defer st.Close()
+		_, err = st.(driver.StmtExecContext).ExecContext(ctx, nil)
+		return err
+	}
+	db := sql.OpenDB(godror.NewConnector(P))
+	defer db.Close()
+	db.SetMaxIdleConns(0)
+
+	ctx = godror.ContextWithSessionTag(ctx, "NLS=GERMAN")
+	for i := 0; i < 3; i++ {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var lang string
+		err = conn.QueryRowContext(ctx, "SELECT value FROM nls_session_parameters WHERE parameter = 'NLS_LANGUAGE'").Scan(&lang)
+		tag, tagErr := godror.GetSessionTag(ctx, conn)
+		conn.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tagErr != nil {
+			t.Fatal(tagErr)
+		}
+		t.Logf("%d. lang=%q tag=%q", i, lang, tag)
+		if lang != "GERMAN" || tag != "NLS=GERMAN" {
+			t.Errorf("%d. got lang=%q tag=%q, wanted GERMAN, NLS=GERMAN", i, lang, tag)
+		}
+	}
+	if n := atomic.LoadInt32(&callbacks); n == 0 {
+		t.Error("TagCallback is not called")
+	} else {
+		t.Logf("TagCallback is called %d times", n)
+	}
+}
+
+func TestPlSQLArrayOutCapacity(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("PlSQLArrayOutCapacity"), 30*time.Second)
+	defer cancel()
+	pkg := strings.ToUpper("test_arrcap" + tblSuffix)
+	if _, err := testDb.ExecContext(ctx, `CREATE OR REPLACE PACKAGE `+pkg+` AS
+  TYPE vc_tab_typ IS TABLE OF VARCHAR2(10) INDEX BY PLS_INTEGER;
+  PROCEDURE fill(p_n IN PLS_INTEGER, p_tab OUT vc_tab_typ);
+END;`); err != nil {
+		t.Skip(err)
+	}
+	defer testDb.Exec("DROP PACKAGE " + pkg)
+	if _, err := testDb.ExecContext(ctx, `CREATE OR REPLACE PACKAGE BODY `+pkg+` AS
+  PROCEDURE fill(p_n IN PLS_INTEGER, p_tab OUT vc_tab_typ) IS
+  BEGIN
+    FOR i IN 1..p_n LOOP
+      p_tab(i) := TO_CHAR(i);
+    END LOOP;
+  END;
+END;`); err != nil {
+		t.Fatal(err)
+	}
+	call := "BEGIN " + pkg + ".fill(:1, :2); END;"
+
+	// nil destination: ArraySize elements
+	var got []string
+	if _, err := testDb.ExecContext(ctx, call, godror.PlSQLArrays, 3, sql.Out{Dest: &got}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Errorf("got %q, wanted 3 elements", got)
+	}
+
+	// per-parameter capacity, above ArraySize
+	got = make([]string, 0, 2000)
+	if _, err := testDb.ExecContext(ctx, call, godror.PlSQLArrays, godror.ArraySize(10), 1500, sql.Out{Dest: &got}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1500 {
+		t.Errorf("got %d elements, wanted 1500", len(got))
+	}
+
+	// too small capacity
+	got = make([]string, 0, 2)
+	_, err := testDb.ExecContext(ctx, call, godror.PlSQLArrays, 3, sql.Out{Dest: &got})
+	var ae *godror.ArrayCapacityError
+	if !errors.As(err, &ae) {
+		t.Fatalf("got %v, wanted ArrayCapacityError", err)
+	}
+	t.Log(err)
+	if ae.Capacities[2] != 2 {
+		t.Errorf("got capacities %v, wanted 2.=2", ae.Capacities)
+	}
+}
+
+func TestQueryNested(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("QueryNested"), 30*time.Second)
+	defer cancel()
+	type object struct {
+		Name string `godror:"OBJECT_NAME"`
+		ID   int64  `godror:"OBJECT_ID"`
+	}
+	type owner struct {
+		Objects []*object
+		Name    string `godror:"OWNER"`
+		Count   int    `godror:"CNT"`
+	}
+	var owners []owner
+	if err := godror.QueryNested(ctx, testDb, &owners, `SELECT A.owner, A.cnt,
+    CURSOR(SELECT O.object_name, O.object_id, O.created FROM all_objects O
+             WHERE O.owner = A.owner AND ROWNUM <= A.cnt) AS objects
+  FROM (SELECT owner, MOD(ROWNUM, 3) AS cnt
+          FROM (SELECT DISTINCT owner FROM all_objects)
+		  WHERE ROWNUM <= 5) A`,
+	); err != nil {
+		t.Fatal(err)
+	}
+	if len(owners) == 0 {
+		t.Fatal("no rows")
+	}
+	for _, o := range owners {
+		t.Logf("%s: %d", o.Name, len(o.Objects))
+		if len(o.Objects) != o.Count {
+			t.Errorf("%s: got %d objects, wanted %d", o.Name, len(o.Objects), o.Count)
+		}
+		for _, obj := range o.Objects {
+			if obj.Name == "" || obj.ID == 0 {
+				t.Errorf("%s: empty object %+v", o.Name, obj)
+			}
+		}
+	}
+}
+
+func TestQueryNestedOneConn(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("QueryNestedOneConn"), 30*time.Second)
+	defer cancel()
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(godror.NewConnector(P))
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	type leaf struct {
+		N int `godror:"N"`
+	}
+	type middle struct {
+		Leaves []leaf `godror:"LEAVES"`
+		N      int    `godror:"N"`
+	}
+	type root struct {
+		Middles []middle `godror:"MIDDLES"`
+		N       int      `godror:"N"`
+	}
+	for i := 0; i < 3; i++ {
+		var roots []root
+		// UNUSED is a cursor without a matching field, it must be closed
+		if err := godror.QueryNested(ctx, db, &roots, `SELECT LEVEL AS n,
+    CURSOR(SELECT LEVEL AS n, CURSOR(SELECT LEVEL AS n FROM DUAL CONNECT BY LEVEL <= 2) AS leaves
+             FROM DUAL CONNECT BY LEVEL <= 2) AS middles,
+    CURSOR(SELECT 1 FROM DUAL) AS unused
+  FROM DUAL CONNECT BY LEVEL <= 3`,
+		); err != nil {
+			t.Fatalf("%d. %+v", i, err)
+		}
+		if len(roots) != 3 || len(roots[2].Middles) != 2 || len(roots[2].Middles[1].Leaves) != 2 {
+			t.Errorf("%d. got %+v", i, roots)
+		}
+	}
+}
+
+func TestDisplayCursor(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("DisplayCursor"), 30*time.Second)
+	defer cancel()
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	const qry = "SELECT /*+ gather_plan_statistics */ COUNT(0) FROM all_objects WHERE ROWNUM <= 10"
+	var n int
+	if err = conn.QueryRowContext(ctx, qry).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	for _, sqlID := range []string{"", godror.SQLID(qry)} {
+		lines, err := godror.DisplayCursor(ctx, conn, sqlID, -1, "")
+		if err != nil {
+			if errors.Is(err, godror.ErrPlanNotFound) || strings.Contains(err.Error(), "ORA-00942") {
+				t.Skip(err)
+			}
+			t.Fatal(err)
+		}
+		plan := strings.Join(lines, "\n")
+		t.Log(plan)
+		if !strings.Contains(plan, "A-Rows") {
+			t.Errorf("%q: no A-Rows in the plan", sqlID)
+		}
+	}
+}
+
+func TestSelectBigNumber(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("SelectBigNumber"), 10*time.Second)
+	defer cancel()
+	var i big.Int
+	var r big.Rat
+	if err := testDb.QueryRowContext(ctx, "SELECT POWER(2, 100), 1/3 FROM DUAL").Scan(
+		godror.NumberScanner(&i), godror.NumberScanner(&r),
+	); err != nil {
+		t.Fatal(err)
+	}
+	if want := new(big.Int).Lsh(big.NewInt(1), 100); i.Cmp(want) != 0 {
+		t.Errorf("got %s, wanted %s", &i, want)
+	}
+	if f, _ := r.Float64(); f < 0.3333333 || f > 0.3333334 {
+		t.Errorf("got %s (%f), wanted 1/3", &r, f)
+	}
+}
+
+func TestAutotrace(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("Autotrace"), 30*time.Second)
+	defer cancel()
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var n int
+	stats, err := godror.Autotrace(ctx, conn, func() error {
+		return conn.QueryRowContext(ctx, "SELECT COUNT(0) FROM (SELECT object_name FROM all_objects WHERE ROWNUM <= 1000 ORDER BY 1)").Scan(&n)
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "ORA-00942") {
+			t.Skip(err)
+		}
+		t.Fatal(err)
+	}
+	t.Log(stats)
+	if stats.ConsistentGets == 0 || stats.RoundTrips == 0 {
+		t.Errorf("got %s", stats)
+	}
+}
+
+func TestFetchNumberAsString(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("FetchNumberAsString"), 10*time.Second)
+	defer cancel()
+	const qry = "SELECT CAST(181 AS NUMBER(5)), 3.14, 12345678901234567890123456789012345678 FROM DUAL"
+	rows, err := testDb.QueryContext(ctx, qry, godror.FetchNumberAsString())
+	if err != nil {
+		t.Fatal(fmt.Errorf("%s: %w", qry, err))
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ct := range types {
+		if ct.ScanType() != reflect.TypeOf("") {
+			t.Errorf("%d. got scan type %s", i, ct.ScanType())
+		}
+	}
+	want := []interface{}{"181", "3.14", "12345678901234567890123456789012345678"}
+	for rows.Next() {
+		got := make([]interface{}, len(want))
+		dests := make([]interface{}, len(got))
+		for i := range got {
+			dests[i] = &got[i]
+		}
+		if err := rows.Scan(dests...); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %#v, wanted %#v", got, want)
+		}
+	}
+}
+
+func TestCurrentSchema(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("CurrentSchema"), 30*time.Second)
+	defer cancel()
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	P.CurrentSchema = "SYS"
+	db := sql.OpenDB(godror.NewConnector(P))
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	const qry = "SELECT SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA') FROM DUAL"
+	for i := 0; i < 2; i++ {
+		var schema string
+		if err := db.QueryRowContext(ctx, qry).Scan(&schema); err != nil {
+			t.Fatal(err)
+		}
+		if schema != P.CurrentSchema {
+			t.Errorf("%d. got %q, wanted %q", i, schema, P.CurrentSchema)
+		}
+		// changed by the user, must be re-asserted on reuse
+		if _, err := db.ExecContext(ctx, "ALTER SESSION SET current_schema = "+P.Username); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTimestampTZFidelity(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("TimestampTZFidelity"), 30*time.Second)
+	defer cancel()
+	budapest, err := time.LoadLocation("Europe/Budapest")
+	if err != nil {
+		t.Skip(err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+	for _, in := range []time.Time{
+		time.Date(2021, 3, 28, 1, 59, 59, 0, budapest),
+		time.Date(2021, 3, 28, 3, 0, 0, 0, budapest),
+		time.Date(2021, 10, 31, 0, 30, 0, 0, time.UTC).In(budapest),
+		time.Date(2021, 10, 31, 1, 30, 0, 0, time.UTC).In(budapest),
+		time.Date(2021, 7, 15, 12, 0, 0, 0, tokyo),
+	} {
+		var tzh string
+		var got time.Time
+		if err := testDb.QueryRowContext(ctx,
+			"SELECT TO_CHAR(CAST(:1 AS TIMESTAMP WITH TIME ZONE), 'TZH:TZM'), CAST(:2 AS TIMESTAMP WITH TIME ZONE) FROM DUAL",
+			in, in, godror.KeepTimeZone(),
+		).Scan(&tzh, &got); err != nil {
+			t.Fatal(err)
+		}
+		if want := in.Format("-07:00"); tzh != want {
+			t.Errorf("%s: bound with offset %q, wanted %q", in, tzh, want)
+		}
+		if !got.Equal(in) || got.Format("-07:00") != in.Format("-07:00") {
+			t.Errorf("%s: got %s", in, got)
+		}
+	}
+
+	// region-named zones
+	for _, tC := range []struct {
+		In   string
+		Want time.Time
+	}{
+		{In: "2021-03-28 01:59:59 Europe/Budapest", Want: time.Date(2021, 3, 28, 0, 59, 59, 0, time.UTC)},
+		{In: "2021-03-28 03:00:00 Europe/Budapest", Want: time.Date(2021, 3, 28, 1, 0, 0, 0, time.UTC)},
+		{In: "2021-07-15 12:00:00 Asia/Tokyo", Want: time.Date(2021, 7, 15, 3, 0, 0, 0, time.UTC)},
+	} {
+		var got time.Time
+		if err := testDb.QueryRowContext(ctx,
+			"SELECT TO_TIMESTAMP_TZ(:1, 'YYYY-MM-DD HH24:MI:SS TZR') FROM DUAL", tC.In,
+		).Scan(&got); err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(tC.Want) {
+			t.Errorf("%s: got %s, wanted %s", tC.In, got, tC.Want)
+		}
+	}
+	// an OUT variable at +00:00 is not mistaken for a value without time zone
+	want := time.Date(2021, 7, 15, 12, 0, 0, 0, time.UTC)
+	var got time.Time
+	if _, err := testDb.ExecContext(ctx,
+		"BEGIN :1 := TO_TIMESTAMP_TZ('2021-07-15 12:00:00 +00:00', 'YYYY-MM-DD HH24:MI:SS TZH:TZM'); END;",
+		sql.Out{Dest: &got},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(want) {
+		t.Errorf("OUT: got %s, wanted %s", got, want)
+	}
+}
+
+func TestNLSCollation(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("NLSCollation"), 30*time.Second)
+	defer cancel()
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	P.NLSComp, P.NLSSort = "LINGUISTIC", "BINARY_AI"
+	db := sql.OpenDB(godror.NewConnector(P))
+	defer db.Close()
+	var n int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(0) FROM DUAL WHERE :1 = 'arvizturo'", "Árvíztűrő").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Error("comparison is not accent-insensitive")
+	}
+}
+
+func TestIntervalYMBind(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("IntervalYMBind"), 30*time.Second)
+	defer cancel()
+
+	const qry = `DECLARE
+  v_ym INTERVAL YEAR TO MONTH := :ym;
+BEGIN
+  :ym := v_ym + INTERVAL '1-3' YEAR TO MONTH;
+  :ds := NUMTODSINTERVAL(:secs, 'SECOND');
+END;`
+	ym := godror.IntervalYM{Years: 2, Months: 10}
+	var ds time.Duration
+	if _, err := testDb.ExecContext(ctx, qry,
+		sql.Named("ym", sql.Out{Dest: &ym, In: true}),
+		sql.Named("ds", sql.Out{Dest: &ds}),
+		sql.Named("secs", 90),
+	); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	if want := (godror.IntervalYM{Years: 4, Months: 1}); ym != want {
+		t.Errorf("got %v, wanted %v", ym, want)
+	}
+	if want := 90 * time.Second; ds != want {
+		t.Errorf("got %v, wanted %v", ds, want)
+	}
+
+	var got godror.IntervalYM
+	var gotDS time.Duration
+	if err := testDb.QueryRowContext(ctx,
+		"SELECT :1 + INTERVAL '0-1' YEAR TO MONTH, INTERVAL '1 02:03:04' DAY TO SECOND FROM DUAL",
+		godror.IntervalYM{Years: -1, Months: -2},
+	).Scan(&got, &gotDS); err != nil {
+		t.Fatal(err)
+	}
+	if want := (godror.IntervalYM{Years: -1, Months: -1}); got != want {
+		t.Errorf("got %v, wanted %v", got, want)
+	}
+	if want := 26*time.Hour + 3*time.Minute + 4*time.Second; gotDS != want {
+		t.Errorf("got %v, wanted %v", gotDS, want)
+	}
+}
+
+func TestIntervalDSRowidBind(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("IntervalDSRowidBind"), 30*time.Second)
+	defer cancel()
+
+	const qry = `DECLARE
+  v_ds INTERVAL DAY TO SECOND := :ds;
+BEGIN
+  :ds := v_ds + INTERVAL '0 00:00:01.5' DAY TO SECOND;
+  SELECT ROWID INTO :rid FROM DUAL;
+END;`
+	ds := godror.IntervalDS(26 * time.Hour)
+	var rid godror.Rowid
+	if _, err := testDb.ExecContext(ctx, qry,
+		sql.Named("ds", sql.Out{Dest: &ds, In: true}),
+		sql.Named("rid", sql.Out{Dest: &rid}),
+	); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	if want := godror.IntervalDS(26*time.Hour + 1500*time.Millisecond); ds != want {
+		t.Errorf("got %v, wanted %v", ds, want)
+	}
+	if rid == "" {
+		t.Error("empty rowid")
+	}
+
+	var gotDS godror.IntervalDS
+	var gotRid godror.Rowid
+	if err := testDb.QueryRowContext(ctx,
+		"SELECT :1 - INTERVAL '1' SECOND, ROWID FROM DUAL WHERE ROWID = :2",
+		godror.IntervalDS(time.Minute), rid,
+	).Scan(&gotDS, &gotRid); err != nil {
+		t.Fatal(err)
+	}
+	if want := godror.IntervalDS(59 * time.Second); gotDS != want {
+		t.Errorf("got %v, wanted %v", gotDS, want)
+	}
+	if gotRid != rid {
+		t.Errorf("got %q, wanted %q", gotRid, rid)
+	}
+}
+
+func TestPLSQLBoolean(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("PLSQLBoolean"), 30*time.Second)
+	defer cancel()
+
+	const qry = `BEGIN :b := NOT :b; :c := :x > 0; END;`
+	b := true
+	var c bool
+	if _, err := testDb.ExecContext(ctx, qry,
+		sql.Named("b", sql.Out{Dest: &b, In: true}), sql.Named("c", sql.Out{Dest: &c}), sql.Named("x", 1),
+	); err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	if b || !c {
+		t.Errorf("got b=%t c=%t, wanted false, true", b, c)
+	}
+
+	pkg := strings.ToUpper("test_bool_pkg" + tblSuffix)
+	if _, err := testDb.ExecContext(ctx, `CREATE OR REPLACE PACKAGE `+pkg+` AS
+TYPE bool_tab_typ IS TABLE OF BOOLEAN INDEX BY PLS_INTEGER;
+FUNCTION in_bool(p_bool IN bool_tab_typ) RETURN VARCHAR2;
+END;`); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP PACKAGE " + pkg)
+	if _, err := testDb.ExecContext(ctx, `CREATE OR REPLACE PACKAGE BODY `+pkg+` AS
+FUNCTION in_bool(p_bool IN bool_tab_typ) RETURN VARCHAR2 IS
+  v_res VARCHAR2(1000);
+BEGIN
+  FOR i IN 1..p_bool.COUNT LOOP
+    v_res := v_res||CASE WHEN p_bool(i) THEN 'T' ELSE 'F' END;
+  END LOOP;
+  RETURN(v_res);
+END;
+END;`); err != nil {
+		t.Fatal(err)
+	}
+	var res string
+	if _, err := testDb.ExecContext(ctx, "BEGIN :1 := "+pkg+".in_bool(:2); END;",
+		godror.PlSQLArrays, sql.Out{Dest: &res}, []bool{true, false, true, false},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if want := "TFTF"; res != want {
+		t.Errorf("got %q, wanted %q", res, want)
+	}
+}
+
+// The call timeout of a call with a deadline must not remain for the next call without deadline.
+func TestCallTimeoutReset(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("CallTimeoutReset"), 30*time.Second)
+	defer cancel()
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for i := 0; i < 3; i++ {
+		shortCtx, shortCancel := context.WithTimeout(ctx, 500*time.Millisecond)
+		var n int
+		err := conn.QueryRowContext(shortCtx, "SELECT 1 FROM DUAL").Scan(&n)
+		shortCancel()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// without deadline, it must not be broken after 500ms
+		if _, err := conn.ExecContext(context.Background(), "BEGIN DBMS_SESSION.SLEEP(1); END;"); err != nil {
+			t.Fatalf("%d. %+v", i, err)
+		}
+	}
+}
+
+func TestAdaptiveFetch(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("AdaptiveFetch"), 30*time.Second)
+	defer cancel()
+	const qry = "SELECT LEVEL, RPAD('x', 100, 'y') FROM DUAL CONNECT BY LEVEL <= 10000"
+	rows, err := testDb.QueryContext(ctx, qry, godror.AdaptiveFetch(1<<20))
+	if err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	defer rows.Close()
+	var n int
+	for rows.Next() {
+		var i int
+		var s string
+		if err = rows.Scan(&i, &s); err != nil {
+			t.Fatal(err)
+		}
+		if n++; i != n || len(s) != 100 {
+			t.Fatalf("%d. got %d, %q", n, i, s)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 10000 {
+		t.Errorf("got %d rows, wanted 10000", n)
+	}
+}
+
+func TestSizedReaderBind(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("SizedReaderBind"), 30*time.Second)
+	defer cancel()
+	const qry = `DECLARE
+  v_raw RAW(32767) := :1;
+  v_vc VARCHAR2(32767) := :2;
+BEGIN
+  :3 := NVL(UTL_RAW.LENGTH(v_raw), 0);
+  :4 := NVL(LENGTH(v_vc), 0);
+END;`
+	stmt, err := testDb.PrepareContext(ctx, qry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	// different sizes on the same statement
+	for _, size := range []int{30000, 1, 20000, 0} {
+		raw := bytes.Repeat([]byte{0xa5}, size)
+		s := strings.Repeat("árvíz", size/6)
+		var rawLen, vcLen int
+		if _, err := stmt.ExecContext(ctx,
+			godror.SizedReader{Reader: bytes.NewReader(raw), Size: len(raw)},
+			godror.SizedReader{Reader: strings.NewReader(s), Size: len(s), IsString: true},
+			sql.Out{Dest: &rawLen}, sql.Out{Dest: &vcLen},
+		); err != nil {
+			t.Fatalf("%d: %+v", size, err)
+		}
+		if rawLen != len(raw) || vcLen != utf8.RuneCountInString(s) {
+			t.Errorf("%d: got %d, %d, wanted %d, %d", size, rawLen, vcLen, len(raw), utf8.RuneCountInString(s))
+		}
+	}
+	if _, err := stmt.ExecContext(ctx,
+		godror.SizedReader{Reader: bytes.NewReader([]byte{1, 2}), Size: 3}, "",
+		sql.Out{Dest: new(int)}, sql.Out{Dest: new(int)},
+	); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("short reader: got %+v, wanted io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestMaxStringSizeBind(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("MaxStringSizeBind"), 30*time.Second)
+	defer cancel()
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	maxSize, err := godror.MaxStringSize(ctx, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("MaxStringSize:", maxSize)
+	tbl := "test_maxstring" + tblSuffix
+	conn.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err = conn.ExecContext(ctx,
+		"CREATE TABLE "+tbl+" (f_id NUMBER(3), f_vc VARCHAR2("+strconv.Itoa(maxSize)+" BYTE))", //nolint:gas
+	); err != nil {
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+	// 4*len would be a LONG bind, which cannot be inserted into a VARCHAR2 (ORA-01461)
+	for i, n := range []int{maxSize / 4, maxSize/4 + 1, maxSize} {
+		s := strings.Repeat("x", n)
+		if _, err = conn.ExecContext(ctx, "INSERT INTO "+tbl+" (f_id, f_vc) VALUES (:1, :2)", i, s); err != nil { //nolint:gas
+			t.Fatalf("%d: %+v", n, err)
+		}
+		var got int
+		if err = conn.QueryRowContext(ctx, "SELECT LENGTH(f_vc) FROM "+tbl+" WHERE f_id = :1", i).Scan(&got); err != nil { //nolint:gas
+			t.Fatal(err)
+		}
+		if got != n {
+			t.Errorf("got %d, wanted %d", got, n)
+		}
+	}
+}
+
+func TestBindOnly(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("BindOnly"), 30*time.Second)
+	defer cancel()
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	tbl := "test_bindonly" + tblSuffix
+	conn.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err = conn.ExecContext(ctx, "CREATE TABLE "+tbl+" (f_id NUMBER(3), f_vc VARCHAR2(10))"); err != nil { //nolint:gas
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+
+	stmt, err := conn.PrepareContext(ctx, "INSERT INTO "+tbl+" (f_id, f_vc) VALUES (:1, :2)") //nolint:gas
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	if _, err = stmt.ExecContext(ctx, godror.BindOnly(), 0, "zero"); err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range []string{"one", "two", "three"} {
+		if _, err = stmt.ExecContext(ctx, i+1, s); err != nil {
+			t.Fatalf("%d: %+v", i+1, err)
+		}
+	}
+	var n, sum int
+	if err = conn.QueryRowContext(ctx, "SELECT COUNT(0), SUM(f_id) FROM "+tbl).Scan(&n, &sum); err != nil { //nolint:gas
+		t.Fatal(err)
+	}
+	if n != 3 || sum != 6 {
+		t.Errorf("got %d rows (sum=%d), wanted 3 (6) - BindOnly should not execute", n, sum)
+	}
+}
+
+func TestExecRefCursors(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("ExecRefCursors"), 30*time.Second)
+	defer cancel()
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var rows1, rows2 *sql.Rows
+	if _, err = godror.ExecRefCursors(ctx, conn, `BEGIN
+  OPEN :1 FOR SELECT LEVEL FROM DUAL CONNECT BY LEVEL <= :2;
+  OPEN :3 FOR SELECT 'x' FROM DUAL;
+END;`,
+		sql.Out{Dest: &rows1}, 3, &rows2,
+	); err != nil {
+		t.Fatal(err)
+	}
+	defer rows1.Close()
+	defer rows2.Close()
+	var sum int
+	for rows1.Next() {
+		var i int
+		if err = rows1.Scan(&i); err != nil {
+			t.Fatal(err)
+		}
+		sum += i
+	}
+	if err = rows1.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if sum != 6 {
+		t.Errorf("first cursor: got sum=%d, wanted 6", sum)
+	}
+	var s string
+	if !rows2.Next() {
+		t.Fatalf("second cursor is empty: %+v", rows2.Err())
+	}
+	if err = rows2.Scan(&s); err != nil {
+		t.Fatal(err)
+	}
+	if s != "x" {
+		t.Errorf("second cursor: got %q, wanted x", s)
+	}
+}
+
+func TestSQLIDInVSQL(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testContext("SQLIDInVSQL"), 30*time.Second)
+	defer cancel()
+	qry := "SELECT 'sqlid" + tblSuffix + "' FROM DUAL"
+	var s string
+	if err := testDb.QueryRowContext(ctx, qry).Scan(&s); err != nil {
+		t.Fatal(err)
+	}
+	var sqlID string
+	if err := testDb.QueryRowContext(ctx,
+		"SELECT sql_id FROM v$sql WHERE sql_text = :1 AND ROWNUM = 1", qry,
+	).Scan(&sqlID); err != nil {
+		if strings.Contains(err.Error(), "ORA-00942") {
+			t.Skip(err)
+		}
+		t.Fatal(err)
+	}
+	if got := godror.SQLID(qry); got != sqlID {
+		t.Errorf("got %q, wanted %q (from V$SQL)", got, sqlID)
+	}
+}
+
+func TestTopSQLByElapsed(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("TopSQLByElapsed"), 30*time.Second)
+	defer cancel()
+	godror.SetDiagnosticsPackLicensed(false)
+	until := time.Now()
+	top, err := godror.TopSQLByElapsed(ctx, testDb, until.Add(-time.Hour), until, 5)
+	if err != nil {
+		if strings.Contains(err.Error(), "ORA-00942") {
+			t.Skip(err)
+		}
+		t.Fatal(err)
+	}
+	if len(top) > 5 {
+		t.Errorf("got %d statements, wanted at most 5", len(top))
+	}
+	for i, ts := range top {
+		t.Logf("%d. %+v", i, ts)
+		if ts.SQLID == "" {
+			t.Errorf("%d. no SQL_ID", i)
+		}
+		if i > 0 && top[i-1].Elapsed < ts.Elapsed {
+			t.Errorf("%d. not ordered by elapsed: %s < %s", i, top[i-1].Elapsed, ts.Elapsed)
+		}
+	}
+}
+
+func TestTableQueue(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("TableQueue"), 30*time.Second)
+	defer cancel()
+	tbl := "test_tablequeue" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (payload NUMBER(3), visible_at TIMESTAMP)"); err != nil { //nolint:gas
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+	if _, err := testDb.ExecContext(ctx, "INSERT INTO "+tbl+" (payload) SELECT LEVEL FROM DUAL CONNECT BY LEVEL <= 5"); err != nil { //nolint:gas
+		t.Fatal(err)
+	}
+
+	Q := godror.TableQueue{Table: tbl, Columns: []string{"payload"}, BatchSize: 3, VisibilityTimeout: time.Minute}
+	first, err := Q.Claim(ctx, testDb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the claimed jobs are invisible
+	second, err := Q.Claim(ctx, testDb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 3 || len(second) != 2 {
+		t.Fatalf("claimed %d and %d jobs, wanted 3 and 2", len(first), len(second))
+	}
+	if again, err := Q.Claim(ctx, testDb); err != nil || len(again) != 0 {
+		t.Fatalf("claimed %d more jobs (%+v)", len(again), err)
+	}
+
+	if err = Q.Ack(ctx, testDb, first...); err != nil {
+		t.Fatal(err)
+	}
+	if err = Q.Release(ctx, testDb, second[0]); err != nil {
+		t.Fatal(err)
+	}
+	released, err := Q.Claim(ctx, testDb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(released) != 1 || released[0].RowID != second[0].RowID {
+		t.Errorf("got %+v, wanted the released %+v", released, second[0])
+	}
+	var n int
+	if err = testDb.QueryRowContext(ctx, "SELECT COUNT(0) FROM "+tbl).Scan(&n); err != nil { //nolint:gas
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("%d rows left, wanted 2 after acknowledging 3", n)
+	}
+
+	// the visibility timeout expires, whatever the session's time zone is
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err = conn.ExecContext(ctx, "ALTER SESSION SET TIME_ZONE='-11:00'"); err != nil {
+		t.Fatal(err)
+	}
+	Q.VisibilityTimeout = time.Second
+	claimed, err := Q.Claim(ctx, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimed) != 2 {
+		t.Fatalf("claimed %d jobs, wanted the 2 remaining", len(claimed))
+	}
+	if again, err := Q.Claim(ctx, conn); err != nil || len(again) != 0 {
+		t.Fatalf("claimed %d jobs before the timeout (%+v)", len(again), err)
+	}
+	time.Sleep(1500 * time.Millisecond)
+	expired, err := Q.Claim(ctx, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expired) != 2 {
+		t.Errorf("reclaimed %d jobs after the visibility timeout, wanted 2", len(expired))
+	}
+}
+
+func TestUpdateStructOptimisticRowSCN(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("UpdateStructOptimisticRowSCN"), 30*time.Second)
+	defer cancel()
+	tbl := "test_optimistic" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (id NUMBER(3), body VARCHAR2(100)) ROWDEPENDENCIES"); err != nil { //nolint:gas
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+	if _, err := testDb.ExecContext(ctx, "INSERT INTO "+tbl+" (id, body) VALUES (1, 'a')"); err != nil { //nolint:gas
+		t.Fatal(err)
+	}
+	type doc struct {
+		ID     int64 `godror:",key"`
+		Body   string
+		RowSCN int64 `godror:",rowscn"`
+	}
+	d := doc{ID: 1}
+	if err := testDb.QueryRowContext(ctx, "SELECT body, ORA_ROWSCN FROM "+tbl+" WHERE id = 1").Scan(&d.Body, &d.RowSCN); err != nil { //nolint:gas
+		t.Fatal(err)
+	}
+	old := d
+	// autocommit, so the new ORA_ROWSCN is known right after the update
+	for _, body := range []string{"b", "c"} {
+		d.Body = body
+		if err := godror.UpdateStructOptimistic(ctx, testDb, tbl, &d); err != nil {
+			t.Fatalf("update to %q: %+v", body, err)
+		}
+		if d.RowSCN <= old.RowSCN {
+			t.Errorf("rowscn is not refreshed: %d <= %d", d.RowSCN, old.RowSCN)
+		}
+	}
+	old.Body = "stale"
+	if err := godror.UpdateStructOptimistic(ctx, testDb, tbl, &old); !errors.Is(err, godror.ErrStaleVersion) {
+		t.Errorf("got %+v, wanted ErrStaleVersion", err)
+	}
+}
+
+func TestExportParquet(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("ExportParquet"), 30*time.Second)
+	defer cancel()
+	const qry = `SELECT LEVEL AS id, 'name'||LEVEL AS name, CAST(LEVEL/4 AS NUMBER(10,2)) AS amount,
+  TRUNC(SYSDATE)-LEVEL AS dt, SYSTIMESTAMP AS ts
+  FROM DUAL CONNECT BY LEVEL <= 10`
+	rows, err := testDb.QueryContext(ctx, qry, godror.FetchArraySize(4))
+	if err != nil {
+		t.Fatalf("%s: %+v", qry, err)
+	}
+	defer rows.Close()
+	var buf bytes.Buffer
+	pw := parquet.NewWriter(&buf)
+	pw.RowGroupSize = 3
+	n, err := godror.ExportRows(ctx, rows, pw, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = pw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 10 {
+		t.Errorf("exported %d rows, wanted 10", n)
+	}
+	if b := buf.Bytes(); len(b) < 12 || !bytes.HasPrefix(b, []byte("PAR1")) || !bytes.HasSuffix(b, []byte("PAR1")) {
+		t.Errorf("not a parquet file: %q", b)
+	}
+}
+
+func TestCopyTable(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("CopyTable"), 30*time.Second)
+	defer cancel()
+	src, dst := "test_copysrc"+tblSuffix, "test_copydst"+tblSuffix
+	for _, tbl := range []string{src, dst} {
+		testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+		if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (f_id NUMBER(5), f_name VARCHAR2(20), f_dt DATE)"); err != nil { //nolint:gas
+			t.Fatal(err)
+		}
+		defer testDb.Exec("DROP TABLE " + tbl)
+	}
+	if _, err := testDb.ExecContext(ctx,
+		"INSERT INTO "+src+" (f_id, f_name, f_dt) SELECT LEVEL, DECODE(MOD(LEVEL, 3), 0, NULL, 'name'||LEVEL), TRUNC(SYSDATE)-LEVEL FROM DUAL CONNECT BY LEVEL <= 7", //nolint:gas
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	var progress []int64
+	n, err := godror.CopyTable(ctx, testDb, testDb, "SELECT f_id, f_name, f_dt FROM "+src, dst, godror.CopyTableOptions{ //nolint:gas
+		BatchSize: 3,
+		Progress:  func(_ context.Context, copied int64) error { progress = append(progress, copied); return nil },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 7 {
+		t.Errorf("copied %d rows, wanted 7", n)
+	}
+	if want := []int64{3, 6, 7}; !reflect.DeepEqual(progress, want) {
+		t.Errorf("progress: got %v, wanted %v", progress, want)
+	}
+	var diff int
+	if err = testDb.QueryRowContext(ctx,
+		"SELECT COUNT(0) FROM ((SELECT * FROM "+src+" MINUS SELECT * FROM "+dst+") UNION ALL (SELECT * FROM "+dst+" MINUS SELECT * FROM "+src+"))", //nolint:gas
+	).Scan(&diff); err != nil {
+		t.Fatal(err)
+	}
+	if diff != 0 {
+		t.Errorf("%d rows differ", diff)
+	}
+}
+
+// batchExecer records the array inserts of CopyTable, and fails the failAt-th (1-based) one.
+type batchExecer struct {
+	qry     string
+	batches [][]int64 // the F_ID column of each batch
+	failAt  int
+}
+
+var errBatchFailed = errors.New("batch failed")
+
+func (be *batchExecer) ExecContext(ctx context.Context, qry string, args ...interface{}) (sql.Result, error) {
+	be.qry = qry
+	if len(be.batches)+1 == be.failAt {
+		return nil, errBatchFailed
+	}
+	// the column type depends on how the source NUMBER is scanned
+	ids := reflect.ValueOf(args[0])
+	batch := make([]int64, ids.Len())
+	for i := range batch {
+		var err error
+		if batch[i], err = strconv.ParseInt(fmt.Sprint(ids.Index(i).Interface()), 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	be.batches = append(be.batches, batch)
+	return driver.RowsAffected(len(batch)), nil
+}
+
+func TestCopyTableBatches(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("CopyTableBatches"), 30*time.Second)
+	defer cancel()
+	t.Run("batches", func(t *testing.T) {
+		var be batchExecer
+		var progress []int64
+		n, err := godror.CopyTable(ctx, testDb, &be,
+			"SELECT LEVEL AS f_id, 'name'||LEVEL AS f_name FROM DUAL CONNECT BY LEVEL <= 7 ORDER BY 1", "dst",
+			godror.CopyTableOptions{
+				BatchSize: 3, Columns: []string{"id", "name"},
+				Progress: func(_ context.Context, copied int64) error { progress = append(progress, copied); return nil },
+			})
+		if err != nil || n != 7 {
+			t.Fatalf("copied %d, %+v", n, err)
+		}
+		if want := "INSERT INTO dst (id, name) VALUES (:1, :2)"; be.qry != want {
+			t.Errorf("got %q, wanted %q", be.qry, want)
+		}
+		if want := [][]int64{{1, 2, 3}, {4, 5, 6}, {7}}; !reflect.DeepEqual(be.batches, want) {
+			t.Errorf("batches: got %v, wanted %v", be.batches, want)
+		}
+		if want := []int64{3, 6, 7}; !reflect.DeepEqual(progress, want) {
+			t.Errorf("progress: got %v, wanted %v", progress, want)
+		}
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		// the second batch fails: the rows of the first one are copied
+		be := batchExecer{failAt: 2}
+		n, err := godror.CopyTable(ctx, testDb, &be,
+			"SELECT LEVEL AS f_id, 'name'||LEVEL AS f_name FROM DUAL CONNECT BY LEVEL <= 7 ORDER BY 1", "dst",
+			godror.CopyTableOptions{BatchSize: 3})
+		if !errors.Is(err, errBatchFailed) || n != 3 {
+			t.Fatalf("copied %d, %+v; wanted 3, errBatchFailed", n, err)
+		}
+		// resume after the last copied row
+		be.failAt = 0
+		last := be.batches[len(be.batches)-1]
+		resumed, err := godror.CopyTable(ctx, testDb, &be,
+			fmt.Sprintf("SELECT f_id, f_name FROM (SELECT LEVEL AS f_id, 'name'||LEVEL AS f_name FROM DUAL CONNECT BY LEVEL <= 7) WHERE f_id > %d ORDER BY 1", last[len(last)-1]), "dst",
+			godror.CopyTableOptions{BatchSize: 3})
+		if err != nil || n+resumed != 7 {
+			t.Fatalf("resumed %d (after %d), %+v", resumed, n, err)
+		}
+		if want := [][]int64{{1, 2, 3}, {4, 5, 6}, {7}}; !reflect.DeepEqual(be.batches, want) {
+			t.Errorf("batches: got %v, wanted %v", be.batches, want)
+		}
+	})
+
+	t.Run("progressError", func(t *testing.T) {
+		var be batchExecer
+		errStop := errors.New("stop")
+		n, err := godror.CopyTable(ctx, testDb, &be,
+			"SELECT LEVEL AS f_id, 'name'||LEVEL AS f_name FROM DUAL CONNECT BY LEVEL <= 7 ORDER BY 1", "dst",
+			godror.CopyTableOptions{BatchSize: 3, Progress: func(_ context.Context, copied int64) error {
+				if copied >= 6 {
+					return errStop
+				}
+				return nil
+			}})
+		if !errors.Is(err, errStop) || n != 6 || len(be.batches) != 2 {
+			t.Errorf("copied %d in %d batches, %+v; wanted 6 in 2, errStop", n, len(be.batches), err)
+		}
+	})
+}
+
+// TestCopyTableCommit checks that each batch is committed with a *sql.DB, and nothing with a rolled back *sql.Tx.
+func TestCopyTableCommit(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("CopyTableCommit"), 30*time.Second)
+	defer cancel()
+	dst := "test_copycommit" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+dst)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+dst+" (f_id NUMBER(5), f_name VARCHAR2(20))"); err != nil { //nolint:gas
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + dst)
+	const qry = "SELECT LEVEL AS f_id, 'name'||LEVEL AS f_name FROM DUAL CONNECT BY LEVEL <= 7 ORDER BY 1"
+	errStop := errors.New("stop")
+	stopAt6 := func(_ context.Context, copied int64) error {
+		if copied >= 6 {
+			return errStop
+		}
+		return nil
+	}
+	count := func() int {
+		var n int
+		if err := testDb.QueryRowContext(ctx, "SELECT COUNT(0) FROM "+dst).Scan(&n); err != nil { //nolint:gas
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	tx, err := testDb.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if n, err := godror.CopyTable(ctx, testDb, tx, qry, dst, godror.CopyTableOptions{BatchSize: 3, Progress: stopAt6}); !errors.Is(err, errStop) || n != 6 {
+		t.Fatalf("copied %d, %+v", n, err)
+	}
+	if err = tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(); n != 0 {
+		t.Errorf("%d rows after the rollback, wanted 0", n)
+	}
+
+	if n, err := godror.CopyTable(ctx, testDb, testDb, qry, dst, godror.CopyTableOptions{BatchSize: 3, Progress: stopAt6}); !errors.Is(err, errStop) || n != 6 {
+		t.Fatalf("copied %d, %+v", n, err)
+	}
+	if n := count(); n != 6 {
+		t.Errorf("%d rows after the stopped copy, wanted the 6 committed", n)
+	}
+}
+
+func TestLeakedRows(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("LeakedRows"), 30*time.Second)
+	defer cancel()
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	godror.SetLeakDebug(true)
+	defer godror.SetLeakDebug(false)
+	before := godror.GetLeakStats()
+	if err = godror.Raw(ctx, conn, func(c godror.Conn) error {
+		st, err := c.PrepareContext(ctx, "SELECT 1 FROM DUAL")
+		if err != nil {
+			return err
+		}
+		_, err = st.(driver.StmtQueryContext).QueryContext(ctx, nil)
+		return err // the rows are left unclosed
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10 && godror.GetLeakStats().Rows == before.Rows; i++ {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := godror.GetLeakStats(); after.Rows != before.Rows+1 {
+		t.Errorf("leaked rows: got %d, wanted %d", after.Rows, before.Rows+1)
+	}
+}
+
+func TestUnsafeDPI(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("UnsafeDPI"), 10*time.Second)
+	defer cancel()
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err = godror.Raw(ctx, conn, func(c godror.Conn) error {
+		if err := godror.UnsafeDPIConn(c, func(dpiConn, dpiContext unsafe.Pointer) error {
+			if dpiConn == nil || dpiContext == nil {
+				t.Errorf("got dpiConn=%p dpiContext=%p", dpiConn, dpiContext)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		st, err := c.PrepareContext(ctx, "SELECT 1 FROM DUAL")
+		if err != nil {
+			return err
+		}
+		defer st.Close()
+		return godror.UnsafeDPIStmt(st, func(dpiStmt unsafe.Pointer) error {
+			if dpiStmt == nil {
+				t.Error("nil dpiStmt")
+			}
+			return nil
+		})
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInitAfterConnect(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("InitAfterConnect"), 10*time.Second)
+	defer cancel()
+	if err := testDb.PingContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := godror.Init(godror.ClientOptions{DriverName: "late"}); !errors.Is(err, godror.ErrClientInitialized) {
+		t.Errorf("got %+v, wanted %v", err, godror.ErrClientInitialized)
+	}
+}
+
+func TestGetLibraryVersions(t *testing.T) {
+	V, err := godror.GetLibraryVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(V)
+	if V.Driver != godror.Version {
+		t.Errorf("Driver: got %q, wanted %q", V.Driver, godror.Version)
+	}
+	if V.ODPI.Version != godror.DpiMajorVersion || V.Client.Version == 0 {
+		t.Errorf("got %+v", V)
+	}
+	cv, err := godror.ClientVersion(testContext("GetLibraryVersions"), testDb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if V.Client.Version != cv.Version || V.Client.Release != cv.Release {
+		t.Errorf("Client: got %s, wanted %s", &V.Client, &cv)
+	}
+}
+
+func TestTransactionOutcome(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("TransactionOutcome"), 30*time.Second)
+	defer cancel()
+	tbl := "test_ltxid" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (f_id NUMBER(3))"); err != nil { //nolint:gas
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if _, err = tx.ExecContext(ctx, "INSERT INTO "+tbl+" (f_id) VALUES (1)"); err != nil { //nolint:gas
+		t.Fatal(err)
+	}
+	ltxid, err := godror.GetLTXID(ctx, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ltxid) == 0 {
+		t.Skip("no Transaction Guard (COMMIT_OUTCOME) on this service")
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	outcome, err := godror.GetTransactionOutcome(ctx, testDb, ltxid)
+	if err != nil {
+		if strings.Contains(err.Error(), "PLS-00201") {
+			t.Skip(err)
+		}
+		t.Fatal(err)
+	}
+	if !outcome.Committed || !outcome.UserCallCompleted {
+		t.Errorf("got %+v, wanted committed and completed", outcome)
+	}
+}
+
+func TestPartitionExchange(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("PartitionExchange"), 60*time.Second)
+	defer cancel()
+	tbl := "test_partxchg" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl+" PURGE")
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (f_id NUMBER(5), f_txt VARCHAR2(10)) "+ //nolint:gas
+		"PARTITION BY RANGE (f_id) (PARTITION p1 VALUES LESS THAN (10), PARTITION p2 VALUES LESS THAN (20))",
+	); err != nil {
+		if strings.Contains(err.Error(), "ORA-00439") { // feature not enabled: Partitioning
+			t.Skip(err)
+		}
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl + " PURGE")
+	if _, err := testDb.ExecContext(ctx, "INSERT INTO "+tbl+" (f_id, f_txt) SELECT LEVEL+5, 'old' FROM DUAL CONNECT BY LEVEL <= 10"); err != nil { //nolint:gas
+		t.Fatal(err)
+	}
+
+	staging := "test_partxchg_stg" + tblSuffix
+	pe := godror.PartitionExchange{
+		Table: tbl, Partition: "p1", StagingTable: staging,
+		Load: func(ctx context.Context, stagingTable string) error {
+			_, err := testDb.ExecContext(ctx, "INSERT INTO "+stagingTable+" (f_id, f_txt) VALUES (:1, :2)", //nolint:gas
+				[]int{1, 2, 3}, []string{"new", "new", "new"})
+			return err
+		},
+	}
+	if err := pe.Run(ctx, testDb); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		Partition, Txt string
+		Count          int
+	}{{"p1", "new", 3}, {"p2", "old", 6}} {
+		var n int
+		var txt string
+		if err := testDb.QueryRowContext(ctx, "SELECT COUNT(0), MAX(f_txt) FROM "+tbl+" PARTITION ("+tc.Partition+")").Scan(&n, &txt); err != nil { //nolint:gas
+			t.Fatal(err)
+		}
+		if n != tc.Count || txt != tc.Txt {
+			t.Errorf("%s: got %d %q rows, wanted %d %q", tc.Partition, n, txt, tc.Count, tc.Txt)
+		}
+	}
+	var n int
+	if err := testDb.QueryRowContext(ctx, "SELECT COUNT(0) FROM user_tables WHERE table_name = UPPER(:1)", staging).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("staging table %s is not dropped", staging)
+	}
+}
+
+func TestWithParallelDML(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("WithParallelDML"), 30*time.Second)
+	defer cancel()
+	tbl := "test_pdml" + tblSuffix
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (f_id NUMBER(5))"); err != nil { //nolint:gas
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	const statusQry = "SELECT pdml_status FROM v$session WHERE sid = SYS_CONTEXT('USERENV', 'SID')"
+	pdmlStatus := func(q interface {
+		QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+	}) string {
+		t.Helper()
+		var status string
+		if err := q.QueryRowContext(ctx, statusQry).Scan(&status); err != nil {
+			if strings.Contains(err.Error(), "ORA-00942") {
+				t.Skip(err)
+			}
+			t.Fatal(err)
+		}
+		return status
+	}
+
+	if err = godror.WithParallelDML(ctx, conn, godror.ParallelOptions{Degree: 2}, func(ctx context.Context, tx *sql.Tx) error {
+		if status := pdmlStatus(tx); status != "FORCED" {
+			t.Errorf("in the transaction: got %q, wanted FORCED", status)
+		}
+		_, err := tx.ExecContext(ctx, "INSERT /*+ APPEND */ INTO "+tbl+" (f_id) SELECT LEVEL FROM DUAL CONNECT BY LEVEL <= 100") //nolint:gas
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if status := pdmlStatus(conn); status != "DISABLED" {
+		t.Errorf("after: got %q, wanted DISABLED", status)
+	}
+	var n int
+	if err = conn.QueryRowContext(ctx, "SELECT COUNT(0) FROM "+tbl).Scan(&n); err != nil { //nolint:gas
+		t.Fatal(err)
+	}
+	if n != 100 {
+		t.Errorf("got %d rows, wanted 100 committed", n)
+	}
+}
+
+func TestQueueAdmin(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("QueueAdmin"), 60*time.Second)
+	defer cancel()
+	qTbl, qName := strings.ToUpper("test_aqadm_tbl"+tblSuffix), strings.ToUpper("test_aqadm_q"+tblSuffix)
+	_ = godror.DropQueueTable(ctx, testDb, qTbl, true)
+	if err := godror.CreateQueueTable(ctx, testDb, qTbl, "RAW", godror.QueueTableOptions{Comment: "test"}); err != nil {
+		if strings.Contains(err.Error(), "PLS-00201") || strings.Contains(err.Error(), "ORA-01031:") {
+			t.Skip(err)
+		}
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := godror.DropQueueTable(context.Background(), testDb, qTbl, true); err != nil {
+			t.Error(err)
+		}
+	}()
+	if err := godror.CreateQueue(ctx, testDb, qName, qTbl, godror.QueueOptions{
+		MaxRetries: 3, RetryDelay: 5 * time.Second, RetentionTime: time.Minute,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := godror.StartQueue(ctx, testDb, qName); err != nil {
+		t.Fatal(err)
+	}
+	qi, err := godror.DescribeQueue(ctx, testDb, "", qName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("%+v", qi)
+	if qi.QueueTable != qTbl || qi.PayloadType != "RAW" || qi.MaxRetries != 3 ||
+		qi.RetryDelay != 5*time.Second || qi.Retention != time.Minute ||
+		!qi.Enqueue || !qi.Dequeue || qi.MultipleConsumers {
+		t.Errorf("got %+v", qi)
+	}
+
+	if err = godror.StopQueue(ctx, testDb, qName, true); err != nil {
+		t.Fatal(err)
+	}
+	if qi, err = godror.DescribeQueue(ctx, testDb, "", qName); err != nil {
+		t.Fatal(err)
+	} else if qi.Enqueue || qi.Dequeue {
+		t.Errorf("stopped queue: got %+v", qi)
+	}
+	if err = godror.DropQueue(ctx, testDb, qName); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = godror.DescribeQueue(ctx, testDb, "", qName); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("dropped queue: got %+v, wanted %v", err, sql.ErrNoRows)
+	}
+}
+
+func TestPubSub(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("PubSub"), 60*time.Second)
+	defer cancel()
+	topic := strings.ToUpper("test_topic" + tblSuffix)
+	_ = godror.DropQueueTable(ctx, testDb, topic+"_QT", true)
+	if err := godror.CreateTopic(ctx, testDb, topic, "RAW"); err != nil {
+		if strings.Contains(err.Error(), "PLS-00201") || strings.Contains(err.Error(), "ORA-01031:") {
+			t.Skip(err)
+		}
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := godror.DropQueueTable(context.Background(), testDb, topic+"_QT", true); err != nil {
+			t.Error(err)
+		}
+	}()
+	consumers := []string{"SUB_A", "SUB_B"}
+	for _, c := range consumers {
+		if err := godror.AddSubscriber(ctx, testDb, topic, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pub, err := godror.NewPublisher(ctx, testDb, topic, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pub.Close()
+	if err = pub.Publish(godror.Message{Raw: []byte("hello"), Expiration: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range consumers {
+		sub, err := godror.NewSubscriber(ctx, testDb, topic, c, "", time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sub.Close()
+		msgs := make([]godror.Message, 1)
+		n, err := sub.Receive(msgs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 || string(msgs[0].Raw) != "hello" || msgs[0].Redelivered() {
+			t.Fatalf("%s: got %d %+v", c, n, msgs[:n])
+		}
+		// rolled back, so received again
+		if err = sub.Nack(); err != nil {
+			t.Fatal(err)
+		}
+		if n, err = sub.Receive(msgs); err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 || !msgs[0].Redelivered() {
+			t.Errorf("%s: got %d %+v, wanted the redelivered message", c, n, msgs[:n])
+		}
+		if err = sub.Ack(); err != nil {
+			t.Fatal(err)
+		}
+		if n, err = sub.Receive(msgs); err != nil {
+			t.Fatal(err)
+		} else if n != 0 {
+			t.Errorf("%s: got %d messages after Ack", c, n)
+		}
+	}
+}
+
+func TestWithTxQueue(t *testing.T) {
+	ctx, cancel := context.WithTimeout(testContext("WithTxQueue"), 60*time.Second)
+	defer cancel()
+	qTbl, qName := strings.ToUpper("test_txq_tbl"+tblSuffix), strings.ToUpper("test_txq"+tblSuffix)
+	tbl := "test_txq_dst" + tblSuffix
+	_ = godror.DropQueueTable(ctx, testDb, qTbl, true)
+	if err := godror.CreateQueueTable(ctx, testDb, qTbl, "RAW", godror.QueueTableOptions{}); err != nil {
+		if strings.Contains(err.Error(), "PLS-00201") || strings.Contains(err.Error(), "ORA-01031:") {
+			t.Skip(err)
+		}
+		t.Fatal(err)
+	}
+	defer godror.DropQueueTable(context.Background(), testDb, qTbl, true)
+	if err := godror.CreateQueue(ctx, testDb, qName, qTbl, godror.QueueOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := godror.StartQueue(ctx, testDb, qName); err != nil {
+		t.Fatal(err)
+	}
+	testDb.ExecContext(ctx, "DROP TABLE "+tbl)
+	if _, err := testDb.ExecContext(ctx, "CREATE TABLE "+tbl+" (payload VARCHAR2(10))"); err != nil { //nolint:gas
+		t.Fatal(err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+
+	enqOpts := godror.DefaultEnqOptions
+	enqOpts.Visibility = godror.VisibleImmediate
+	q, err := godror.NewQueue(ctx, testDb, qName, "", godror.WithEnqOptions(enqOpts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = q.Enqueue([]godror.Message{{Raw: []byte("order")}})
+	q.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consume := func(commit bool) int {
+		t.Helper()
+		tx, err := testDb.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tx.Rollback()
+		var n int
+		if err = godror.WithTxQueue(ctx, tx, qName, "", godror.DeqOptions{Wait: time.Second}, func(Q *godror.Queue) error {
+			msgs := make([]godror.Message, 1)
+			var err error
+			if n, err = Q.Dequeue(msgs); err != nil || n == 0 {
+				return err
+			}
+			_, err = tx.ExecContext(ctx, "INSERT INTO "+tbl+" (payload) VALUES (:1)", string(msgs[0].Raw)) //nolint:gas
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if commit {
+			if err = tx.Commit(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return n
+	}
+	count := func() int {
+		var n int
+		if err := testDb.QueryRowContext(ctx, "SELECT COUNT(0) FROM "+tbl).Scan(&n); err != nil { //nolint:gas
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	// the rollback returns the message to the queue, and undoes the insert
+	if n := consume(false); n != 1 {
+		t.Fatalf("got %d messages, wanted 1", n)
+	}
+	if n := count(); n != 0 {
+		t.Errorf("got %d rows after rollback", n)
+	}
+	if n := consume(true); n != 1 {
+		t.Fatalf("got %d messages after rollback, wanted 1", n)
+	}
+	if n := count(); n != 1 {
+		t.Errorf("got %d rows after commit, wanted 1", n)
+	}
+	if n := consume(true); n != 0 {
+		t.Errorf("got %d messages after commit, wanted 0", n)
+	}
+}
+
+func TestResetSessionKeepAlive(t *testing.T) {
+	P, err := godror.ParseDSN(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	P.StandaloneConnection = false
+	P.MinSessions, P.MaxSessions = 0, 2
+	// the ticker runs at half the interval, so it is active during the re-acquires below
+	P.KeepAliveInterval = 20 * time.Millisecond
+	db := sql.OpenDB(godror.NewConnector(P))
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	ctx, cancel := context.WithTimeout(testContext("ResetSessionKeepAlive"), 30*time.Second)
+	defer cancel()
+	// each Close returns the session to the pool (IsValid), the next Conn re-acquires it (ResetSession),
+	// while the keepalive of the same conn may ping - run with -race
+	for i := 0; i < 50; i++ {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatalf("%d. %+v", i, err)
+		}
+		var n int
+		err = conn.QueryRowContext(ctx, "SELECT 1 FROM DUAL").Scan(&n)
+		conn.Close()
+		if err != nil {
+			t.Fatalf("%d. %+v", i, err)
+		}
+		time.Sleep(time.Duration(i%4) * 10 * time.Millisecond)
+	}
+}